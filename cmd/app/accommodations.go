@@ -0,0 +1,198 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/coraxwolf/CCTA_3-4/pkg/canvas"
+)
+
+// Accommodation is one row of the accommodations CSV: a student and the extra quiz time they are approved for.
+type Accommodation struct {
+	SISUserID  string
+	CourseID   int     // 0 checks every course the student is enrolled in
+	Minutes    int     // fixed extra minutes, or
+	Multiplier float64 // time limit multiplier, e.g. 1.5
+}
+
+// ExtraTime returns the extra minutes the student needs on a quiz with the given time limit.
+func (a Accommodation) ExtraTime(timeLimit int) int {
+	if a.Multiplier > 0 {
+		return int(math.Ceil(float64(timeLimit) * (a.Multiplier - 1)))
+	}
+	return a.Minutes
+}
+
+type AccommodationItem struct {
+	CourseID      int    `json:"course_id" csv:"course_id"`
+	CourseName    string `json:"course_name" csv:"course_name"`
+	Modality      string `json:"modality" csv:"modality"`
+	QuizID        int    `json:"quiz_id" csv:"quiz_id"`
+	QuizTitle     string `json:"quiz_title" csv:"quiz_title"`
+	TimeLimit     int    `json:"time_limit" csv:"time_limit"`
+	SISUserID     string `json:"sis_user_id" csv:"sis_user_id"`
+	StudentName   string `json:"student_name" csv:"student_name"`
+	RequiredExtra int    `json:"required_extra_time" csv:"required_extra_time"`
+	ActualExtra   int    `json:"actual_extra_time" csv:"actual_extra_time"`
+	Issue         string `json:"issue" csv:"issue"`
+}
+
+const accommodationsSummary = "Check that students with approved extra time have it on every timed quiz"
+
+func init() {
+	register(command{Group: "quizzes", Name: "accommodations", Summary: accommodationsSummary, Run: runAccommodations})
+}
+
+func runAccommodations(args []string) error {
+	var opts commonOptions
+	fs := newFlagSet("quizzes accommodations", accommodationsSummary)
+	addOutputFlags(fs, &opts, "")
+	file := fs.String("file", "", "CSV with sis_user_id and extra_time (minutes, or a multiplier like 1.5x) columns, and an optional course_id column (required)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *file == "" {
+		return fmt.Errorf("--file is required")
+	}
+	accommodations, err := readAccommodations(*file)
+	if err != nil {
+		return err
+	}
+	modalities, err := NewModalityClassifierFromEnv()
+	if err != nil {
+		return fmt.Errorf("error loading modality rules: %w", err)
+	}
+
+	var results []AccommodationItem
+	quizzes := make(map[int][]canvas.Quiz) // by course
+	courseNames := make(map[int]string)
+	courseModalities := make(map[int]string)
+	submissions := make(map[int]map[int]canvas.QuizSubmission) // by quiz, then user
+	for _, acc := range accommodations {
+		enrollments, err := api.Enrollments().ListUserEnrollments("sis_user_id:"+acc.SISUserID, canvas.EnrollmentListOptions{
+			Types: []string{"StudentEnrollment"},
+		})
+		if err != nil {
+			results = append(results, AccommodationItem{SISUserID: acc.SISUserID, Issue: "Error: " + withHint(err)})
+			continue
+		}
+		checked := make(map[int]bool)
+		for _, e := range enrollments {
+			if checked[e.CourseID] || (acc.CourseID != 0 && e.CourseID != acc.CourseID) {
+				continue
+			}
+			checked[e.CourseID] = true
+			courseQuizzes, ok := quizzes[e.CourseID]
+			if !ok {
+				courseQuizzes, err = api.Quizzes().ListQuizzes(e.CourseID)
+				if err != nil {
+					results = append(results, AccommodationItem{CourseID: e.CourseID, SISUserID: acc.SISUserID, Issue: "Error: " + withHint(err)})
+					continue
+				}
+				quizzes[e.CourseID] = courseQuizzes
+				if course, err := api.Courses().GetCourse(e.CourseID); err == nil {
+					courseNames[e.CourseID] = course.Name
+					courseModalities[e.CourseID] = modalities.Classify(*course)
+				}
+			}
+			for _, quiz := range courseQuizzes {
+				if quiz.TimeLimit == nil || *quiz.TimeLimit == 0 || !quiz.Published {
+					continue
+				}
+				byUser, ok := submissions[quiz.ID]
+				if !ok {
+					subs, err := api.Quizzes().ListQuizSubmissions(e.CourseID, quiz.ID)
+					if err != nil {
+						results = append(results, AccommodationItem{CourseID: e.CourseID, CourseName: courseNames[e.CourseID], Modality: courseModalities[e.CourseID], QuizID: quiz.ID, QuizTitle: quiz.Title, SISUserID: acc.SISUserID, Issue: "Error: " + withHint(err)})
+						continue
+					}
+					byUser = make(map[int]canvas.QuizSubmission)
+					for _, sub := range subs {
+						byUser[sub.UserID] = sub
+					}
+					submissions[quiz.ID] = byUser
+				}
+				required := acc.ExtraTime(*quiz.TimeLimit)
+				actual := byUser[e.UserID].ExtraTime
+				if actual >= required {
+					continue
+				}
+				results = append(results, AccommodationItem{
+					CourseID:      e.CourseID,
+					CourseName:    courseNames[e.CourseID],
+					Modality:      courseModalities[e.CourseID],
+					QuizID:        quiz.ID,
+					QuizTitle:     quiz.Title,
+					TimeLimit:     *quiz.TimeLimit,
+					SISUserID:     acc.SISUserID,
+					StudentName:   e.User.Name,
+					RequiredExtra: required,
+					ActualExtra:   actual,
+					Issue:         "missing extra time",
+				})
+			}
+		}
+	}
+	fmt.Fprintf(os.Stderr, "Found %d accommodation gaps\n", len(results))
+	return opts.writeRows("accommodation_gaps", results)
+}
+
+func readAccommodations(file string) ([]Accommodation, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, fmt.Errorf("error opening accommodations file %s: %w", file, err)
+	}
+	defer f.Close()
+	reader := csv.NewReader(f)
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("error reading header from %s: %w", file, err)
+	}
+	sisCol, timeCol, courseCol := -1, -1, -1
+	for i, col := range header {
+		switch strings.TrimSpace(strings.ToLower(col)) {
+		case "sis_user_id":
+			sisCol = i
+		case "extra_time":
+			timeCol = i
+		case "course_id":
+			courseCol = i
+		}
+	}
+	if sisCol < 0 || timeCol < 0 {
+		return nil, fmt.Errorf("accommodations file %s needs sis_user_id and extra_time columns", file)
+	}
+	var accommodations []Accommodation
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error reading %s: %w", file, err)
+		}
+		acc := Accommodation{SISUserID: strings.TrimSpace(record[sisCol])}
+		extra := strings.ToLower(strings.TrimSpace(record[timeCol]))
+		if m, ok := strings.CutSuffix(extra, "x"); ok {
+			acc.Multiplier, err = strconv.ParseFloat(m, 64)
+		} else {
+			acc.Minutes, err = strconv.Atoi(extra)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid extra_time %q on line %d of %s", record[timeCol], line, file)
+		}
+		if courseCol >= 0 && strings.TrimSpace(record[courseCol]) != "" {
+			acc.CourseID, err = strconv.Atoi(strings.TrimSpace(record[courseCol]))
+			if err != nil {
+				return nil, fmt.Errorf("invalid course_id %q on line %d of %s", record[courseCol], line, file)
+			}
+		}
+		accommodations = append(accommodations, acc)
+	}
+	return accommodations, nil
+}
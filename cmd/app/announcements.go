@@ -0,0 +1,202 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/coraxwolf/CCTA_3-4/pkg/canvas"
+)
+
+type AnnouncementItem struct {
+	CourseID    int    `json:"course_id" csv:"course_id"`
+	CourseName  string `json:"course_name" csv:"course_name"`
+	Modality    string `json:"modality" csv:"modality"`
+	CourseState string `json:"course_state" csv:"course_state"`
+	TopicID     int    `json:"topic_id" csv:"topic_id"`
+	Title       string `json:"title" csv:"title"`
+	Status      string `json:"status" csv:"status"` // audit: posted, delayed, unpublished, none, or error; post: posted, scheduled, would_post, skipped, or error
+	PostAt      string `json:"post_at" csv:"post_at"`
+	URL         string `json:"url" csv:"url"`
+	Detail      string `json:"detail" csv:"detail"`
+}
+
+const (
+	announcementsAuditSummary = "List the announcements drafted in a term's unpublished courses"
+	announcementsPostSummary  = "Post or schedule the same announcement in every course of a term"
+)
+
+func init() {
+	register(command{Group: "announcements", Name: "audit", Summary: announcementsAuditSummary, Run: runAnnouncementsAudit})
+	register(command{Group: "announcements", Name: "post", Summary: announcementsPostSummary, Run: runAnnouncementsPost})
+}
+
+func runAnnouncementsAudit(args []string) error {
+	var opts commonOptions
+	fs := newFlagSet("announcements audit", announcementsAuditSummary)
+	addTermFlags(fs, &opts)
+	addAccountFlags(fs, &opts)
+	addOutputFlags(fs, &opts, "")
+	all := fs.Bool("all", false, "audit published courses too")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	modalities, err := NewModalityClassifierFromEnv()
+	if err != nil {
+		return fmt.Errorf("error loading modality rules: %w", err)
+	}
+	courses, err := opts.termCourses(canvas.CourseListOptions{})
+	if err != nil {
+		return fmt.Errorf("error fetching courses: %w", err)
+	}
+	var results []AnnouncementItem
+	var audited, without int
+	for _, course := range courses {
+		if !*all && course.WorkflowState != "unpublished" {
+			continue
+		}
+		audited++
+		base := AnnouncementItem{CourseID: course.ID, CourseName: course.Name, Modality: modalities.Classify(course), CourseState: course.WorkflowState}
+		topics, err := api.Discussions().ListCourseAnnouncements(course.ID)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error fetching announcements for course %d: %s\n", course.ID, withHint(err))
+			base.Status, base.Detail = "error", withHint(err)
+			results = append(results, base)
+			continue
+		}
+		if len(topics) == 0 {
+			without++
+			base.Status, base.Detail = "none", "no announcement drafted"
+			results = append(results, base)
+			continue
+		}
+		for _, t := range topics {
+			item := base
+			item.TopicID, item.Title, item.URL = t.ID, t.Title, t.HTMLURL
+			switch {
+			case !t.Published:
+				item.Status = "unpublished"
+			case t.Delayed():
+				item.Status, item.PostAt = "delayed", t.DelayedPostAt.Format(time.RFC3339)
+			default:
+				item.Status = "posted"
+				if t.PostedAt != nil {
+					item.PostAt = t.PostedAt.Format(time.RFC3339)
+				}
+			}
+			results = append(results, item)
+		}
+	}
+	fmt.Fprintf(os.Stderr, "Audited %d courses, %d without announcements\n", audited, without)
+	return opts.writeRows(opts.Term+"_announcements", results)
+}
+
+func runAnnouncementsPost(args []string) error {
+	var opts commonOptions
+	fs := newFlagSet("announcements post", announcementsPostSummary)
+	addTermFlags(fs, &opts)
+	addAccountFlags(fs, &opts)
+	addOutputFlags(fs, &opts, "")
+	title := fs.String("title", "", "announcement title (required)")
+	message := fs.String("message", "", "announcement HTML; {course_name} and {course_code} are replaced per course")
+	messageFile := fs.String("message-file", "", "read the announcement HTML from a file instead of --message")
+	delay := fs.String("delay-until", "", "schedule the announcement for this time (RFC 3339 or YYYY-MM-DD) instead of posting it now")
+	published := fs.Bool("published-only", false, "skip unpublished courses")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *title == "" {
+		return fmt.Errorf("--title is required")
+	}
+	if *messageFile != "" {
+		data, err := os.ReadFile(*messageFile)
+		if err != nil {
+			return fmt.Errorf("error reading --message-file: %w", err)
+		}
+		*message = string(data)
+	}
+	if strings.TrimSpace(*message) == "" {
+		return fmt.Errorf("--message or --message-file is required")
+	}
+	var postAt *time.Time
+	if *delay != "" {
+		t, err := parseCourseDate(*delay)
+		if err != nil {
+			return fmt.Errorf("invalid --delay-until %q: %w", *delay, err)
+		}
+		postAt = &t
+	}
+	modalities, err := NewModalityClassifierFromEnv()
+	if err != nil {
+		return fmt.Errorf("error loading modality rules: %w", err)
+	}
+	list := canvas.CourseListOptions{}
+	if *published {
+		list.Published = published
+	}
+	courses, err := opts.termCourses(list)
+	if err != nil {
+		return fmt.Errorf("error fetching courses: %w", err)
+	}
+
+	var results []AnnouncementItem
+	var failed int
+	discussions := api.Discussions()
+	for _, course := range courses {
+		item := AnnouncementItem{CourseID: course.ID, CourseName: course.Name, Modality: modalities.Classify(course), CourseState: course.WorkflowState, Title: *title}
+		// a course that already has the announcement is skipped, so a run that stopped part way can be repeated
+		existing, err := discussions.ListCourseAnnouncements(course.ID)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error fetching announcements for course %d: %s\n", course.ID, withHint(err))
+			item.Status, item.Detail = "error", withHint(err)
+			results = append(results, item)
+			failed++
+			continue
+		}
+		if t := findAnnouncement(existing, *title); t != nil {
+			item.TopicID, item.URL = t.ID, t.HTMLURL
+			item.Status, item.Detail = "skipped", "course already has an announcement with this title"
+			results = append(results, item)
+			continue
+		}
+		body := strings.NewReplacer("{course_name}", course.Name, "{course_code}", course.CourseCode).Replace(*message)
+		topic, err := discussions.CreateAnnouncement(course.ID, canvas.DiscussionTopicInput{Title: title, Message: &body, DelayedPostAt: postAt})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error posting announcement in course %d: %s\n", course.ID, withHint(err))
+			item.Status, item.Detail = "error", withHint(err)
+			results = append(results, item)
+			failed++
+			continue
+		}
+		item.TopicID, item.URL = topic.ID, topic.HTMLURL
+		switch {
+		case api.DryRun():
+			item.Status = "would_post"
+		case postAt != nil:
+			item.Status = "scheduled"
+		default:
+			item.Status = "posted"
+		}
+		if postAt != nil {
+			item.PostAt = postAt.Format(time.RFC3339)
+		}
+		results = append(results, item)
+	}
+	if err := opts.writeRows(opts.Term+"_announcements_posted", results); err != nil {
+		return err
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d courses could not be updated", failed, len(courses))
+	}
+	return nil
+}
+
+func findAnnouncement(topics []canvas.DiscussionTopic, title string) *canvas.DiscussionTopic {
+	for i, t := range topics {
+		if strings.EqualFold(strings.TrimSpace(t.Title), strings.TrimSpace(title)) {
+			return &topics[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"time"
+
+	"github.com/coraxwolf/CCTA_3-4/pkg/canvas"
+)
+
+type BlueprintCourseItem struct {
+	BlueprintID int    `json:"blueprint_id" csv:"blueprint_id"`
+	CourseID    int    `json:"course_id" csv:"course_id"`
+	CourseName  string `json:"course_name" csv:"course_name"`
+	SISCourseID string `json:"sis_course_id" csv:"sis_course_id"`
+	Status      string `json:"status" csv:"status"` // associated, would_associate, already_associated, skipped, or error
+	Detail      string `json:"detail" csv:"detail"`
+}
+
+const (
+	blueprintSyncSummary   = "Associate a term's matching courses with a blueprint and sync its content to them"
+	blueprintStatusSummary = "List a blueprint's associated courses and its latest sync"
+)
+
+func init() {
+	register(command{Group: "blueprint", Name: "sync", Summary: blueprintSyncSummary, Run: runBlueprintSync})
+	register(command{Group: "blueprint", Name: "status", Summary: blueprintStatusSummary, Run: runBlueprintStatus})
+}
+
+func runBlueprintSync(args []string) error {
+	var opts commonOptions
+	fs := newFlagSet("blueprint sync", blueprintSyncSummary)
+	addTermFlags(fs, &opts)
+	addAccountFlags(fs, &opts)
+	addOutputFlags(fs, &opts, "")
+	blueprintID := fs.Int("blueprint", 0, "Canvas ID of the blueprint course (required)")
+	match := fs.String("match", "", "with --term, associate the courses whose SIS course ID matches this regular expression, e.g. -ENGL-101")
+	sync := fs.Bool("sync", true, "start a sync after updating the associations")
+	comment := fs.String("comment", "", "comment recorded with the sync")
+	notify := fs.Bool("notify", false, "notify the blueprint's users when the sync completes")
+	copySettings := fs.Bool("copy-settings", false, "also push the blueprint's course settings")
+	publish := fs.Bool("publish", false, "publish newly associated courses after their first sync")
+	wait := fs.Bool("wait", true, "wait for the sync to finish")
+	interval := fs.Duration("interval", 15*time.Second, "how often to check the sync while waiting")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *blueprintID == 0 {
+		return fmt.Errorf("--blueprint is required")
+	}
+	if (opts.Term == "") != (*match == "") {
+		return fmt.Errorf("--term and --match go together")
+	}
+	blueprints := api.Blueprints()
+	template, err := blueprints.GetTemplate(*blueprintID)
+	if err != nil {
+		return fmt.Errorf("course %d is not a blueprint or can't be read: %w", *blueprintID, err)
+	}
+
+	var results []BlueprintCourseItem
+	if opts.Term != "" {
+		re, err := regexp.Compile(*match)
+		if err != nil {
+			return fmt.Errorf("invalid --match: %w", err)
+		}
+		associated, err := blueprints.ListAssociatedCourses(*blueprintID)
+		if err != nil {
+			return err
+		}
+		already := make(map[int]bool, len(associated))
+		for _, c := range associated {
+			already[c.ID] = true
+		}
+		courses, err := opts.termCourses(canvas.CourseListOptions{})
+		if err != nil {
+			return fmt.Errorf("error fetching courses: %w", err)
+		}
+		var add []int
+		for _, course := range courses {
+			if !re.MatchString(course.SISCourseID) {
+				continue
+			}
+			item := BlueprintCourseItem{BlueprintID: *blueprintID, CourseID: course.ID, CourseName: course.Name, SISCourseID: course.SISCourseID}
+			switch {
+			case course.ID == *blueprintID || course.Blueprint:
+				item.Status, item.Detail = "skipped", "course is a blueprint"
+			case already[course.ID]:
+				item.Status = "already_associated"
+			default:
+				item.Status = "pending"
+				add = append(add, course.ID)
+			}
+			results = append(results, item)
+		}
+		// one request for every course: Canvas applies all of the associations or none
+		err = blueprints.AddAssociations(*blueprintID, add...)
+		for i := range results {
+			if results[i].Status != "pending" {
+				continue
+			}
+			switch {
+			case err != nil:
+				results[i].Status, results[i].Detail = "error", withHint(err)
+			case api.DryRun():
+				results[i].Status = "would_associate"
+			default:
+				results[i].Status = "associated"
+			}
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error associating %d courses with blueprint %d: %s\n", len(add), *blueprintID, withHint(err))
+		} else {
+			fmt.Fprintf(os.Stderr, "Associated %d new courses with blueprint %d (%d already associated)\n", len(add), *blueprintID, len(associated))
+		}
+		if err := opts.writeRows(fmt.Sprintf("blueprint_%d_associations", *blueprintID), results); err != nil {
+			return err
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if !*sync {
+		return nil
+	}
+
+	migration, err := blueprints.StartSync(*blueprintID, canvas.BlueprintSyncOptions{
+		Comment:                 *comment,
+		SendNotification:        *notify,
+		CopySettings:            *copySettings,
+		PublishAfterInitialSync: *publish,
+	})
+	if err != nil {
+		return err
+	}
+	if api.DryRun() {
+		fmt.Fprintf(os.Stderr, "Dry run: blueprint %d was not synced to its %d courses\n", *blueprintID, template.AssociatedCourseCount)
+		return nil
+	}
+	fmt.Fprintf(os.Stderr, "Started sync %d of blueprint %d\n", migration.ID, *blueprintID)
+	if !*wait {
+		return nil
+	}
+	migration, err = blueprints.WaitMigration(*blueprintID, migration.ID, *interval)
+	if err != nil {
+		return err
+	}
+	if migration.Failed() {
+		return fmt.Errorf("sync %d of blueprint %d ended as %s; see the blueprint's sync history in Canvas", migration.ID, *blueprintID, migration.WorkflowState)
+	}
+	fmt.Fprintf(os.Stderr, "Sync %d of blueprint %d completed\n", migration.ID, *blueprintID)
+	return nil
+}
+
+func runBlueprintStatus(args []string) error {
+	var opts commonOptions
+	fs := newFlagSet("blueprint status", blueprintStatusSummary)
+	addOutputFlags(fs, &opts, "")
+	blueprintID := fs.Int("blueprint", 0, "Canvas ID of the blueprint course (required)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *blueprintID == 0 {
+		return fmt.Errorf("--blueprint is required")
+	}
+	blueprints := api.Blueprints()
+	template, err := blueprints.GetTemplate(*blueprintID)
+	if err != nil {
+		return err
+	}
+	if m := template.LatestMigration; m != nil {
+		started := ""
+		if m.CreatedAt != nil {
+			started = " started " + m.CreatedAt.Format(time.RFC3339)
+		}
+		fmt.Fprintf(os.Stderr, "Latest sync %d%s: %s\n", m.ID, started, m.WorkflowState)
+	} else {
+		fmt.Fprintf(os.Stderr, "Blueprint %d has never been synced\n", *blueprintID)
+	}
+	courses, err := blueprints.ListAssociatedCourses(*blueprintID)
+	if err != nil {
+		return err
+	}
+	sort.Slice(courses, func(i, j int) bool { return courses[i].SISCourseID < courses[j].SISCourseID })
+	results := make([]BlueprintCourseItem, 0, len(courses))
+	for _, c := range courses {
+		results = append(results, BlueprintCourseItem{BlueprintID: *blueprintID, CourseID: c.ID, CourseName: c.Name, SISCourseID: c.SISCourseID, Status: "associated"})
+	}
+	return opts.writeRows(fmt.Sprintf("blueprint_%d_courses", *blueprintID), results)
+}
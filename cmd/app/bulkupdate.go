@@ -0,0 +1,333 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/coraxwolf/CCTA_3-4/pkg/canvas"
+)
+
+// bulkColumns are the course settings a bulk update can change, in manifest column order.
+var bulkColumns = []string{"name", "course_code", "start_at", "default_view"}
+
+var defaultViews = []string{"feed", "wiki", "modules", "syllabus", "assignments"}
+
+// clearValue in a start_at cell removes the date. Undo manifests use it for courses that had none.
+const clearValue = "none"
+
+// CourseChange is one row of the bulk update file: the settings to set on a course.
+// Settings that are not in the file or are blank are left as they are.
+type CourseChange struct {
+	Line     int
+	CourseID int
+	Settings map[string]string
+}
+
+type BulkUpdateItem struct {
+	CourseID int    `json:"course_id" csv:"course_id"`
+	Field    string `json:"field" csv:"field"`
+	Old      string `json:"old" csv:"old"`
+	New      string `json:"new" csv:"new"`
+	Status   string `json:"status" csv:"status"` // updated, would_update, unchanged, or error
+	Error    string `json:"error" csv:"error"`
+}
+
+const coursesBulkUpdateSummary = "Apply course setting changes from a CSV concurrently, with an undo manifest"
+
+func init() {
+	register(command{Group: "courses", Name: "bulk-update", Summary: coursesBulkUpdateSummary, Run: runCoursesBulkUpdate})
+}
+
+func runCoursesBulkUpdate(args []string) error {
+	var opts commonOptions
+	fs := newFlagSet("courses bulk-update", coursesBulkUpdateSummary)
+	addOutputFlags(fs, &opts, "")
+	file := fs.String("file", "", "CSV with a course_id column and any of "+strings.Join(bulkColumns, ", ")+" (required)")
+	undo := fs.String("undo", "", "where to write the undo manifest (default bulk_update_undo_<time>.csv in the output directory)")
+	workers := fs.Int("workers", 4, "courses to update at once")
+	sticky := addStickyFlag(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *file == "" {
+		return fmt.Errorf("--file is required")
+	}
+	changes, err := readCourseChanges(*file)
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+	pool := canvas.NewPool(api, *workers)
+
+	// fetch every course first, both to skip settings that are already right and for the undo manifest
+	gets := make([]canvas.Request, len(changes))
+	for i, change := range changes {
+		gets[i] = canvas.Request{Key: strconv.Itoa(change.CourseID), Endpoint: fmt.Sprintf("courses/%d", change.CourseID)}
+	}
+	current := pool.Do(ctx, gets)
+
+	var puts []canvas.Request
+	undoRows := [][]string{append([]string{"course_id"}, bulkColumns...)}
+	planned := make(map[string][]BulkUpdateItem)
+	for i, change := range changes {
+		key := strconv.Itoa(change.CourseID)
+		var course canvas.Course
+		if err := decodeResult(gets[i], current[key], &course); err != nil {
+			planned[key] = []BulkUpdateItem{{CourseID: change.CourseID, Status: "error", Error: withHint(err)}}
+			continue
+		}
+		fields := make(map[string]any)
+		undoRow := make([]string, len(bulkColumns)+1)
+		undoRow[0] = key
+		for i, col := range bulkColumns {
+			value, ok := change.Settings[col]
+			if !ok {
+				continue
+			}
+			old := courseSetting(course, col)
+			item := BulkUpdateItem{CourseID: change.CourseID, Field: col, Old: old, New: value, Status: "unchanged"}
+			if !sameSetting(col, old, value) {
+				fields[col] = settingValue(col, value)
+				undoRow[i+1] = old
+				if old == "" && col == "start_at" {
+					undoRow[i+1] = clearValue
+				}
+				item.Status = "pending"
+			}
+			planned[key] = append(planned[key], item)
+		}
+		if len(fields) == 0 {
+			continue
+		}
+		body := map[string]any{"course": fields}
+		if *sticky {
+			body["override_sis_stickiness"] = true
+		}
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("error encoding update for course %d: %w", change.CourseID, err)
+		}
+		puts = append(puts, canvas.Request{Key: key, Method: http.MethodPut, Endpoint: "courses/" + key, Body: data})
+		undoRows = append(undoRows, undoRow)
+	}
+
+	if len(puts) > 0 && !api.DryRun() {
+		path := *undo
+		if path == "" {
+			path = filepath.Join(opts.Output, "bulk_update_undo_"+time.Now().Format("20060102_150405")+".csv")
+		}
+		// written before anything changes, so a run that stops part way can still be undone
+		if err := writeCSVFile(path, undoRows); err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "Undo manifest written to %s (apply it with --file to revert)\n", path)
+	}
+	updated := pool.Do(ctx, puts)
+	var results []BulkUpdateItem
+	for _, change := range changes {
+		key := strconv.Itoa(change.CourseID)
+		res, sent := updated[key]
+		err := decodeResult(canvas.Request{Method: http.MethodPut, Endpoint: "courses/" + key}, res, nil)
+		for _, item := range planned[key] {
+			switch {
+			case !sent || item.Status != "pending":
+			case err != nil:
+				item.Status, item.Error = "error", withHint(err)
+			case canvas.IsDryRunResult(res):
+				item.Status = "would_update"
+			default:
+				item.Status = "updated"
+			}
+			results = append(results, item)
+		}
+	}
+	return opts.writeRows("bulk_update", results)
+}
+
+// decodeResult returns the Canvas error for a failed pool result, or decodes its body into v.
+func decodeResult(req canvas.Request, res canvas.Result, v any) error {
+	if res.Err != nil {
+		return res.Err
+	}
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		method := req.Method
+		if method == "" {
+			method = http.MethodGet
+		}
+		return canvas.NewAPIError(&http.Response{
+			StatusCode: res.StatusCode,
+			Header:     res.Header,
+			Body:       io.NopCloser(bytes.NewReader(res.Body)),
+			Request:    &http.Request{Method: method, URL: &url.URL{Path: req.Endpoint}},
+		})
+	}
+	if v == nil {
+		return nil
+	}
+	return json.Unmarshal(res.Body, v)
+}
+
+func courseSetting(course canvas.Course, col string) string {
+	switch col {
+	case "name":
+		return course.Name
+	case "course_code":
+		return course.CourseCode
+	case "default_view":
+		return course.DefaultView
+	case "start_at":
+		if course.StartAt != nil {
+			return course.StartAt.Format(time.RFC3339)
+		}
+	}
+	return ""
+}
+
+func sameSetting(col, old, value string) bool {
+	if col != "start_at" {
+		return old == value
+	}
+	if value == clearValue {
+		return old == ""
+	}
+	a, errA := parseCourseDate(old)
+	b, errB := parseCourseDate(value)
+	return errA == nil && errB == nil && a.Equal(b)
+}
+
+// settingValue is the value to send for a setting; a cleared date is sent as null.
+func settingValue(col, value string) any {
+	if col == "start_at" {
+		if value == clearValue {
+			return nil
+		}
+		t, _ := parseCourseDate(value) // validated when the file is read
+		return t.Format(time.RFC3339)
+	}
+	return value
+}
+
+// parseCourseDate accepts RFC 3339 times and plain dates, which are midnight local time.
+func parseCourseDate(s string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	return time.ParseInLocation(time.DateOnly, s, time.Local)
+}
+
+// readCourseChanges reads and validates the whole file, so a bad row stops the run before anything changes.
+func readCourseChanges(file string) ([]CourseChange, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, fmt.Errorf("error opening bulk update file %s: %w", file, err)
+	}
+	defer f.Close()
+	reader := csv.NewReader(f)
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("error reading header from %s: %w", file, err)
+	}
+	cols := make(map[string]int)
+	for i, col := range header {
+		col = strings.TrimSpace(strings.ToLower(col))
+		if col != "course_id" && !slices.Contains(bulkColumns, col) {
+			return nil, fmt.Errorf("bulk update file %s has unknown column %q; use course_id and %s", file, col, strings.Join(bulkColumns, ", "))
+		}
+		cols[col] = i
+	}
+	if _, ok := cols["course_id"]; !ok {
+		return nil, fmt.Errorf("bulk update file %s needs a course_id column", file)
+	}
+
+	var changes []CourseChange
+	var problems []error
+	seen := make(map[int]int)
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error reading %s: %w", file, err)
+		}
+		change := CourseChange{Line: line, Settings: make(map[string]string)}
+		for col, i := range cols {
+			value := ""
+			if i < len(record) {
+				value = strings.TrimSpace(record[i])
+			}
+			if col == "course_id" {
+				change.CourseID, err = strconv.Atoi(value)
+				if err != nil || change.CourseID <= 0 {
+					problems = append(problems, fmt.Errorf("line %d: invalid course_id %q", line, value))
+				}
+				continue
+			}
+			if value == "" {
+				continue
+			}
+			if err := validateSetting(col, value); err != nil {
+				problems = append(problems, fmt.Errorf("line %d: %w", line, err))
+			}
+			change.Settings[col] = value
+		}
+		if first, ok := seen[change.CourseID]; ok && change.CourseID > 0 {
+			problems = append(problems, fmt.Errorf("line %d: course %d is also on line %d", line, change.CourseID, first))
+		}
+		seen[change.CourseID] = line
+		changes = append(changes, change)
+	}
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("%s has %d invalid rows, nothing was changed:\n%w", file, len(problems), errors.Join(problems...))
+	}
+	return changes, nil
+}
+
+func validateSetting(col, value string) error {
+	switch col {
+	case "start_at":
+		if value == clearValue {
+			return nil
+		}
+		if _, err := parseCourseDate(value); err != nil {
+			return fmt.Errorf("invalid start_at %q, expected YYYY-MM-DD, an RFC 3339 time, or %s", value, clearValue)
+		}
+	case "default_view":
+		if !slices.Contains(defaultViews, value) {
+			return fmt.Errorf("invalid default_view %q, expected one of %s", value, strings.Join(defaultViews, ", "))
+		}
+	}
+	return nil
+}
+
+func writeCSVFile(path string, rows [][]string) error {
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return fmt.Errorf("error creating directory for %s: %w", path, err)
+		}
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("error creating %s: %w", path, err)
+	}
+	w := csv.NewWriter(f)
+	w.WriteAll(rows)
+	if err := w.Error(); err != nil {
+		f.Close()
+		return fmt.Errorf("error writing %s: %w", path, err)
+	}
+	return f.Close()
+}
@@ -0,0 +1,210 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/coraxwolf/CCTA_3-4/pkg/canvas"
+)
+
+type SectionCapItem struct {
+	SectionSISID string  `json:"section_sis_id" csv:"section_sis_id"`
+	SectionID    int     `json:"section_id" csv:"section_id"`
+	SectionName  string  `json:"section_name" csv:"section_name"`
+	CourseID     int     `json:"course_id" csv:"course_id"`
+	CourseName   string  `json:"course_name" csv:"course_name"`
+	Modality     string  `json:"modality" csv:"modality"`
+	Enrolled     int     `json:"enrolled" csv:"enrolled"`
+	SISEnrolled  *int    `json:"sis_enrolled" csv:"sis_enrolled"`
+	Capacity     int     `json:"capacity" csv:"capacity"`
+	FillPercent  float64 `json:"fill_percent" csv:"fill_percent"`
+	Status       string  `json:"status" csv:"status"` // over_capacity, empty, count_mismatch, not_in_canvas, no_capacity, ok, or error
+	Detail       string  `json:"detail" csv:"detail"`
+}
+
+// sectionCap is one row of the SIS capacity data.
+type sectionCap struct {
+	capacity int
+	enrolled *int // the SIS enrollment count, when the source has one
+}
+
+const sectionCapsSummary = "Compare section enrollment counts with SIS capacities and flag over-capacity and empty sections"
+
+func init() {
+	register(command{Group: "sections", Name: "caps", Summary: sectionCapsSummary, Run: runSectionCaps})
+}
+
+func runSectionCaps(args []string) error {
+	var opts commonOptions
+	fs := newFlagSet("sections caps", sectionCapsSummary)
+	addTermFlags(fs, &opts)
+	addAccountFlags(fs, &opts)
+	addOutputFlags(fs, &opts, "")
+	source := fs.String("caps", os.Getenv("SECTION_CAPS_SOURCE"), "SIS capacity CSV file or http(s) URL with section_sis_id, capacity, and optional enrolled columns (default SECTION_CAPS_SOURCE)")
+	all := fs.Bool("all", false, "list every section, not only the flagged ones")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *source == "" {
+		return fmt.Errorf("--caps is required")
+	}
+	modalities, err := NewModalityClassifierFromEnv()
+	if err != nil {
+		return fmt.Errorf("error loading modality rules: %w", err)
+	}
+	caps, err := readSectionCaps(*source)
+	if err != nil {
+		return err
+	}
+	courses, err := opts.termCourses(canvas.CourseListOptions{})
+	if err != nil {
+		return fmt.Errorf("error fetching courses: %w", err)
+	}
+
+	var results []SectionCapItem
+	seen := make(map[string]bool)
+	for _, course := range courses {
+		modality := modalities.Classify(course)
+		sections, err := api.Sections().ListCourseSections(course.ID, "total_students")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error fetching sections for course %d: %s\n", course.ID, withHint(err))
+			results = append(results, SectionCapItem{CourseID: course.ID, CourseName: course.Name, Modality: modality, Status: "error", Detail: withHint(err)})
+			continue
+		}
+		for _, section := range sections {
+			if section.SISSectionID == "" {
+				continue // sections made in Canvas have no SIS capacity
+			}
+			seen[section.SISSectionID] = true
+			item := SectionCapItem{
+				SectionSISID: section.SISSectionID,
+				SectionID:    section.ID,
+				SectionName:  section.Name,
+				CourseID:     course.ID,
+				CourseName:   course.Name,
+				Modality:     modality,
+				Enrolled:     section.TotalStudents,
+			}
+			sisCap, ok := caps[section.SISSectionID]
+			if !ok {
+				item.Status, item.Detail = "no_capacity", "section is not in the SIS capacity data"
+			} else {
+				item.Capacity, item.SISEnrolled = sisCap.capacity, sisCap.enrolled
+				if item.Capacity > 0 {
+					item.FillPercent = math.Round(1000*float64(item.Enrolled)/float64(item.Capacity)) / 10
+				}
+				item.Status, item.Detail = capStatus(item)
+			}
+			if *all || item.Status != "ok" {
+				results = append(results, item)
+			}
+		}
+	}
+
+	// sections in the SIS data but not in the term's courses, e.g. never created or cross-listed out of the term
+	var missing []string
+	for sisID := range caps {
+		if !seen[sisID] {
+			missing = append(missing, sisID)
+		}
+	}
+	sort.Strings(missing)
+	for _, sisID := range missing {
+		results = append(results, SectionCapItem{SectionSISID: sisID, Capacity: caps[sisID].capacity, SISEnrolled: caps[sisID].enrolled, Status: "not_in_canvas", Detail: "section is not in the term's Canvas courses"})
+	}
+	fmt.Fprintf(os.Stderr, "Checked %d courses, %d sections in the SIS data\n", len(courses), len(caps))
+	return opts.writeRows(opts.Term+"_section_caps", results)
+}
+
+// capStatus classifies a section that has SIS capacity data and explains the status.
+func capStatus(item SectionCapItem) (string, string) {
+	switch {
+	case item.Capacity > 0 && item.Enrolled > item.Capacity:
+		return "over_capacity", fmt.Sprintf("%d students over the capacity of %d", item.Enrolled-item.Capacity, item.Capacity)
+	case item.Enrolled == 0:
+		return "empty", "no students enrolled; candidate for cancellation"
+	case item.SISEnrolled != nil && *item.SISEnrolled != item.Enrolled:
+		return "count_mismatch", fmt.Sprintf("Canvas has %d students and the SIS %d", item.Enrolled, *item.SISEnrolled)
+	}
+	return "ok", ""
+}
+
+// readSectionCaps reads the SIS capacity data from a CSV file or URL, keyed by SIS section ID.
+func readSectionCaps(source string) (map[string]sectionCap, error) {
+	var r io.Reader
+	if strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://") {
+		resp, err := http.Get(source)
+		if err != nil {
+			return nil, fmt.Errorf("error fetching capacity data from %s: %w", source, err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("error fetching capacity data from %s: %s", source, resp.Status)
+		}
+		r = resp.Body
+	} else {
+		f, err := os.Open(source)
+		if err != nil {
+			return nil, fmt.Errorf("error opening capacity file %s: %w", source, err)
+		}
+		defer f.Close()
+		r = f
+	}
+	reader := csv.NewReader(r)
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("error reading header from %s: %w", source, err)
+	}
+	cols := make(map[string]int)
+	for i, col := range header {
+		cols[strings.TrimSpace(strings.ToLower(col))] = i
+	}
+	for _, name := range []string{"section_sis_id", "capacity"} {
+		if _, ok := cols[name]; !ok {
+			return nil, fmt.Errorf("capacity data %s needs a %s column", source, name)
+		}
+	}
+	get := func(record []string, name string) string {
+		if i, ok := cols[name]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+	caps := make(map[string]sectionCap)
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error reading %s: %w", source, err)
+		}
+		sisID := get(record, "section_sis_id")
+		if sisID == "" {
+			continue
+		}
+		var c sectionCap
+		if c.capacity, err = strconv.Atoi(get(record, "capacity")); err != nil || c.capacity < 0 {
+			return nil, fmt.Errorf("line %d of %s: invalid capacity %q", line, source, get(record, "capacity"))
+		}
+		if s := get(record, "enrolled"); s != "" {
+			n, err := strconv.Atoi(s)
+			if err != nil {
+				return nil, fmt.Errorf("line %d of %s: invalid enrolled %q", line, source, s)
+			}
+			c.enrolled = &n
+		}
+		if _, dup := caps[sisID]; dup {
+			return nil, fmt.Errorf("line %d of %s: section %s is listed twice", line, source, sisID)
+		}
+		caps[sisID] = c
+	}
+	return caps, nil
+}
@@ -0,0 +1,334 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/coraxwolf/CCTA_3-4/pkg/canvas"
+	"github.com/coraxwolf/CCTA_3-4/pkg/config"
+)
+
+// CareFlag is a student's submission comment or discussion post that contains a configured keyword.
+type CareFlag struct {
+	CourseID    int        `json:"course_id" csv:"course_id"`
+	CourseName  string     `json:"course_name" csv:"course_name"`
+	Source      string     `json:"source" csv:"source"` // submission_comment or discussion_post
+	StudentID   int        `json:"student_id" csv:"student_id"`
+	StudentName string     `json:"student_name" csv:"student_name"`
+	SISUserID   string     `json:"sis_user_id" csv:"sis_user_id"`
+	Keywords    []string   `json:"keywords" csv:"keywords"`
+	Excerpt     string     `json:"excerpt" csv:"excerpt"`
+	PostedAt    *time.Time `json:"posted_at" csv:"posted_at"`
+	URL         string     `json:"url" csv:"url"`
+}
+
+// careAuditEntry is a line of the care scan audit log. It records who ran a scan and what it
+// covered, never what was found.
+type careAuditEntry struct {
+	Time         time.Time `json:"time"`
+	Event        string    `json:"event"` // denied, failed, or completed
+	OSUser       string    `json:"os_user"`
+	CanvasUserID int       `json:"canvas_user_id,omitempty"`
+	CanvasLogin  string    `json:"canvas_login,omitempty"`
+	Profile      string    `json:"profile"`
+	Term         string    `json:"term,omitempty"`
+	CourseID     int       `json:"course_id,omitempty"`
+	Sources      []string  `json:"sources,omitempty"`
+	Since        time.Time `json:"since"`
+	Courses      int       `json:"courses"`
+	Scanned      int       `json:"scanned"`
+	Flagged      int       `json:"flagged"`
+	Output       string    `json:"output,omitempty"`
+	Error        string    `json:"error,omitempty"`
+}
+
+const careScanSummary = "Flag recent student submission comments and discussion posts that contain configured keywords, for student-care teams"
+
+func init() {
+	register(command{Group: "care", Name: "scan", Summary: careScanSummary, Run: runCareScan})
+}
+
+func runCareScan(args []string) error {
+	var opts commonOptions
+	kf := cfg.KeywordFlags
+	fs := newFlagSet("care scan", careScanSummary)
+	addTermFlags(fs, &opts)
+	addAccountFlags(fs, &opts)
+	addOutputFlags(fs, &opts, "")
+	courseID := fs.Int("course", 0, "scan one course instead of a term")
+	days := fs.Int("days", 7, "only scan comments and posts from the last this many days")
+	sources := fs.String("sources", "comments,discussions", "comma separated sources: comments (submission comments), discussions")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *courseID == 0 && opts.Term == "" {
+		return fmt.Errorf("--term or --course is required")
+	}
+	// Flagged text must not end up on stdout or in the shared report directory
+	outputSet := false
+	fs.Visit(func(f *flag.Flag) { outputSet = outputSet || f.Name == "output" })
+	if !outputSet {
+		opts.Output = kf.OutputDir
+		if opts.Output == "" {
+			opts.Output = filepath.Join("data", "care")
+		}
+	}
+	if opts.Output == "" {
+		return fmt.Errorf("care scan reports can't be written to stdout; set --output")
+	}
+	auditLog := kf.AuditLog
+	if auditLog == "" {
+		auditLog = filepath.Join("data", "audit", "keyword_flags.log")
+	}
+	entry := careAuditEntry{
+		OSUser:   osUser(),
+		Profile:  cfg.Profile,
+		Term:     opts.Term,
+		CourseID: *courseID,
+		Sources:  splitList(*sources),
+		Since:    time.Now().AddDate(0, 0, -*days).Truncate(time.Second),
+	}
+	audit := func(event string, err error) error {
+		entry.Time, entry.Event = time.Now(), event
+		if err != nil {
+			entry.Error = err.Error()
+		}
+		if werr := appendAudit(auditLog, entry); werr != nil {
+			return fmt.Errorf("error writing audit log %s: %w", auditLog, werr)
+		}
+		return err
+	}
+
+	if len(kf.Operators) == 0 {
+		return audit("denied", fmt.Errorf("care scan is disabled: list the Canvas login IDs allowed to run it in keyword_flags.operators in the config file"))
+	}
+	self, err := api.Users().GetUser("self")
+	if err != nil {
+		return audit("failed", fmt.Errorf("error identifying the token's user: %w", err))
+	}
+	entry.CanvasUserID, entry.CanvasLogin = self.ID, self.LoginID
+	if !slices.Contains(kf.Operators, self.LoginID) {
+		return audit("denied", fmt.Errorf("the token's user %q is not in keyword_flags.operators", self.LoginID))
+	}
+	keywords, err := careKeywords(kf)
+	if err != nil {
+		return audit("failed", err)
+	}
+	for _, s := range entry.Sources {
+		if s != "comments" && s != "discussions" {
+			return audit("failed", fmt.Errorf("unknown source %q (expected comments or discussions)", s))
+		}
+	}
+
+	var courses []canvas.Course
+	if *courseID != 0 {
+		course, err := api.Courses().GetCourse(*courseID)
+		if err != nil {
+			return audit("failed", fmt.Errorf("error fetching course %d: %w", *courseID, err))
+		}
+		courses = []canvas.Course{*course}
+	} else if courses, err = opts.termCourses(canvas.CourseListOptions{}); err != nil {
+		return audit("failed", fmt.Errorf("error fetching courses: %w", err))
+	}
+
+	var flags []CareFlag
+	for _, course := range courses {
+		found, scanned, err := scanCourse(course, keywords, entry.Sources, entry.Since)
+		entry.Courses++
+		entry.Scanned += scanned
+		flags = append(flags, found...)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error scanning course %d: %s\n", course.ID, withHint(err))
+		}
+	}
+	entry.Flagged = len(flags)
+	fmt.Fprintf(os.Stderr, "Scanned %d comments and posts in %d courses: %d flagged\n", entry.Scanned, entry.Courses, entry.Flagged)
+
+	name := "care_flags_" + time.Now().Format("20060102_150405")
+	if err := os.MkdirAll(opts.Output, 0700); err != nil {
+		return audit("failed", fmt.Errorf("error creating %s: %w", opts.Output, err))
+	}
+	// MkdirAll leaves a directory that already exists as it is
+	if err := os.Chmod(opts.Output, 0700); err != nil {
+		return audit("failed", fmt.Errorf("error restricting access to %s: %w", opts.Output, err))
+	}
+	// The file is created readable only by its owner, before the flagged text is written to it
+	if entry.Output, err = opts.writeReport(name, flags, 0600); err != nil {
+		return audit("failed", err)
+	}
+	return audit("completed", nil)
+}
+
+// scanCourse flags the course's students' submission comments and discussion posts since the given time.
+// Comments and posts by teachers and other staff are not scanned.
+func scanCourse(course canvas.Course, keywords *regexp.Regexp, sources []string, since time.Time) ([]CareFlag, int, error) {
+	enrollments, err := api.Enrollments().ListCourseEnrollments(course.ID, canvas.EnrollmentListOptions{Types: []string{"StudentEnrollment"}})
+	if err != nil {
+		return nil, 0, err
+	}
+	students := make(map[int]canvas.Enrollment, len(enrollments))
+	for _, e := range enrollments {
+		students[e.UserID] = e
+	}
+	var flags []CareFlag
+	scanned := 0
+	check := func(source string, userID int, text string, postedAt *time.Time, link string) {
+		student, ok := students[userID]
+		if !ok || postedAt == nil || postedAt.Before(since) {
+			return
+		}
+		scanned++
+		matches := keywords.FindAllStringIndex(text, -1)
+		if len(matches) == 0 {
+			return
+		}
+		var found []string
+		for _, m := range matches {
+			if kw := strings.ToLower(text[m[0]:m[1]]); !slices.Contains(found, kw) {
+				found = append(found, kw)
+			}
+		}
+		flags = append(flags, CareFlag{
+			CourseID:    course.ID,
+			CourseName:  course.Name,
+			Source:      source,
+			StudentID:   userID,
+			StudentName: student.User.Name,
+			SISUserID:   student.User.SISUserID,
+			Keywords:    found,
+			Excerpt:     excerpt(text, matches[0][0], matches[0][1]),
+			PostedAt:    postedAt,
+			URL:         link,
+		})
+	}
+
+	if slices.Contains(sources, "comments") {
+		submissions, err := api.Submissions().ListStudentSubmissions(course.ID, canvas.SubmissionListOptions{Include: []string{"submission_comments"}})
+		if err != nil {
+			return flags, scanned, err
+		}
+		for _, s := range submissions {
+			for _, c := range s.SubmissionComments {
+				link := api.CourseURL(course.ID, fmt.Sprintf("assignments/%d/submissions/%d", s.AssignmentID, s.UserID))
+				check("submission_comment", c.AuthorID, c.Comment, c.CreatedAt, link)
+			}
+		}
+	}
+	if slices.Contains(sources, "discussions") {
+		topics, err := api.Discussions().ListTopics(course.ID, canvas.DiscussionListOptions{OrderBy: "recent_activity"})
+		if err != nil {
+			return flags, scanned, err
+		}
+		for _, topic := range topics {
+			if topic.LastReplyAt == nil || topic.LastReplyAt.Before(since) {
+				continue
+			}
+			view, err := api.Discussions().GetTopicView(course.ID, topic.ID)
+			if err != nil {
+				return flags, scanned, err
+			}
+			var walk func(entries []canvas.DiscussionEntry)
+			walk = func(entries []canvas.DiscussionEntry) {
+				for _, e := range entries {
+					if !e.Deleted {
+						link := api.CourseURL(course.ID, fmt.Sprintf("discussion_topics/%d", topic.ID)) + fmt.Sprintf("#entry-%d", e.ID)
+						check("discussion_post", e.UserID, plainText(e.Message), e.CreatedAt, link)
+					}
+					walk(e.Replies)
+				}
+			}
+			walk(view.View)
+		}
+	}
+	return flags, scanned, nil
+}
+
+// careKeywords compiles the configured keywords and phrases into one case-insensitive pattern
+// that only matches whole words.
+func careKeywords(kf config.KeywordFlags) (*regexp.Regexp, error) {
+	keywords := slices.Clone(kf.Keywords)
+	if kf.KeywordsFile != "" {
+		f, err := os.Open(kf.KeywordsFile)
+		if err != nil {
+			return nil, fmt.Errorf("error opening keywords file: %w", err)
+		}
+		defer f.Close()
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			line, _, _ := strings.Cut(scanner.Text(), "#")
+			keywords = append(keywords, line)
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, fmt.Errorf("error reading keywords file %s: %w", kf.KeywordsFile, err)
+		}
+	}
+	var quoted []string
+	for _, k := range keywords {
+		if k = strings.Join(strings.Fields(k), " "); k != "" {
+			// any run of spaces between the words of a phrase matches
+			quoted = append(quoted, strings.ReplaceAll(regexp.QuoteMeta(k), " ", `\s+`))
+		}
+	}
+	if len(quoted) == 0 {
+		return nil, fmt.Errorf("no keywords: set keyword_flags.keywords or keyword_flags.keywords_file in the config file")
+	}
+	return regexp.Compile(`(?i)\b(?:` + strings.Join(quoted, "|") + `)\b`)
+}
+
+// excerpt returns the text around a match, so a reviewer can judge it without opening Canvas.
+func excerpt(text string, start, end int) string {
+	const context = 80
+	from, to := max(start-context, 0), min(end+context, len(text))
+	for from > 0 && !utf8.RuneStart(text[from]) {
+		from--
+	}
+	for to < len(text) && !utf8.RuneStart(text[to]) {
+		to++
+	}
+	s := strings.Join(strings.Fields(text[from:to]), " ")
+	if from > 0 {
+		s = "..." + s
+	}
+	if to < len(text) {
+		s += "..."
+	}
+	return s
+}
+
+// appendAudit adds an entry to the audit log, which only its owner can read.
+func appendAudit(path string, entry careAuditEntry) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		f.Close()
+		return err
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func osUser() string {
+	if u, err := user.Current(); err == nil {
+		return u.Username
+	}
+	return os.Getenv("USER")
+}
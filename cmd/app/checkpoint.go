@@ -0,0 +1,142 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// checkpointInterval is how often a checkpoint is saved while courses finish. A run that is killed
+// repeats at most the courses of the last interval when it is resumed.
+const checkpointInterval = 2 * time.Second
+
+// checkpoint keeps the results of the courses a long run has finished in a state file, so a run
+// that dies partway through can be started again with --resume and skip them. Courses that failed
+// are not recorded and are tried again.
+type checkpoint struct {
+	Command   string                  `json:"command"`
+	Key       string                  `json:"key"` // the flags that shape the results, e.g. the term and checks
+	StartedAt time.Time               `json:"started_at"`
+	UpdatedAt time.Time               `json:"updated_at"`
+	Results   map[int]json.RawMessage `json:"results"` // by course ID
+	file      string
+	resumed   map[int]json.RawMessage
+	mu        sync.Mutex
+	saved     time.Time
+	saveErr   error
+}
+
+type checkpointFlags struct {
+	resume bool
+	file   string
+}
+
+func addCheckpointFlags(fs *flag.FlagSet, f *checkpointFlags) {
+	fs.BoolVar(&f.resume, "resume", false, "skip the courses an interrupted run with the same flags already finished")
+	fs.StringVar(&f.file, "checkpoint", "", "state file of finished courses (default data/state/<command>_<term>.checkpoint.json)")
+}
+
+// open starts the run's checkpoint. With --resume the courses in an existing checkpoint are
+// skipped, as long as it was written by the same command with the same key; without it any
+// earlier checkpoint is replaced.
+func (f checkpointFlags) open(command, term, key string) (*checkpoint, error) {
+	file := f.file
+	if file == "" {
+		file = path.Join("data", "state", unsafeNameChars.ReplaceAllString(command+"_"+term, "_")+".checkpoint.json")
+	}
+	cp := &checkpoint{Command: command, Key: key, StartedAt: time.Now(), Results: make(map[int]json.RawMessage), file: file}
+	if !f.resume {
+		return cp, nil
+	}
+	data, err := os.ReadFile(file)
+	if errors.Is(err, os.ErrNotExist) {
+		fmt.Fprintf(os.Stderr, "No checkpoint at %s, starting from the beginning\n", file)
+		return cp, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading checkpoint %s: %w", file, err)
+	}
+	var prev checkpoint
+	if err := json.Unmarshal(data, &prev); err != nil {
+		return nil, fmt.Errorf("error decoding checkpoint %s: %w", file, err)
+	}
+	if prev.Command != command || prev.Key != key {
+		return nil, fmt.Errorf("checkpoint %s is from %s with %q, not %q; run without --resume to start over", file, prev.Command, prev.Key, key)
+	}
+	cp.StartedAt = prev.StartedAt
+	cp.resumed = prev.Results
+	for id, result := range prev.Results {
+		cp.Results[id] = result
+	}
+	fmt.Fprintf(os.Stderr, "Resuming from %s: %d courses already done (started %s)\n", file, len(prev.Results), prev.StartedAt.Format(time.DateTime))
+	return cp, nil
+}
+
+// Lookup decodes a course's result from the resumed checkpoint into v and reports whether there
+// was one.
+func (cp *checkpoint) Lookup(courseID int, v any) bool {
+	result, ok := cp.resumed[courseID]
+	return ok && json.Unmarshal(result, v) == nil
+}
+
+// Done records a finished course's result and saves the checkpoint if the last save is older than
+// checkpointInterval.
+func (cp *checkpoint) Done(courseID int, v any) {
+	result, err := json.Marshal(v)
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+	if err != nil {
+		cp.saveErr = err
+		return
+	}
+	cp.Results[courseID] = result
+	if time.Since(cp.saved) >= checkpointInterval {
+		cp.save()
+	}
+}
+
+// Close saves the checkpoint when some courses failed, so --resume only tries those again, and
+// removes it when every course finished.
+func (cp *checkpoint) Close(failed int) error {
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+	if failed == 0 {
+		if err := os.Remove(cp.file); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("error removing checkpoint %s: %w", cp.file, err)
+		}
+		return nil
+	}
+	cp.save()
+	if cp.saveErr != nil {
+		return fmt.Errorf("error saving checkpoint %s: %w", cp.file, cp.saveErr)
+	}
+	fmt.Fprintf(os.Stderr, "Saved %d finished courses to %s; add --resume to retry the %d that failed\n", len(cp.Results), cp.file, failed)
+	return nil
+}
+
+// save writes the checkpoint through a temporary file, so a run killed while saving leaves the
+// previous checkpoint. The caller holds cp.mu.
+func (cp *checkpoint) save() {
+	cp.saved = time.Now()
+	cp.UpdatedAt = cp.saved
+	if err := os.MkdirAll(filepath.Dir(cp.file), 0o755); err != nil {
+		cp.saveErr = err
+		return
+	}
+	data, err := json.Marshal(cp)
+	if err == nil {
+		tmp := cp.file + ".tmp"
+		if err = os.WriteFile(tmp, data, 0o644); err == nil {
+			err = os.Rename(tmp, cp.file)
+		}
+	}
+	if err != nil {
+		cp.saveErr = err
+	}
+}
@@ -0,0 +1,233 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/coraxwolf/CCTA_3-4/pkg/canvas"
+	"github.com/coraxwolf/CCTA_3-4/pkg/report"
+)
+
+type command struct {
+	Group   string // e.g. "courses"
+	Name    string // e.g. "list"; empty for a single-word command such as "compare-env"
+	Summary string
+	Run     func(args []string) error
+	Local   bool   // doesn't call Canvas, so the pre-flight check is skipped
+	Feature string // experimental feature the command needs, see config.KnownFeatures
+}
+
+func (c command) FullName() string {
+	if c.Name == "" {
+		return c.Group
+	}
+	return c.Group + " " + c.Name
+}
+
+var commands []command
+
+// register adds a command to the CLI. Commands register themselves from init functions.
+func register(c command) {
+	commands = append(commands, c)
+}
+
+func findCommand(args []string) (command, []string, bool) {
+	if len(args) == 0 {
+		return command{}, nil, false
+	}
+	for _, c := range commands {
+		if c.Group != args[0] {
+			continue
+		}
+		if c.Name == "" {
+			return c, args[1:], true
+		}
+		if len(args) > 1 && c.Name == args[1] {
+			return c, args[2:], true
+		}
+	}
+	return command{}, nil, false
+}
+
+func usage(out io.Writer) {
+	fmt.Fprintln(out, "Usage: app [--env profile] [--config file] [--base-url url] [--rate-limit n] [--timeout secs] [--request-log file] [--http-log level] [--http-dump dir] [--metrics-file file] [--metrics-listen addr] [--cache-dir dir [--cache-ttl 168h] | --no-cache] [--preflight mode] [--as-user id] [--dry-run] <group> <command> [flags]")
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, "Commands:")
+	sorted := append([]command(nil), commands...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].FullName() < sorted[j].FullName() })
+	for _, c := range sorted {
+		summary := c.Summary
+		if c.Feature != "" {
+			summary += " (experimental: " + c.Feature + ")"
+		}
+		fmt.Fprintf(out, "  %-32s %s\n", c.FullName(), summary)
+	}
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, "Run 'app <group> <command> -h' for the flags of a command.")
+}
+
+// commonOptions are the flags shared by most commands.
+type commonOptions struct {
+	Term      string
+	AccountID int
+	Format    string
+	Output    string
+	Aggregate string // comma separated group columns; aggregate-only export when set
+	Metrics   string
+	MinCell   int
+	Delimiter rune
+	BOM       bool
+	DateFmt   string // Go time layout
+}
+
+func newFlagSet(name, summary string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: app %s [flags]\n\n%s\n\nFlags:\n", name, summary)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+func addAccountFlags(fs *flag.FlagSet, opts *commonOptions) {
+	fs.IntVar(&opts.AccountID, "account", cfg.AccountID, "Canvas account ID")
+}
+
+func addTermFlags(fs *flag.FlagSet, opts *commonOptions) {
+	fs.StringVar(&opts.Term, "term", "", "SIS term prefix, e.g. 6253 for Summer 2025 (required)")
+}
+
+func addOutputFlags(fs *flag.FlagSet, opts *commonOptions, defaultOutput string) {
+	if cfg.OutputDir != "" {
+		defaultOutput = cfg.OutputDir
+	}
+	fs.StringVar(&opts.Format, "format", "csv", "output format: csv, json, or xlsx")
+	fs.StringVar(&opts.Output, "output", defaultOutput, "output directory (empty writes to stdout)")
+	fs.StringVar(&opts.Aggregate, "aggregate", "", "only write counts grouped by these comma separated columns (use - for a single total)")
+	fs.StringVar(&opts.Metrics, "metrics", "", "comma separated numeric columns to average per group with --aggregate")
+	minCell := 10
+	if v, err := strconv.Atoi(os.Getenv("REPORT_MIN_CELL_SIZE")); err == nil && v > 0 {
+		minCell = v
+	}
+	fs.IntVar(&opts.MinCell, "min-cell-size", minCell, "suppress --aggregate groups with fewer rows than this")
+	var err error
+	if opts.Delimiter, err = report.ParseDelimiter(os.Getenv("REPORT_DELIMITER")); err != nil {
+		fmt.Fprintf(os.Stderr, "Ignoring REPORT_DELIMITER: %v\n", err)
+		opts.Delimiter = ','
+	}
+	fs.Func("delimiter", "CSV delimiter: comma, semicolon, tab, or one character (default REPORT_DELIMITER or comma)", func(s string) (err error) {
+		opts.Delimiter, err = report.ParseDelimiter(s)
+		return err
+	})
+	fs.BoolVar(&opts.BOM, "bom", os.Getenv("REPORT_BOM") == "true", "start CSV files with a UTF-8 byte order mark (for Excel)")
+	if opts.DateFmt, err = report.ParseTimeFormat(os.Getenv("REPORT_DATE_FORMAT")); err != nil {
+		fmt.Fprintf(os.Stderr, "Ignoring REPORT_DATE_FORMAT: %v\n", err)
+		opts.DateFmt = time.RFC3339
+	}
+	fs.Func("date-format", "dates in CSV and XLSX: rfc3339, iso, eu, uk, us, or a Go layout (default REPORT_DATE_FORMAT or rfc3339)", func(s string) (err error) {
+		opts.DateFmt, err = report.ParseTimeFormat(s)
+		return err
+	})
+}
+
+// termPrefix returns the SIS ID prefix for the term flag, e.g. "6253" -> "6253-".
+func (opts commonOptions) termPrefix() (string, error) {
+	term := strings.TrimSuffix(strings.TrimSpace(opts.Term), "-")
+	if term == "" {
+		return "", fmt.Errorf("--term is required")
+	}
+	return term + "-", nil
+}
+
+// termCourses lists the account's courses in the --term term. The term is resolved to a Canvas enrollment
+// term so Canvas does the filtering; if it can't be resolved the courses are matched by SIS ID prefix instead.
+func (opts commonOptions) termCourses(list canvas.CourseListOptions) ([]canvas.Course, error) {
+	prefix, err := opts.termPrefix()
+	if err != nil {
+		return nil, err
+	}
+	term, err := api.Terms().FindTerm(opts.AccountID, opts.Term)
+	if err == nil {
+		list.EnrollmentTermID = term.ID
+		return api.Courses().ListAccountCourses(opts.AccountID, list)
+	}
+	fmt.Fprintf(os.Stderr, "Could not resolve term %s (%v), matching courses by SIS ID prefix\n", opts.Term, err)
+	list.SearchTerm = prefix
+	courses, err := api.Courses().ListAccountCourses(opts.AccountID, list)
+	if err != nil {
+		return nil, err
+	}
+	// search_term matches names and codes too, so keep only the courses in the term
+	inTerm := make([]canvas.Course, 0, len(courses))
+	for _, course := range courses {
+		if strings.HasPrefix(course.SISCourseID, prefix) {
+			inTerm = append(inTerm, course)
+		}
+	}
+	return inTerm, nil
+}
+
+// splitList splits a comma separated flag value, dropping blanks and "-".
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" && item != "-" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// withHint returns the error text followed by its remediation hint, for report error records.
+func withHint(err error) string {
+	if hint := canvas.Hint(err); hint != "" {
+		return fmt.Sprintf("%v (hint: %s)", err, hint)
+	}
+	return err.Error()
+}
+
+// writeRows writes rows to opts.Output/name.<format>, or to stdout when no output directory is set.
+func (opts commonOptions) writeRows(name string, rows any) error {
+	_, err := opts.writeReport(name, rows, 0)
+	return err
+}
+
+// writeReport is writeRows for reports whose file needs other permissions than 0644 from the
+// start, such as ones with student text. It returns the path written, or "" for stdout.
+func (opts commonOptions) writeReport(name string, rows any, mode os.FileMode) (string, error) {
+	format, err := report.ParseFormat(opts.Format)
+	if err != nil {
+		return "", err
+	}
+	writer := report.NewWriter(opts.Output, format)
+	writer.FileMode = mode
+	writer.Delimiter, writer.TimeFormat, writer.BOM = opts.Delimiter, opts.DateFmt, opts.BOM
+	if opts.Aggregate != "" {
+		writer.Aggregation = &report.Aggregation{
+			GroupBy:     splitList(opts.Aggregate),
+			Metrics:     splitList(opts.Metrics),
+			MinCellSize: opts.MinCell,
+		}
+		name += "_aggregate"
+	}
+	defer func() {
+		if writer.Suppressed > 0 {
+			fmt.Fprintf(os.Stderr, "Suppressed %d groups or means with fewer than %d rows or values\n", writer.Suppressed, opts.MinCell)
+		}
+	}()
+	if opts.Output == "" {
+		return "", writer.Encode(os.Stdout, name, rows)
+	}
+	outputFile, err := writer.Write(name, rows)
+	if err != nil {
+		return "", err
+	}
+	fmt.Printf("Written Report to %s\n", outputFile)
+	return outputFile, nil
+}
@@ -0,0 +1,198 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+)
+
+type CompareItem struct {
+	Key    string `json:"key" csv:"key"`
+	Status string `json:"status" csv:"status"` // only_in_<env>, or changed
+	Column string `json:"column" csv:"column"`
+	Left   string `json:"left" csv:"left"`
+	Right  string `json:"right" csv:"right"`
+}
+
+const compareEnvSummary = "Run a read-only report against two profiles (beta and prod by default) and list the differences"
+
+func init() {
+	register(command{Group: "compare-env", Summary: compareEnvSummary, Run: runCompareEnv, Local: true})
+}
+
+// keyColumns are tried in order when --key is not given.
+var keyColumns = []string{"course_id", "sis_course_id", "section_sis_id", "sis_user_id", "id", "endpoint", "permission"}
+
+func runCompareEnv(args []string) error {
+	var opts commonOptions
+	fs := newFlagSet("compare-env", compareEnvSummary+". Usage: app compare-env [flags] <group> <command> [report flags]")
+	addOutputFlags(fs, &opts, "")
+	envs := fs.String("envs", "beta,prod", "the two profiles to compare")
+	key := fs.String("key", "", "column that identifies a row in both reports (default: the first of "+strings.Join(keyColumns, ", ")+")")
+	ignore := fs.String("ignore", "", "comma separated columns to leave out of the comparison")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	profiles := splitList(*envs)
+	if len(profiles) != 2 {
+		return fmt.Errorf("--envs needs two profiles, e.g. beta,prod")
+	}
+	report := fs.Args()
+	reportCmd, _, ok := findCommand(report)
+	if !ok || reportCmd.Local {
+		return fmt.Errorf("give the report to run after the flags, e.g. app compare-env --envs beta,prod courses list --term 6253")
+	}
+
+	var results [2][]map[string]any
+	for i, profile := range profiles {
+		rows, err := runReport(profile, reportCmd.FullName(), report)
+		if err != nil {
+			return err
+		}
+		results[i] = rows
+	}
+	keyCol := *key
+	if keyCol == "" {
+		keyCol = detectKey(results[0], results[1])
+	}
+	items := diffRows(profiles, keyCol, splitList(*ignore), results[0], results[1])
+
+	counts := make(map[string]int)
+	for _, item := range items {
+		counts[item.Status]++
+	}
+	fmt.Fprintf(os.Stderr, "%s: %d rows, %s: %d rows; %d only in %s, %d only in %s, %d changed values (key %s)\n",
+		profiles[0], len(results[0]), profiles[1], len(results[1]),
+		counts["only_in_"+profiles[0]], profiles[0], counts["only_in_"+profiles[1]], profiles[1], counts["changed"], keyOrRow(keyCol))
+	return opts.writeRows(fmt.Sprintf("compare_%s_%s", profiles[0], profiles[1]), items)
+}
+
+// runReport runs the report as a separate process for the profile, with writes turned off,
+// and decodes its JSON output.
+func runReport(profile, name string, report []string) ([]map[string]any, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return nil, err
+	}
+	args := []string{"--env", profile, "--dry-run"}
+	if configFile != "" {
+		args = append(args, "--config", configFile)
+	}
+	args = append(args, report...)
+	args = append(args, "--format", "json", "--output=", "--aggregate=")
+	var stdout bytes.Buffer
+	cmd := exec.Command(exe, args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = os.Stderr
+	fmt.Fprintf(os.Stderr, "Running %s against %s\n", name, profile)
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("report failed for profile %s: %w", profile, err)
+	}
+	var rows []map[string]any
+	if err := json.Unmarshal(stdout.Bytes(), &rows); err != nil {
+		return nil, fmt.Errorf("error decoding report output for profile %s: %w", profile, err)
+	}
+	return rows, nil
+}
+
+func detectKey(left, right []map[string]any) string {
+	for _, col := range keyColumns {
+		if hasColumn(left, col) && hasColumn(right, col) {
+			return col
+		}
+	}
+	return ""
+}
+
+func hasColumn(rows []map[string]any, col string) bool {
+	if len(rows) == 0 {
+		return true // an empty report can't rule the column out
+	}
+	_, ok := rows[0][col]
+	return ok
+}
+
+func keyOrRow(key string) string {
+	if key == "" {
+		return "whole row"
+	}
+	return key
+}
+
+// rowKey identifies a row by the key column, or by all of its values when there is no key column.
+func rowKey(row map[string]any, key string) string {
+	if key != "" {
+		return formatValue(row[key])
+	}
+	data, _ := json.Marshal(row) // map keys are sorted, so equal rows encode the same
+	return string(data)
+}
+
+func formatValue(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64, bool:
+		return fmt.Sprint(v)
+	default:
+		data, _ := json.Marshal(v)
+		return string(data)
+	}
+}
+
+func diffRows(profiles []string, key string, ignore []string, left, right []map[string]any) []CompareItem {
+	index := func(rows []map[string]any) (map[string]map[string]any, []string) {
+		byKey := make(map[string]map[string]any)
+		var order []string
+		for _, row := range rows {
+			k := rowKey(row, key)
+			if _, ok := byKey[k]; !ok {
+				order = append(order, k)
+			}
+			byKey[k] = row
+		}
+		return byKey, order
+	}
+	leftRows, leftOrder := index(left)
+	rightRows, rightOrder := index(right)
+
+	var items []CompareItem
+	for _, k := range leftOrder {
+		l := leftRows[k]
+		r, ok := rightRows[k]
+		if !ok {
+			items = append(items, CompareItem{Key: k, Status: "only_in_" + profiles[0]})
+			continue
+		}
+		cols := make([]string, 0, len(l))
+		for col := range l {
+			cols = append(cols, col)
+		}
+		for col := range r {
+			if _, ok := l[col]; !ok {
+				cols = append(cols, col)
+			}
+		}
+		sort.Strings(cols)
+		for _, col := range cols {
+			if col == key || slices.Contains(ignore, col) || reflect.DeepEqual(l[col], r[col]) {
+				continue
+			}
+			items = append(items, CompareItem{Key: k, Status: "changed", Column: col, Left: formatValue(l[col]), Right: formatValue(r[col])})
+		}
+	}
+	for _, k := range rightOrder {
+		if _, ok := leftRows[k]; !ok {
+			items = append(items, CompareItem{Key: k, Status: "only_in_" + profiles[1]})
+		}
+	}
+	return items
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/coraxwolf/CCTA_3-4/pkg/canvas"
+)
+
+// CourseItem is a course in the courses list report, with its modality next to the Canvas fields.
+type CourseItem struct {
+	canvas.Course
+	Modality string `json:"modality" csv:"modality"`
+}
+
+const coursesListSummary = "List the courses in a term"
+
+func init() {
+	register(command{Group: "courses", Name: "list", Summary: coursesListSummary, Run: runCoursesList})
+}
+
+func runCoursesList(args []string) error {
+	var opts commonOptions
+	fs := newFlagSet("courses list", coursesListSummary)
+	addTermFlags(fs, &opts)
+	addAccountFlags(fs, &opts)
+	addOutputFlags(fs, &opts, "")
+	published := fs.String("published", "", "only list published (true) or unpublished (false) courses")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	var listOpts canvas.CourseListOptions
+	switch *published {
+	case "":
+	case "true", "false":
+		p := *published == "true"
+		listOpts.Published = &p
+	default:
+		return fmt.Errorf("--published must be true or false")
+	}
+	modalities, err := NewModalityClassifierFromEnv()
+	if err != nil {
+		return fmt.Errorf("error loading modality rules: %w", err)
+	}
+	courses, err := opts.termCourses(listOpts)
+	if err != nil {
+		return fmt.Errorf("error fetching courses: %w", err)
+	}
+	results := make([]CourseItem, 0, len(courses))
+	for _, course := range courses {
+		results = append(results, CourseItem{Course: course, Modality: modalities.Classify(course)})
+	}
+	return opts.writeRows(opts.Term+"_courses", results)
+}
@@ -0,0 +1,528 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/coraxwolf/CCTA_3-4/pkg/canvas"
+)
+
+type DeletePlanItem struct {
+	Line   int    `json:"line" csv:"line"`
+	Object string `json:"object" csv:"object"`
+	Status string `json:"status" csv:"status"` // ok, or error when the object can't be deleted
+	Error  string `json:"error" csv:"error"`
+}
+
+type DeleteResultItem struct {
+	Line   int    `json:"line" csv:"line"`
+	Object string `json:"object" csv:"object"`
+	Status string `json:"status" csv:"status"` // deleted, would_delete, restored, would_restore, skipped, or error
+	Error  string `json:"error" csv:"error"`
+}
+
+// deleteKind is a kind of object the deletion commands handle. The restore row holds what is
+// needed to bring the object back: the API can undo some deletions, and the rest are recreated.
+type deleteKind struct {
+	columns  []string // required manifest columns
+	optional []string
+	restore  []string // restore manifest columns
+	// describe names the object of a manifest or restore row, e.g. "page intro in course 12".
+	describe func(row map[string]string) string
+	// fetch looks the object up, refuses objects that can't be deleted, and returns its restore row.
+	fetch  func(ctx context.Context, row map[string]string) ([]string, error)
+	remove func(ctx context.Context, row map[string]string) error
+	undo   func(ctx context.Context, row map[string]string) error
+}
+
+var enrollmentTasks = []string{canvas.EnrollmentDelete, canvas.EnrollmentConclude, canvas.EnrollmentDeactivate}
+
+// deleteKinds returns the kinds the deletion commands handle. Enrollments are looked up in accountID.
+func deleteKinds(accountID int) map[string]deleteKind {
+	return map[string]deleteKind{
+		"enrollments": {
+			columns:  []string{"course_id", "enrollment_id"},
+			optional: []string{"task"},
+			restore:  []string{"course_id", "enrollment_id", "task", "user_id", "section_id", "type", "role_id", "enrollment_state"},
+			describe: func(row map[string]string) string {
+				return fmt.Sprintf("enrollment %s in course %s", row["enrollment_id"], row["course_id"])
+			},
+			fetch: func(ctx context.Context, row map[string]string) ([]string, error) {
+				courseID, id, err := intColumns(row, "course_id", "enrollment_id")
+				if err != nil {
+					return nil, err
+				}
+				e, err := api.Enrollments().WithContext(ctx).GetEnrollment(accountID, id)
+				if err != nil {
+					return nil, err
+				}
+				if e.CourseID != courseID {
+					return nil, fmt.Errorf("enrollment %d is in course %d, not %d", id, e.CourseID, courseID)
+				}
+				if e.EnrollmentState == "deleted" {
+					return nil, fmt.Errorf("enrollment %d is already deleted", id)
+				}
+				return []string{strconv.Itoa(courseID), strconv.Itoa(id), enrollmentTask(row), strconv.Itoa(e.UserID), strconv.Itoa(e.SectionID),
+					e.Type, strconv.Itoa(e.RoleID), e.EnrollmentState}, nil
+			},
+			remove: func(ctx context.Context, row map[string]string) error {
+				courseID, id, err := intColumns(row, "course_id", "enrollment_id")
+				if err != nil {
+					return err
+				}
+				_, err = api.Enrollments().WithContext(ctx).DeleteEnrollment(courseID, id, enrollmentTask(row))
+				return err
+			},
+			// deactivated enrollments are reactivated; deleted and concluded ones are enrolled again
+			undo: func(ctx context.Context, row map[string]string) error {
+				courseID, id, err := intColumns(row, "course_id", "enrollment_id")
+				if err != nil {
+					return err
+				}
+				if row["task"] == canvas.EnrollmentDeactivate {
+					_, err := api.Enrollments().WithContext(ctx).ReactivateEnrollment(courseID, id)
+					return err
+				}
+				userID, sectionID, err := intColumns(row, "user_id", "section_id")
+				if err != nil {
+					return err
+				}
+				roleID, _ := strconv.Atoi(row["role_id"])
+				input := canvas.EnrollmentInput{UserID: userID, Type: row["type"], RoleID: roleID}
+				if row["enrollment_state"] == "active" {
+					input.EnrollmentState = "active"
+				}
+				_, err = api.Enrollments().WithContext(ctx).EnrollInSection(sectionID, input)
+				return err
+			},
+		},
+		"pages": {
+			columns: []string{"course_id", "page_url"},
+			restore: []string{"course_id", "page_url", "title", "body", "published", "editing_roles"},
+			describe: func(row map[string]string) string {
+				return fmt.Sprintf("page %s in course %s", row["page_url"], row["course_id"])
+			},
+			fetch: func(ctx context.Context, row map[string]string) ([]string, error) {
+				courseID, err := strconv.Atoi(row["course_id"])
+				if err != nil {
+					return nil, fmt.Errorf("invalid course_id %q", row["course_id"])
+				}
+				page, err := api.Pages().WithContext(ctx).GetPage(courseID, row["page_url"])
+				if err != nil {
+					return nil, err
+				}
+				if page.FrontPage {
+					return nil, fmt.Errorf("page %s is the front page of course %d; choose another front page first", page.URL, courseID)
+				}
+				return []string{strconv.Itoa(courseID), page.URL, page.Title, page.Body, strconv.FormatBool(page.Published), page.EditingRoles}, nil
+			},
+			remove: func(ctx context.Context, row map[string]string) error {
+				courseID, err := strconv.Atoi(row["course_id"])
+				if err != nil {
+					return fmt.Errorf("invalid course_id %q", row["course_id"])
+				}
+				return api.Pages().WithContext(ctx).DeletePage(courseID, row["page_url"])
+			},
+			// pages are recreated with their title and body; Canvas gives them a new ID and history
+			undo: func(ctx context.Context, row map[string]string) error {
+				courseID, err := strconv.Atoi(row["course_id"])
+				if err != nil {
+					return fmt.Errorf("invalid course_id %q", row["course_id"])
+				}
+				title, body, roles := row["title"], row["body"], row["editing_roles"]
+				published := row["published"] == "true"
+				input := canvas.WikiPageInput{Title: &title, Body: &body, Published: &published}
+				if roles != "" {
+					input.EditingRoles = &roles
+				}
+				_, err = api.Pages().WithContext(ctx).CreatePage(courseID, input)
+				return err
+			},
+		},
+		"events": {
+			columns: []string{"event_id"},
+			restore: []string{"event_id", "context_code", "title", "description", "start_at", "end_at", "location_name"},
+			describe: func(row map[string]string) string {
+				return "calendar event " + row["event_id"]
+			},
+			fetch: func(ctx context.Context, row map[string]string) ([]string, error) {
+				id, err := strconv.Atoi(row["event_id"])
+				if err != nil {
+					return nil, fmt.Errorf("invalid event_id %q", row["event_id"])
+				}
+				event, err := api.Calendar().WithContext(ctx).GetEvent(id)
+				if err != nil {
+					return nil, err
+				}
+				if event.WorkflowState == "deleted" {
+					return nil, fmt.Errorf("calendar event %d is already deleted", id)
+				}
+				return []string{strconv.Itoa(id), event.ContextCode, event.Title, event.Description, formatTime(event.StartAt), formatTime(event.EndAt), event.LocationName}, nil
+			},
+			remove: func(ctx context.Context, row map[string]string) error {
+				id, err := strconv.Atoi(row["event_id"])
+				if err != nil {
+					return fmt.Errorf("invalid event_id %q", row["event_id"])
+				}
+				return api.Calendar().WithContext(ctx).DeleteEvent(id, "") // only this event of a series
+			},
+			// events are recreated as single events with a new ID
+			undo: func(ctx context.Context, row map[string]string) error {
+				input := canvas.CalendarEventInput{ContextCode: row["context_code"], Title: row["title"], Description: row["description"], LocationName: row["location_name"]}
+				for _, t := range []struct {
+					col string
+					dst **time.Time
+				}{{"start_at", &input.StartAt}, {"end_at", &input.EndAt}} {
+					if row[t.col] == "" {
+						continue
+					}
+					v, err := time.Parse(time.RFC3339, row[t.col])
+					if err != nil {
+						return fmt.Errorf("invalid %s %q", t.col, row[t.col])
+					}
+					*t.dst = &v
+				}
+				_, err := api.Calendar().WithContext(ctx).CreateEvent(input)
+				return err
+			},
+		},
+	}
+}
+
+const (
+	deletePlanSummary    = "Check a deletion manifest of enrollments, pages, or calendar events and print the code that confirms it"
+	deleteRunSummary     = "Delete the objects in a confirmed manifest, slowly, after writing a restore manifest"
+	deleteRestoreSummary = "Restore or recreate the objects in a restore manifest written by delete run"
+)
+
+func init() {
+	register(command{Group: "delete", Name: "plan", Summary: deletePlanSummary, Run: runDeletePlan})
+	register(command{Group: "delete", Name: "run", Summary: deleteRunSummary, Run: runDeleteRun})
+	register(command{Group: "delete", Name: "restore", Summary: deleteRestoreSummary, Run: runDeleteRestore})
+}
+
+// deleteFlags are the flags shared by the deletion commands.
+type deleteFlags struct {
+	kind string
+	file string
+	rate float64
+}
+
+func addDeleteFlags(fs *flag.FlagSet, f *deleteFlags, fileUsage string) {
+	fs.StringVar(&f.kind, "kind", "", "what to delete: enrollments, pages, or events (required)")
+	fs.StringVar(&f.file, "file", "", fileUsage+" (required)")
+	fs.Float64Var(&f.rate, "rate", 2, "most requests per second")
+}
+
+// load checks the flags and reads the manifest, or the restore manifest when restore is set.
+func (f deleteFlags) load(accountID int, restore bool) (deleteKind, []manifestRow, error) {
+	kind, ok := deleteKinds(accountID)[f.kind]
+	if !ok {
+		return kind, nil, fmt.Errorf("--kind must be enrollments, pages, or events")
+	}
+	if f.file == "" {
+		return kind, nil, fmt.Errorf("--file is required")
+	}
+	if f.rate <= 0 {
+		return kind, nil, fmt.Errorf("--rate must be above 0")
+	}
+	optional := kind.optional
+	if restore {
+		optional = kind.restore
+	}
+	rows, err := readManifest(f.file, kind.columns, optional)
+	if err != nil {
+		return kind, nil, err
+	}
+	if !restore && f.kind == "enrollments" {
+		for _, row := range rows {
+			if !slices.Contains(enrollmentTasks, enrollmentTask(row.values)) {
+				return kind, nil, fmt.Errorf("%s line %d: task must be one of %s", f.file, row.line, strings.Join(enrollmentTasks, ", "))
+			}
+		}
+	}
+	return kind, rows, nil
+}
+
+func runDeletePlan(args []string) error {
+	var opts commonOptions
+	var flags deleteFlags
+	fs := newFlagSet("delete plan", deletePlanSummary)
+	addAccountFlags(fs, &opts)
+	addOutputFlags(fs, &opts, "")
+	addDeleteFlags(fs, &flags, "manifest CSV: course_id and enrollment_id (and an optional task: delete, conclude, or deactivate) for enrollments, course_id and page_url for pages, or event_id for events")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	kind, rows, err := flags.load(opts.AccountID, false)
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+	var items []DeletePlanItem
+	ok := 0
+	paceRows(ctx, "Checking "+flags.kind, rows, flags.rate, func(row manifestRow) error {
+		item := DeletePlanItem{Line: row.line, Object: kind.describe(row.values), Status: "ok"}
+		if _, err := kind.fetch(ctx, row.values); err != nil {
+			item.Status, item.Error = "error", withHint(err)
+			items = append(items, item)
+			return err
+		}
+		ok++
+		items = append(items, item)
+		return nil
+	})
+	if err := opts.writeRows("delete_plan", items); err != nil {
+		return err
+	}
+	code, err := manifestCode(flags.kind, flags.file)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "%d of %d %s can be deleted from %s (profile %s); rows with errors will be skipped.\n", ok, len(rows), flags.kind, cfg.BaseURL, cfg.Profile)
+	fmt.Fprintf(os.Stderr, "To delete them, run: app --env %s delete run --kind %s --file %s --confirm %s\n", cfg.Profile, flags.kind, flags.file, code)
+	return nil
+}
+
+func runDeleteRun(args []string) error {
+	var opts commonOptions
+	var flags deleteFlags
+	fs := newFlagSet("delete run", deleteRunSummary)
+	addAccountFlags(fs, &opts)
+	addOutputFlags(fs, &opts, "")
+	addDeleteFlags(fs, &flags, "manifest CSV checked with delete plan")
+	confirm := fs.String("confirm", "", "the code printed by delete plan for this manifest (required)")
+	restorePath := fs.String("restore", "", "where to write the restore manifest (default delete_restore_<kind>_<time>.csv in the output directory)")
+	maxRows := fs.Int("max", 200, "refuse manifests with more rows than this")
+	maxErrors := fs.Int("max-errors", 10, "stop after this many failed deletions")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	kind, rows, err := flags.load(opts.AccountID, false)
+	if err != nil {
+		return err
+	}
+	if len(rows) > *maxRows {
+		return fmt.Errorf("%s has %d rows, more than --max %d; split it or raise --max", flags.file, len(rows), *maxRows)
+	}
+	code, err := manifestCode(flags.kind, flags.file)
+	if err != nil {
+		return err
+	}
+	if *confirm != code {
+		return fmt.Errorf("--confirm doesn't match %s; run delete plan --kind %s --file %s first, and again whenever the manifest changes", flags.file, flags.kind, flags.file)
+	}
+	ctx := context.Background()
+
+	// look everything up again and write the restore manifest before anything is deleted
+	restoreRows := [][]string{kind.restore}
+	var targets []manifestRow
+	var results []DeleteResultItem
+	paceRows(ctx, "Checking "+flags.kind, rows, flags.rate, func(row manifestRow) error {
+		restore, err := kind.fetch(ctx, row.values)
+		if err != nil {
+			results = append(results, DeleteResultItem{Line: row.line, Object: kind.describe(row.values), Status: "skipped", Error: withHint(err)})
+			return err
+		}
+		restoreRows = append(restoreRows, restore)
+		targets = append(targets, row)
+		return nil
+	})
+	if len(targets) > 0 && !api.DryRun() {
+		path := *restorePath
+		if path == "" {
+			path = filepath.Join(opts.Output, "delete_restore_"+flags.kind+"_"+time.Now().Format("20060102_150405")+".csv")
+		}
+		if err := writeCSVFile(path, restoreRows); err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "Restore manifest written to %s (apply it with delete restore --kind %s --file %s)\n", path, flags.kind, path)
+	}
+
+	failed := 0
+	paceRows(ctx, "Deleting "+flags.kind, targets, flags.rate, func(row manifestRow) error {
+		item := DeleteResultItem{Line: row.line, Object: kind.describe(row.values), Status: "deleted"}
+		if api.DryRun() {
+			item.Status = "would_delete"
+		}
+		if failed >= *maxErrors {
+			item.Status, item.Error = "skipped", fmt.Sprintf("stopped after %d errors", failed)
+			results = append(results, item)
+			return nil
+		}
+		err := kind.remove(ctx, row.values)
+		if err != nil {
+			failed++
+			item.Status, item.Error = "error", withHint(err)
+		}
+		results = append(results, item)
+		return err
+	})
+	slices.SortFunc(results, func(a, b DeleteResultItem) int { return a.Line - b.Line })
+	if err := opts.writeRows("delete_"+flags.kind, results); err != nil {
+		return err
+	}
+	if failed >= *maxErrors {
+		return fmt.Errorf("stopped after %d failed deletions; the rest of the manifest was skipped", failed)
+	}
+	return nil
+}
+
+func runDeleteRestore(args []string) error {
+	var opts commonOptions
+	var flags deleteFlags
+	fs := newFlagSet("delete restore", deleteRestoreSummary)
+	addOutputFlags(fs, &opts, "")
+	addDeleteFlags(fs, &flags, "restore manifest written by delete run")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	kind, rows, err := flags.load(0, true)
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+	var results []DeleteResultItem
+	paceRows(ctx, "Restoring "+flags.kind, rows, flags.rate, func(row manifestRow) error {
+		item := DeleteResultItem{Line: row.line, Object: kind.describe(row.values), Status: "restored"}
+		if api.DryRun() {
+			item.Status = "would_restore"
+		}
+		err := kind.undo(ctx, row.values)
+		if err != nil {
+			item.Status, item.Error = "error", withHint(err)
+		}
+		results = append(results, item)
+		return err
+	})
+	return opts.writeRows("restore_"+flags.kind, results)
+}
+
+// paceRows runs fn for each row, at most rate times a second, with a progress bar.
+func paceRows(ctx context.Context, label string, rows []manifestRow, rate float64, fn func(row manifestRow) error) {
+	bar := newProgress(os.Stderr, label, len(rows))
+	tick := time.NewTicker(time.Duration(float64(time.Second) / rate))
+	defer tick.Stop()
+	for i, row := range rows {
+		if i > 0 {
+			select {
+			case <-ctx.Done():
+				bar.Finish()
+				return
+			case <-tick.C:
+			}
+		}
+		bar.Done(fn(row) != nil)
+	}
+	bar.Finish()
+}
+
+// manifestCode is the confirmation code for deleting a manifest's objects on this profile. It
+// changes when the manifest, the kind, or the Canvas instance does.
+func manifestCode(kind, file string) (string, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return "", fmt.Errorf("error reading %s: %w", file, err)
+	}
+	sum := sha256.Sum256([]byte(kind + "\n" + cfg.BaseURL + "\n" + string(data)))
+	return hex.EncodeToString(sum[:4]), nil
+}
+
+type manifestRow struct {
+	line   int
+	values map[string]string
+}
+
+// readManifest reads a CSV with the given columns. Rows without a value in a required column are
+// refused, so a bad file stops the run before anything is deleted.
+func readManifest(file string, columns, optional []string) ([]manifestRow, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, fmt.Errorf("error opening manifest %s: %w", file, err)
+	}
+	defer f.Close()
+	reader := csv.NewReader(f)
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("error reading header from %s: %w", file, err)
+	}
+	cols := make(map[string]int)
+	for i, col := range header {
+		cols[strings.TrimSpace(strings.ToLower(col))] = i
+	}
+	for _, col := range columns {
+		if _, ok := cols[col]; !ok {
+			return nil, fmt.Errorf("manifest %s needs a %s column", file, col)
+		}
+	}
+	var rows []manifestRow
+	seen := make(map[string]int)
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error reading %s: %w", file, err)
+		}
+		row := manifestRow{line: line, values: make(map[string]string)}
+		var key []string
+		for _, col := range slices.Concat(columns, optional) {
+			i, ok := cols[col]
+			if !ok || i >= len(record) {
+				continue
+			}
+			row.values[col] = strings.TrimSpace(record[i])
+		}
+		for _, col := range columns {
+			key = append(key, row.values[col])
+		}
+		if slices.Contains(key, "") {
+			return nil, fmt.Errorf("%s line %d: %s are required", file, line, strings.Join(columns, ", "))
+		}
+		if first, dup := seen[strings.Join(key, ",")]; dup {
+			return nil, fmt.Errorf("%s line %d repeats line %d", file, line, first)
+		}
+		seen[strings.Join(key, ",")] = line
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+func enrollmentTask(row map[string]string) string {
+	if task := row["task"]; task != "" {
+		return task
+	}
+	return canvas.EnrollmentDelete
+}
+
+// intColumns parses two ID columns of a manifest row.
+func intColumns(row map[string]string, a, b string) (int, int, error) {
+	x, err := strconv.Atoi(row[a])
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid %s %q", a, row[a])
+	}
+	y, err := strconv.Atoi(row[b])
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid %s %q", b, row[b])
+	}
+	return x, y, nil
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
@@ -0,0 +1,282 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/coraxwolf/CCTA_3-4/pkg/canvas"
+	"github.com/coraxwolf/CCTA_3-4/pkg/config"
+	"github.com/coraxwolf/CCTA_3-4/pkg/report"
+)
+
+// DoctorItem is the result of one configuration check.
+type DoctorItem struct {
+	Check  string `json:"check" csv:"check"`
+	Target string `json:"target" csv:"target"`
+	Status string `json:"status" csv:"status"` // ok, warn, or fail
+	Detail string `json:"detail" csv:"detail"`
+	Fix    string `json:"fix" csv:"fix"`
+}
+
+const doctorSummary = "Check the configuration, tokens, terms, and output paths before scheduled runs rely on them"
+
+// doctorTimeout bounds each request doctor sends, so an unreachable host fails the check instead of hanging.
+const doctorTimeout = 30 * time.Second
+
+func init() {
+	register(command{Group: "doctor", Summary: doctorSummary, Run: runDoctor, Local: true})
+}
+
+func runDoctor(args []string) error {
+	var opts commonOptions
+	fs := newFlagSet("doctor", doctorSummary)
+	addAccountFlags(fs, &opts)
+	addOutputFlags(fs, &opts, "")
+	terms := fs.String("terms", "", "comma separated terms that scheduled runs use, e.g. 6253,6255; each must resolve to one Canvas term")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	var results []DoctorItem
+	add := func(check, target, status, detail, fix string) {
+		results = append(results, DoctorItem{Check: check, Target: target, Status: status, Detail: detail, Fix: fix})
+	}
+	prefix := config.EnvPrefix(cfg.Profile)
+
+	// base URL: everything else talks to it
+	baseOK := false
+	switch u, err := url.Parse(cfg.BaseURL); {
+	case cfg.BaseURL == "":
+		add("base_url", cfg.Profile, "fail", "no base URL", fmt.Sprintf("set %s_API_URL or base_url in the %s profile", prefix, cfg.Profile))
+	case err != nil || u.Host == "":
+		add("base_url", cfg.BaseURL, "fail", "not an absolute URL", "use the form https://school.instructure.com/api/v1/")
+	case u.Scheme != "https":
+		baseOK = true
+		add("base_url", cfg.BaseURL, "warn", "tokens are sent without TLS", "use https")
+	case !strings.HasSuffix(u.Path, "/api/v1/"):
+		add("base_url", cfg.BaseURL, "fail", "path must end in /api/v1/", "use the form https://school.instructure.com/api/v1/")
+	default:
+		baseOK = true
+		add("base_url", cfg.BaseURL, "ok", "", "")
+	}
+
+	// tokens: each one on its own, so a revoked fallback is found before it is needed
+	tokens := []string{cfg.Token}
+	tokens = append(tokens, cfg.FallbackTokens...)
+	tokenOK := false
+	for i, token := range tokens {
+		name := "token"
+		if i > 0 {
+			name = fmt.Sprintf("fallback token %d", i)
+		}
+		target := fmt.Sprintf("%s (%s)", name, maskToken(token))
+		switch {
+		case token == "":
+			add("token", name, "fail", "no token", fmt.Sprintf("set %s_TOKEN or token in the %s profile", prefix, cfg.Profile))
+		case !baseOK:
+			add("token", target, "warn", "not checked: the base URL is invalid", "")
+		default:
+			single := cfg
+			single.Token, single.FallbackTokens = token, nil
+			client := canvas.NewAPIFromConfig(slog.Default(), single)
+			ctx, cancel := context.WithTimeout(context.Background(), doctorTimeout)
+			user, err := client.Users().WithContext(ctx).GetUser("self")
+			cancel()
+			if err != nil {
+				add("token", target, "fail", err.Error(), tokenFix(err, name))
+				continue
+			}
+			add("token", target, "ok", fmt.Sprintf("authenticates as %s (ID %d)", user.Name, user.ID), "")
+			tokenOK = tokenOK || i == 0
+		}
+	}
+
+	// account and terms
+	accountTarget := "account " + strconv.Itoa(opts.AccountID)
+	if !tokenOK {
+		add("terms", accountTarget, "warn", "not checked: the primary token doesn't work", "")
+	} else {
+		ctx, cancel := context.WithTimeout(context.Background(), doctorTimeout)
+		list, err := api.Terms().WithContext(ctx).ListTerms(opts.AccountID)
+		cancel()
+		switch {
+		case err != nil:
+			add("terms", accountTarget, "fail", err.Error(), "check account_id and that the token's user is an admin of the account")
+		default:
+			add("terms", accountTarget, "ok", fmt.Sprintf("%d terms", len(list)), "")
+			for _, query := range strings.Split(*terms, ",") {
+				if query = strings.TrimSpace(query); query == "" {
+					continue
+				}
+				term, err := canvas.ResolveTerm(list, query)
+				if err != nil {
+					add("term", query, "fail", err.Error(), "use the SIS term ID, e.g. 6253, or the exact term name")
+					continue
+				}
+				add("term", query, "ok", fmt.Sprintf("%s (ID %d)", term.Name, term.ID), "")
+			}
+		}
+	}
+
+	// output paths: reports, state files, and the logs set on the command line or in the environment
+	dirs := []struct{ name, dir string }{
+		{"output_dir", opts.Output},
+		{"reports", path.Join("data", "reports")},
+		{"state", path.Join("data", "state")},
+	}
+	for _, file := range []struct{ name, env string }{
+		{"request log", "REQUEST_LOG"}, {"metrics file", "METRICS_FILE"},
+	} {
+		if f := os.Getenv(file.env); f != "" {
+			dirs = append(dirs, struct{ name, dir string }{file.name, filepath.Dir(f)})
+		}
+	}
+	if d := os.Getenv("HTTP_DUMP_DIR"); d != "" {
+		dirs = append(dirs, struct{ name, dir string }{"http dump", d})
+	}
+	if d := os.Getenv("CACHE_DIR"); d != "" {
+		dirs = append(dirs, struct{ name, dir string }{"response cache", d})
+	}
+	for _, d := range dirs {
+		if d.dir == "" {
+			continue // reports go to stdout
+		}
+		if err := checkWritable(d.dir); err != nil {
+			add("writable", d.name+": "+d.dir, "fail", err.Error(), "create the directory or fix its permissions for the user the scheduled job runs as")
+			continue
+		}
+		add("writable", d.name+": "+d.dir, "ok", "", "")
+	}
+
+	// settings read from the environment, which commands only report when they first use them
+	for _, setting := range []struct {
+		name string
+		load func() error
+	}{
+		{"MODALITY_RULES", func() error { _, err := NewModalityClassifierFromEnv(); return err }},
+		{"REGISTRAR_NAMES_FILE", func() error { _, err := NewNameNormalizerFromEnv(); return err }},
+		{"TEMPLATE_PATTERN", func() error { _, err := NewTemplateDetectorFromEnv(); return err }},
+		{"REPORT_DELIMITER", func() error { _, err := report.ParseDelimiter(os.Getenv("REPORT_DELIMITER")); return err }},
+		{"REPORT_DATE_FORMAT", func() error { _, err := report.ParseTimeFormat(os.Getenv("REPORT_DATE_FORMAT")); return err }},
+		{"STAFFING_MIN_STUDENTS", func() error { return checkEnvInt("STAFFING_MIN_STUDENTS") }},
+		{"REPORT_MIN_CELL_SIZE", func() error { return checkEnvInt("REPORT_MIN_CELL_SIZE") }},
+		{"INSTRUCTOR_CHANGE_AFTER", func() error {
+			if v := os.Getenv("INSTRUCTOR_CHANGE_AFTER"); v != "" {
+				_, err := parseCourseDate(v)
+				return err
+			}
+			return nil
+		}},
+		{"SECTION_CAPS_SOURCE", func() error {
+			if v := os.Getenv("SECTION_CAPS_SOURCE"); v != "" {
+				_, err := readSectionCaps(v)
+				return err
+			}
+			return nil
+		}},
+	} {
+		if err := setting.load(); err != nil {
+			add("setting", setting.name, "fail", err.Error(), "fix or unset "+setting.name)
+			continue
+		}
+		add("setting", setting.name, "ok", "", "")
+	}
+
+	// availability: a maintenance window or incident now means scheduled runs will wait or skip
+	statusURL := envOr("STATUS_URL", canvas.DefaultStatusURL)
+	if w, ok := cfg.ActiveMaintenance(time.Now()); ok {
+		add("maintenance", cfg.Profile, "warn", fmt.Sprintf("in a maintenance window (%s) until %s", w.Reason, w.End.Local().Format(time.DateTime)), "")
+	}
+	if statusURL != "off" {
+		ctx, cancel := context.WithTimeout(context.Background(), doctorTimeout)
+		status, err := canvas.CheckStatus(ctx, statusURL)
+		cancel()
+		switch {
+		case err != nil:
+			add("status_page", statusURL, "warn", err.Error(), "set STATUS_URL, or STATUS_URL=off to skip the status check")
+		default:
+			if notice, ok := status.Disrupted(); ok {
+				add("status_page", statusURL, "warn", fmt.Sprintf("%q (%s)", notice.Name, notice.Status), "")
+			} else {
+				add("status_page", statusURL, "ok", "", "")
+			}
+		}
+	}
+
+	var failed, warned int
+	for _, r := range results {
+		switch r.Status {
+		case "fail":
+			failed++
+			fmt.Fprintf(os.Stderr, "FAIL %s %s: %s\n", r.Check, r.Target, r.Detail)
+			if r.Fix != "" {
+				fmt.Fprintf(os.Stderr, "     fix: %s\n", r.Fix)
+			}
+		case "warn":
+			warned++
+			fmt.Fprintf(os.Stderr, "WARN %s %s: %s\n", r.Check, r.Target, r.Detail)
+		}
+	}
+	fmt.Fprintf(os.Stderr, "%d checks for the %s profile: %d failed, %d warnings\n", len(results), cfg.Profile, failed, warned)
+	if err := opts.writeRows("doctor", results); err != nil {
+		return err
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d configuration checks failed", failed)
+	}
+	return nil
+}
+
+// maskToken shows enough of a token to tell which one it is, e.g. "7~ab…wxyz".
+func maskToken(token string) string {
+	if len(token) <= 8 {
+		return strings.Repeat("*", len(token))
+	}
+	return token[:4] + "…" + token[len(token)-4:]
+}
+
+func tokenFix(err error, name string) string {
+	switch {
+	case errors.Is(err, canvas.ErrUnauthorized):
+		return "the " + name + " is invalid, expired, or revoked: generate a new one in Canvas under Account > Settings > Approved Integrations"
+	case errors.Is(err, context.DeadlineExceeded):
+		return "Canvas didn't answer in time: check the base URL and the network"
+	}
+	if hint := canvas.Hint(err); hint != "" {
+		return hint
+	}
+	return "check the base URL and the network"
+}
+
+// checkWritable creates dir if needed and writes and removes a file in it.
+func checkWritable(dir string) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(dir, ".doctor-*")
+	if err != nil {
+		return err
+	}
+	name := f.Name()
+	f.Close()
+	return os.Remove(name)
+}
+
+func checkEnvInt(name string) error {
+	v := os.Getenv(name)
+	if v == "" {
+		return nil
+	}
+	if _, err := strconv.Atoi(v); err != nil {
+		return fmt.Errorf("invalid %s %q: %w", name, v, err)
+	}
+	return nil
+}
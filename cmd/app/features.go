@@ -0,0 +1,29 @@
+package main
+
+import "github.com/coraxwolf/CCTA_3-4/pkg/config"
+
+type FeatureItem struct {
+	Feature     string `json:"feature" csv:"feature"`
+	Enabled     bool   `json:"enabled" csv:"enabled"`
+	Description string `json:"description" csv:"description"`
+}
+
+const featuresSummary = "List the experimental features and whether they are on for the profile"
+
+func init() {
+	register(command{Group: "features", Summary: featuresSummary, Run: runFeatures, Local: true})
+}
+
+func runFeatures(args []string) error {
+	var opts commonOptions
+	fs := newFlagSet("features", featuresSummary+". Switch one on with experimental: {<feature>: true} in the config file or <PROFILE>_EXPERIMENTAL=<feature>")
+	addOutputFlags(fs, &opts, "")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	var rows []FeatureItem
+	for _, name := range config.FeatureNames() {
+		rows = append(rows, FeatureItem{Feature: name, Enabled: cfg.Enabled(name), Description: config.KnownFeatures[name]})
+	}
+	return opts.writeRows("features", rows)
+}
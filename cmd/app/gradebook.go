@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/coraxwolf/CCTA_3-4/pkg/canvas"
+	"github.com/coraxwolf/CCTA_3-4/pkg/gradebook"
+)
+
+const gradebookExportSummary = "Export a course's full gradebook in the layout of Canvas' gradebook export"
+
+func init() {
+	register(command{Group: "gradebook", Name: "export", Summary: gradebookExportSummary, Run: runGradebookExport})
+}
+
+func runGradebookExport(args []string) error {
+	var opts commonOptions
+	fs := newFlagSet("gradebook export", gradebookExportSummary)
+	addAccountFlags(fs, &opts)
+	addOutputFlags(fs, &opts, "")
+	courseID := fs.Int("course", 0, "Canvas course ID (required unless --term is given)")
+	fs.StringVar(&opts.Term, "term", "", "export the gradebook of every course in this term, one file per course (needs --output)")
+	states := fs.String("states", "", "comma separated student enrollment states, e.g. active,completed,inactive (default active and invited)")
+	published := fs.Bool("published-only", false, "leave out unpublished assignments")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *courseID == 0 && opts.Term == "" {
+		return fmt.Errorf("--course or --term is required")
+	}
+	if opts.Aggregate != "" {
+		return fmt.Errorf("--aggregate can't be used with gradebook export")
+	}
+	options := gradebook.Options{States: splitList(*states), PublishedOnly: *published}
+
+	if *courseID != 0 {
+		return exportGradebook(opts, *courseID, options)
+	}
+	if opts.Output == "" {
+		return fmt.Errorf("--output is required with --term")
+	}
+	courses, err := opts.termCourses(canvas.CourseListOptions{})
+	if err != nil {
+		return fmt.Errorf("error fetching courses: %w", err)
+	}
+	failed := 0
+	for i, course := range courses {
+		fmt.Fprintf(os.Stderr, "[%d/%d] %s\n", i+1, len(courses), course.Name)
+		if err := exportGradebook(opts, course.ID, options); err != nil {
+			fmt.Fprintf(os.Stderr, "Error exporting course %d: %s\n", course.ID, withHint(err))
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d gradebooks could not be exported", failed, len(courses))
+	}
+	return nil
+}
+
+func exportGradebook(opts commonOptions, courseID int, options gradebook.Options) error {
+	gb, err := gradebook.Build(context.Background(), api, courseID, options)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Course %d: %d students, %d assignments\n", courseID, len(gb.Students), len(gb.Assignments))
+	return opts.writeRows(strconv.Itoa(courseID)+"_gradebook", gb.Table())
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/coraxwolf/CCTA_3-4/pkg/canvas"
+)
+
+type GroupCheckItem struct {
+	CourseID        int    `json:"course_id" csv:"course_id"`
+	CourseName      string `json:"course_name" csv:"course_name"`
+	Modality        string `json:"modality" csv:"modality"`
+	AssignmentID    int    `json:"assignment_id" csv:"assignment_id"`
+	AssignmentName  string `json:"assignment_name" csv:"assignment_name"`
+	GroupCategoryID int    `json:"group_category_id" csv:"group_category_id"`
+	Issue           string `json:"issue" csv:"issue"`
+	Unassigned      int    `json:"unassigned_count" csv:"unassigned_count"`
+	Students        string `json:"unassigned_students" csv:"unassigned_students"`
+	Action          string `json:"action" csv:"action"`
+	URL             string `json:"url" csv:"url"` // the assignment, or the course for a course that couldn't be checked
+}
+
+const groupCheckSummary = "Find group assignments with a deleted group set or students not in any group"
+
+func init() {
+	register(command{Group: "courses", Name: "group-check", Summary: groupCheckSummary, Run: runGroupCheck})
+}
+
+func runGroupCheck(args []string) error {
+	var opts commonOptions
+	fs := newFlagSet("courses group-check", groupCheckSummary)
+	addTermFlags(fs, &opts)
+	addAccountFlags(fs, &opts)
+	addOutputFlags(fs, &opts, path.Join("data", "reports"))
+	courseID := fs.Int("course", 0, "check a single course instead of a whole term")
+	fix := fs.Bool("fix", false, "assign unassigned students to groups with Canvas' auto-assign")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	modalities, err := NewModalityClassifierFromEnv()
+	if err != nil {
+		return fmt.Errorf("error loading modality rules: %w", err)
+	}
+	var courses []canvas.Course
+	name := fmt.Sprintf("course_%d_group_check", *courseID)
+	if *courseID > 0 {
+		course, err := api.Courses().GetCourse(*courseID)
+		if err != nil {
+			return err
+		}
+		courses = append(courses, *course)
+	} else {
+		courses, err = opts.termCourses(canvas.CourseListOptions{})
+		if err != nil {
+			return fmt.Errorf("error fetching courses: %w", err)
+		}
+		name = opts.Term + "_group_check"
+	}
+
+	var results []GroupCheckItem
+	for _, course := range courses {
+		fmt.Printf("Checking group assignments in course %s (ID: %d)\n", course.Name, course.ID)
+		modality := modalities.Classify(course)
+		items, err := checkCourseGroups(course, modality, *fix)
+		if err != nil {
+			fmt.Printf("Error checking groups for course %d: %s\n", course.ID, withHint(err))
+			results = append(results, GroupCheckItem{CourseID: course.ID, CourseName: course.Name, Modality: modality, Issue: "Error: " + withHint(err), URL: api.CourseURL(course.ID, "")})
+			continue
+		}
+		results = append(results, items...)
+	}
+	fmt.Printf("Found %d group assignment issues\n", len(results))
+	return opts.writeRows(name, results)
+}
+
+func checkCourseGroups(course canvas.Course, modality string, fix bool) ([]GroupCheckItem, error) {
+	assignments, err := api.Assignments().ListAssignments(course.ID)
+	if err != nil {
+		return nil, err
+	}
+	var results []GroupCheckItem
+	checked := make(map[int]*GroupCheckItem) // group set results are shared by every assignment using it
+	for _, a := range assignments {
+		if a.GroupCategoryID == nil {
+			continue
+		}
+		gcID := *a.GroupCategoryID
+		item, ok := checked[gcID]
+		if !ok {
+			item, err = checkGroupCategory(gcID, fix)
+			if err != nil {
+				return nil, err
+			}
+			checked[gcID] = item
+		}
+		if item == nil {
+			continue // no problems with this group set
+		}
+		row := *item
+		row.CourseID = course.ID
+		row.CourseName = course.Name
+		row.Modality = modality
+		row.AssignmentID = a.ID
+		row.AssignmentName = a.Name
+		row.URL = api.AssignmentURL(course.ID, a.ID)
+		results = append(results, row)
+	}
+	return results, nil
+}
+
+// checkGroupCategory returns nil when the group set exists and every student is in a group.
+func checkGroupCategory(gcID int, fix bool) (*GroupCheckItem, error) {
+	if _, err := canvas.GetJSON[json.RawMessage](api, fmt.Sprintf("group_categories/%d", gcID)); err != nil {
+		if errors.Is(err, canvas.ErrNotFound) {
+			return &GroupCheckItem{GroupCategoryID: gcID, Issue: "group set deleted", Action: "reassign a group set"}, nil
+		}
+		return nil, fmt.Errorf("error fetching group set %d: %w", gcID, err)
+	}
+	users, err := canvas.GetAllPagesJSON[CanvasUser](api, fmt.Sprintf("group_categories/%d/users", gcID), canvas.WithParam("unassigned", "true"), canvas.WithPerPage(100))
+	if err != nil {
+		if errors.Is(err, canvas.ErrNotFound) {
+			return &GroupCheckItem{GroupCategoryID: gcID, Issue: "group set deleted", Action: "reassign a group set"}, nil
+		}
+		return nil, fmt.Errorf("error fetching unassigned students for group set %d: %w", gcID, err)
+	}
+	if len(users) == 0 {
+		return nil, nil
+	}
+	names := make([]string, 0, len(users))
+	for _, u := range users {
+		names = append(names, u.Name)
+	}
+	item := &GroupCheckItem{
+		GroupCategoryID: gcID,
+		Issue:           "students not in a group",
+		Unassigned:      len(users),
+		Students:        strings.Join(names, "; "),
+		Action:          "none",
+	}
+	if fix {
+		resp, err := api.Post(fmt.Sprintf("group_categories/%d/assign_unassigned_members", gcID), []byte("{}"))
+		if err != nil {
+			item.Action = "auto-assign failed: " + withHint(err)
+		} else {
+			if resp.StatusCode == 200 || resp.StatusCode == 201 {
+				item.Action = "auto-assign started"
+			} else {
+				item.Action = "auto-assign failed: " + withHint(canvas.NewAPIError(resp))
+			}
+			resp.Body.Close()
+		}
+	}
+	return item, nil
+}
@@ -0,0 +1,199 @@
+package main
+
+import (
+	"fmt"
+	"html"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/coraxwolf/CCTA_3-4/pkg/canvas"
+	"github.com/coraxwolf/CCTA_3-4/pkg/ics"
+)
+
+const icsSummary = "Build ICS calendar files of assignment due dates and calendar events for students"
+
+func init() {
+	register(command{Group: "courses", Name: "ics", Summary: icsSummary, Run: runICS})
+}
+
+func runICS(args []string) error {
+	var opts commonOptions
+	fs := newFlagSet("courses ics", icsSummary)
+	addTermFlags(fs, &opts)
+	addAccountFlags(fs, &opts)
+	courseID := fs.Int("course", 0, "build the calendar of a single course instead of a whole term")
+	by := fs.String("by", "course", "one calendar per course, or per program (the subject in the SIS course ID, e.g. ENGL)")
+	published := fs.Bool("published-only", true, "skip unpublished courses")
+	defaultDir := path.Join("data", "calendars")
+	if cfg.OutputDir != "" {
+		defaultDir = cfg.OutputDir
+	}
+	dir := fs.String("output", defaultDir, "directory to write the .ics files to")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *by != "course" && *by != "program" {
+		return fmt.Errorf("--by must be course or program")
+	}
+	var courses []canvas.Course
+	if *courseID > 0 {
+		course, err := api.Courses().GetCourse(*courseID)
+		if err != nil {
+			return err
+		}
+		courses = append(courses, *course)
+	} else {
+		list := canvas.CourseListOptions{}
+		if *published {
+			list.Published = published
+		}
+		var err error
+		if courses, err = opts.termCourses(list); err != nil {
+			return fmt.Errorf("error fetching courses: %w", err)
+		}
+	}
+
+	host := "canvas"
+	if u, err := url.Parse(cfg.BaseURL); err == nil && u.Hostname() != "" {
+		host = u.Hostname()
+	}
+	calendars := make(map[string]*ics.Calendar)
+	var failed int
+	for _, course := range courses {
+		events, err := courseCalendarEvents(course, host)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error reading due dates for course %d: %s\n", course.ID, withHint(err))
+			failed++
+			continue
+		}
+		key, name := calendarKey(course), course.Name
+		if *by == "program" {
+			key = programOf(course)
+			name = key + " due dates"
+			if opts.Term != "" {
+				name = fmt.Sprintf("%s %s due dates", key, opts.Term)
+			}
+		}
+		if calendars[key] == nil {
+			calendars[key] = &ics.Calendar{Name: name}
+		}
+		calendars[key].Events = append(calendars[key].Events, events...)
+	}
+
+	if err := os.MkdirAll(*dir, 0o755); err != nil {
+		return fmt.Errorf("error creating output directory %s: %w", *dir, err)
+	}
+	keys := make([]string, 0, len(calendars))
+	for key := range calendars {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	stamp := time.Now()
+	for _, key := range keys {
+		cal := calendars[key]
+		cal.Stamp = stamp
+		sort.SliceStable(cal.Events, func(i, j int) bool { return cal.Events[i].Start.Before(cal.Events[j].Start) })
+		file := filepath.Join(*dir, unsafeNameChars.ReplaceAllString(key, "_")+".ics")
+		f, err := os.Create(file)
+		if err != nil {
+			return fmt.Errorf("error creating %s: %w", file, err)
+		}
+		err = cal.Encode(f)
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			return fmt.Errorf("error writing %s: %w", file, err)
+		}
+		fmt.Printf("Written Calendar to %s (%d events)\n", file, len(cal.Events))
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d courses could not be read", failed, len(courses))
+	}
+	return nil
+}
+
+var (
+	unsafeNameChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+	htmlTags        = regexp.MustCompile(`<[^>]*>`)
+)
+
+// courseCalendarEvents returns the course's published assignments that have a due date and its
+// calendar events, as students see them.
+func courseCalendarEvents(course canvas.Course, host string) ([]ics.Event, error) {
+	assignments, err := api.Assignments().ListAssignments(course.ID)
+	if err != nil {
+		return nil, err
+	}
+	calendarEvents, err := api.Calendar().ListEvents(canvas.CalendarEventListOptions{
+		ContextCodes: []string{fmt.Sprintf("course_%d", course.ID)},
+		AllEvents:    true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	label := course.CourseCode
+	if label == "" {
+		label = course.Name
+	}
+	var events []ics.Event
+	for _, a := range assignments {
+		if !a.Published || a.DueAt == nil {
+			continue
+		}
+		events = append(events, ics.Event{
+			UID:         fmt.Sprintf("assignment-%d@%s", a.ID, host),
+			Summary:     fmt.Sprintf("%s: %s due", label, a.Name),
+			Description: fmt.Sprintf("Due in %s\n%s", course.Name, a.HTMLURL),
+			URL:         a.HTMLURL,
+			Start:       *a.DueAt,
+			End:         *a.DueAt,
+		})
+	}
+	for _, e := range calendarEvents {
+		if e.StartAt == nil || e.WorkflowState == "deleted" {
+			continue
+		}
+		event := ics.Event{
+			UID:         fmt.Sprintf("event-%d@%s", e.ID, host),
+			Summary:     fmt.Sprintf("%s: %s", label, e.Title),
+			Description: plainText(e.Description),
+			Location:    e.LocationName,
+			URL:         e.HTMLURL,
+			Start:       *e.StartAt,
+		}
+		if e.EndAt != nil {
+			event.End = *e.EndAt
+		}
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// calendarKey names a course's calendar file: its SIS course ID, or its Canvas ID when it has none.
+func calendarKey(course canvas.Course) string {
+	if course.SISCourseID != "" {
+		return course.SISCourseID
+	}
+	return fmt.Sprintf("course_%d", course.ID)
+}
+
+// programOf returns the subject of a SIS course ID such as 6253-01-ENGL-101W.
+func programOf(course canvas.Course) string {
+	parts := strings.Split(course.SISCourseID, "-")
+	if len(parts) > 2 && parts[2] != "" {
+		return parts[2]
+	}
+	return "other"
+}
+
+func plainText(s string) string {
+	s = strings.NewReplacer("<br>", "\n", "<br/>", "\n", "<br />", "\n", "</p>", "\n").Replace(s)
+	return strings.TrimSpace(html.UnescapeString(htmlTags.ReplaceAllString(s, "")))
+}
@@ -0,0 +1,239 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/coraxwolf/CCTA_3-4/pkg/canvas"
+)
+
+// InstructorChangeItem is a teacher added to or removed from a course between two runs.
+type InstructorChangeItem struct {
+	CourseID      int        `json:"course_id" csv:"course_id"`
+	CourseName    string     `json:"course_name" csv:"course_name"`
+	SISCourseID   string     `json:"sis_course_id" csv:"sis_course_id"`
+	Modality      string     `json:"modality" csv:"modality"`
+	Change        string     `json:"change" csv:"change"` // added or removed
+	UserID        int        `json:"user_id" csv:"user_id"`
+	TeacherName   string     `json:"teacher_name" csv:"teacher_name"`
+	SISUserID     string     `json:"sis_user_id" csv:"sis_user_id"`
+	PreviousCheck *time.Time `json:"previous_check" csv:"previous_check"` // the change happened between this run and DetectedAt
+	DetectedAt    time.Time  `json:"detected_at" csv:"detected_at"`
+	Teachers      string     `json:"teachers" csv:"teachers"` // the course's teachers after the change
+	New           bool       `json:"new" csv:"new"`           // detected by this run
+}
+
+// instructorState is what the detector remembers between runs: each course's teachers when it was
+// last checked, and every change recorded after the cutoff date.
+type instructorState struct {
+	Term    string                     `json:"term"`
+	Courses map[int]*courseInstructors `json:"courses"`
+	Changes []InstructorChangeItem     `json:"changes"`
+}
+
+type courseInstructors struct {
+	Name        string                `json:"name"`
+	SISCourseID string                `json:"sis_course_id"`
+	CheckedAt   time.Time             `json:"checked_at"`
+	Teachers    map[int]teacherRecord `json:"teachers"` // by user ID
+}
+
+type teacherRecord struct {
+	Name      string    `json:"name"`
+	SISUserID string    `json:"sis_user_id"`
+	FirstSeen time.Time `json:"first_seen"`
+}
+
+const instructorChangesSummary = "Track teachers per course across runs and report instructor changes after a cutoff date"
+
+func init() {
+	register(command{Group: "courses", Name: "instructor-changes", Summary: instructorChangesSummary, Run: runInstructorChanges})
+}
+
+func runInstructorChanges(args []string) error {
+	var opts commonOptions
+	fs := newFlagSet("courses instructor-changes", instructorChangesSummary)
+	addTermFlags(fs, &opts)
+	addAccountFlags(fs, &opts)
+	addOutputFlags(fs, &opts, "")
+	stateFile := fs.String("state", "", "JSON file that keeps the teachers between runs (default data/state/instructors_<term>.json)")
+	after := fs.String("after", os.Getenv("INSTRUCTOR_CHANGE_AFTER"), "only record changes detected on or after this date (YYYY-MM-DD or RFC 3339), e.g. the end of week 1 (default INSTRUCTOR_CHANGE_AFTER)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if opts.Term == "" {
+		return fmt.Errorf("--term is required")
+	}
+	var cutoff time.Time
+	if *after != "" {
+		t, err := parseCourseDate(*after)
+		if err != nil {
+			return fmt.Errorf("invalid --after %q: %w", *after, err)
+		}
+		cutoff = t
+	}
+	modalities, err := NewModalityClassifierFromEnv()
+	if err != nil {
+		return fmt.Errorf("error loading modality rules: %w", err)
+	}
+	if *stateFile == "" {
+		*stateFile = path.Join("data", "state", "instructors_"+unsafeNameChars.ReplaceAllString(opts.Term, "_")+".json")
+	}
+	state, err := loadInstructorState(*stateFile, opts.Term)
+	if err != nil {
+		return err
+	}
+	courses, err := opts.termCourses(canvas.CourseListOptions{})
+	if err != nil {
+		return fmt.Errorf("error fetching courses: %w", err)
+	}
+
+	now := time.Now()
+	record := !now.Before(cutoff)
+	var detected, baselined, failed int
+	modality := make(map[int]string, len(courses))
+	for _, course := range courses {
+		modality[course.ID] = modalities.Classify(course)
+		enrollments, err := api.Enrollments().ListCourseEnrollments(course.ID, canvas.EnrollmentListOptions{Types: []string{"TeacherEnrollment"}})
+		if err != nil {
+			// the course keeps its last known teachers, so a failed fetch doesn't look like everyone left
+			fmt.Fprintf(os.Stderr, "Error fetching teachers for course %d: %s\n", course.ID, withHint(err))
+			failed++
+			continue
+		}
+		current := make(map[int]teacherRecord)
+		for _, e := range enrollments {
+			current[e.User.ID] = teacherRecord{Name: e.User.Name, SISUserID: e.User.SISUserID, FirstSeen: now}
+		}
+		prev := state.Courses[course.ID]
+		if prev == nil {
+			// first time the course is seen: its teachers are the baseline, not a change
+			state.Courses[course.ID] = &courseInstructors{Name: course.Name, SISCourseID: course.SISCourseID, CheckedAt: now, Teachers: current}
+			baselined++
+			continue
+		}
+		changes := diffTeachers(prev.Teachers, current)
+		previousCheck := prev.CheckedAt
+		for i := range changes {
+			changes[i].CourseID, changes[i].CourseName, changes[i].SISCourseID = course.ID, course.Name, course.SISCourseID
+			changes[i].Modality = modality[course.ID]
+			changes[i].PreviousCheck = &previousCheck
+			changes[i].DetectedAt = now
+			changes[i].Teachers = teacherNames(current)
+		}
+		for id, t := range prev.Teachers {
+			if _, ok := current[id]; ok {
+				current[id] = t // keep when the teacher was first seen
+			}
+		}
+		prev.Name, prev.SISCourseID, prev.CheckedAt, prev.Teachers = course.Name, course.SISCourseID, now, current
+		if record {
+			state.Changes = append(state.Changes, changes...)
+			detected += len(changes)
+		}
+	}
+	if err := saveInstructorState(*stateFile, state); err != nil {
+		return err
+	}
+
+	var results []InstructorChangeItem
+	for _, change := range state.Changes {
+		if change.DetectedAt.Before(cutoff) {
+			continue // recorded under an earlier --after
+		}
+		change.New = change.DetectedAt.Equal(now)
+		if m, ok := modality[change.CourseID]; ok {
+			change.Modality = m // changes recorded before the column existed, or before the rules changed
+		}
+		results = append(results, change)
+	}
+	if !record {
+		fmt.Fprintf(os.Stderr, "Before %s: teachers updated in %s without recording changes\n", cutoff.Format(time.DateOnly), *stateFile)
+	}
+	fmt.Fprintf(os.Stderr, "Checked %d courses (%d new), %d instructor changes this run, %d since the cutoff\n", len(courses), baselined, detected, len(results))
+	if err := opts.writeRows(opts.Term+"_instructor_changes", results); err != nil {
+		return err
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d courses could not be checked", failed, len(courses))
+	}
+	return nil
+}
+
+// diffTeachers returns the teachers added and removed, sorted by name. Only the change, user, and teacher
+// fields are set.
+func diffTeachers(prev, current map[int]teacherRecord) []InstructorChangeItem {
+	var changes []InstructorChangeItem
+	for id, t := range current {
+		if _, ok := prev[id]; !ok {
+			changes = append(changes, InstructorChangeItem{Change: "added", UserID: id, TeacherName: t.Name, SISUserID: t.SISUserID})
+		}
+	}
+	for id, t := range prev {
+		if _, ok := current[id]; !ok {
+			changes = append(changes, InstructorChangeItem{Change: "removed", UserID: id, TeacherName: t.Name, SISUserID: t.SISUserID})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		if changes[i].Change != changes[j].Change {
+			return changes[i].Change < changes[j].Change
+		}
+		return changes[i].TeacherName < changes[j].TeacherName
+	})
+	return changes
+}
+
+func teacherNames(teachers map[int]teacherRecord) string {
+	names := make([]string, 0, len(teachers))
+	for _, t := range teachers {
+		names = append(names, t.Name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, "; ")
+}
+
+// loadInstructorState reads the state file, or returns an empty state when there is none yet.
+func loadInstructorState(file, term string) (*instructorState, error) {
+	state := &instructorState{Term: term, Courses: make(map[int]*courseInstructors)}
+	data, err := os.ReadFile(file)
+	if errors.Is(err, os.ErrNotExist) {
+		return state, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading instructor state %s: %w", file, err)
+	}
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, fmt.Errorf("error decoding instructor state %s: %w", file, err)
+	}
+	if state.Term != term {
+		return nil, fmt.Errorf("instructor state %s is for term %s, not %s", file, state.Term, term)
+	}
+	if state.Courses == nil {
+		state.Courses = make(map[int]*courseInstructors)
+	}
+	return state, nil
+}
+
+// saveInstructorState writes the state through a temporary file, so an interrupted run can't
+// leave a half-written file that loses the baseline.
+func saveInstructorState(file string, state *instructorState) error {
+	if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+		return fmt.Errorf("error creating state directory: %w", err)
+	}
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error encoding instructor state: %w", err)
+	}
+	tmp := file + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("error writing instructor state %s: %w", file, err)
+	}
+	return os.Rename(tmp, file)
+}
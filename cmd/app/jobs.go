@@ -0,0 +1,158 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/coraxwolf/CCTA_3-4/pkg/canvas"
+)
+
+// courseFailure is a course a job could not finish.
+type courseFailure struct {
+	Course canvas.Course
+	Err    error
+}
+
+// runCourseJobs runs job for every course on up to workers goroutines, fewer as the rate limit
+// drains, and shows a progress bar with an ETA on stderr. job gets the course's index so it can
+// write its result into a slice without locking. Failures are returned in course order and
+// listed after the bar instead of being printed as they happen.
+func runCourseJobs(ctx context.Context, label string, courses []canvas.Course, workers int, job func(ctx context.Context, i int, course canvas.Course) error) []courseFailure {
+	if workers < 1 {
+		workers = 1
+	}
+	bar := newProgress(os.Stderr, label, len(courses))
+	errs := make([]error, len(courses))
+	rate := api.RateTracker()
+	var mu sync.Mutex
+	cond := sync.NewCond(&mu)
+	running := 0
+	queue := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range queue {
+				mu.Lock()
+				for running >= rate.Share(workers) {
+					cond.Wait()
+				}
+				running++
+				mu.Unlock()
+				if err := ctx.Err(); err != nil {
+					errs[i] = err
+				} else {
+					errs[i] = job(ctx, i, courses[i])
+				}
+				mu.Lock()
+				running--
+				mu.Unlock()
+				cond.Broadcast()
+				bar.Done(errs[i] != nil)
+			}
+		}()
+	}
+	for i := range courses {
+		queue <- i
+	}
+	close(queue)
+	wg.Wait()
+	bar.Finish()
+
+	var failures []courseFailure
+	for i, err := range errs {
+		if err != nil {
+			failures = append(failures, courseFailure{Course: courses[i], Err: err})
+		}
+	}
+	return failures
+}
+
+// reportFailures lists the courses a job could not finish.
+func reportFailures(out io.Writer, failures []courseFailure) {
+	if len(failures) == 0 {
+		return
+	}
+	fmt.Fprintf(out, "Courses with errors (%d):\n", len(failures))
+	for _, f := range failures {
+		fmt.Fprintf(out, "  %s (ID: %d): %s\n", f.Course.Name, f.Course.ID, withHint(f.Err))
+	}
+}
+
+// progress draws a progress bar on a terminal, redrawn in place. Elsewhere, such as a log file
+// of a scheduled run, it prints a line every 10% instead.
+type progress struct {
+	mu       sync.Mutex
+	out      io.Writer
+	label    string
+	total    int
+	done     int
+	failed   int
+	start    time.Time
+	tty      bool
+	lastStep int
+}
+
+func newProgress(out io.Writer, label string, total int) *progress {
+	p := &progress{out: out, label: label, total: total, start: time.Now()}
+	if f, ok := out.(*os.File); ok {
+		if info, err := f.Stat(); err == nil && info.Mode()&os.ModeCharDevice != 0 {
+			p.tty = true
+		}
+	}
+	return p
+}
+
+// Done records a finished item.
+func (p *progress) Done(failed bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.done++
+	if failed {
+		p.failed++
+	}
+	if p.tty {
+		fmt.Fprintf(p.out, "\r%s   ", p.line()) // the spaces clear what's left of a longer previous line
+		return
+	}
+	if step := p.done * 10 / max(p.total, 1); step > p.lastStep || p.done == p.total {
+		p.lastStep = step
+		fmt.Fprintln(p.out, p.line())
+	}
+}
+
+// Finish ends the bar's line and prints how long the run took.
+func (p *progress) Finish() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.tty && p.done > 0 {
+		fmt.Fprintln(p.out)
+	}
+	fmt.Fprintf(p.out, "%s: %d of %d done, %d failed, in %s\n", p.label, p.done, p.total, p.failed, time.Since(p.start).Round(time.Second))
+}
+
+// line is e.g. "Checking courses [#########-----------] 45/100 45% ETA 2m10s (1 failed)".
+func (p *progress) line() string {
+	const width = 20
+	filled := width
+	percent := 100
+	if p.total > 0 {
+		filled = width * p.done / p.total
+		percent = 100 * p.done / p.total
+	}
+	line := fmt.Sprintf("%s [%s%s] %d/%d %d%%", p.label, strings.Repeat("#", filled), strings.Repeat("-", width-filled), p.done, p.total, percent)
+	if p.done > 0 && p.done < p.total {
+		eta := time.Since(p.start) / time.Duration(p.done) * time.Duration(p.total-p.done)
+		line += " ETA " + eta.Round(time.Second).String()
+	}
+	if p.failed > 0 {
+		line += fmt.Sprintf(" (%d failed)", p.failed)
+	}
+	return line
+}
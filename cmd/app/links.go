@@ -0,0 +1,411 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/coraxwolf/CCTA_3-4/pkg/canvas"
+)
+
+// LinkFixItem is one link rewritten, or to be rewritten, by a mapping rule.
+type LinkFixItem struct {
+	CourseID   int    `json:"course_id" csv:"course_id"`
+	CourseName string `json:"course_name" csv:"course_name"`
+	ItemType   string `json:"item_type" csv:"item_type"` // page, assignment, or syllabus
+	ItemID     string `json:"item_id" csv:"item_id"`     // page slug or assignment ID
+	ItemTitle  string `json:"item_title" csv:"item_title"`
+	OldURL     string `json:"old_url" csv:"old_url"`
+	NewURL     string `json:"new_url" csv:"new_url"`
+	Rule       string `json:"rule" csv:"rule"`
+	Status     string `json:"status" csv:"status"` // would_fix, fixed, restored, skipped, or error
+	Detail     string `json:"detail" csv:"detail"`
+}
+
+// linkRule rewrites links matching Pattern. {course_id} in Replacement is the ID of the course the
+// link is in, so links into a prior term's course can point at the current one.
+type linkRule struct {
+	Pattern     *regexp.Regexp
+	Replacement string
+	Name        string
+}
+
+// linkAttr matches an href or src attribute and captures its quoted value.
+var linkAttr = regexp.MustCompile(`(?i)\b(href|src)(\s*=\s*)("([^"]*)"|'([^']*)')`)
+
+// linkUndo is the undo manifest: each changed item's HTML before and after the fix.
+type linkUndo struct {
+	CreatedAt time.Time      `json:"created_at"`
+	Items     []linkUndoItem `json:"items"`
+}
+
+type linkUndoItem struct {
+	CourseID int    `json:"course_id"`
+	Type     string `json:"type"`
+	ID       string `json:"id"`
+	Title    string `json:"title"`
+	Original string `json:"original"`
+	Fixed    string `json:"fixed"`
+}
+
+// linkTarget is a piece of course content whose HTML can be rewritten.
+type linkTarget struct {
+	undo  linkUndoItem
+	items []LinkFixItem
+}
+
+const fixLinksSummary = "Rewrite links to old domains or prior-term courses in pages, assignments, and syllabi, with a preview and undo"
+
+func init() {
+	register(command{Group: "courses", Name: "fix-links", Summary: fixLinksSummary, Run: runFixLinks})
+}
+
+func runFixLinks(args []string) error {
+	var opts commonOptions
+	fs := newFlagSet("courses fix-links", fixLinksSummary)
+	addTermFlags(fs, &opts)
+	addAccountFlags(fs, &opts)
+	addOutputFlags(fs, &opts, "")
+	courseID := fs.Int("course", 0, "fix one course instead of a term")
+	rulesFile := fs.String("rules", os.Getenv("LINK_RULES_FILE"), "CSV of mapping rules with pattern (regular expression) and replacement columns, and an optional name (default LINK_RULES_FILE)")
+	types := fs.String("types", "page,assignment,syllabus", "comma separated content to fix: page, assignment, syllabus")
+	fix := fs.Bool("fix", false, "rewrite the links; without it the report is a preview of the changes")
+	undo := fs.String("undo", "", "where to write the undo manifest (default link_fix_undo_<time>.json in the output directory)")
+	restore := fs.String("restore", "", "put back the content recorded in this undo manifest instead of fixing links")
+	force := fs.Bool("force", false, "with --restore, also restore content edited since the fix")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *restore != "" {
+		return restoreLinks(opts, *restore, *force)
+	}
+	if *rulesFile == "" {
+		return fmt.Errorf("--rules is required")
+	}
+	if (opts.Term == "") == (*courseID == 0) {
+		return fmt.Errorf("use either --term or --course")
+	}
+	rules, err := readLinkRules(*rulesFile)
+	if err != nil {
+		return err
+	}
+	kinds := make(map[string]bool)
+	for _, t := range splitList(*types) {
+		switch t {
+		case "page", "assignment", "syllabus":
+			kinds[t] = true
+		default:
+			return fmt.Errorf("invalid --types %q: use page, assignment, or syllabus", t)
+		}
+	}
+
+	var courses []canvas.Course
+	if *courseID != 0 {
+		course, err := api.Courses().GetCourse(*courseID, "syllabus_body")
+		if err != nil {
+			return err
+		}
+		courses = []canvas.Course{*course}
+	} else {
+		courses, err = opts.termCourses(canvas.CourseListOptions{Include: []string{"syllabus_body"}})
+		if err != nil {
+			return fmt.Errorf("error fetching courses: %w", err)
+		}
+	}
+
+	// scan everything first, so the undo manifest is complete before anything changes
+	var targets []*linkTarget
+	var results []LinkFixItem
+	for _, course := range courses {
+		found, errs := scanCourseLinks(course, rules, kinds)
+		targets = append(targets, found...)
+		results = append(results, errs...)
+	}
+	changes := 0
+	for _, t := range targets {
+		changes += len(t.items)
+	}
+	fmt.Fprintf(os.Stderr, "Found %d links to rewrite in %d items of %d courses\n", changes, len(targets), len(courses))
+
+	if *fix && len(targets) > 0 && !api.DryRun() {
+		manifest := linkUndo{CreatedAt: time.Now()}
+		for _, t := range targets {
+			manifest.Items = append(manifest.Items, t.undo)
+		}
+		path := *undo
+		if path == "" {
+			path = filepath.Join(opts.Output, "link_fix_undo_"+time.Now().Format("20060102_150405")+".json")
+		}
+		if err := writeLinkUndo(path, manifest); err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "Undo manifest written to %s (apply it with --restore to revert)\n", path)
+	}
+	failed := 0
+	for _, t := range targets {
+		status, detail := "would_fix", ""
+		if *fix {
+			status = "fixed"
+			if err := updateLinkTarget(t.undo.CourseID, t.undo.Type, t.undo.ID, t.undo.Fixed); err != nil {
+				status, detail = "error", withHint(err)
+				failed++
+			} else if api.DryRun() {
+				status = "would_fix"
+			}
+		}
+		for _, item := range t.items {
+			item.Status, item.Detail = status, detail
+			results = append(results, item)
+		}
+	}
+	name := "link_fixes"
+	if opts.Term != "" {
+		name = opts.Term + "_link_fixes"
+	}
+	if err := opts.writeRows(name, results); err != nil {
+		return err
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d items could not be updated", failed, len(targets))
+	}
+	return nil
+}
+
+// scanCourseLinks returns the course's content with links to rewrite, and error rows for the
+// content that couldn't be read.
+func scanCourseLinks(course canvas.Course, rules []linkRule, kinds map[string]bool) ([]*linkTarget, []LinkFixItem) {
+	var targets []*linkTarget
+	var errs []LinkFixItem
+	check := func(kind, id, title, body string) {
+		if t := rewriteLinks(course, kind, id, title, body, rules); t != nil {
+			targets = append(targets, t)
+		}
+	}
+	fail := func(kind string, err error) {
+		fmt.Fprintf(os.Stderr, "Error scanning %ss of course %d: %s\n", kind, course.ID, withHint(err))
+		errs = append(errs, LinkFixItem{CourseID: course.ID, CourseName: course.Name, ItemType: kind, Status: "error", Detail: withHint(err)})
+	}
+	if kinds["syllabus"] {
+		check("syllabus", strconv.Itoa(course.ID), "Syllabus", course.SyllabusBody)
+	}
+	if kinds["page"] {
+		pages, err := api.Pages().ListPages(course.ID)
+		if err != nil {
+			fail("page", err)
+		}
+		for _, p := range pages {
+			page, err := api.Pages().GetPage(course.ID, p.URL) // listing leaves out the body
+			if err != nil {
+				fail("page", err)
+				continue
+			}
+			check("page", page.URL, page.Title, page.Body)
+		}
+	}
+	if kinds["assignment"] {
+		assignments, err := api.Assignments().ListAssignments(course.ID)
+		if err != nil {
+			fail("assignment", err)
+		}
+		for _, a := range assignments {
+			check("assignment", strconv.Itoa(a.ID), a.Name, a.Description)
+		}
+	}
+	return targets, errs
+}
+
+// rewriteLinks applies the first matching rule to each link in body. It returns nil when no link
+// changes.
+func rewriteLinks(course canvas.Course, kind, id, title, body string, rules []linkRule) *linkTarget {
+	t := &linkTarget{}
+	fixed := linkAttr.ReplaceAllStringFunc(body, func(attr string) string {
+		m := linkAttr.FindStringSubmatch(attr)
+		old, quote := m[4], `"`
+		if strings.HasPrefix(m[3], "'") {
+			old, quote = m[5], "'"
+		}
+		for _, rule := range rules {
+			if !rule.Pattern.MatchString(old) {
+				continue
+			}
+			replacement := strings.ReplaceAll(rule.Replacement, "{course_id}", strconv.Itoa(course.ID))
+			updated := rule.Pattern.ReplaceAllString(old, replacement)
+			if updated == old {
+				return attr
+			}
+			t.items = append(t.items, LinkFixItem{CourseID: course.ID, CourseName: course.Name, ItemType: kind, ItemID: id, ItemTitle: title, OldURL: old, NewURL: updated, Rule: rule.Name})
+			return m[1] + m[2] + quote + updated + quote
+		}
+		return attr
+	})
+	if len(t.items) == 0 {
+		return nil
+	}
+	t.undo = linkUndoItem{CourseID: course.ID, Type: kind, ID: id, Title: title, Original: body, Fixed: fixed}
+	return t
+}
+
+func updateLinkTarget(courseID int, kind, id, body string) error {
+	switch kind {
+	case "syllabus":
+		return api.Courses().UpdateSyllabus(courseID, body)
+	case "page":
+		_, err := api.Pages().UpdatePage(courseID, id, canvas.WikiPageInput{Body: &body})
+		return err
+	case "assignment":
+		assignmentID, err := strconv.Atoi(id)
+		if err != nil {
+			return fmt.Errorf("invalid assignment ID %q", id)
+		}
+		_, err = api.Assignments().UpdateAssignment(courseID, assignmentID, canvas.AssignmentInput{Description: &body})
+		return err
+	}
+	return fmt.Errorf("unknown item type %q", kind)
+}
+
+func currentLinkTarget(courseID int, kind, id string) (string, error) {
+	switch kind {
+	case "syllabus":
+		return api.Courses().GetSyllabus(courseID)
+	case "page":
+		page, err := api.Pages().GetPage(courseID, id)
+		if err != nil {
+			return "", err
+		}
+		return page.Body, nil
+	case "assignment":
+		assignmentID, err := strconv.Atoi(id)
+		if err != nil {
+			return "", fmt.Errorf("invalid assignment ID %q", id)
+		}
+		a, err := api.Assignments().GetAssignment(courseID, assignmentID)
+		if err != nil {
+			return "", err
+		}
+		return a.Description, nil
+	}
+	return "", fmt.Errorf("unknown item type %q", kind)
+}
+
+// restoreLinks puts back the content recorded in an undo manifest. Content edited after the fix is
+// skipped unless force is set, so a restore doesn't throw away a teacher's later changes.
+func restoreLinks(opts commonOptions, file string, force bool) error {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return fmt.Errorf("error reading undo manifest: %w", err)
+	}
+	var manifest linkUndo
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return fmt.Errorf("error decoding undo manifest %s: %w", file, err)
+	}
+	var results []LinkFixItem
+	failed := 0
+	for _, item := range manifest.Items {
+		row := LinkFixItem{CourseID: item.CourseID, ItemType: item.Type, ItemID: item.ID, ItemTitle: item.Title, Status: "restored"}
+		current, err := currentLinkTarget(item.CourseID, item.Type, item.ID)
+		switch {
+		case err != nil:
+			row.Status, row.Detail = "error", withHint(err)
+			failed++
+		case current == item.Original:
+			row.Status, row.Detail = "skipped", "already restored"
+		case current != item.Fixed && !force:
+			row.Status, row.Detail = "skipped", "edited since the fix; use --force to restore anyway"
+		default:
+			if err := updateLinkTarget(item.CourseID, item.Type, item.ID, item.Original); err != nil {
+				row.Status, row.Detail = "error", withHint(err)
+				failed++
+			}
+		}
+		results = append(results, row)
+	}
+	restored := 0
+	for _, r := range results {
+		if r.Status == "restored" {
+			restored++
+		}
+	}
+	fmt.Fprintf(os.Stderr, "Restored %d of %d items from %s (%d failed)\n", restored, len(results), file, failed)
+	if err := opts.writeRows("link_fix_restore", results); err != nil {
+		return err
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d items could not be restored", failed, len(manifest.Items))
+	}
+	return nil
+}
+
+// readLinkRules reads the mapping rules CSV: pattern and replacement columns, and an optional name
+// for the report. Rules are tried in file order.
+func readLinkRules(file string) ([]linkRule, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, fmt.Errorf("error opening link rules %s: %w", file, err)
+	}
+	defer f.Close()
+	reader := csv.NewReader(f)
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("error reading header from %s: %w", file, err)
+	}
+	patternCol, replacementCol, nameCol := -1, -1, -1
+	for i, col := range header {
+		switch strings.TrimSpace(strings.ToLower(col)) {
+		case "pattern":
+			patternCol = i
+		case "replacement":
+			replacementCol = i
+		case "name":
+			nameCol = i
+		}
+	}
+	if patternCol < 0 || replacementCol < 0 {
+		return nil, fmt.Errorf("link rules %s need pattern and replacement columns", file)
+	}
+	var rules []linkRule
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error reading %s: %w", file, err)
+		}
+		re, err := regexp.Compile(record[patternCol])
+		if err != nil {
+			return nil, fmt.Errorf("%s line %d: invalid pattern: %w", file, line, err)
+		}
+		rule := linkRule{Pattern: re, Replacement: record[replacementCol], Name: record[patternCol]}
+		if nameCol >= 0 && record[nameCol] != "" {
+			rule.Name = record[nameCol]
+		}
+		rules = append(rules, rule)
+	}
+	if len(rules) == 0 {
+		return nil, fmt.Errorf("link rules %s has no rules", file)
+	}
+	return rules, nil
+}
+
+// writeLinkUndo writes the manifest through a temporary file, like the other state files.
+func writeLinkUndo(file string, manifest linkUndo) error {
+	if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+		return fmt.Errorf("error creating undo directory: %w", err)
+	}
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error encoding undo manifest: %w", err)
+	}
+	tmp := file + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("error writing undo manifest %s: %w", file, err)
+	}
+	return os.Rename(tmp, file)
+}
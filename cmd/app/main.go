@@ -1,327 +1,233 @@
 package main
 
 import (
-	"encoding/csv"
-	"encoding/json"
+	"context"
+	"errors"
+	"flag"
 	"fmt"
 	"log/slog"
+	"net"
+	"net/http"
 	"os"
-	"path"
 	"strings"
+	"time"
 
 	"github.com/coraxwolf/CCTA_3-4/pkg/canvas"
+	"github.com/coraxwolf/CCTA_3-4/pkg/config"
 	"github.com/joho/godotenv"
 )
 
-type CanvasCourse struct {
-	ID              int    `json:"id"`
-	Name            string `json:"name"`
-	WorkflowState   string `json:"workflow_state"`
-	DefaultViewType string `json:"default_view"`
-	Format          string `json:"course_format"`
-	CourseSISID     string `json:"sis_course_id"`
-}
-
 type CanvasUser struct {
 	ID    int    `json:"id" csv:"id"`
 	Name  string `json:"name" csv:"name"`
 	Email string `json:"email" csv:"email"`
 	SisID string `json:"sis_user_id" csv:"sis_user_id"`
+	URL   string `json:"url,omitempty" csv:"url"` // set by users lookup
 }
 
-type ResultItem struct {
-	CourseID        int    `json:"course_id" csv:"course_id"`
-	CourseName      string `json:"course_name" csv:"course_name"`
-	Subject         string `json:"subject" csv:"subject"`
-	Format          string `json:"format" csv:"format"`
-	WithModules     string `json:"with_modules" csv:"with_modules"`
-	WithAssignments string `json:"with_assignments" csv:"with_assignments"`
-	WithFrontPage   string `json:"with_front_page" csv:"with_front_page"`
-	FacultyName     string `json:"faculty_name" csv:"faculty_name"`
-	FacultyEmail    string `json:"faculty_email" csv:"faculty_email"`
+func canvasUser(u canvas.User) CanvasUser {
+	return CanvasUser{ID: u.ID, Name: u.Name, Email: u.Email, SisID: u.SISUserID}
 }
 
 var (
-	api *canvas.APIManager
+	api        *canvas.APIManager
+	cfg        config.Config
+	configFile string // --config, passed on to the runs of compare-env
 )
 
 func main() {
-	var results []ResultItem // Holder for final results
-	err := godotenv.Load(".env")
-	if err != nil {
+	if err := godotenv.Load(".env"); err != nil && !os.IsNotExist(err) {
 		fmt.Printf("Error loading .env file: %v\n", err)
-		return
+		os.Exit(1)
+	}
+	global := flag.NewFlagSet("app", flag.ContinueOnError)
+	global.Usage = func() { usage(global.Output()) }
+	configPath := global.String("config", os.Getenv("CCTA_CONFIG"), "YAML config file (default "+config.DefaultFile+" if present)")
+	profile := global.String("env", os.Getenv("CANVAS_ENV"), "config profile to use, e.g. prod, beta, or test (default "+config.DefaultProfile+")")
+	var flags config.Config
+	global.StringVar(&flags.BaseURL, "base-url", "", "Canvas API base URL")
+	global.IntVar(&flags.RateLimit, "rate-limit", 0, "Canvas rate limit bucket size")
+	global.IntVar(&flags.Timeout, "timeout", 0, "read timeout in seconds")
+	httpLog := global.String("http-log", os.Getenv("HTTP_LOG_LEVEL"), "log every HTTP request at this level: debug, info, or warn (off when empty)")
+	httpDump := global.String("http-dump", os.Getenv("HTTP_DUMP_DIR"), "write every response body to a file in this directory")
+	requestLog := global.String("request-log", os.Getenv("REQUEST_LOG"), "append a JSON line for every API request to this file")
+	metricsFile := global.String("metrics-file", os.Getenv("METRICS_FILE"), "keep per-endpoint request counts, costs, and throttles across runs in this JSON file")
+	metricsListen := global.String("metrics-listen", os.Getenv("METRICS_LISTEN"), "serve Prometheus metrics of the API requests at /metrics on this address, e.g. :9090, while the command runs")
+	cacheDir := global.String("cache-dir", os.Getenv("CACHE_DIR"), "keep GET responses in this directory and ask Canvas whether they changed (If-None-Match) instead of downloading them again")
+	cacheTTL := global.String("cache-ttl", envOr("CACHE_TTL", "168h"), "drop cached responses older than this (0 keeps them)")
+	noCache := global.Bool("no-cache", false, "don't use the response cache for this run")
+	dryRun := global.Bool("dry-run", os.Getenv("DRY_RUN") == "true", "log POST, PUT, and DELETE requests with their payload instead of sending them")
+	asUser := global.String("as-user", "", "send every request masquerading as this Canvas user ID (or sis_user_id:X); each one is logged")
+	preflightMode := global.String("preflight", envOr("PREFLIGHT", preflightWarn), "check maintenance windows and the Canvas status page first: off, warn, wait, or skip")
+	preflightWait := global.Duration("preflight-max-wait", 2*time.Hour, "longest --preflight wait before the run is skipped")
+	if err := global.Parse(os.Args[1:]); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return
+		}
+		os.Exit(2)
 	}
-
-	logger := slog.Default()
-	api = canvas.NewAPI(logger, os.Getenv("BETA_TOKEN"), os.Getenv("BETA_API_URL"), 700, 120)
-	fmt.Println("Starting to fetch Summer 2025 courses...")
-	// Get Summer 2025 courses (6253)
-	courses, err := getCourses("6253-")
-	if err != nil {
-		fmt.Printf("Error fetching courses: %v\n", err)
+	args := global.Args()
+	if len(args) == 0 || args[0] == "help" {
+		usage(os.Stdout)
 		return
 	}
-
-	// check each course if it is part of Summer 2025
-	var courseList []CanvasCourse
-	for _, course := range courses {
-		if strings.HasPrefix(course.CourseSISID, "6253-") {
-			courseList = append(courseList, course)
-		} else {
-			fmt.Printf("Skipping course %s (ID: %d) - not part of Summer 2025 --%s--\n", course.Name, course.ID, course.CourseSISID)
-		}
-	}
-	fmt.Printf("Found %d courses for Summer 2025\n", len(courseList))
-
-	// Pull Individual Course Data
-	for _, course := range courseList {
-		fmt.Printf("Processing course: %s (ID: %d) Workflow State %s\n", course.Name, course.ID, course.WorkflowState)
-		// Check if course is published or not (workflow_state == "unavailable")
-		if course.WorkflowState == "unpublished" {
-			var result ResultItem
-			result.CourseID = course.ID
-			result.CourseName = course.Name
-			result.Format = course.Format
-			parts := strings.Split(course.CourseSISID, "-")
-			if len(parts) == 4 {
-				result.Subject = parts[2] // Assuming the subject is the third part of the SIS ID
-			} else {
-				result.Subject = "Unknown"
-			}
-			// Check for Modules
-			mods, err := getCourseModules(course.ID)
-			if err != nil {
-				fmt.Printf("Error fetching modules for course %d: %v\n", course.ID, err)
-				result.WithModules = "Error"
-			}
-			if mods {
-				result.WithModules = "Yes"
-			} else {
-				result.WithModules = "No"
-			}
-			// Check if Default View is "wiki"
-			if course.DefaultViewType == "wiki" {
-				// Check for Front Page Content
-				fp, err := getCourseFrontPage(course.ID)
-				if err != nil {
-					fmt.Printf("Error fetching front page for course %d: %v\n", course.ID, err)
-					result.WithFrontPage = "Error"
-				}
-				if fp {
-					result.WithFrontPage = "Yes"
-				} else {
-					result.WithFrontPage = "No"
-				}
-			}
-			// Check for Assignments
-			asngs, err := getCourseAssignments(course.ID)
-			if err != nil {
-				fmt.Printf("Error fetching assignments for course %d: %v\n", course.ID, err)
-				result.WithAssignments = "Error"
-			}
-			if asngs {
-				result.WithAssignments = "Yes"
-			} else {
-				result.WithAssignments = "No"
+	configFile = *configPath
+	var err error
+	cfg, err = config.Load(*configPath, *profile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading configuration: %v\n", err)
+		os.Exit(1)
+	}
+	cfg.Merge(flags)
+	cmd, cmdArgs, ok := findCommand(args)
+	if !ok {
+		fmt.Fprintf(os.Stderr, "Unknown command: %s\n\n", strings.Join(args, " "))
+		usage(os.Stderr)
+		os.Exit(2)
+	}
+	if cmd.Feature != "" && !cfg.Enabled(cmd.Feature) {
+		fmt.Fprintf(os.Stderr, "Error: %s needs the experimental %s feature, which is off for profile %s (set experimental: {%s: true} in the config file or %s_EXPERIMENTAL=%s)\n",
+			cmd.FullName(), cmd.Feature, cfg.Profile, cmd.Feature, config.EnvPrefix(cfg.Profile), cmd.Feature)
+		os.Exit(2)
+	}
+	api = canvas.NewAPIFromConfig(slog.Default(), cfg)
+	if *httpLog != "" || *httpDump != "" {
+		level := slog.LevelDebug
+		if *httpLog != "" {
+			if err := level.UnmarshalText([]byte(*httpLog)); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: invalid --http-log level %q\n", *httpLog)
+				os.Exit(2)
 			}
-			// Pull Teachers from Course
-			teachers, err := getCourseTeachers(course.ID)
-			if err != nil {
-				fmt.Printf("Error fetching teachers for course %d: %v\n", course.ID, err)
-				result.FacultyName = "Error"
-				result.FacultyEmail = "Error"
-			} else if len(teachers) > 0 {
-				facultyNames := make([]string, 0)
-				facultyEmails := make([]string, 0)
-				for _, teacher := range teachers {
-					facultyNames = append(facultyNames, teacher.Name)
-					if teacher.Email != "" {
-						facultyEmails = append(facultyEmails, teacher.Email)
-					} else {
-						facultyEmails = append(facultyEmails, "No Email")
-					}
-				}
-				result.FacultyName = strings.Join(facultyNames, "; ")
-				result.FacultyEmail = strings.Join(facultyEmails, "; ")
-			} else {
-				result.FacultyName = "No Faculty"
-				result.FacultyEmail = "No Email"
+			if level < slog.LevelInfo {
+				slog.SetLogLoggerLevel(level)
 			}
-			fmt.Printf("Course %s (ID: %d) processed: Added to List (%d)\n", result.CourseName, result.CourseID, len(results)+1)
-			results = append(results, result)
+		}
+		if err := api.SetHTTPLogging(canvas.HTTPLogOptions{Level: level, DumpDir: *httpDump}); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
 		}
 	}
-
-	// Create csv output
-	filepath := path.Join("data", "reports")
-	_, err = os.Stat(filepath)
-	if os.IsNotExist(err) {
-		err = os.MkdirAll(filepath, 0755)
+	if *cacheDir != "" && !*noCache {
+		ttl, err := time.ParseDuration(*cacheTTL)
 		if err != nil {
-			fmt.Printf("Error creating directory %s: %v\n", filepath, err)
-			return
+			fmt.Fprintf(os.Stderr, "Error: invalid --cache-ttl %q: %v\n", *cacheTTL, err)
+			os.Exit(2)
+		}
+		if err := api.SetCache(canvas.CacheOptions{Dir: *cacheDir, TTL: ttl}); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
 		}
 	}
-	fmt.Printf("Gotten %d courses for Summer 2025\n", len(results))
-	outputFile := path.Join(filepath, "summer_2025_unpublished_courses.csv")
-	of, err := os.OpenFile(outputFile, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
-	if err != nil {
-		fmt.Printf("Error opening output file %s: %v\n", outputFile, err)
-		return
+	if *dryRun {
+		api.SetDryRun(true)
+		fmt.Fprintln(os.Stderr, "Dry run: changes are logged but not sent to Canvas")
 	}
-	defer of.Close()
-	writer := csv.NewWriter(of)
-	defer writer.Flush()
-	header := []string{"course_id", "course_name", "format", "subject", "with_modules", "with_assignments", "with_front_page", "faculty_name", "faculty_email"}
-	if err := writer.Write(header); err != nil {
-		fmt.Printf("Error writing header to CSV: %v\n", err)
-		return
+	if *asUser != "" {
+		api.SetAsUser(*asUser)
+		fmt.Fprintf(os.Stderr, "Acting as user %s: every request is masqueraded and logged\n", *asUser)
 	}
-	for _, result := range results {
-		record := []string{
-			fmt.Sprintf("%d", result.CourseID),
-			result.CourseName,
-			result.Format,
-			result.Subject,
-			result.WithModules,
-			result.WithAssignments,
-			result.WithFrontPage,
-			result.FacultyName,
-			result.FacultyEmail,
-		}
-		if err := writer.Write(record); err != nil {
-			fmt.Printf("Error writing record to CSV: %v\n", err)
-			return
+	if *requestLog != "" {
+		f, err := os.OpenFile(*requestLog, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error opening request log: %v\n", err)
+			os.Exit(1)
 		}
+		defer f.Close()
+		api.SetRequestLog(f)
 	}
-	fmt.Printf("Written Report to %s with %d entries\n", outputFile, len(results))
-}
-
-func getCourses(search string) ([]CanvasCourse, error) {
-	ep := fmt.Sprintf("accounts/1/courses?search_term=%s&per_page=100", search)
-	next := true               // assume more than one page of results
-	page := 1                  // page counter for debugging
-	var courses []CanvasCourse // holder for all courses
-	for next {
-		resp, err := api.Get(ep)
+	var metrics *canvas.MetricsStore
+	if *metricsFile != "" {
+		metrics, err = canvas.LoadMetricsStore(*metricsFile)
 		if err != nil {
-			return nil, fmt.Errorf("error fetching courses: %w", err)
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
 		}
-		defer resp.Body.Close() // Ensure the response body is closed after reading
-		ep = ""                 // Reset endpoint for next iteration
-		if resp.StatusCode != 200 {
-			return nil, fmt.Errorf("error fetching courses: received status code %d", resp.StatusCode)
+		api.SetMetricsStore(metrics)
+	}
+	if *metricsListen != "" {
+		if err := serveMetrics(*metricsListen); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
 		}
-		var pageCourses []CanvasCourse
-		if err := json.NewDecoder(resp.Body).Decode(&pageCourses); err != nil {
-			return nil, fmt.Errorf("error decoding courses response: %w", err)
+	}
+	if !cmd.Local {
+		statusURL := envOr("STATUS_URL", canvas.DefaultStatusURL)
+		if statusURL == "off" {
+			statusURL = ""
 		}
-		courses = append(courses, pageCourses...) // Add found courses to the final list
-		// Check if there is a next page
-		headers := resp.Header.Get("Link")
-		if headers == "" {
-			fmt.Printf("WARNING: No Link header found, assuming no more pages\n")
-			next = false
-		} else {
-			parts := strings.Split(headers, ",")
-			for _, part := range parts {
-				if strings.Contains(part, `rel="next"`) {
-					// Extract the URL for the next page
-					link := strings.Split(part, ";")
-					link[0] = strings.Trim(link[0], " <>")
-					ep, _ = strings.CutPrefix(link[0], os.Getenv("BETA_API_URL")) // Remove base URL from the link
-					fmt.Printf("DEBUG: Next page endpoint: %s\n", ep)
-					next = true
-					break // Exit the loop since we found the next page
-				}
-			}
-			if ep == "" {
-				fmt.Printf("WARNING: No next page found in Link header, stopping pagination\n")
-				fmt.Println("DEBUG: Headers:")
-				for key, values := range resp.Header {
-					fmt.Printf("  %s: %s\n", key, values)
-				}
-				next = false // No next page found, stop pagination
+		if err := preflight(context.Background(), *preflightMode, statusURL, *preflightWait); err != nil {
+			var skip errSkipRun
+			if errors.As(err, &skip) {
+				fmt.Fprintf(os.Stderr, "Skipping %s: %s\n", cmd.FullName(), skip.reason)
+				return
 			}
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(2)
 		}
-		page++
-		fmt.Printf("Getting Page %d of courses\n", page)
-	}
-	fmt.Printf("Found %d courses within %d total pages\n", len(courses), page)
-	return courses, nil
-}
-
-func getCourseTeachers(courseID int) ([]CanvasUser, error) {
-	fac, err := api.Get(fmt.Sprintf("courses/%d/users?enrollment_type=teacher", courseID))
-	if err != nil {
-		return nil, fmt.Errorf("error fetching teachers for course %d: %w", courseID, err)
 	}
-	if fac.StatusCode != 200 {
-		return nil, fmt.Errorf("error fetching teachers for course %d: received status code %d", courseID, fac.StatusCode)
+	err = cmd.Run(cmdArgs)
+	if metrics != nil {
+		if err := metrics.Save(cmd.FullName(), api.Stats()); err != nil {
+			fmt.Fprintf(os.Stderr, "Error saving metrics: %v\n", err)
+		}
 	}
-	var teachers []CanvasUser
-	if err := json.NewDecoder(fac.Body).Decode(&teachers); err != nil {
-		return nil, fmt.Errorf("error decoding teachers response for course %d: %w", courseID, err)
+	reportDeprecations(api.Deprecations())
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return
+		}
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		if hint := canvas.Hint(err); hint != "" {
+			fmt.Fprintf(os.Stderr, "Hint: %s\n", hint)
+		}
+		os.Exit(1)
 	}
-
-	return teachers, nil
 }
 
-func getCourseModules(courseID int) (bool, error) {
-	resp, err := api.Get(fmt.Sprintf("courses/%d/modules", courseID))
+// serveMetrics serves the API metrics for Prometheus in the background until the program exits.
+func serveMetrics(addr string) error {
+	ln, err := net.Listen("tcp", addr)
 	if err != nil {
-		return false, fmt.Errorf("error fetching course %d: %w", courseID, err)
-	}
-	if resp.StatusCode != 200 {
-		return false, fmt.Errorf("error fetching course %d: received status code %d", courseID, resp.StatusCode)
-	}
-	defer resp.Body.Close() // Ensure the response body is closed after reading
-	var mods []map[string]interface{}
-	if err := json.NewDecoder(resp.Body).Decode(&mods); err != nil {
-		return false, fmt.Errorf("error decoding modules response for course %d: %w", courseID, err)
-	}
-	if len(mods) > 0 {
-		return true, nil // Course has modules
-	}
-	return false, nil
+		return fmt.Errorf("error listening for metrics on %s: %w", addr, err)
+	}
+	mux := http.NewServeMux()
+	mux.Handle("GET /metrics", api.MetricsHandler())
+	srv := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		if err := srv.Serve(ln); err != nil {
+			slog.Error("metrics listener stopped", "error", err)
+		}
+	}()
+	fmt.Fprintf(os.Stderr, "Serving metrics at http://%s/metrics\n", ln.Addr())
+	return nil
 }
 
-func getCourseAssignments(courseID int) (bool, error) {
-	resp, err := api.Get(fmt.Sprintf("courses/%d/assignments?per_page=100", courseID))
-	if err != nil {
-		return false, fmt.Errorf("error fetching assignments for course %d: %w", courseID, err)
-	}
-	defer resp.Body.Close() // Ensure the response body is closed after reading
-	if resp.StatusCode != 200 {
-		return false, fmt.Errorf("error fetching assignments for course %d: received status code %d", courseID, resp.StatusCode)
-	}
-	var assignments []map[string]interface{}
-	if err := json.NewDecoder(resp.Body).Decode(&assignments); err != nil {
-		return false, fmt.Errorf("error decoding assignments response for course %d: %w", courseID, err)
+// reportDeprecations lists the deprecated endpoints the run called, so scheduled jobs can be
+// updated before Canvas removes them.
+func reportDeprecations(deprecations []canvas.Deprecation) {
+	if len(deprecations) == 0 {
+		return
 	}
-	if len(assignments) > 0 {
-		return true, nil // Course has assignments
+	fmt.Fprintf(os.Stderr, "Upcoming API changes: Canvas marked %d endpoints this run called as deprecated\n", len(deprecations))
+	for _, d := range deprecations {
+		line := fmt.Sprintf("  %s %s (%d requests)", d.Method, d.Endpoint, d.Requests)
+		if d.Sunset != nil {
+			line += ", removed after " + d.Sunset.Format(time.DateOnly)
+		}
+		if d.Message != "" {
+			line += ": " + d.Message
+		}
+		if d.Link != "" {
+			line += " -- see " + d.Link
+		}
+		fmt.Fprintln(os.Stderr, line)
 	}
-
-	return false, nil
 }
 
-func getCourseFrontPage(courseID int) (bool, error) {
-	resp, err := api.Get(fmt.Sprintf("courses/%d/front_page", courseID))
-	if err != nil {
-		return false, fmt.Errorf("error fetching front page for course %d: %w", courseID, err)
-	}
-	defer resp.Body.Close() // Ensure the response body is closed after reading
-	if resp.StatusCode != 200 {
-		return false, fmt.Errorf("error fetching front page for course %d: received status code %d", courseID, resp.StatusCode)
-	}
-	var frontPage map[string]interface{}
-	if err := json.NewDecoder(resp.Body).Decode(&frontPage); err != nil {
-		return false, fmt.Errorf("error decoding front page response for course %d: %w", courseID, err)
-	}
-	if content, ok := frontPage["body"].(string); ok && content != "" {
-		return true, nil // Course has front page content
+// envOr returns the environment variable, or def when it is unset or empty.
+func envOr(name, def string) string {
+	if v := os.Getenv(name); v != "" {
+		return v
 	}
-	return false, nil
+	return def
 }
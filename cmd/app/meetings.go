@@ -0,0 +1,322 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/coraxwolf/CCTA_3-4/pkg/canvas"
+)
+
+// Meeting is one class meeting pattern from the SIS schedule extract.
+type Meeting struct {
+	SectionSISID string
+	Key          string // section SIS ID and the pattern's position for that section, e.g. "6253-01-ENGL-101#1"
+	Days         []string
+	Start, End   string // HH:MM
+	StartDate    time.Time
+	EndDate      time.Time
+	Location     string
+	Title        string
+}
+
+type MeetingSyncItem struct {
+	SectionSISID string `json:"section_sis_id" csv:"section_sis_id"`
+	Meeting      string `json:"meeting" csv:"meeting"`
+	Action       string `json:"action" csv:"action"`
+	EventID      int    `json:"event_id" csv:"event_id"`
+	Detail       string `json:"detail" csv:"detail"`
+}
+
+const meetingsSyncSummary = "Create or update recurring calendar events for section meeting times from a SIS schedule"
+
+func init() {
+	register(command{Group: "sections", Name: "sync-meetings", Summary: meetingsSyncSummary, Run: runMeetingsSync})
+}
+
+func runMeetingsSync(args []string) error {
+	var opts commonOptions
+	fs := newFlagSet("sections sync-meetings", meetingsSyncSummary)
+	addOutputFlags(fs, &opts, "")
+	file := fs.String("file", "", "schedule CSV with section_sis_id, days, start_time, end_time, start_date, end_date, and optional location and title columns (required)")
+	tz := fs.String("tz", "", "time zone of the schedule, e.g. America/Chicago (default local time)")
+	prune := fs.Bool("prune", false, "delete synced events for meetings no longer in the schedule")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *file == "" {
+		return fmt.Errorf("--file is required")
+	}
+	// time.LoadLocation("") is UTC, so an empty --tz has to mean time.Local here.
+	loc := time.Local
+	if *tz != "" {
+		var err error
+		if loc, err = time.LoadLocation(*tz); err != nil {
+			return fmt.Errorf("invalid --tz: %w", err)
+		}
+	}
+	meetings, err := readSchedule(*file, loc)
+	if err != nil {
+		return err
+	}
+	bySection := make(map[string][]Meeting)
+	var sections []string
+	for _, m := range meetings {
+		if _, ok := bySection[m.SectionSISID]; !ok {
+			sections = append(sections, m.SectionSISID)
+		}
+		bySection[m.SectionSISID] = append(bySection[m.SectionSISID], m)
+	}
+
+	var results []MeetingSyncItem
+	for _, sisID := range sections {
+		items, err := syncSectionMeetings(sisID, bySection[sisID], *prune)
+		if err != nil {
+			results = append(results, MeetingSyncItem{SectionSISID: sisID, Action: "error", Detail: withHint(err)})
+			continue
+		}
+		results = append(results, items...)
+	}
+	return opts.writeRows("meeting_sync", results)
+}
+
+var meetingMarker = regexp.MustCompile(`ccta-meeting:(\S+) sig:([0-9a-f]+)`)
+
+func syncSectionMeetings(sisID string, meetings []Meeting, prune bool) ([]MeetingSyncItem, error) {
+	section, err := api.Sections().GetSectionBySISID(sisID)
+	if err != nil {
+		return nil, err
+	}
+	contextCode := fmt.Sprintf("course_section_%d", section.ID)
+	events, err := api.Calendar().ListEvents(canvas.CalendarEventListOptions{ContextCodes: []string{contextCode}, AllEvents: true})
+	if err != nil {
+		return nil, err
+	}
+	// a series is listed once per occurrence, so keep the first event of each synced meeting
+	type synced struct {
+		id  int
+		sig string
+	}
+	existing := make(map[string]synced)
+	for _, e := range events {
+		match := meetingMarker.FindStringSubmatch(e.Description)
+		if match == nil {
+			continue
+		}
+		if _, ok := existing[match[1]]; !ok {
+			existing[match[1]] = synced{id: e.ID, sig: match[2]}
+		}
+	}
+
+	var results []MeetingSyncItem
+	for _, m := range meetings {
+		item := MeetingSyncItem{SectionSISID: sisID, Meeting: m.Key}
+		input, sig := m.event(contextCode, section.Name)
+		current, ok := existing[m.Key]
+		delete(existing, m.Key)
+		switch {
+		case !ok:
+			event, err := api.Calendar().CreateEvent(input)
+			if err != nil {
+				item.Action, item.Detail = "error", withHint(err)
+			} else {
+				item.Action, item.EventID = "created", event.ID
+			}
+		case current.sig != sig:
+			input.ContextCode = "" // the section can't change
+			event, err := api.Calendar().UpdateEvent(current.id, input, canvas.SeriesAll)
+			if err != nil {
+				item.Action, item.EventID, item.Detail = "error", current.id, withHint(err)
+			} else {
+				item.Action, item.EventID = "updated", event.ID
+			}
+		default:
+			item.Action, item.EventID = "unchanged", current.id
+		}
+		results = append(results, item)
+	}
+
+	stale := make([]string, 0, len(existing))
+	for key := range existing {
+		stale = append(stale, key)
+	}
+	sort.Strings(stale)
+	for _, key := range stale {
+		item := MeetingSyncItem{SectionSISID: sisID, Meeting: key, EventID: existing[key].id, Action: "stale", Detail: "not in the schedule; use --prune to delete"}
+		if prune {
+			if err := api.Calendar().DeleteEvent(existing[key].id, canvas.SeriesAll); err != nil {
+				item.Action, item.Detail = "error", withHint(err)
+			} else {
+				item.Action, item.Detail = "deleted", ""
+			}
+		}
+		results = append(results, item)
+	}
+	return results, nil
+}
+
+var weekdays = []time.Weekday{time.Sunday, time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday, time.Saturday}
+
+var rruleDays = map[time.Weekday]string{
+	time.Sunday: "SU", time.Monday: "MO", time.Tuesday: "TU", time.Wednesday: "WE",
+	time.Thursday: "TH", time.Friday: "FR", time.Saturday: "SA",
+}
+
+// event builds the calendar series for the meeting and the signature used to detect schedule changes.
+func (m Meeting) event(contextCode, sectionName string) (canvas.CalendarEventInput, string) {
+	first := m.StartDate
+	for !m.meetsOn(first.Weekday()) {
+		first = first.AddDate(0, 0, 1)
+	}
+	start := atClock(first, m.Start)
+	end := atClock(first, m.End)
+	until := m.EndDate.AddDate(0, 0, 1).UTC().Format("20060102T150405Z")
+	rrule := fmt.Sprintf("FREQ=WEEKLY;BYDAY=%s;UNTIL=%s", strings.Join(m.Days, ","), until)
+	title := m.Title
+	if title == "" {
+		title = sectionName + " class meeting"
+	}
+	sum := sha256.Sum256([]byte(strings.Join([]string{title, m.Location, rrule, start.Format(time.RFC3339), end.Format(time.RFC3339)}, "|")))
+	sig := hex.EncodeToString(sum[:4])
+	return canvas.CalendarEventInput{
+		ContextCode:  contextCode,
+		Title:        title,
+		Description:  fmt.Sprintf("<p>Class meeting synced from the SIS schedule (ccta-meeting:%s sig:%s)</p>", m.Key, sig),
+		StartAt:      &start,
+		EndAt:        &end,
+		LocationName: m.Location,
+		RRule:        rrule,
+	}, sig
+}
+
+func (m Meeting) meetsOn(day time.Weekday) bool {
+	for _, d := range m.Days {
+		if rruleDays[day] == d {
+			return true
+		}
+	}
+	return false
+}
+
+func atClock(day time.Time, clock string) time.Time {
+	t, _ := time.Parse("15:04", clock) // validated when the schedule is read
+	return time.Date(day.Year(), day.Month(), day.Day(), t.Hour(), t.Minute(), 0, 0, day.Location())
+}
+
+// parseDays accepts SIS day letters ("MWF", "TR") or RRULE days ("MO,WE").
+func parseDays(s string) ([]string, error) {
+	s = strings.ToUpper(strings.TrimSpace(s))
+	if strings.Contains(s, ",") {
+		var days []string
+		for _, d := range strings.Split(s, ",") {
+			d = strings.TrimSpace(d)
+			if !validRRuleDay(d) {
+				return nil, fmt.Errorf("unknown day %q", d)
+			}
+			days = append(days, d)
+		}
+		return days, nil
+	}
+	letters := map[rune]time.Weekday{'U': time.Sunday, 'M': time.Monday, 'T': time.Tuesday, 'W': time.Wednesday, 'R': time.Thursday, 'F': time.Friday, 'S': time.Saturday}
+	seen := make(map[time.Weekday]bool)
+	for _, r := range s {
+		day, ok := letters[r]
+		if !ok {
+			return nil, fmt.Errorf("unknown day %q", string(r))
+		}
+		seen[day] = true
+	}
+	var days []string
+	for _, day := range weekdays {
+		if seen[day] {
+			days = append(days, rruleDays[day])
+		}
+	}
+	if len(days) == 0 {
+		return nil, fmt.Errorf("no days")
+	}
+	return days, nil
+}
+
+func validRRuleDay(d string) bool {
+	for _, v := range rruleDays {
+		if v == d {
+			return true
+		}
+	}
+	return false
+}
+
+func readSchedule(file string, loc *time.Location) ([]Meeting, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, fmt.Errorf("error opening schedule file %s: %w", file, err)
+	}
+	defer f.Close()
+	reader := csv.NewReader(f)
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("error reading header from %s: %w", file, err)
+	}
+	cols := make(map[string]int)
+	for i, col := range header {
+		cols[strings.TrimSpace(strings.ToLower(col))] = i
+	}
+	for _, name := range []string{"section_sis_id", "days", "start_time", "end_time", "start_date", "end_date"} {
+		if _, ok := cols[name]; !ok {
+			return nil, fmt.Errorf("schedule file %s needs a %s column", file, name)
+		}
+	}
+	get := func(record []string, name string) string {
+		if i, ok := cols[name]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+	var meetings []Meeting
+	count := make(map[string]int)
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error reading %s: %w", file, err)
+		}
+		m := Meeting{
+			SectionSISID: get(record, "section_sis_id"),
+			Start:        get(record, "start_time"),
+			End:          get(record, "end_time"),
+			Location:     get(record, "location"),
+			Title:        get(record, "title"),
+		}
+		if m.Days, err = parseDays(get(record, "days")); err != nil {
+			return nil, fmt.Errorf("line %d of %s: %w", line, file, err)
+		}
+		for _, clock := range []string{m.Start, m.End} {
+			if _, err := time.Parse("15:04", clock); err != nil {
+				return nil, fmt.Errorf("line %d of %s: invalid time %q, expected HH:MM", line, file, clock)
+			}
+		}
+		if m.StartDate, err = time.ParseInLocation("2006-01-02", get(record, "start_date"), loc); err != nil {
+			return nil, fmt.Errorf("line %d of %s: invalid start_date: %w", line, file, err)
+		}
+		if m.EndDate, err = time.ParseInLocation("2006-01-02", get(record, "end_date"), loc); err != nil {
+			return nil, fmt.Errorf("line %d of %s: invalid end_date: %w", line, file, err)
+		}
+		if m.EndDate.Before(m.StartDate) {
+			return nil, fmt.Errorf("line %d of %s: end_date is before start_date", line, file)
+		}
+		count[m.SectionSISID]++
+		m.Key = fmt.Sprintf("%s#%d", m.SectionSISID, count[m.SectionSISID])
+		meetings = append(meetings, m)
+	}
+	return meetings, nil
+}
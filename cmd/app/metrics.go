@@ -0,0 +1,89 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/coraxwolf/CCTA_3-4/pkg/canvas"
+)
+
+type EndpointMetricsItem struct {
+	Endpoint    string  `json:"endpoint" csv:"endpoint"`
+	Requests    int     `json:"requests" csv:"requests"`
+	Errors      int     `json:"errors" csv:"errors"`
+	AverageCost float64 `json:"average_cost" csv:"average_cost"`
+	TotalCost   float64 `json:"total_cost" csv:"total_cost"`
+	Throttles   int     `json:"throttles" csv:"throttles"`
+}
+
+type MetricsRunItem struct {
+	StartedAt       string  `json:"started_at" csv:"started_at"`
+	Command         string  `json:"command" csv:"command"`
+	DurationSeconds float64 `json:"duration_seconds" csv:"duration_seconds"`
+	Requests        int     `json:"requests" csv:"requests"`
+	AverageCost     float64 `json:"average_cost" csv:"average_cost"`
+	Throttles       int     `json:"throttles" csv:"throttles"`
+	ThrottleSeconds float64 `json:"throttle_seconds" csv:"throttle_seconds"`
+}
+
+const metricsReportSummary = "Report API consumption per endpoint or per run from the --metrics-file store"
+
+func init() {
+	register(command{Group: "metrics", Name: "report", Summary: metricsReportSummary, Run: runMetricsReport, Local: true})
+}
+
+func runMetricsReport(args []string) error {
+	var opts commonOptions
+	fs := newFlagSet("metrics report", metricsReportSummary)
+	addOutputFlags(fs, &opts, "")
+	file := fs.String("file", os.Getenv("METRICS_FILE"), "metrics store written with --metrics-file (required)")
+	runs := fs.Bool("runs", false, "list the recorded runs instead of the endpoint totals")
+	only := fs.String("command", "", "with --runs, only list runs of this command, e.g. \"courses unpublished-report\"")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *file == "" {
+		return fmt.Errorf("--file is required")
+	}
+	if _, err := os.Stat(*file); err != nil {
+		return fmt.Errorf("error reading metrics store: %w", err)
+	}
+	ms, err := canvas.LoadMetricsStore(*file)
+	if err != nil {
+		return err
+	}
+
+	if *runs {
+		var rows []MetricsRunItem
+		for _, run := range ms.Runs {
+			if *only != "" && !strings.EqualFold(run.Command, *only) {
+				continue
+			}
+			rows = append(rows, MetricsRunItem{
+				StartedAt:       run.StartedAt.Format("2006-01-02 15:04:05"),
+				Command:         run.Command,
+				DurationSeconds: run.FinishedAt.Sub(run.StartedAt).Seconds(),
+				Requests:        run.Requests,
+				AverageCost:     run.AverageCost,
+				Throttles:       run.Throttles,
+				ThrottleSeconds: run.ThrottleTime.Seconds(),
+			})
+		}
+		return opts.writeRows("metrics_runs", rows)
+	}
+
+	var rows []EndpointMetricsItem
+	for _, key := range ms.EndpointKeys() {
+		em := ms.Endpoints[key]
+		rows = append(rows, EndpointMetricsItem{
+			Endpoint:    key,
+			Requests:    em.Requests,
+			Errors:      em.Errors,
+			AverageCost: em.AverageCost(),
+			TotalCost:   em.TotalCost,
+			Throttles:   em.Throttles,
+		})
+	}
+	return opts.writeRows("metrics_endpoints", rows)
+}
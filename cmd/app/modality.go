@@ -0,0 +1,75 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/coraxwolf/CCTA_3-4/pkg/canvas"
+)
+
+const (
+	ModalityOnline      = "online"
+	ModalityHybrid      = "hybrid"
+	ModalityFaceToFace  = "face-to-face"
+	ModalityUnknown     = "unknown"
+	defaultModalityRule = "online=(?i)-(OL|WEB|W)[0-9]*$;hybrid=(?i)-(HY|H)[0-9]*$"
+)
+
+type ModalityRule struct {
+	Modality string
+	Pattern  *regexp.Regexp
+}
+
+type ModalityClassifier struct {
+	rules []ModalityRule
+}
+
+// NewModalityClassifier builds a classifier from a rule string in the form
+// "modality=regex;modality=regex". Rules are checked in order against the course SIS ID.
+func NewModalityClassifier(spec string) (*ModalityClassifier, error) {
+	if strings.TrimSpace(spec) == "" {
+		spec = defaultModalityRule
+	}
+	var rules []ModalityRule
+	for _, entry := range strings.Split(spec, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		name, pattern, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid modality rule %q: expected modality=pattern", entry)
+		}
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern for modality %q: %w", name, err)
+		}
+		rules = append(rules, ModalityRule{Modality: strings.TrimSpace(name), Pattern: re})
+	}
+	return &ModalityClassifier{rules: rules}, nil
+}
+
+// NewModalityClassifierFromEnv reads the rules from MODALITY_RULES, falling back to the defaults.
+func NewModalityClassifierFromEnv() (*ModalityClassifier, error) {
+	return NewModalityClassifier(os.Getenv("MODALITY_RULES"))
+}
+
+// Classify returns the modality for a course. SIS ID rules win, then the Canvas course_format setting.
+func (mc *ModalityClassifier) Classify(course canvas.Course) string {
+	for _, rule := range mc.rules {
+		if rule.Pattern.MatchString(course.SISCourseID) {
+			return rule.Modality
+		}
+	}
+	switch course.CourseFormat {
+	case "online":
+		return ModalityOnline
+	case "blended":
+		return ModalityHybrid
+	case "on_campus":
+		return ModalityFaceToFace
+	}
+	return ModalityUnknown
+}
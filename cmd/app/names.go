@@ -0,0 +1,77 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// NameNormalizer maps a Canvas user to the name the registrar has on record.
+type NameNormalizer interface {
+	OfficialName(user CanvasUser) string
+}
+
+// noopNormalizer keeps the Canvas display name.
+type noopNormalizer struct{}
+
+func (noopNormalizer) OfficialName(user CanvasUser) string {
+	return user.Name
+}
+
+// registrarNames looks users up by SIS user ID in a registrar extract and falls back to the Canvas name.
+type registrarNames struct {
+	bySISID map[string]string
+}
+
+func (rn registrarNames) OfficialName(user CanvasUser) string {
+	if name, ok := rn.bySISID[user.SisID]; ok && name != "" {
+		return name
+	}
+	return user.Name
+}
+
+// NewNameNormalizerFromEnv loads REGISTRAR_NAMES_FILE (a CSV with sis_user_id and official_name columns)
+// or returns the no-op normalizer when it is not set.
+func NewNameNormalizerFromEnv() (NameNormalizer, error) {
+	file := os.Getenv("REGISTRAR_NAMES_FILE")
+	if file == "" {
+		return noopNormalizer{}, nil
+	}
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, fmt.Errorf("error opening registrar names file %s: %w", file, err)
+	}
+	defer f.Close()
+	reader := csv.NewReader(f)
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("error reading header from %s: %w", file, err)
+	}
+	header[0] = strings.TrimPrefix(header[0], "\ufeff") // Excel saves CSV with a byte order mark
+	sisCol, nameCol := -1, -1
+	for i, col := range header {
+		switch strings.TrimSpace(strings.ToLower(col)) {
+		case "sis_user_id":
+			sisCol = i
+		case "official_name":
+			nameCol = i
+		}
+	}
+	if sisCol < 0 || nameCol < 0 {
+		return nil, fmt.Errorf("registrar names file %s needs sis_user_id and official_name columns", file)
+	}
+	names := registrarNames{bySISID: make(map[string]string)}
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error reading %s: %w", file, err)
+		}
+		names.bySISID[strings.TrimSpace(record[sisCol])] = strings.TrimSpace(record[nameCol])
+	}
+	return names, nil
+}
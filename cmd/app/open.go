@@ -0,0 +1,54 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+)
+
+const coursesOpenSummary = "Open a course, or one of its pages, in the browser"
+
+func init() {
+	register(command{Group: "courses", Name: "open", Summary: coursesOpenSummary, Run: runCoursesOpen, Local: true})
+}
+
+func runCoursesOpen(args []string) error {
+	fs := newFlagSet("courses open", coursesOpenSummary)
+	courseID := fs.Int("course", 0, "Canvas course ID (required)")
+	page := fs.String("page", "", "course page to open, e.g. modules, assignments, users, or assignments/syllabus")
+	printOnly := fs.Bool("print", false, "print the link instead of opening it")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *courseID <= 0 {
+		return fmt.Errorf("--course is required")
+	}
+	link := api.CourseURL(*courseID, *page)
+	if link == "" {
+		return fmt.Errorf("can't build a link from the base URL %q", cfg.BaseURL)
+	}
+	fmt.Println(link)
+	if *printOnly {
+		return nil
+	}
+	return openBrowser(link)
+}
+
+// openBrowser opens link in the user's default browser.
+func openBrowser(link string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", link)
+	case "darwin":
+		cmd = exec.Command("open", link)
+	default:
+		cmd = exec.Command("xdg-open", link)
+	}
+	cmd.Stderr = os.Stderr
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("error opening the browser (use --print to only print the link): %w", err)
+	}
+	return cmd.Process.Release()
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/coraxwolf/CCTA_3-4/pkg/canvas"
+)
+
+// Pre-flight modes. In warn mode a disruption is only reported; wait sleeps until it is over
+// (up to the max wait) and then skips; skip ends the run right away.
+const (
+	preflightOff  = "off"
+	preflightWarn = "warn"
+	preflightWait = "wait"
+	preflightSkip = "skip"
+)
+
+// statusPollInterval is how often the status page is checked again while waiting out an incident.
+const statusPollInterval = 5 * time.Minute
+
+// errSkipRun ends a run that the pre-flight check skipped.
+type errSkipRun struct{ reason string }
+
+func (e errSkipRun) Error() string { return "skipped: " + e.reason }
+
+// preflight checks the configured maintenance windows and the status page before a command
+// talks to Canvas. It returns errSkipRun when the run should not go ahead.
+func preflight(ctx context.Context, mode, statusURL string, maxWait time.Duration) error {
+	switch mode {
+	case preflightOff:
+		return nil
+	case preflightWarn, preflightWait, preflightSkip:
+	default:
+		return fmt.Errorf("invalid --preflight mode %q: use off, warn, wait, or skip", mode)
+	}
+	deadline := time.Now().Add(maxWait)
+	for {
+		reason, until := disruption(ctx, statusURL)
+		if reason == "" {
+			return nil
+		}
+		switch {
+		case mode == preflightWarn:
+			fmt.Fprintf(os.Stderr, "Warning: %s; results may be incomplete\n", reason)
+			return nil
+		case mode == preflightSkip || !until.Before(deadline):
+			return errSkipRun{reason}
+		}
+		wait := time.Until(until)
+		fmt.Fprintf(os.Stderr, "%s; waiting %s\n", reason, wait.Round(time.Second))
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+	}
+}
+
+// disruption returns why Canvas is unavailable now, or "", and when to check again.
+func disruption(ctx context.Context, statusURL string) (string, time.Time) {
+	now := time.Now()
+	if w, ok := cfg.ActiveMaintenance(now); ok {
+		reason := w.Reason
+		if reason == "" {
+			reason = "maintenance"
+		}
+		return fmt.Sprintf("%s profile is in a maintenance window (%s) until %s", cfg.Profile, reason, w.End.Local().Format(time.DateTime)), w.End
+	}
+	if statusURL == "" {
+		return "", time.Time{}
+	}
+	status, err := canvas.CheckStatus(ctx, statusURL)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: could not check the Canvas status page: %v\n", err)
+		return "", time.Time{}
+	}
+	for _, m := range status.Maintenance {
+		if m.Status == "scheduled" && m.Start.Before(now.Add(time.Hour)) {
+			fmt.Fprintf(os.Stderr, "Warning: Canvas maintenance %q is scheduled to start at %s\n", m.Name, m.Start.Local().Format(time.DateTime))
+		}
+	}
+	notice, ok := status.Disrupted()
+	if !ok {
+		return "", time.Time{}
+	}
+	until := notice.End
+	if until.IsZero() || until.Before(now) {
+		until = now.Add(statusPollInterval)
+	}
+	return fmt.Sprintf("Canvas status page reports %q (%s)", notice.Name, notice.Status), until
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/coraxwolf/CCTA_3-4/pkg/canvas"
+)
+
+type ReplayItem struct {
+	Time           string `json:"time" csv:"time"`
+	Method         string `json:"method" csv:"method"`
+	Endpoint       string `json:"endpoint" csv:"endpoint"`
+	OriginalStatus int    `json:"original_status" csv:"original_status"`
+	OriginalError  string `json:"original_error" csv:"original_error"`
+	ReplayStatus   int    `json:"replay_status" csv:"replay_status"`
+	ReplayError    string `json:"replay_error" csv:"replay_error"`
+}
+
+const requestsReplaySummary = "Send the requests recorded in a request log again"
+
+func init() {
+	register(command{Group: "requests", Name: "replay", Summary: requestsReplaySummary, Run: runRequestsReplay})
+}
+
+func runRequestsReplay(args []string) error {
+	var opts commonOptions
+	fs := newFlagSet("requests replay", requestsReplaySummary)
+	addOutputFlags(fs, &opts, "")
+	logFile := fs.String("log", "", "request log written with --request-log (required)")
+	onlyFailed := fs.Bool("only-failed", false, "only replay requests that failed or returned a non-2xx status")
+	allowWrites := fs.Bool("allow-writes", false, "also replay POST, PUT, and DELETE requests (GET only by default)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *logFile == "" {
+		return fmt.Errorf("--log is required")
+	}
+	f, err := os.Open(*logFile)
+	if err != nil {
+		return fmt.Errorf("error opening request log: %w", err)
+	}
+	records, err := canvas.ReadRequestLog(f)
+	f.Close()
+	if err != nil {
+		return err
+	}
+
+	var results []ReplayItem
+	skipped, dryRuns := 0, 0
+	for _, rec := range records {
+		if rec.DryRun {
+			dryRuns++ // never sent, so there is nothing to compare
+			continue
+		}
+		if *onlyFailed && !rec.Failed() {
+			continue
+		}
+		if rec.Method != http.MethodGet && !*allowWrites {
+			skipped++
+			continue
+		}
+		item := ReplayItem{
+			Time:           rec.Time.Format(time.RFC3339),
+			Method:         rec.Method,
+			Endpoint:       rec.Endpoint,
+			OriginalStatus: rec.Status,
+			OriginalError:  rec.Error,
+		}
+		resp, err := api.Replay(context.Background(), rec)
+		if err != nil {
+			item.ReplayError = withHint(err)
+		} else {
+			item.ReplayStatus = resp.StatusCode
+			if resp.StatusCode < 200 || resp.StatusCode > 299 {
+				item.ReplayError = withHint(canvas.NewAPIError(resp))
+			}
+			resp.Body.Close()
+		}
+		results = append(results, item)
+	}
+	if dryRuns > 0 {
+		fmt.Fprintf(os.Stderr, "Skipped %d dry-run requests, which were never sent\n", dryRuns)
+	}
+	if skipped > 0 {
+		fmt.Fprintf(os.Stderr, "Skipped %d write requests (use --allow-writes to replay them)\n", skipped)
+	}
+	return opts.writeRows("replay", results)
+}
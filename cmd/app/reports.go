@@ -0,0 +1,124 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type ReportTypeItem struct {
+	Report     string `json:"report" csv:"report"`
+	Title      string `json:"title" csv:"title"`
+	Parameters string `json:"parameters" csv:"parameters"` // required parameters are marked with *
+	LastRun    string `json:"last_run" csv:"last_run"`
+}
+
+const (
+	reportsListSummary = "List the Canvas account reports the account can run"
+	reportsRunSummary  = "Run a Canvas account report, wait for it, and download its files"
+)
+
+func init() {
+	register(command{Group: "reports", Name: "list", Summary: reportsListSummary, Run: runReportsList})
+	register(command{Group: "reports", Name: "run", Summary: reportsRunSummary, Run: runReportsRun})
+}
+
+func runReportsList(args []string) error {
+	var opts commonOptions
+	fs := newFlagSet("reports list", reportsListSummary)
+	addAccountFlags(fs, &opts)
+	addOutputFlags(fs, &opts, "")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	reports, err := api.AccountReports().ListAvailableReports(opts.AccountID)
+	if err != nil {
+		return err
+	}
+	rows := make([]ReportTypeItem, 0, len(reports))
+	for _, r := range reports {
+		params := make([]string, 0, len(r.Parameters))
+		for name, p := range r.Parameters {
+			if p.Required {
+				name += "*"
+			}
+			params = append(params, name)
+		}
+		sort.Strings(params)
+		item := ReportTypeItem{Report: r.Report, Title: r.Title, Parameters: strings.Join(params, ",")}
+		if r.LastRun != nil && r.LastRun.CreatedAt != nil {
+			item.LastRun = r.LastRun.CreatedAt.Format(time.RFC3339)
+		}
+		rows = append(rows, item)
+	}
+	sort.Slice(rows, func(i, j int) bool { return rows[i].Report < rows[j].Report })
+	return opts.writeRows("account_reports", rows)
+}
+
+func runReportsRun(args []string) error {
+	var opts commonOptions
+	fs := newFlagSet("reports run", reportsRunSummary)
+	addAccountFlags(fs, &opts)
+	defaultDir := path.Join("data", "reports")
+	if cfg.OutputDir != "" {
+		defaultDir = cfg.OutputDir
+	}
+	report := fs.String("report", "", "report to run, e.g. provisioning_csv or unpublished_courses_csv (see reports list) (required)")
+	fs.StringVar(&opts.Term, "term", "", "limit the report to a term (SIS term ID, name, or Canvas term ID)")
+	params := fs.String("param", "", "comma separated report parameters as name=value, or name for true, e.g. users,courses,include_deleted=false")
+	dir := fs.String("output", defaultDir, "directory to save the report files in")
+	interval := fs.Duration("interval", 15*time.Second, "how often to check the report while waiting")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *report == "" {
+		return fmt.Errorf("--report is required")
+	}
+	parameters := make(map[string]any)
+	for _, p := range splitList(*params) {
+		name, value, ok := strings.Cut(p, "=")
+		if !ok {
+			parameters[name] = true
+			continue
+		}
+		if b, err := strconv.ParseBool(value); err == nil {
+			parameters[name] = b
+		} else {
+			parameters[name] = value
+		}
+	}
+	if opts.Term != "" {
+		term, err := api.Terms().FindTerm(opts.AccountID, opts.Term)
+		if err != nil {
+			return fmt.Errorf("error resolving --term: %w", err)
+		}
+		parameters["enrollment_term_id"] = term.ID
+	}
+
+	reports := api.AccountReports()
+	run, err := reports.StartReport(opts.AccountID, *report, parameters)
+	if err != nil {
+		return err
+	}
+	if api.DryRun() {
+		fmt.Fprintf(os.Stderr, "Dry run: report %s was not started\n", *report)
+		return nil
+	}
+	fmt.Fprintf(os.Stderr, "Started report %s (ID: %d), waiting for Canvas to build it\n", *report, run.ID)
+	run, err = reports.WaitReport(opts.AccountID, *report, run.ID, *interval)
+	if err != nil {
+		return err
+	}
+	files, err := reports.Download(run, *dir)
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		fmt.Printf("Written Report to %s\n", file)
+	}
+	return nil
+}
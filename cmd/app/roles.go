@@ -0,0 +1,158 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/coraxwolf/CCTA_3-4/pkg/canvas"
+	"github.com/coraxwolf/CCTA_3-4/pkg/report"
+)
+
+const rolesPermissionsSummary = "Export every role's permissions as a matrix, or list the changes since a saved export"
+
+func init() {
+	register(command{Group: "roles", Name: "permissions", Summary: rolesPermissionsSummary, Run: runRolesPermissions})
+}
+
+func runRolesPermissions(args []string) error {
+	var opts commonOptions
+	fs := newFlagSet("roles permissions", rolesPermissionsSummary)
+	addAccountFlags(fs, &opts)
+	addOutputFlags(fs, &opts, "")
+	only := fs.String("roles", "", "comma separated role labels to include (default every active role)")
+	baseline := fs.String("baseline", "", "an earlier export of the matrix (CSV or JSON) to list the changes against instead")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	roles, err := api.Roles().ListRoles(opts.AccountID)
+	if err != nil {
+		return err
+	}
+	if labels := splitList(*only); len(labels) > 0 {
+		roles = slices.DeleteFunc(roles, func(r canvas.Role) bool { return !slices.Contains(labels, r.Label) })
+		if len(roles) == 0 {
+			return fmt.Errorf("no active role in account %d is labelled %s", opts.AccountID, strings.Join(labels, " or "))
+		}
+	}
+	matrix := permissionsMatrix(roles)
+	fmt.Fprintf(os.Stderr, "%d roles, %d permissions\n", len(roles), len(matrix.Records))
+	if *baseline == "" {
+		return opts.writeRows(fmt.Sprintf("%d_permissions", opts.AccountID), matrix)
+	}
+
+	old, err := readSnapshot(*baseline)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(matrix)
+	if err != nil {
+		return err
+	}
+	var current []map[string]any
+	if err := json.Unmarshal(data, &current); err != nil {
+		return err
+	}
+	items := diffRows([]string{"baseline", "current"}, "permission", nil, old, current)
+	fmt.Fprintf(os.Stderr, "%d differences from %s\n", len(items), *baseline)
+	return opts.writeRows(fmt.Sprintf("%d_permissions_diff", opts.AccountID), items)
+}
+
+// permissionsMatrix has a row per permission and a column per role, labelled as on the
+// Permissions page. A cell is "on" or "off", with "(locked)" added when sub-accounts can't change it.
+func permissionsMatrix(roles []canvas.Role) report.Table {
+	labels := make(map[string]int)
+	for _, role := range roles {
+		labels[role.Label]++
+	}
+	header := []string{"permission"}
+	var names []string
+	for _, role := range roles {
+		label := role.Label
+		if labels[label] > 1 { // e.g. a custom role named after a base role
+			label += " (" + strconv.Itoa(role.ID) + ")"
+		}
+		header = append(header, label)
+		for name := range role.Permissions {
+			if !slices.Contains(names, name) {
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+	table := report.Table{Header: header}
+	for _, name := range names {
+		record := []string{name}
+		for _, role := range roles {
+			perm, ok := role.Permissions[name]
+			cell := ""
+			switch {
+			case !ok: // the permission doesn't apply to the role's type
+			case perm.Enabled:
+				cell = "on"
+			default:
+				cell = "off"
+			}
+			if ok && perm.Locked {
+				cell += " (locked)"
+			}
+			record = append(record, cell)
+		}
+		table.Records = append(table.Records, record)
+	}
+	return table
+}
+
+// readSnapshot reads an earlier report export, a JSON list of objects or a CSV file with a header,
+// into rows for diffRows.
+func readSnapshot(file string) ([]map[string]any, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("error reading %s: %w", file, err)
+	}
+	var rows []map[string]any
+	if strings.EqualFold(filepath.Ext(file), ".json") {
+		if err := json.Unmarshal(data, &rows); err != nil {
+			return nil, fmt.Errorf("error decoding %s: %w", file, err)
+		}
+		return rows, nil
+	}
+	reader := csv.NewReader(strings.NewReader(strings.TrimPrefix(string(data), "\ufeff")))
+	reader.Comma = detectDelimiter(data)
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("error reading %s: %w", file, err)
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("%s is empty", file)
+	}
+	for _, record := range records[1:] {
+		row := make(map[string]any, len(record))
+		for i, col := range records[0] {
+			if i < len(record) {
+				row[col] = record[i]
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// detectDelimiter picks the delimiter of a CSV export from its header line, for exports written
+// with --delimiter or REPORT_DELIMITER.
+func detectDelimiter(data []byte) rune {
+	header, _, _ := strings.Cut(string(data), "\n")
+	best, count := ',', strings.Count(header, ",")
+	for _, d := range []rune{';', '\t'} {
+		if n := strings.Count(header, string(d)); n > count {
+			best, count = d, n
+		}
+	}
+	return best
+}
@@ -0,0 +1,263 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"hash/fnv"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/coraxwolf/CCTA_3-4/pkg/audit"
+	"github.com/coraxwolf/CCTA_3-4/pkg/canvas"
+)
+
+type SampleItem struct {
+	Department  string `json:"department" csv:"department"`
+	Stratum     string `json:"stratum" csv:"stratum"`
+	CourseID    int    `json:"course_id" csv:"course_id"`
+	CourseName  string `json:"course_name" csv:"course_name"`
+	SISCourseID string `json:"sis_course_id" csv:"sis_course_id"`
+	Modality    string `json:"modality" csv:"modality"`
+	Faculty     string `json:"faculty" csv:"faculty"`
+	URL         string `json:"url" csv:"url"`
+	Packet      string `json:"packet" csv:"packet"`
+	Errors      string `json:"errors" csv:"errors"`
+}
+
+const sampleSummary = "Pick a reproducible random sample of courses per department for quality review, with reviewer packets"
+
+func init() {
+	register(command{Group: "sample", Summary: sampleSummary, Run: runSample})
+}
+
+func runSample(args []string) error {
+	var opts commonOptions
+	fs := newFlagSet("sample", sampleSummary)
+	addTermFlags(fs, &opts)
+	addAccountFlags(fs, &opts)
+	addOutputFlags(fs, &opts, filepath.Join("data", "reports"))
+	perDept := fs.Int("per-department", 3, "courses to sample from each department (the subject in the SIS course ID)")
+	stratify := fs.String("stratify", "none", "spread each department's sample over modality, format (the Canvas course format), or none")
+	seed := fs.Uint64("seed", 0, "random seed; the same seed and course list give the same sample (default derived from --term)")
+	published := fs.Bool("published-only", true, "only sample published courses")
+	packets := fs.String("packets", "", "directory for the reviewer packets (default review_<term>_<seed> in the output directory)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if opts.Term == "" {
+		return fmt.Errorf("--term is required")
+	}
+	if *perDept < 1 {
+		return fmt.Errorf("--per-department must be at least 1")
+	}
+	modalities, err := NewModalityClassifierFromEnv()
+	if err != nil {
+		return fmt.Errorf("error loading modality rules: %w", err)
+	}
+	var stratum func(canvas.Course) string
+	switch *stratify {
+	case "none":
+		stratum = func(canvas.Course) string { return "all" }
+	case "modality":
+		stratum = modalities.Classify
+	case "format":
+		stratum = func(c canvas.Course) string {
+			if c.CourseFormat == "" {
+				return "unset"
+			}
+			return c.CourseFormat
+		}
+	default:
+		return fmt.Errorf("--stratify must be modality, format, or none")
+	}
+	if *seed == 0 {
+		h := fnv.New64a()
+		h.Write([]byte(opts.Term))
+		*seed = h.Sum64()
+	}
+
+	list := canvas.CourseListOptions{}
+	if *published {
+		list.Published = published
+	}
+	courses, err := opts.termCourses(list)
+	if err != nil {
+		return fmt.Errorf("error fetching courses: %w", err)
+	}
+	// the sample must not depend on the order Canvas lists the courses in
+	sort.Slice(courses, func(i, j int) bool { return courses[i].ID < courses[j].ID })
+	departments := make(map[string]map[string][]canvas.Course)
+	for _, course := range courses {
+		dept := programOf(course)
+		if departments[dept] == nil {
+			departments[dept] = make(map[string][]canvas.Course)
+		}
+		s := stratum(course)
+		departments[dept][s] = append(departments[dept][s], course)
+	}
+	depts := make([]string, 0, len(departments))
+	for dept := range departments {
+		depts = append(depts, dept)
+	}
+	sort.Strings(depts)
+
+	dir := *packets
+	if dir == "" {
+		dir = filepath.Join(opts.Output, fmt.Sprintf("review_%s_%d", unsafeNameChars.ReplaceAllString(opts.Term, "_"), *seed))
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("error creating packet directory %s: %w", dir, err)
+	}
+	rng := rand.New(rand.NewPCG(*seed, 0))
+	var results []SampleItem
+	for _, dept := range depts {
+		strata := departments[dept]
+		counts := allocate(strata, *perDept)
+		for _, s := range sortedKeys(strata) {
+			for _, course := range sampleCourses(rng, strata[s], counts[s]) {
+				item := SampleItem{Department: dept, Stratum: s, CourseID: course.ID, CourseName: course.Name, SISCourseID: course.SISCourseID, Modality: modalities.Classify(course), URL: api.CourseURL(course.ID, "")}
+				fmt.Fprintf(os.Stderr, "Sampled %s (ID: %d) for %s/%s\n", course.Name, course.ID, dept, s)
+				packet, faculty, errs := reviewPacket(course, dept, s, item.Modality)
+				item.Faculty = faculty
+				item.Errors = strings.Join(errs, "; ")
+				// the department and key come from SIS IDs, so a / or .. in them must not leave the packet directory
+				file := filepath.Join(dir, fmt.Sprintf("%s_%s.md", unsafeNameChars.ReplaceAllString(dept, "_"), unsafeNameChars.ReplaceAllString(calendarKey(course), "_")))
+				if err := os.WriteFile(file, packet, 0o644); err != nil {
+					return fmt.Errorf("error writing packet %s: %w", file, err)
+				}
+				item.Packet = file
+				results = append(results, item)
+			}
+		}
+	}
+	fmt.Fprintf(os.Stderr, "Sampled %d of %d courses from %d departments (seed %d); packets in %s\n", len(results), len(courses), len(depts), *seed, dir)
+	return opts.writeRows(fmt.Sprintf("%s_review_sample", opts.Term), results)
+}
+
+// allocate splits a department's sample over its strata in proportion to their sizes, rounding by
+// largest remainder. Ties go to the stratum that sorts first, so the split is reproducible.
+func allocate(strata map[string][]canvas.Course, n int) map[string]int {
+	total := 0
+	for _, courses := range strata {
+		total += len(courses)
+	}
+	if n > total {
+		n = total
+	}
+	keys := sortedKeys(strata)
+	counts := make(map[string]int)
+	remainders := make(map[string]float64)
+	assigned := 0
+	for _, k := range keys {
+		exact := float64(n) * float64(len(strata[k])) / float64(total)
+		counts[k] = int(exact)
+		remainders[k] = exact - float64(counts[k])
+		assigned += counts[k]
+	}
+	sort.SliceStable(keys, func(i, j int) bool { return remainders[keys[i]] > remainders[keys[j]] })
+	for _, k := range keys {
+		if assigned == n {
+			break
+		}
+		if counts[k] < len(strata[k]) {
+			counts[k]++
+			assigned++
+		}
+	}
+	return counts
+}
+
+// sampleCourses picks n courses without replacement, keeping them in course ID order.
+func sampleCourses(rng *rand.Rand, courses []canvas.Course, n int) []canvas.Course {
+	if n >= len(courses) {
+		return courses
+	}
+	picked := rng.Perm(len(courses))[:n]
+	sort.Ints(picked)
+	sample := make([]canvas.Course, n)
+	for i, p := range picked {
+		sample[i] = courses[p]
+	}
+	return sample
+}
+
+func sortedKeys(m map[string][]canvas.Course) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// reviewPacket builds the Markdown packet a reviewer works from: the course's content counts,
+// faculty, links to the pages to look at, and a checklist. It returns the faculty names and the
+// checks that failed, which are noted in the packet too.
+func reviewPacket(course canvas.Course, dept, stratum, modality string) ([]byte, string, []string) {
+	var errs []string
+	note := func(what string, err error) string {
+		errs = append(errs, what+": "+withHint(err))
+		return "Error: " + withHint(err)
+	}
+	audited := audit.NewCourseAuditor(api, audit.CheckModules, audit.CheckAssignments, audit.CheckQuizzes, audit.CheckTeachers).
+		Audit(context.Background(), course)
+	result := func(c, value string) string {
+		if err := audited.Err(c); err != nil {
+			return note(checkLabel(c), err)
+		}
+		return value
+	}
+	modules := result(audit.CheckModules, strconv.Itoa(audited.Modules))
+	items := ""
+	if audited.Ran(audit.CheckModules) {
+		items = strconv.Itoa(audited.ModuleItems)
+	}
+	assignments := result(audit.CheckAssignments, strconv.Itoa(audited.Assignments))
+	quizzes := result(audit.CheckQuizzes, audited.Quizzes.String())
+	var faculty []string
+	if err := audited.Err(audit.CheckTeachers); err != nil {
+		faculty = []string{note("teachers", err)}
+	}
+	for _, t := range audited.Teachers {
+		faculty = append(faculty, t.Name)
+	}
+	if len(faculty) == 0 {
+		faculty = []string{"No Faculty"}
+	}
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "# Quality review: %s\n\n", course.Name)
+	fmt.Fprintf(&b, "- Course ID: %d\n- SIS course ID: %s\n- Department: %s\n- Sample stratum: %s\n- Modality: %s\n- State: %s\n- Faculty: %s\n\n",
+		course.ID, course.SISCourseID, dept, stratum, modality, course.WorkflowState, strings.Join(faculty, "; "))
+	fmt.Fprintf(&b, "## Content\n\n| Check | Result |\n| --- | --- |\n")
+	fmt.Fprintf(&b, "| Modules | %s |\n| Module items | %s |\n| Assignments | %s |\n| Quizzes | %s |\n| Default view | %s |\n\n", modules, items, assignments, quizzes, course.DefaultView)
+	fmt.Fprintf(&b, "## Links\n\n")
+	for _, link := range []struct{ name, page string }{
+		{"Home", ""}, {"Modules", "modules"}, {"Assignments", "assignments"}, {"Quizzes", "quizzes"},
+		{"Syllabus", "assignments/syllabus"}, {"People", "users"}, {"Settings", "settings"},
+	} {
+		fmt.Fprintf(&b, "- [%s](%s)\n", link.name, api.CourseURL(course.ID, link.page))
+	}
+	fmt.Fprintf(&b, "\n## Reviewer checklist\n\n")
+	for _, check := range []string{
+		"Syllabus is posted and current",
+		"Modules are organized and published in order",
+		"Due dates are set and fall within the term",
+		"Instructor contact information is present",
+		"Accessibility: headings, alt text, and captions",
+	} {
+		fmt.Fprintf(&b, "- [ ] %s\n", check)
+	}
+	fmt.Fprintf(&b, "\nReviewer: ________  Date: ________  Notes:\n\n")
+	if len(errs) > 0 {
+		fmt.Fprintf(&b, "_Some checks could not run: %s_\n", strings.Join(errs, "; "))
+	}
+	fmt.Fprintf(&b, "_Generated %s_\n", time.Now().Format(time.DateOnly))
+	return b.Bytes(), strings.Join(faculty, "; "), errs
+}
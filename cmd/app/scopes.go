@@ -0,0 +1,228 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// termScopes are needed by every command that takes --term: the term is resolved and the account's
+// courses are listed.
+var termScopes = []string{
+	"url:GET|/api/v1/accounts/:account_id/terms",
+	"url:GET|/api/v1/accounts/:account_id/courses",
+}
+
+// commandScopes lists the developer key scopes each command calls, so a scoped key can be issued
+// for a job instead of a full admin token. Keep it in step with the commands' API calls.
+var commandScopes = map[string][]string{
+	"blueprint sync": slices.Concat(termScopes, []string{
+		"url:GET|/api/v1/courses/:course_id/blueprint_templates/:template_id",
+		"url:GET|/api/v1/courses/:course_id/blueprint_templates/:template_id/associated_courses",
+		"url:PUT|/api/v1/courses/:course_id/blueprint_templates/:template_id/update_associations",
+		"url:POST|/api/v1/courses/:course_id/blueprint_templates/:template_id/migrations",
+		"url:GET|/api/v1/courses/:course_id/blueprint_templates/:template_id/migrations/:id",
+	}),
+	"blueprint status": {
+		"url:GET|/api/v1/courses/:course_id/blueprint_templates/:template_id",
+		"url:GET|/api/v1/courses/:course_id/blueprint_templates/:template_id/associated_courses",
+	},
+	"care scan": slices.Concat(termScopes, []string{
+		"url:GET|/api/v1/users/:id",
+		"url:GET|/api/v1/courses/:id", // --course
+		"url:GET|/api/v1/courses/:course_id/enrollments",
+		"url:GET|/api/v1/courses/:course_id/students/submissions",
+		"url:GET|/api/v1/courses/:course_id/discussion_topics",
+		"url:GET|/api/v1/courses/:course_id/discussion_topics/:topic_id/view",
+	}),
+	"doctor": {
+		"url:GET|/api/v1/users/:id",
+		"url:GET|/api/v1/accounts/:account_id/terms",
+	},
+	"announcements audit": slices.Concat(termScopes, []string{
+		"url:GET|/api/v1/courses/:course_id/discussion_topics",
+	}),
+	"announcements post": slices.Concat(termScopes, []string{
+		"url:GET|/api/v1/courses/:course_id/discussion_topics",
+		"url:POST|/api/v1/courses/:course_id/discussion_topics",
+	}),
+	"courses list": termScopes,
+	"courses fix-links": slices.Concat(termScopes, []string{
+		"url:GET|/api/v1/courses/:id",
+		"url:PUT|/api/v1/courses/:id",
+		"url:GET|/api/v1/courses/:course_id/pages",
+		"url:GET|/api/v1/courses/:course_id/pages/:url_or_id",
+		"url:PUT|/api/v1/courses/:course_id/pages/:url_or_id",
+		"url:GET|/api/v1/courses/:course_id/assignments",
+		"url:GET|/api/v1/courses/:course_id/assignments/:id",
+		"url:PUT|/api/v1/courses/:course_id/assignments/:id",
+	}),
+	"courses syllabus-audit": termScopes,
+	"courses unpublished-report": slices.Concat(termScopes, []string{
+		"url:GET|/api/v1/courses/:course_id/modules",
+		"url:GET|/api/v1/courses/:course_id/assignments",
+		"url:GET|/api/v1/courses/:course_id/front_page",
+		"url:GET|/api/v1/courses/:course_id/users",
+		"url:GET|/api/v1/courses/:course_id/quizzes",
+		"url:GET|/api/quiz/v1/courses/:course_id/quizzes",
+		"url:GET|/api/v1/courses/:course_id/student_view_student", // --student-view
+		"url:GET|/api/v1/courses/:id",                             // --student-view, --checks syllabus
+	}),
+	"courses group-check": slices.Concat(termScopes, []string{
+		"url:GET|/api/v1/courses/:id",
+		"url:GET|/api/v1/courses/:course_id/assignments",
+		"url:GET|/api/v1/group_categories/:group_category_id",
+		"url:GET|/api/v1/group_categories/:group_category_id/users",
+		"url:POST|/api/v1/group_categories/:group_category_id/assign_unassigned_members", // --fix
+	}),
+	"courses staffing": slices.Concat(termScopes, []string{
+		"url:GET|/api/v1/courses/:course_id/enrollments",
+	}),
+	"courses instructor-changes": slices.Concat(termScopes, []string{
+		"url:GET|/api/v1/courses/:course_id/enrollments",
+	}),
+	"courses ics": slices.Concat(termScopes, []string{
+		"url:GET|/api/v1/courses/:id", // --course
+		"url:GET|/api/v1/courses/:course_id/assignments",
+		"url:GET|/api/v1/calendar_events",
+	}),
+	"courses update": {"url:PUT|/api/v1/courses/:id"},
+	"courses bulk-update": {
+		"url:GET|/api/v1/courses/:id",
+		"url:PUT|/api/v1/courses/:id",
+	},
+	"delete plan": {
+		"url:GET|/api/v1/accounts/:account_id/enrollments/:id", // --kind enrollments
+		"url:GET|/api/v1/courses/:course_id/pages/:url_or_id",  // --kind pages
+		"url:GET|/api/v1/calendar_events/:id",                  // --kind events
+	},
+	"delete run": {
+		"url:GET|/api/v1/accounts/:account_id/enrollments/:id",
+		"url:DELETE|/api/v1/courses/:course_id/enrollments/:id",
+		"url:GET|/api/v1/courses/:course_id/pages/:url_or_id",
+		"url:DELETE|/api/v1/courses/:course_id/pages/:url_or_id",
+		"url:GET|/api/v1/calendar_events/:id",
+		"url:DELETE|/api/v1/calendar_events/:id",
+	},
+	"delete restore": {
+		"url:PUT|/api/v1/courses/:course_id/enrollments/:id/reactivate",
+		"url:POST|/api/v1/sections/:section_id/enrollments",
+		"url:POST|/api/v1/courses/:course_id/pages",
+		"url:POST|/api/v1/calendar_events",
+	},
+	"gradebook export": slices.Concat(termScopes, []string{ // --term
+		"url:GET|/api/v1/courses/:course_id/assignments",
+		"url:GET|/api/v1/courses/:course_id/sections",
+		"url:GET|/api/v1/courses/:course_id/enrollments",
+		"url:GET|/api/v1/courses/:course_id/students/submissions",
+	}),
+	"quizzes accommodations": {
+		"url:GET|/api/v1/users/:user_id/enrollments",
+		"url:GET|/api/v1/courses/:id",
+		"url:GET|/api/v1/courses/:course_id/quizzes",
+		"url:GET|/api/v1/courses/:course_id/quizzes/:quiz_id/submissions",
+	},
+	"reports list": {"url:GET|/api/v1/accounts/:account_id/reports"},
+	"reports run": {
+		"url:GET|/api/v1/accounts/:account_id/terms", // --term
+		"url:POST|/api/v1/accounts/:account_id/reports/:report",
+		"url:GET|/api/v1/accounts/:account_id/reports/:report/:id",
+	},
+	"roles permissions": {"url:GET|/api/v1/accounts/:account_id/roles"},
+	"sample": slices.Concat(termScopes, []string{
+		"url:GET|/api/v1/courses/:course_id/modules",
+		"url:GET|/api/v1/courses/:course_id/assignments",
+		"url:GET|/api/v1/courses/:course_id/quizzes",
+		"url:GET|/api/quiz/v1/courses/:course_id/quizzes",
+		"url:GET|/api/v1/courses/:course_id/users",
+	}),
+	"sections caps": slices.Concat(termScopes, []string{
+		"url:GET|/api/v1/courses/:course_id/sections",
+	}),
+	"sections update": {"url:PUT|/api/v1/sections/:id"},
+	"sections sync-meetings": {
+		"url:GET|/api/v1/sections/:id",
+		"url:GET|/api/v1/calendar_events",
+		"url:POST|/api/v1/calendar_events",
+		"url:PUT|/api/v1/calendar_events/:id",
+		"url:DELETE|/api/v1/calendar_events/:id", // --prune
+	},
+	"sis import": {
+		"url:POST|/api/v1/accounts/:account_id/sis_imports",
+		"url:GET|/api/v1/accounts/:account_id/sis_imports/:id",
+		"url:GET|/api/v1/accounts/:account_id/sis_imports/:id/errors",
+	},
+	"sis validate": slices.Concat(termScopes, []string{
+		"url:GET|/api/v1/courses/:course_id/sections",
+		"url:GET|/api/v1/accounts/:account_id/users",
+	}),
+	"sis status": {
+		"url:GET|/api/v1/accounts/:account_id/sis_imports", // without --id
+		"url:GET|/api/v1/accounts/:account_id/sis_imports/:id",
+		"url:GET|/api/v1/accounts/:account_id/sis_imports/:id/errors",
+	},
+	"terms list": {"url:GET|/api/v1/accounts/:account_id/terms"},
+	"users lookup": {
+		"url:GET|/api/v1/accounts/:account_id/users",
+		"url:GET|/api/v1/users/:id",
+	},
+	"users update": {"url:PUT|/api/v1/users/:id"},
+}
+
+type ScopeItem struct {
+	Command string `json:"command" csv:"command"`
+	Scope   string `json:"scope" csv:"scope"`
+}
+
+const scopesSummary = "List the developer key scopes a command needs"
+
+func init() {
+	register(command{Group: "scopes", Summary: scopesSummary, Run: runScopes, Local: true})
+}
+
+func runScopes(args []string) error {
+	var opts commonOptions
+	fs := newFlagSet("scopes", scopesSummary+". Usage: app scopes [flags] [<group> <command>]...")
+	addOutputFlags(fs, &opts, "")
+	unique := fs.Bool("unique", false, "list each scope once, under the first command that needs it, for setting up a developer key")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	var names []string
+	for rest := fs.Args(); len(rest) > 0; {
+		cmd, next, ok := findCommand(rest)
+		if !ok {
+			return fmt.Errorf("unknown command: %s", strings.Join(rest, " "))
+		}
+		names = append(names, cmd.FullName())
+		rest = next
+	}
+	if len(names) == 0 {
+		for name := range commandScopes {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+	}
+
+	var rows []ScopeItem
+	seen := make(map[string]bool)
+	for _, name := range names {
+		scopes, ok := commandScopes[name]
+		if !ok {
+			fmt.Fprintf(os.Stderr, "%s calls no Canvas endpoints of its own\n", name)
+			continue
+		}
+		for _, scope := range scopes {
+			if *unique {
+				if seen[scope] {
+					continue
+				}
+				seen[scope] = true
+			}
+			rows = append(rows, ScopeItem{Command: name, Scope: scope})
+		}
+	}
+	return opts.writeRows("scopes", rows)
+}
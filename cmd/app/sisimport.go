@@ -0,0 +1,142 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"time"
+
+	"github.com/coraxwolf/CCTA_3-4/pkg/canvas"
+)
+
+type SISImportMessage struct {
+	ImportID int    `json:"import_id" csv:"import_id"`
+	File     string `json:"file" csv:"file"`
+	Row      *int   `json:"row" csv:"row"`
+	Message  string `json:"message" csv:"message"`
+	RowInfo  string `json:"row_info" csv:"row_info"`
+}
+
+const (
+	sisImportSummary = "Upload a SIS CSV or ZIP file to Canvas and report its warnings and errors"
+	sisStatusSummary = "Show the state of a SIS import and report its warnings and errors"
+)
+
+func init() {
+	register(command{Group: "sis", Name: "import", Summary: sisImportSummary, Run: runSISImport})
+	register(command{Group: "sis", Name: "status", Summary: sisStatusSummary, Run: runSISStatus})
+}
+
+func runSISImport(args []string) error {
+	var opts commonOptions
+	fs := newFlagSet("sis import", sisImportSummary)
+	addAccountFlags(fs, &opts)
+	addOutputFlags(fs, &opts, path.Join("data", "reports"))
+	file := fs.String("file", "", "SIS CSV file, or a ZIP of CSV files (required)")
+	batchTerm := fs.String("batch-term", "", "run in batch mode for this term: Canvas deletes term data missing from the file (Canvas term ID or sis_term_id:<id>)")
+	overrideSticky := fs.Bool("override-sticky", false, "overwrite values changed in Canvas since the last import")
+	diffing := fs.String("diffing", "", "only apply changes since the last import with this data set identifier")
+	threshold := fs.Int("change-threshold", 0, "with --diffing, abort if more than this percent of rows would be removed")
+	wait := fs.Bool("wait", true, "wait for Canvas to process the import and report its warnings and errors")
+	interval := fs.Duration("interval", 10*time.Second, "how often to check the import while waiting")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *file == "" {
+		return fmt.Errorf("--file is required")
+	}
+	sisImport, err := api.SISImports().ImportFile(opts.AccountID, *file, canvas.SISImportOptions{
+		BatchMode:       *batchTerm != "",
+		BatchModeTermID: *batchTerm,
+		OverrideSticky:  *overrideSticky,
+		DiffingDataSet:  *diffing,
+		ChangeThreshold: *threshold,
+	})
+	if err != nil {
+		return err
+	}
+	if api.DryRun() {
+		fmt.Fprintf(os.Stderr, "Dry run: %s was not uploaded\n", *file)
+		return nil
+	}
+	fmt.Fprintf(os.Stderr, "Started SIS import %d from %s\n", sisImport.ID, *file)
+	if !*wait {
+		return nil
+	}
+	return reportSISImport(opts, sisImport.ID, *interval)
+}
+
+func runSISStatus(args []string) error {
+	var opts commonOptions
+	fs := newFlagSet("sis status", sisStatusSummary)
+	addAccountFlags(fs, &opts)
+	addOutputFlags(fs, &opts, path.Join("data", "reports"))
+	id := fs.Int("id", 0, "SIS import ID (default the most recent import)")
+	wait := fs.Bool("wait", false, "wait for the import to finish")
+	interval := fs.Duration("interval", 10*time.Second, "how often to check the import while waiting")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *id == 0 {
+		imports, err := api.SISImports().ListImports(opts.AccountID, canvas.SISImportListOptions{})
+		if err != nil {
+			return err
+		}
+		if len(imports) == 0 {
+			return fmt.Errorf("account %d has no SIS imports", opts.AccountID)
+		}
+		*id = imports[0].ID
+	}
+	if !*wait {
+		*interval = 0
+	}
+	return reportSISImport(opts, *id, *interval)
+}
+
+// reportSISImport prints the state of an import and writes its warnings and errors. A positive
+// interval waits for the import to finish first. An import that failed is returned as an error.
+func reportSISImport(opts commonOptions, id int, interval time.Duration) error {
+	var sisImport *canvas.SISImport
+	var err error
+	if interval > 0 {
+		fmt.Fprintf(os.Stderr, "Waiting for SIS import %d\n", id)
+		sisImport, err = api.SISImports().WaitImport(opts.AccountID, id, interval)
+	} else {
+		sisImport, err = api.SISImports().GetImport(opts.AccountID, id)
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "SIS import %d: %s (%d%%)\n", sisImport.ID, sisImport.WorkflowState, sisImport.Progress)
+	kinds := make([]string, 0, len(sisImport.Data.Counts))
+	for kind, n := range sisImport.Data.Counts {
+		if n > 0 {
+			kinds = append(kinds, kind)
+		}
+	}
+	sort.Strings(kinds)
+	for _, kind := range kinds {
+		fmt.Fprintf(os.Stderr, "  %-24s %d\n", kind, sisImport.Data.Counts[kind])
+	}
+	if !sisImport.Done() {
+		return nil
+	}
+
+	errs, err := api.SISImports().ListImportErrors(opts.AccountID, id)
+	if err != nil {
+		return err
+	}
+	rows := make([]SISImportMessage, 0, len(errs))
+	for _, e := range errs {
+		rows = append(rows, SISImportMessage{ImportID: id, File: e.File, Row: e.Row, Message: e.Message, RowInfo: e.RowInfo})
+	}
+	fmt.Fprintf(os.Stderr, "%d warnings and errors\n", len(rows))
+	if err := opts.writeRows(fmt.Sprintf("sis_import_%d", id), rows); err != nil {
+		return err
+	}
+	if sisImport.Failed() {
+		return fmt.Errorf("SIS import %d ended as %s", id, sisImport.WorkflowState)
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+
+	"github.com/coraxwolf/CCTA_3-4/pkg/canvas"
+)
+
+// SISIDItem is a course, section, or user whose SIS ID breaks the documented pattern.
+type SISIDItem struct {
+	Type     string `json:"type" csv:"type"` // course, section, or user
+	CanvasID int    `json:"canvas_id" csv:"canvas_id"`
+	Name     string `json:"name" csv:"name"`
+	SISID    string `json:"sis_id" csv:"sis_id"`
+	CourseID int    `json:"course_id" csv:"course_id"` // the section's course
+	Status   string `json:"status" csv:"status"`       // invalid, missing, or error
+	Pattern  string `json:"pattern" csv:"pattern"`
+	Detail   string `json:"detail" csv:"detail"`
+}
+
+const sisValidateSummary = "Check course, section, and user SIS IDs against the institution's documented patterns"
+
+func init() {
+	register(command{Group: "sis", Name: "validate", Summary: sisValidateSummary, Run: runSISValidate})
+}
+
+func runSISValidate(args []string) error {
+	var opts commonOptions
+	fs := newFlagSet("sis validate", sisValidateSummary)
+	addAccountFlags(fs, &opts)
+	addOutputFlags(fs, &opts, "")
+	fs.StringVar(&opts.Term, "term", "", "only check the courses and sections of this term (users are always checked account wide)")
+	coursePattern := fs.String("course-pattern", cfg.SISPatterns.Course, "regular expression every SIS course ID must match in full (default sis_patterns.course in the config file)")
+	sectionPattern := fs.String("section-pattern", cfg.SISPatterns.Section, "regular expression every SIS section ID must match in full (default sis_patterns.section)")
+	userPattern := fs.String("user-pattern", cfg.SISPatterns.User, "regular expression every SIS user ID must match in full (default sis_patterns.user)")
+	missing := fs.Bool("missing", false, "also list records without a SIS ID, such as courses created by hand")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	sources := map[string]string{"course": *coursePattern, "section": *sectionPattern, "user": *userPattern}
+	patterns := make(map[string]*regexp.Regexp)
+	for kind, p := range sources {
+		if p == "" {
+			continue
+		}
+		re, err := regexp.Compile(`^(?:` + p + `)$`)
+		if err != nil {
+			return fmt.Errorf("invalid %s pattern: %w", kind, err)
+		}
+		patterns[kind] = re
+	}
+	if len(patterns) == 0 {
+		return fmt.Errorf("no SIS ID patterns: add sis_patterns to the config file or use --course-pattern, --section-pattern, or --user-pattern")
+	}
+
+	var results []SISIDItem
+	checked := make(map[string]int)
+	check := func(item SISIDItem) {
+		re := patterns[item.Type]
+		checked[item.Type]++
+		switch {
+		case item.SISID == "":
+			if *missing {
+				item.Status = "missing"
+				results = append(results, item)
+			}
+		case !re.MatchString(item.SISID):
+			item.Status, item.Pattern = "invalid", sources[item.Type]
+			results = append(results, item)
+		}
+	}
+
+	if patterns["course"] != nil || patterns["section"] != nil {
+		var courses []canvas.Course
+		var err error
+		if opts.Term != "" {
+			courses, err = opts.termCourses(canvas.CourseListOptions{})
+		} else {
+			courses, err = api.Courses().ListAccountCourses(opts.AccountID, canvas.CourseListOptions{})
+		}
+		if err != nil {
+			return fmt.Errorf("error fetching courses: %w", err)
+		}
+		for _, course := range courses {
+			if patterns["course"] != nil {
+				check(SISIDItem{Type: "course", CanvasID: course.ID, Name: course.Name, SISID: course.SISCourseID, CourseID: course.ID})
+			}
+			if patterns["section"] == nil {
+				continue
+			}
+			sections, err := api.Sections().ListCourseSections(course.ID)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error fetching sections for course %d: %s\n", course.ID, withHint(err))
+				results = append(results, SISIDItem{Type: "section", CourseID: course.ID, Name: course.Name, Status: "error", Detail: withHint(err)})
+				continue
+			}
+			for _, section := range sections {
+				check(SISIDItem{Type: "section", CanvasID: section.ID, Name: section.Name, SISID: section.SISSectionID, CourseID: course.ID})
+			}
+		}
+	}
+	if patterns["user"] != nil {
+		users, err := api.Users().ListAccountUsers(opts.AccountID, "")
+		if err != nil {
+			return fmt.Errorf("error fetching users: %w", err)
+		}
+		for _, user := range users {
+			check(SISIDItem{Type: "user", CanvasID: user.ID, Name: user.Name, SISID: user.SISUserID})
+		}
+	}
+
+	invalid := 0
+	for _, r := range results {
+		if r.Status == "invalid" {
+			invalid++
+		}
+	}
+	fmt.Fprintf(os.Stderr, "Checked %d courses, %d sections, and %d users: %d SIS IDs break the patterns\n", checked["course"], checked["section"], checked["user"], invalid)
+	name := "sis_id_violations"
+	if opts.Term != "" {
+		name = opts.Term + "_" + name
+	}
+	return opts.writeRows(name, results)
+}
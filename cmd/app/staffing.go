@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/coraxwolf/CCTA_3-4/pkg/canvas"
+)
+
+type StaffingItem struct {
+	CourseID    int    `json:"course_id" csv:"course_id"`
+	CourseName  string `json:"course_name" csv:"course_name"`
+	SISCourseID string `json:"sis_course_id" csv:"sis_course_id"`
+	Modality    string `json:"modality" csv:"modality"`
+	Students    int    `json:"students" csv:"students"`
+	Teachers    string `json:"teachers" csv:"teachers"`
+	TAs         string `json:"tas" csv:"tas"`
+	Designers   string `json:"designers" csv:"designers"`
+	Issue       string `json:"issue" csv:"issue"`
+	URL         string `json:"url" csv:"url"` // the course's People page
+}
+
+const staffingSummary = "Find large courses with a single teacher and no TA, and courses that still have designers"
+
+func init() {
+	register(command{Group: "courses", Name: "staffing", Summary: staffingSummary, Run: runStaffingReport})
+}
+
+func runStaffingReport(args []string) error {
+	var opts commonOptions
+	fs := newFlagSet("courses staffing", staffingSummary)
+	addTermFlags(fs, &opts)
+	addAccountFlags(fs, &opts)
+	addOutputFlags(fs, &opts, "")
+	defaultMin := 25
+	if v := os.Getenv("STAFFING_MIN_STUDENTS"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf("invalid STAFFING_MIN_STUDENTS %q: %w", v, err)
+		}
+		defaultMin = n
+	}
+	minStudents := fs.Int("min-students", defaultMin, "flag single-teacher courses without a TA at or above this many students")
+	workers := fs.Int("workers", 4, "courses to check at once; fewer run as the rate limit drains")
+	var resume checkpointFlags
+	addCheckpointFlags(fs, &resume)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	modalities, err := NewModalityClassifierFromEnv()
+	if err != nil {
+		return fmt.Errorf("error loading modality rules: %w", err)
+	}
+	cp, err := resume.open("courses staffing", opts.Term, "min-students="+strconv.Itoa(*minStudents))
+	if err != nil {
+		return err
+	}
+	courses, err := opts.termCourses(canvas.CourseListOptions{Include: []string{"total_students"}})
+	if err != nil {
+		return fmt.Errorf("error fetching courses: %w", err)
+	}
+
+	rows := make([]*StaffingItem, len(courses)) // nil for courses without issues
+	failures := runCourseJobs(context.Background(), "Checking staff", courses, *workers, func(ctx context.Context, i int, course canvas.Course) error {
+		if cp.Lookup(course.ID, &rows[i]) {
+			return nil
+		}
+		enrollments, err := api.Enrollments().WithContext(ctx).ListCourseEnrollments(course.ID, canvas.EnrollmentListOptions{
+			Types: []string{"TeacherEnrollment", "TaEnrollment", "DesignerEnrollment"},
+		})
+		if err != nil {
+			rows[i] = &StaffingItem{CourseID: course.ID, CourseName: course.Name, SISCourseID: course.SISCourseID, Issue: "Error: " + withHint(err), URL: api.CourseURL(course.ID, "users")}
+			return err
+		}
+		staff := map[string][]string{}
+		for _, e := range enrollments {
+			if !slices.Contains(staff[e.Type], e.User.Name) { // one row per section enrollment
+				staff[e.Type] = append(staff[e.Type], e.User.Name)
+			}
+		}
+		var issues []string
+		if len(staff["TeacherEnrollment"]) <= 1 && len(staff["TaEnrollment"]) == 0 && course.TotalStudents >= *minStudents {
+			issues = append(issues, fmt.Sprintf("%d teacher(s) and no TA for %d students", len(staff["TeacherEnrollment"]), course.TotalStudents))
+		}
+		if len(staff["DesignerEnrollment"]) > 0 {
+			issues = append(issues, "designers still enrolled")
+		}
+		if len(issues) == 0 {
+			cp.Done(course.ID, rows[i])
+			return nil
+		}
+		rows[i] = &StaffingItem{
+			CourseID:    course.ID,
+			CourseName:  course.Name,
+			SISCourseID: course.SISCourseID,
+			Students:    course.TotalStudents,
+			Teachers:    strings.Join(staff["TeacherEnrollment"], "; "),
+			TAs:         strings.Join(staff["TaEnrollment"], "; "),
+			Designers:   strings.Join(staff["DesignerEnrollment"], "; "),
+			Issue:       strings.Join(issues, "; "),
+			URL:         api.CourseURL(course.ID, "users"),
+		}
+		cp.Done(course.ID, rows[i])
+		return nil
+	})
+	reportFailures(os.Stderr, failures)
+	var results []StaffingItem
+	for i, row := range rows {
+		if row != nil {
+			row.Modality = modalities.Classify(courses[i]) // also for rows resumed from a checkpoint
+			results = append(results, *row)
+		}
+	}
+	fmt.Fprintf(os.Stderr, "Found %d courses with staffing issues\n", len(results))
+	if err := opts.writeRows(opts.Term+"_staffing", results); err != nil {
+		return err
+	}
+	return cp.Close(len(failures))
+}
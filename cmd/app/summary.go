@@ -0,0 +1,95 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/coraxwolf/CCTA_3-4/pkg/canvas"
+)
+
+type StageTiming struct {
+	Name     string        `json:"name"`
+	Duration time.Duration `json:"duration_ns"`
+	Seconds  float64       `json:"seconds"`
+}
+
+type RunSummary struct {
+	Status       string           `json:"status"`
+	Error        string           `json:"error,omitempty"`
+	ErrorKind    string           `json:"error_kind,omitempty"`
+	Hint         string           `json:"hint,omitempty"`
+	MissingScope string           `json:"missing_scope,omitempty"`
+	StartedAt    time.Time        `json:"started_at"`
+	FinishedAt   time.Time        `json:"finished_at"`
+	TotalSeconds float64          `json:"total_seconds"`
+	Stages       []StageTiming    `json:"stages"`
+	Counts       map[string]int   `json:"counts"`
+	API          *canvas.APIStats `json:"api,omitempty"`
+	ThrottleSecs float64          `json:"throttle_seconds"`
+	// UpcomingAPIChanges lists the endpoints Canvas marked as deprecated during the run.
+	UpcomingAPIChanges []canvas.Deprecation `json:"upcoming_api_changes,omitempty"`
+	stageStart         time.Time
+	currentStage       string
+	outputFile         string
+}
+
+func NewRunSummary(outputFile string) *RunSummary {
+	return &RunSummary{
+		Status:     "running",
+		StartedAt:  time.Now(),
+		Counts:     make(map[string]int),
+		outputFile: outputFile,
+	}
+}
+
+// StartStage closes the running stage (if any) and starts timing a new one.
+func (rs *RunSummary) StartStage(name string) {
+	rs.endStage()
+	rs.currentStage = name
+	rs.stageStart = time.Now()
+}
+
+func (rs *RunSummary) endStage() {
+	if rs.currentStage == "" {
+		return
+	}
+	d := time.Since(rs.stageStart)
+	rs.Stages = append(rs.Stages, StageTiming{Name: rs.currentStage, Duration: d, Seconds: d.Seconds()})
+	rs.currentStage = ""
+}
+
+func (rs *RunSummary) Fail(err error) {
+	rs.Status = "failed"
+	rs.Error = err.Error()
+	diag := canvas.Diagnose(err)
+	rs.ErrorKind = string(diag.Kind)
+	rs.Hint = diag.Hint
+	rs.MissingScope = diag.Scope
+}
+
+// Write finalizes the summary and writes it as JSON to the configured output file.
+func (rs *RunSummary) Write(api *canvas.APIManager) error {
+	rs.endStage()
+	if rs.Status == "running" {
+		rs.Status = "ok"
+	}
+	rs.FinishedAt = time.Now()
+	rs.TotalSeconds = rs.FinishedAt.Sub(rs.StartedAt).Seconds()
+	if api != nil {
+		stats := api.Stats()
+		rs.API = &stats
+		rs.ThrottleSecs = stats.ThrottleTime.Seconds()
+		rs.UpcomingAPIChanges = api.Deprecations()
+	}
+	if err := os.MkdirAll(filepath.Dir(rs.outputFile), 0755); err != nil {
+		return fmt.Errorf("error creating summary directory: %w", err)
+	}
+	data, err := json.MarshalIndent(rs, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error encoding run summary: %w", err)
+	}
+	return os.WriteFile(rs.outputFile, data, 0644)
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/coraxwolf/CCTA_3-4/pkg/canvas"
+)
+
+type SyllabusItem struct {
+	CourseID    int    `json:"course_id" csv:"course_id"`
+	CourseName  string `json:"course_name" csv:"course_name"`
+	SISCourseID string `json:"sis_course_id" csv:"sis_course_id"`
+	Modality    string `json:"modality" csv:"modality"`
+	CourseState string `json:"course_state" csv:"course_state"`
+	Status      string `json:"status" csv:"status"` // missing, placeholder, file_only, short, or ok
+	Words       int    `json:"words" csv:"words"`
+	Links       int    `json:"links" csv:"links"`
+	FileLinks   int    `json:"file_links" csv:"file_links"` // links to course files, e.g. an attached syllabus PDF
+	DefaultView string `json:"default_view" csv:"default_view"`
+	URL         string `json:"url" csv:"url"`
+}
+
+// defaultSyllabusPlaceholder matches the filler text template shells ship with.
+const defaultSyllabusPlaceholder = `(?i)((insert|paste|add|place) (your |the )?(course )?syllabus here|lorem ipsum)`
+
+var hrefAttr = regexp.MustCompile(`(?i)href\s*=\s*["']([^"']*)["']`)
+
+const syllabusAuditSummary = "Check every course in a term for a missing, placeholder, or too short syllabus"
+
+func init() {
+	register(command{Group: "courses", Name: "syllabus-audit", Summary: syllabusAuditSummary, Run: runSyllabusAudit})
+}
+
+func runSyllabusAudit(args []string) error {
+	var opts commonOptions
+	fs := newFlagSet("courses syllabus-audit", syllabusAuditSummary)
+	addTermFlags(fs, &opts)
+	addAccountFlags(fs, &opts)
+	addOutputFlags(fs, &opts, "")
+	minWords := fs.Int("min-words", 150, "flag syllabi with fewer words than this as short")
+	placeholder := fs.String("placeholder", envOr("SYLLABUS_PLACEHOLDER", defaultSyllabusPlaceholder), "regular expression for template filler text (default SYLLABUS_PLACEHOLDER or common filler)")
+	published := fs.Bool("published-only", false, "only check published courses")
+	problems := fs.Bool("problems-only", false, "only list courses whose syllabus isn't ok")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if opts.Term == "" {
+		return fmt.Errorf("--term is required")
+	}
+	filler, err := regexp.Compile(*placeholder)
+	if err != nil {
+		return fmt.Errorf("invalid --placeholder: %w", err)
+	}
+	templates, err := NewTemplateDetectorFromEnv()
+	if err != nil {
+		return fmt.Errorf("error loading template rules: %w", err)
+	}
+	modalities, err := NewModalityClassifierFromEnv()
+	if err != nil {
+		return fmt.Errorf("error loading modality rules: %w", err)
+	}
+	list := canvas.CourseListOptions{Include: []string{"syllabus_body"}}
+	if *published {
+		list.Published = published
+	}
+	courses, err := opts.termCourses(list)
+	if err != nil {
+		return fmt.Errorf("error fetching courses: %w", err)
+	}
+
+	var results []SyllabusItem
+	counts := make(map[string]int)
+	checked := 0
+	for _, course := range courses {
+		if templates.Detect(course) != "" {
+			continue // template shells have placeholder syllabi on purpose
+		}
+		item := checkSyllabus(course, filler, *minWords)
+		item.Modality = modalities.Classify(course)
+		counts[item.Status]++
+		checked++
+		if *problems && item.Status == "ok" {
+			continue
+		}
+		results = append(results, item)
+	}
+	fmt.Fprintf(os.Stderr, "Checked %d syllabi: %d ok, %d missing, %d placeholder, %d file only, %d short\n",
+		checked, counts["ok"], counts["missing"], counts["placeholder"], counts["file_only"], counts["short"])
+	return opts.writeRows(opts.Term+"_syllabus_audit", results)
+}
+
+// checkSyllabus classifies a course's syllabus body. A short syllabus that links to course files is
+// file_only: the syllabus is probably an attached document, which a reviewer has to open.
+func checkSyllabus(course canvas.Course, filler *regexp.Regexp, minWords int) SyllabusItem {
+	item := SyllabusItem{
+		CourseID:    course.ID,
+		CourseName:  course.Name,
+		SISCourseID: course.SISCourseID,
+		CourseState: course.WorkflowState,
+		DefaultView: course.DefaultView,
+		URL:         api.CourseURL(course.ID, "assignments/syllabus"),
+	}
+	text := plainText(course.SyllabusBody)
+	item.Words = len(strings.Fields(text))
+	for _, m := range hrefAttr.FindAllStringSubmatch(course.SyllabusBody, -1) {
+		item.Links++
+		if strings.Contains(m[1], "/files/") {
+			item.FileLinks++
+		}
+	}
+	switch {
+	case item.Words == 0 && item.Links == 0:
+		item.Status = "missing"
+	case filler.MatchString(text):
+		item.Status = "placeholder"
+	case item.Words < minWords && item.FileLinks > 0:
+		item.Status = "file_only"
+	case item.Words < minWords:
+		item.Status = "short"
+	default:
+		item.Status = "ok"
+	}
+	return item
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/coraxwolf/CCTA_3-4/pkg/canvas"
+)
+
+// defaultTemplatePattern only takes "master" in "master course", "master shell", or "master
+// template", since course titles such as "Master Gardener" or "Master's Seminar" are taught.
+const defaultTemplatePattern = `(?i)\b(template|master (course|shell|template)|sandbox|(dev|development) shell)\b`
+
+// TemplateDetector recognizes template, master, and development shells so reports can tag them
+// instead of counting them as teaching courses.
+type TemplateDetector struct {
+	pattern  *regexp.Regexp
+	accounts map[int]bool
+}
+
+// NewTemplateDetector builds a detector from a regex matched against the course name, code, and SIS ID,
+// and a comma separated list of sub-account IDs that only hold templates.
+func NewTemplateDetector(pattern, accountIDs string) (*TemplateDetector, error) {
+	if strings.TrimSpace(pattern) == "" {
+		pattern = defaultTemplatePattern
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid template pattern: %w", err)
+	}
+	td := &TemplateDetector{pattern: re, accounts: make(map[int]bool)}
+	for _, field := range strings.Split(accountIDs, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		id, err := strconv.Atoi(field)
+		if err != nil {
+			return nil, fmt.Errorf("invalid template account ID %q: %w", field, err)
+		}
+		td.accounts[id] = true
+	}
+	return td, nil
+}
+
+// NewTemplateDetectorFromEnv reads TEMPLATE_PATTERN and TEMPLATE_ACCOUNT_IDS, falling back to the default pattern.
+func NewTemplateDetectorFromEnv() (*TemplateDetector, error) {
+	return NewTemplateDetector(os.Getenv("TEMPLATE_PATTERN"), os.Getenv("TEMPLATE_ACCOUNT_IDS"))
+}
+
+// Detect returns why the course looks like a template, or "" for a teaching course.
+func (td *TemplateDetector) Detect(course canvas.Course) string {
+	switch {
+	case course.Blueprint:
+		return "blueprint"
+	case td.accounts[course.AccountID]:
+		return fmt.Sprintf("template account %d", course.AccountID)
+	}
+	for _, field := range []string{course.Name, course.CourseCode, course.SISCourseID} {
+		if match := td.pattern.FindString(field); match != "" {
+			return fmt.Sprintf("name matches %q", match)
+		}
+	}
+	return ""
+}
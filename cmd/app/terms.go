@@ -0,0 +1,26 @@
+package main
+
+import (
+	"fmt"
+)
+
+const termsListSummary = "List the enrollment terms of the account"
+
+func init() {
+	register(command{Group: "terms", Name: "list", Summary: termsListSummary, Run: runTermsList})
+}
+
+func runTermsList(args []string) error {
+	var opts commonOptions
+	fs := newFlagSet("terms list", termsListSummary)
+	addAccountFlags(fs, &opts)
+	addOutputFlags(fs, &opts, "")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	terms, err := api.Terms().ListTerms(opts.AccountID)
+	if err != nil {
+		return fmt.Errorf("error fetching terms: %w", err)
+	}
+	return opts.writeRows("terms", terms)
+}
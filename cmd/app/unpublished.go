@@ -0,0 +1,255 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/coraxwolf/CCTA_3-4/pkg/audit"
+	"github.com/coraxwolf/CCTA_3-4/pkg/canvas"
+)
+
+type ResultItem struct {
+	CourseID        int    `json:"course_id" csv:"course_id"`
+	CourseName      string `json:"course_name" csv:"course_name"`
+	Format          string `json:"format" csv:"format"`
+	Modality        string `json:"modality" csv:"modality"`
+	Subject         string `json:"subject" csv:"subject"`
+	Modules         string `json:"modules" csv:"modules"`
+	ModuleItems     string `json:"module_items" csv:"module_items"`
+	WithAssignments string `json:"with_assignments" csv:"with_assignments"`
+	Quizzes         string `json:"quizzes" csv:"quizzes"`
+	WithFrontPage   string `json:"with_front_page" csv:"with_front_page"`
+	StudentView     string `json:"student_view_missing" csv:"student_view_missing"`
+	FacultyName     string `json:"faculty_name" csv:"faculty_name"`
+	FacultyOfficial string `json:"faculty_official_name" csv:"faculty_official_name"`
+	FacultyEmail    string `json:"faculty_email" csv:"faculty_email"`
+	Template        string `json:"template" csv:"template"`
+	OtherChecks     string `json:"other_checks" csv:"other_checks"` // results of the checks without a column of their own, e.g. "syllabus: No"
+	URL             string `json:"url" csv:"url"`
+	Errors          string `json:"errors" csv:"errors"`
+}
+
+const unpublishedReportSummary = "Report unpublished courses in a term with their content and faculty"
+
+func init() {
+	register(command{Group: "courses", Name: "unpublished-report", Summary: unpublishedReportSummary, Run: runUnpublishedReport})
+}
+
+func runUnpublishedReport(args []string) (err error) {
+	var opts commonOptions
+	fs := newFlagSet("courses unpublished-report", unpublishedReportSummary)
+	addTermFlags(fs, &opts)
+	addAccountFlags(fs, &opts)
+	addOutputFlags(fs, &opts, filepath.Join("data", "reports"))
+	studentViewCheck := fs.Bool("student-view", os.Getenv("STUDENT_VIEW_CHECK") == "true", "check what the test student can see (needs masquerade permission). Warning: Canvas adds a Test Student enrollment to each checked course that has none")
+	checkList := fs.String("checks", strings.Join(audit.DefaultChecks(), ","), "comma separated checks to run: "+strings.Join(audit.CheckNames(), ", "))
+	workers := fs.Int("workers", 4, "courses to check at once; fewer run as the rate limit drains")
+	skipList := fs.String("skip-checks", "", "comma separated checks not to run, e.g. quizzes")
+	var resume checkpointFlags
+	addCheckpointFlags(fs, &resume)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	checks, err := audit.ParseChecks(splitList(*checkList))
+	if err != nil {
+		return err
+	}
+	skip, err := audit.ParseChecks(splitList(*skipList))
+	if err != nil {
+		return err
+	}
+	checks = slices.DeleteFunc(checks, func(c string) bool { return slices.Contains(skip, c) })
+	if *studentViewCheck && !slices.Contains(checks, audit.CheckStudentView) && !slices.Contains(skip, audit.CheckStudentView) {
+		checks = append(checks, audit.CheckStudentView)
+	}
+	if slices.Contains(checks, audit.CheckStudentView) {
+		fmt.Fprintln(os.Stderr, "Warning: the student_view check adds a Test Student enrollment to each course that has none")
+	}
+	auditor := audit.NewCourseAuditor(api, checks...)
+	if _, err := opts.termPrefix(); err != nil {
+		return err
+	}
+	cp, err := resume.open("courses unpublished-report", opts.Term, "checks="+strings.Join(checks, ","))
+	if err != nil {
+		return err
+	}
+	var results []ResultItem                                                 // Holder for final results
+	summary := NewRunSummary(filepath.Join(opts.Output, "run_summary.json")) // next to the report
+	defer func() {
+		if err != nil {
+			summary.Fail(err)
+		}
+		if err := summary.Write(api); err != nil {
+			fmt.Printf("Error writing run summary: %v\n", err)
+		}
+	}()
+	modalities, err := NewModalityClassifierFromEnv()
+	if err != nil {
+		return fmt.Errorf("error loading modality rules: %w", err)
+	}
+	names, err := NewNameNormalizerFromEnv()
+	if err != nil {
+		return fmt.Errorf("error loading registrar names: %w", err)
+	}
+	templates, err := NewTemplateDetectorFromEnv()
+	if err != nil {
+		return fmt.Errorf("error loading template rules: %w", err)
+	}
+	fmt.Printf("Starting to fetch %s courses...\n", opts.Term)
+	summary.StartStage("pagination")
+	courseList, err := opts.termCourses(canvas.CourseListOptions{})
+	if err != nil {
+		return fmt.Errorf("error fetching courses: %w", err)
+	}
+	fmt.Printf("Found %d courses for %s\n", len(courseList), opts.Term)
+	summary.Counts["courses_in_term"] = len(courseList)
+	summary.StartStage("checks")
+
+	var unpublished []canvas.Course
+	for _, course := range courseList {
+		if course.WorkflowState == "unpublished" {
+			unpublished = append(unpublished, course)
+		}
+	}
+	fmt.Printf("Checking %d unpublished courses with %d workers\n", len(unpublished), *workers)
+	results = make([]ResultItem, len(unpublished))
+	checkErrorCounts := make([]int, len(unpublished))
+	failures := runCourseJobs(context.Background(), "Checking courses", unpublished, *workers, func(ctx context.Context, i int, course canvas.Course) error {
+		result := &results[i]
+		if cp.Lookup(course.ID, result) {
+			return nil
+		}
+		result.CourseID = course.ID
+		result.CourseName = course.Name
+		result.URL = api.CourseURL(course.ID, "")
+		result.Format = course.CourseFormat
+		result.Modality = modalities.Classify(course)
+		parts := strings.Split(course.SISCourseID, "-")
+		if len(parts) == 4 {
+			result.Subject = parts[2] // Assuming the subject is the third part of the SIS ID
+		} else {
+			result.Subject = "Unknown"
+		}
+		// Templates and development shells are listed but not checked or counted as unready
+		if reason := templates.Detect(course); reason != "" {
+			result.Template = reason
+			cp.Done(course.ID, result)
+			return nil
+		}
+		audited := auditor.Audit(ctx, course)
+		var checkErrors []string
+		for _, e := range audited.Errors {
+			checkErrors = append(checkErrors, checkLabel(e.Check)+": "+withHint(e.Err))
+		}
+		checkErrorCounts[i] = len(checkErrors)
+		fillResult(result, audited, names)
+		result.Errors = strings.Join(checkErrors, "; ")
+		if len(checkErrors) > 0 {
+			return errors.New(result.Errors)
+		}
+		cp.Done(course.ID, result)
+		return nil
+	})
+	reportFailures(os.Stderr, failures)
+	for i, result := range results {
+		if result.Template != "" {
+			summary.Counts["templates"]++
+		}
+		summary.Counts["check_errors"] += checkErrorCounts[i]
+	}
+
+	summary.Counts["unpublished_reported"] = len(results) - summary.Counts["templates"]
+	summary.StartStage("writes")
+	fmt.Printf("Gotten %d unpublished courses and %d templates for %s\n", summary.Counts["unpublished_reported"], summary.Counts["templates"], opts.Term)
+	if err := opts.writeRows(opts.Term+"_unpublished_courses", results); err != nil {
+		return err
+	}
+	return cp.Close(len(failures))
+}
+
+// fillResult copies an audit result into the report row. Columns of checks that failed read
+// "Error"; columns of checks that weren't run stay empty.
+func fillResult(result *ResultItem, audited audit.Result, names NameNormalizer) {
+	switch {
+	case audited.Ran(audit.CheckModules):
+		result.Modules = strconv.Itoa(audited.Modules)
+		result.ModuleItems = strconv.Itoa(audited.ModuleItems)
+	case audited.Err(audit.CheckModules) != nil:
+		result.Modules, result.ModuleItems = "Error", "Error"
+	}
+	switch {
+	case audited.Err(audit.CheckFrontPage) != nil:
+		result.WithFrontPage = "Error"
+	case audited.FrontPage != nil:
+		result.WithFrontPage = yesNo(*audited.FrontPage)
+	}
+	switch {
+	case audited.Ran(audit.CheckAssignments):
+		result.WithAssignments = yesNo(audited.Assignments > 0)
+	case audited.Err(audit.CheckAssignments) != nil:
+		result.WithAssignments = "Error"
+	}
+	switch {
+	case audited.Ran(audit.CheckQuizzes):
+		result.Quizzes = audited.Quizzes.String()
+	case audited.Err(audit.CheckQuizzes) != nil:
+		result.Quizzes = "Error"
+	}
+	switch {
+	case audited.Ran(audit.CheckStudentView) && len(audited.StudentViewMissing) > 0:
+		result.StudentView = strings.Join(audited.StudentViewMissing, "; ")
+	case audited.Ran(audit.CheckStudentView):
+		result.StudentView = "None"
+	case audited.Err(audit.CheckStudentView) != nil:
+		result.StudentView = "Error"
+	}
+	switch {
+	case audited.Err(audit.CheckTeachers) != nil:
+		result.FacultyName, result.FacultyOfficial, result.FacultyEmail = "Error", "Error", "Error"
+	case audited.Ran(audit.CheckTeachers) && len(audited.Teachers) > 0:
+		var facultyNames, officialNames, facultyEmails []string
+		for _, teacher := range audited.Teachers {
+			facultyNames = append(facultyNames, teacher.Name)
+			officialNames = append(officialNames, names.OfficialName(canvasUser(teacher)))
+			if teacher.Email != "" {
+				facultyEmails = append(facultyEmails, teacher.Email)
+			} else {
+				facultyEmails = append(facultyEmails, "No Email")
+			}
+		}
+		result.FacultyName = strings.Join(facultyNames, "; ")
+		result.FacultyOfficial = strings.Join(officialNames, "; ")
+		result.FacultyEmail = strings.Join(facultyEmails, "; ")
+	case audited.Ran(audit.CheckTeachers):
+		result.FacultyName, result.FacultyOfficial, result.FacultyEmail = "No Faculty", "No Faculty", "No Email"
+	}
+	var others []string
+	for _, c := range audited.Checks {
+		if c.Err != nil || slices.Contains(reportColumnChecks, c.Check) {
+			continue
+		}
+		others = append(others, checkLabel(c.Check)+": "+c.Summary)
+	}
+	result.OtherChecks = strings.Join(others, "; ")
+}
+
+// reportColumnChecks are the checks with columns of their own in the unpublished report.
+var reportColumnChecks = []string{audit.CheckModules, audit.CheckFrontPage, audit.CheckAssignments, audit.CheckQuizzes, audit.CheckTeachers, audit.CheckStudentView}
+
+// checkLabel is the name a check has in the report's errors column, e.g. "front page".
+func checkLabel(c string) string {
+	return strings.ReplaceAll(c, "_", " ")
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "Yes"
+	}
+	return "No"
+}
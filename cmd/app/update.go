@@ -0,0 +1,141 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strconv"
+
+	"github.com/coraxwolf/CCTA_3-4/pkg/canvas"
+)
+
+const (
+	coursesUpdateSummary  = "Update a course's name, code, default view, or published state"
+	sectionsUpdateSummary = "Update a section's name or SIS ID"
+	usersUpdateSummary    = "Update a user's name or email"
+)
+
+func init() {
+	register(command{Group: "courses", Name: "update", Summary: coursesUpdateSummary, Run: runCoursesUpdate})
+	register(command{Group: "sections", Name: "update", Summary: sectionsUpdateSummary, Run: runSectionsUpdate})
+	register(command{Group: "users", Name: "update", Summary: usersUpdateSummary, Run: runUsersUpdate})
+}
+
+// addStickyFlag adds --override-sticky. Without it Canvas may skip fields a SIS import has locked.
+func addStickyFlag(fs *flag.FlagSet) *bool {
+	return fs.Bool("override-sticky", false, "change fields locked by SIS imports (override_sis_stickiness)")
+}
+
+func stickyOverride(override bool) *bool {
+	if !override {
+		return nil
+	}
+	return &override
+}
+
+// stringFlag returns a pointer to the flag's value if it was given on the command line, or nil.
+func stringFlag(fs *flag.FlagSet, name string) *string {
+	var value *string
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			v := f.Value.String()
+			value = &v
+		}
+	})
+	return value
+}
+
+func runCoursesUpdate(args []string) error {
+	var opts commonOptions
+	fs := newFlagSet("courses update", coursesUpdateSummary)
+	addOutputFlags(fs, &opts, "")
+	courseID := fs.Int("course", 0, "course ID (required)")
+	fs.String("name", "", "new course name")
+	fs.String("code", "", "new course code")
+	fs.String("default-view", "", "new home page: feed, wiki, modules, syllabus, or assignments")
+	event := fs.String("event", "", "offer to publish or claim to unpublish")
+	sticky := addStickyFlag(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *courseID <= 0 {
+		return fmt.Errorf("--course is required")
+	}
+	if *event != "" && *event != "offer" && *event != "claim" {
+		return fmt.Errorf("--event must be offer or claim")
+	}
+	update := canvas.CourseUpdate{
+		Name:                  stringFlag(fs, "name"),
+		CourseCode:            stringFlag(fs, "code"),
+		DefaultView:           stringFlag(fs, "default-view"),
+		EventAction:           *event,
+		OverrideSISStickiness: stickyOverride(*sticky),
+	}
+	course, err := api.Courses().UpdateCourse(*courseID, update)
+	if err != nil {
+		return err
+	}
+	return opts.writeRows(fmt.Sprintf("course_%d", course.ID), []canvas.Course{*course})
+}
+
+func runSectionsUpdate(args []string) error {
+	var opts commonOptions
+	fs := newFlagSet("sections update", sectionsUpdateSummary)
+	addOutputFlags(fs, &opts, "")
+	sectionID := fs.Int("section", 0, "section ID (required)")
+	fs.String("name", "", "new section name")
+	fs.String("sis-id", "", "new SIS section ID")
+	sticky := addStickyFlag(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *sectionID <= 0 {
+		return fmt.Errorf("--section is required")
+	}
+	update := canvas.SectionUpdate{
+		Name:                  stringFlag(fs, "name"),
+		SISSectionID:          stringFlag(fs, "sis-id"),
+		OverrideSISStickiness: stickyOverride(*sticky),
+	}
+	section, err := api.Sections().UpdateSection(*sectionID, update)
+	if err != nil {
+		return err
+	}
+	return opts.writeRows(fmt.Sprintf("section_%d", section.ID), []canvas.Section{*section})
+}
+
+func runUsersUpdate(args []string) error {
+	var opts commonOptions
+	fs := newFlagSet("users update", usersUpdateSummary)
+	addOutputFlags(fs, &opts, "")
+	userID := fs.Int("id", 0, "Canvas user ID")
+	sisID := fs.String("sis-id", "", "SIS user ID")
+	fs.String("name", "", "new display name")
+	fs.String("short-name", "", "new short name")
+	fs.String("sortable-name", "", "new sortable name")
+	fs.String("email", "", "new default email")
+	sticky := addStickyFlag(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	var id string
+	switch {
+	case *userID > 0:
+		id = strconv.Itoa(*userID)
+	case *sisID != "":
+		id = "sis_user_id:" + *sisID
+	default:
+		return fmt.Errorf("one of --id or --sis-id is required")
+	}
+	update := canvas.UserUpdate{
+		Name:                  stringFlag(fs, "name"),
+		ShortName:             stringFlag(fs, "short-name"),
+		SortableName:          stringFlag(fs, "sortable-name"),
+		Email:                 stringFlag(fs, "email"),
+		OverrideSISStickiness: stickyOverride(*sticky),
+	}
+	user, err := api.Users().UpdateUser(id, update)
+	if err != nil {
+		return err
+	}
+	return opts.writeRows(fmt.Sprintf("user_%d", user.ID), []canvas.User{*user})
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+
+	"github.com/coraxwolf/CCTA_3-4/pkg/canvas"
+)
+
+const usersLookupSummary = "Look up users by Canvas ID, SIS user ID, or a name/email search"
+
+func init() {
+	register(command{Group: "users", Name: "lookup", Summary: usersLookupSummary, Run: runUsersLookup})
+}
+
+func runUsersLookup(args []string) error {
+	var opts commonOptions
+	fs := newFlagSet("users lookup", usersLookupSummary)
+	addAccountFlags(fs, &opts)
+	addOutputFlags(fs, &opts, "")
+	id := fs.Int("id", 0, "Canvas user ID")
+	sisID := fs.String("sis-id", "", "SIS user ID")
+	search := fs.String("search", "", "name, login, or email to search for in the account")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	var users []CanvasUser
+	switch {
+	case *id > 0:
+		user, err := getUser(strconv.Itoa(*id))
+		if err != nil {
+			return err
+		}
+		users = append(users, *user)
+	case *sisID != "":
+		user, err := getUser("sis_user_id:" + url.PathEscape(*sisID))
+		if err != nil {
+			return err
+		}
+		users = append(users, *user)
+	case *search != "":
+		found, err := canvas.GetAllPagesJSON[CanvasUser](api, fmt.Sprintf("accounts/%d/users", opts.AccountID), canvas.WithParam("search_term", *search), canvas.WithPerPage(100))
+		if err != nil {
+			return fmt.Errorf("error searching users: %w", err)
+		}
+		users = found
+	default:
+		return fmt.Errorf("one of --id, --sis-id, or --search is required")
+	}
+	for i := range users {
+		users[i].URL = api.UserURL(users[i].ID)
+	}
+	return opts.writeRows("users", users)
+}
+
+func getUser(userID string) (*CanvasUser, error) {
+	user, err := canvas.GetJSON[CanvasUser](api, "users/"+userID)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching user %s: %w", userID, err)
+	}
+	return &user, nil
+}
@@ -3,3 +3,5 @@ module github.com/coraxwolf/CCTA_3-4
 go 1.24.3
 
 require github.com/joho/godotenv v1.5.1
+
+require gopkg.in/yaml.v3 v3.0.1
@@ -0,0 +1,164 @@
+// Package audit checks whether courses are ready to publish: whether they have modules,
+// assignments, quizzes, a front page, and teachers, and what a student can see.
+package audit
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/coraxwolf/CCTA_3-4/pkg/canvas"
+)
+
+// The names of the built-in checks.
+const (
+	CheckModules     = "modules"
+	CheckFrontPage   = "front_page" // only for courses whose home page is the front page
+	CheckAssignments = "assignments"
+	CheckQuizzes     = "quizzes" // classic quizzes and New Quizzes
+	CheckTeachers    = "teachers"
+	// CheckStudentView masquerades as the course's test student, which needs the masquerade
+	// permission and creates the test student if the course has none. That enrollment changes the
+	// course, so the check is never a default.
+	CheckStudentView = "student_view"
+	CheckSyllabus    = "syllabus"
+	CheckDueDates    = "due_dates" // published assignments without a due date
+)
+
+// Result is the outcome of auditing one course. The built-in checks also fill the typed fields;
+// the fields of a check that wasn't run or failed are left at their zero values, and Ran and
+// Errors tell them apart.
+type Result struct {
+	Course             canvas.Course
+	Modules            int
+	ModuleItems        int
+	Assignments        int
+	Quizzes            QuizCounts
+	FrontPage          *bool    // whether the front page has content; nil when it isn't the home page
+	StudentViewMissing []string // key items the test student can't see: front page, first module, syllabus
+	Teachers           []canvas.User
+	Checks             []CheckResult // every check that ran, in order
+	Errors             []CheckError  // in the order the checks ran
+}
+
+// CheckError is a check that could not be run.
+type CheckError struct {
+	Check string
+	Err   error
+}
+
+func (e CheckError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Check, e.Err)
+}
+
+func (e CheckError) Unwrap() error {
+	return e.Err
+}
+
+// Check returns the result of the named check, if it ran.
+func (r Result) Check(name string) (CheckResult, bool) {
+	for _, c := range r.Checks {
+		if c.Check == name {
+			return c, true
+		}
+	}
+	return CheckResult{}, false
+}
+
+// Ran reports whether the check ran and succeeded.
+func (r Result) Ran(name string) bool {
+	c, ok := r.Check(name)
+	return ok && c.Err == nil
+}
+
+// Err returns the error of a failed check, or nil.
+func (r Result) Err(name string) error {
+	c, _ := r.Check(name)
+	return c.Err
+}
+
+// Problems lists what keeps the course from being ready, based on the checks that ran, such as no
+// module items, no assignments, an empty front page, or no teachers. Failed checks are listed too,
+// since the course couldn't be verified.
+func (r Result) Problems() []string {
+	var problems []string
+	for _, c := range r.Checks {
+		if c.Err == nil {
+			problems = append(problems, c.Problems...)
+		}
+	}
+	for _, e := range r.Errors {
+		problems = append(problems, "check failed: "+e.Error())
+	}
+	return problems
+}
+
+// Ready reports whether every check ran and found nothing missing.
+func (r Result) Ready() bool {
+	return len(r.Problems()) == 0
+}
+
+// QuizCounts are a course's classic quizzes, with their questions, and New Quizzes.
+type QuizCounts struct {
+	Classic          int
+	ClassicQuestions int
+	New              int
+}
+
+// String describes the counts, e.g. "2 classic (25 questions); 1 new", or "No".
+func (q QuizCounts) String() string {
+	var parts []string
+	if q.Classic > 0 {
+		parts = append(parts, fmt.Sprintf("%d classic (%d questions)", q.Classic, q.ClassicQuestions))
+	}
+	if q.New > 0 {
+		parts = append(parts, fmt.Sprintf("%d new", q.New))
+	}
+	if len(parts) == 0 {
+		return "No"
+	}
+	return strings.Join(parts, "; ")
+}
+
+// CourseAuditor runs readiness checks on courses.
+type CourseAuditor struct {
+	checks []Check
+}
+
+// NewCourseAuditor returns an auditor that runs the named checks from the registry, or
+// DefaultChecks when none are given. Unknown names fail in every result; use ParseChecks
+// to reject them up front.
+func NewCourseAuditor(api *canvas.APIManager, checks ...string) *CourseAuditor {
+	if len(checks) == 0 {
+		checks = DefaultChecks()
+	}
+	a := &CourseAuditor{}
+	for _, name := range checks {
+		r, ok := lookup(name)
+		if !ok {
+			a.checks = append(a.checks, CheckFunc(name, func(context.Context, canvas.Course) CheckResult {
+				return CheckResult{Err: fmt.Errorf("unknown check %q", name)}
+			}))
+			continue
+		}
+		a.checks = append(a.checks, r.New(api))
+	}
+	return a
+}
+
+// Audit runs the auditor's checks on a course. A failed check is recorded in the result's Errors
+// and the other checks still run.
+func (a *CourseAuditor) Audit(ctx context.Context, course canvas.Course) Result {
+	result := Result{Course: course}
+	for _, c := range a.checks {
+		cr := c.Run(ctx, course)
+		cr.Check = c.Name()
+		if cr.Err != nil {
+			result.Errors = append(result.Errors, CheckError{Check: cr.Check, Err: cr.Err})
+		} else if cr.detail != nil {
+			cr.detail(&result)
+		}
+		result.Checks = append(result.Checks, cr)
+	}
+	return result
+}
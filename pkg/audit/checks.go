@@ -0,0 +1,166 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/coraxwolf/CCTA_3-4/pkg/canvas"
+)
+
+func init() {
+	Register(Registration{Name: CheckModules, Default: true, Description: "the course has module items", New: modulesCheck})
+	Register(Registration{Name: CheckFrontPage, Default: true, Description: "the front page has content, when it is the home page", New: frontPageCheck})
+	Register(Registration{Name: CheckAssignments, Default: true, Description: "the course has assignments", New: assignmentsCheck})
+	Register(Registration{Name: CheckQuizzes, Default: true, Description: "counts classic quizzes and New Quizzes", New: quizzesCheck})
+	Register(Registration{Name: CheckTeachers, Default: true, Description: "the course has teachers", New: teachersCheck})
+	Register(Registration{Name: CheckStudentView, Description: "the test student can see the front page, first module, and syllabus (needs masquerade permission; off by default because it adds a Test Student enrollment to courses without one)",
+		New: func(api *canvas.APIManager) Check { return studentViewCheck{api: api} }})
+	Register(Registration{Name: CheckSyllabus, Description: "the syllabus has content", New: syllabusCheck})
+	Register(Registration{Name: CheckDueDates, Description: "every published assignment has a due date", New: dueDatesCheck})
+}
+
+func modulesCheck(api *canvas.APIManager) Check {
+	return CheckFunc(CheckModules, func(ctx context.Context, course canvas.Course) CheckResult {
+		mods, err := api.Modules().WithContext(ctx).ListModules(course.ID)
+		if err != nil {
+			return CheckResult{Err: err}
+		}
+		items := 0
+		for _, m := range mods {
+			items += m.ItemsCount
+		}
+		cr := CheckResult{
+			Summary: fmt.Sprintf("%d modules, %d items", len(mods), items),
+			detail:  func(r *Result) { r.Modules, r.ModuleItems = len(mods), items },
+		}
+		if items == 0 {
+			cr.Problems = []string{"no module items"}
+		}
+		return cr
+	})
+}
+
+func frontPageCheck(api *canvas.APIManager) Check {
+	return CheckFunc(CheckFrontPage, func(ctx context.Context, course canvas.Course) CheckResult {
+		if course.DefaultView != "wiki" {
+			return CheckResult{Summary: "not the home page"}
+		}
+		fp, err := api.Pages().WithContext(ctx).GetFrontPage(course.ID)
+		if err != nil {
+			return CheckResult{Err: err}
+		}
+		hasContent := fp.Body != ""
+		cr := CheckResult{Summary: yesNo(hasContent), detail: func(r *Result) { r.FrontPage = &hasContent }}
+		if !hasContent {
+			cr.Problems = []string{"empty front page"}
+		}
+		return cr
+	})
+}
+
+func assignmentsCheck(api *canvas.APIManager) Check {
+	return CheckFunc(CheckAssignments, func(ctx context.Context, course canvas.Course) CheckResult {
+		assignments, err := api.Assignments().WithContext(ctx).ListAssignments(course.ID)
+		if err != nil {
+			return CheckResult{Err: err}
+		}
+		cr := CheckResult{Summary: strconv.Itoa(len(assignments)), detail: func(r *Result) { r.Assignments = len(assignments) }}
+		if len(assignments) == 0 {
+			cr.Problems = []string{"no assignments"}
+		}
+		return cr
+	})
+}
+
+// quizzesCheck counts a course's classic quizzes and New Quizzes. Instances without New Quizzes
+// answer 404, which counts as none. Courses without quizzes are not a problem.
+func quizzesCheck(api *canvas.APIManager) Check {
+	return CheckFunc(CheckQuizzes, func(ctx context.Context, course canvas.Course) CheckResult {
+		classic, err := api.Quizzes().WithContext(ctx).ListQuizzes(course.ID)
+		if err != nil {
+			return CheckResult{Err: err}
+		}
+		newQuizzes, err := api.NewQuizzes().WithContext(ctx).ListQuizzes(course.ID)
+		if err != nil && !errors.Is(err, canvas.ErrNotFound) {
+			return CheckResult{Err: err}
+		}
+		counts := QuizCounts{Classic: len(classic), New: len(newQuizzes)}
+		for _, q := range classic {
+			counts.ClassicQuestions += q.QuestionCount
+		}
+		return CheckResult{Summary: counts.String(), detail: func(r *Result) { r.Quizzes = counts }}
+	})
+}
+
+func teachersCheck(api *canvas.APIManager) Check {
+	return CheckFunc(CheckTeachers, func(ctx context.Context, course canvas.Course) CheckResult {
+		teachers, err := api.Users().WithContext(ctx).ListCourseUsers(course.ID, "teacher")
+		if err != nil {
+			return CheckResult{Err: err}
+		}
+		var names []string
+		for _, t := range teachers {
+			names = append(names, t.Name)
+		}
+		cr := CheckResult{Summary: strings.Join(names, "; "), detail: func(r *Result) { r.Teachers = teachers }}
+		if len(teachers) == 0 {
+			cr.Summary, cr.Problems = "None", []string{"no teachers"}
+		}
+		return cr
+	})
+}
+
+var htmlTag = regexp.MustCompile(`<[^>]*>`)
+
+// syllabusCheck fails courses whose syllabus has no text or links. Use courses syllabus-audit to
+// find placeholder and short syllabi too.
+func syllabusCheck(api *canvas.APIManager) Check {
+	return CheckFunc(CheckSyllabus, func(ctx context.Context, course canvas.Course) CheckResult {
+		body, err := api.Courses().WithContext(ctx).GetSyllabus(course.ID)
+		if err != nil {
+			return CheckResult{Err: err}
+		}
+		text := strings.TrimSpace(strings.ReplaceAll(htmlTag.ReplaceAllString(body, ""), "&nbsp;", " "))
+		if text == "" && !strings.Contains(body, "href=") {
+			return CheckResult{Summary: "No", Problems: []string{"no syllabus"}}
+		}
+		return CheckResult{Summary: "Yes"}
+	})
+}
+
+func dueDatesCheck(api *canvas.APIManager) Check {
+	return CheckFunc(CheckDueDates, func(ctx context.Context, course canvas.Course) CheckResult {
+		assignments, err := api.Assignments().WithContext(ctx).ListAssignments(course.ID)
+		if err != nil {
+			return CheckResult{Err: err}
+		}
+		published, missing := 0, 0
+		for _, a := range assignments {
+			if !a.Published {
+				continue
+			}
+			published++
+			if a.DueAt == nil {
+				missing++
+			}
+		}
+		if missing == 0 {
+			return CheckResult{Summary: "all set"}
+		}
+		return CheckResult{
+			Summary:  fmt.Sprintf("%d of %d without a due date", missing, published),
+			Problems: []string{fmt.Sprintf("%d published assignments without a due date", missing)},
+		}
+	})
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "Yes"
+	}
+	return "No"
+}
@@ -0,0 +1,111 @@
+package audit
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/coraxwolf/CCTA_3-4/pkg/canvas"
+)
+
+// Check is one readiness check a CourseAuditor can run. Checks are added with Register, so new ones
+// don't need changes to the auditor.
+type Check interface {
+	Name() string
+	Run(ctx context.Context, course canvas.Course) CheckResult
+}
+
+// CheckResult is the outcome of one check on one course.
+type CheckResult struct {
+	Check    string   // the check's name, set by the auditor
+	Summary  string   // what the check found, e.g. "Yes" or "3 of 12 without a due date"
+	Problems []string // what keeps the course from being ready; empty when the check passed
+	Err      error    // the check could not be run
+	// detail copies what a built-in check found into Result's fields.
+	detail func(*Result)
+}
+
+// Registration describes a check for the registry.
+type Registration struct {
+	Name        string // e.g. "front_page"; used in --checks flags and in CheckResult.Check
+	Description string
+	Default     bool // run when no checks are named
+	New         func(api *canvas.APIManager) Check
+}
+
+var registry []Registration
+
+// Register adds a check to the registry. Checks register themselves from init functions, and a
+// name can only be registered once.
+func Register(r Registration) {
+	if _, ok := lookup(r.Name); ok {
+		panic("audit: check " + r.Name + " registered twice")
+	}
+	registry = append(registry, r)
+}
+
+// Registered returns the registered checks in the order they were registered, which is the order
+// DefaultChecks runs them in.
+func Registered() []Registration {
+	return append([]Registration(nil), registry...)
+}
+
+func lookup(name string) (Registration, bool) {
+	for _, r := range registry {
+		if r.Name == name {
+			return r, true
+		}
+	}
+	return Registration{}, false
+}
+
+// DefaultChecks returns the names of the checks run when none are given.
+func DefaultChecks() []string {
+	var names []string
+	for _, r := range registry {
+		if r.Default {
+			names = append(names, r.Name)
+		}
+	}
+	return names
+}
+
+// CheckNames returns the names of every registered check.
+func CheckNames() []string {
+	names := make([]string, 0, len(registry))
+	for _, r := range registry {
+		names = append(names, r.Name)
+	}
+	return names
+}
+
+// ParseChecks parses a list of check names, such as the values of a comma separated flag.
+// Dashes may be used for underscores, e.g. "front-page".
+func ParseChecks(names []string) ([]string, error) {
+	var checks []string
+	for _, name := range names {
+		c := strings.ReplaceAll(strings.ToLower(strings.TrimSpace(name)), "-", "_")
+		if _, ok := lookup(c); !ok {
+			return nil, fmt.Errorf("unknown check %q (known: %s)", name, strings.Join(CheckNames(), ", "))
+		}
+		checks = append(checks, c)
+	}
+	return checks, nil
+}
+
+// checkFunc adapts a function to a Check.
+type checkFunc struct {
+	name string
+	run  func(ctx context.Context, course canvas.Course) CheckResult
+}
+
+func (c checkFunc) Name() string { return c.name }
+
+func (c checkFunc) Run(ctx context.Context, course canvas.Course) CheckResult {
+	return c.run(ctx, course)
+}
+
+// CheckFunc returns a Check that runs fn, for registering simple checks.
+func CheckFunc(name string, fn func(ctx context.Context, course canvas.Course) CheckResult) Check {
+	return checkFunc{name: name, run: fn}
+}
@@ -0,0 +1,112 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/coraxwolf/CCTA_3-4/pkg/canvas"
+)
+
+// getStudentViewStudent returns the ID of the course's test student, which Canvas creates if it does not exist yet.
+func (c studentViewCheck) getStudentViewStudent(ctx context.Context, courseID int) (int, error) {
+	student, err := canvas.GetJSONCtx[canvas.User](ctx, c.api, fmt.Sprintf("courses/%d/student_view_student", courseID))
+	if err != nil {
+		return 0, fmt.Errorf("error fetching test student for course %d: %w", courseID, err)
+	}
+	return student.ID, nil
+}
+
+// visibleAsStudent requests the endpoint masquerading as the test student and reports whether the
+// response has content. A 401/403/404 means the item is hidden from students.
+func (c studentViewCheck) visibleAsStudent(ctx context.Context, endpoint string, studentID int, hasContent func(body json.RawMessage) bool, opts ...canvas.RequestOption) (bool, error) {
+	resp, err := c.api.GetCtx(canvas.WithAsUser(ctx, strconv.Itoa(studentID)), endpoint, opts...)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close() // Ensure the response body is closed after reading
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound:
+		return false, nil
+	default:
+		return false, canvas.NewAPIError(resp)
+	}
+	var body json.RawMessage
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return false, fmt.Errorf("error decoding %s: %w", endpoint, err)
+	}
+	return hasContent(body), nil
+}
+
+// studentViewCheck lists the key items (front page, first module, syllabus) a student cannot see.
+type studentViewCheck struct {
+	api *canvas.APIManager
+}
+
+func (c studentViewCheck) Name() string { return CheckStudentView }
+
+func (c studentViewCheck) Run(ctx context.Context, course canvas.Course) CheckResult {
+	missing, err := c.missing(ctx, course)
+	if err != nil {
+		return CheckResult{Err: err}
+	}
+	cr := CheckResult{Summary: "None", detail: func(r *Result) { r.StudentViewMissing = missing }}
+	if len(missing) > 0 {
+		cr.Summary = strings.Join(missing, "; ")
+	}
+	for _, item := range missing {
+		cr.Problems = append(cr.Problems, "students can't see the "+item)
+	}
+	return cr
+}
+
+func (c studentViewCheck) missing(ctx context.Context, course canvas.Course) ([]string, error) {
+	studentID, err := c.getStudentViewStudent(ctx, course.ID)
+	if err != nil {
+		return nil, err
+	}
+	var missing []string
+	if course.DefaultView == "wiki" {
+		ok, err := c.visibleAsStudent(ctx, fmt.Sprintf("courses/%d/front_page", course.ID), studentID, func(body json.RawMessage) bool {
+			var page struct {
+				Body string `json:"body"`
+			}
+			return json.Unmarshal(body, &page) == nil && page.Body != ""
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error checking front page as student for course %d: %w", course.ID, err)
+		}
+		if !ok {
+			missing = append(missing, "front page")
+		}
+	}
+	ok, err := c.visibleAsStudent(ctx, fmt.Sprintf("courses/%d/modules", course.ID), studentID, func(body json.RawMessage) bool {
+		var mods []struct {
+			Items []json.RawMessage `json:"items"`
+		}
+		return json.Unmarshal(body, &mods) == nil && len(mods) > 0 && len(mods[0].Items) > 0
+	}, canvas.WithInclude("items"), canvas.WithPerPage(1))
+	if err != nil {
+		return nil, fmt.Errorf("error checking modules as student for course %d: %w", course.ID, err)
+	}
+	if !ok {
+		missing = append(missing, "first module")
+	}
+	ok, err = c.visibleAsStudent(ctx, fmt.Sprintf("courses/%d", course.ID), studentID, func(body json.RawMessage) bool {
+		var c struct {
+			SyllabusBody string `json:"syllabus_body"`
+		}
+		return json.Unmarshal(body, &c) == nil && c.SyllabusBody != ""
+	}, canvas.WithInclude("syllabus_body"))
+	if err != nil {
+		return nil, fmt.Errorf("error checking syllabus as student for course %d: %w", course.ID, err)
+	}
+	if !ok {
+		missing = append(missing, "syllabus")
+	}
+	return missing, nil
+}
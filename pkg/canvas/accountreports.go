@@ -0,0 +1,202 @@
+package canvas
+
+import (
+	"archive/zip"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// AccountReport is one run of an account report, such as provisioning_csv.
+type AccountReport struct {
+	ID         int               `json:"id"`
+	Report     string            `json:"report"`
+	Status     string            `json:"status"`   // created, running, complete, error, aborted, or deleted
+	Progress   int               `json:"progress"` // percent complete
+	CreatedAt  *time.Time        `json:"created_at"`
+	StartedAt  *time.Time        `json:"started_at"`
+	EndedAt    *time.Time        `json:"ended_at"`
+	Parameters map[string]any    `json:"parameters"`
+	Attachment *ReportAttachment `json:"attachment"` // set when the report is complete
+	Message    string            `json:"message"`    // why the report failed
+}
+
+type ReportAttachment struct {
+	ID          int    `json:"id"`
+	Filename    string `json:"filename"`
+	DisplayName string `json:"display_name"`
+	ContentType string `json:"content-type"`
+	Size        int64  `json:"size"`
+	URL         string `json:"url"`
+}
+
+// Done reports whether the report has finished, successfully or not.
+func (r AccountReport) Done() bool {
+	switch r.Status {
+	case "complete", "error", "aborted", "deleted":
+		return true
+	}
+	return false
+}
+
+// ReportType is a report the account can run and the parameters it takes.
+type ReportType struct {
+	Report     string                     `json:"report"`
+	Title      string                     `json:"title"`
+	Parameters map[string]ReportParameter `json:"parameters"`
+	LastRun    *AccountReport             `json:"last_run"`
+}
+
+type ReportParameter struct {
+	Description string `json:"description"`
+	Required    bool   `json:"required"`
+}
+
+type AccountReportsService struct {
+	service
+}
+
+func (api *APIManager) AccountReports() *AccountReportsService {
+	return &AccountReportsService{service{api: api}}
+}
+
+// WithContext returns a copy of the service that sends its requests with ctx.
+func (rs *AccountReportsService) WithContext(ctx context.Context) *AccountReportsService {
+	return &AccountReportsService{service{api: rs.api, ctx: ctx}}
+}
+
+// ListAvailableReports returns the reports the account can run.
+func (rs *AccountReportsService) ListAvailableReports(accountID int) ([]ReportType, error) {
+	var reports []ReportType
+	if err := rs.listJSON(fmt.Sprintf("accounts/%d/reports?per_page=100", accountID), &reports); err != nil {
+		return nil, fmt.Errorf("error listing reports for account %d: %w", accountID, err)
+	}
+	return reports, nil
+}
+
+// StartReport starts a report with the given parameters, e.g. {"enrollment_term_id": 77, "users": true}.
+// Canvas builds it in the background; wait for it with WaitReport.
+func (rs *AccountReportsService) StartReport(accountID int, report string, params map[string]any) (*AccountReport, error) {
+	var run AccountReport
+	endpoint := fmt.Sprintf("accounts/%d/reports/%s", accountID, url.PathEscape(report))
+	if err := rs.sendJSON(http.MethodPost, endpoint, map[string]any{"parameters": params}, &run); err != nil {
+		return nil, fmt.Errorf("error starting report %s: %w", report, err)
+	}
+	if run.Report == "" {
+		run.Report = report // not echoed in dry-run mode
+	}
+	return &run, nil
+}
+
+func (rs *AccountReportsService) GetReport(accountID int, report string, id int) (*AccountReport, error) {
+	var run AccountReport
+	if err := rs.getJSON(fmt.Sprintf("accounts/%d/reports/%s/%d", accountID, url.PathEscape(report), id), &run); err != nil {
+		return nil, fmt.Errorf("error fetching report %s %d: %w", report, id, err)
+	}
+	return &run, nil
+}
+
+// WaitReport polls a report every interval until it finishes and returns its final state. A report
+// that ends in error returns an error with Canvas' message.
+func (rs *AccountReportsService) WaitReport(accountID int, report string, id int, interval time.Duration) (*AccountReport, error) {
+	for {
+		run, err := rs.GetReport(accountID, report, id)
+		if err != nil {
+			return nil, err
+		}
+		if run.Done() {
+			if run.Status != "complete" {
+				return run, fmt.Errorf("report %s %d ended as %s: %s", report, id, run.Status, run.Message)
+			}
+			return run, nil
+		}
+		if err := sleepCtx(rs.context(), interval); err != nil {
+			return run, err
+		}
+	}
+}
+
+// Download saves a complete report's file in dir and returns the paths written. A ZIP file, such
+// as the provisioning report with several CSV files, is extracted to dir/<report>_<id>.
+func (rs *AccountReportsService) Download(run *AccountReport, dir string) ([]string, error) {
+	if run.Attachment == nil || run.Attachment.URL == "" {
+		return nil, fmt.Errorf("report %s %d has no file (status %s)", run.Report, run.ID, run.Status)
+	}
+	resp, err := rs.api.download(rs.context(), run.Attachment.URL)
+	if err != nil {
+		return nil, fmt.Errorf("error downloading report %s %d: %w", run.Report, run.ID, err)
+	}
+	defer resp.Body.Close()
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("error creating directory %s: %w", dir, err)
+	}
+	name := filepath.Base(run.Attachment.Filename)
+	if name == "." || name == ".." || name == string(filepath.Separator) {
+		name = fmt.Sprintf("%s_%d", run.Report, run.ID)
+	}
+	file := filepath.Join(dir, name)
+	if err := writeFile(file, resp.Body); err != nil {
+		return nil, err
+	}
+	if !strings.EqualFold(filepath.Ext(name), ".zip") {
+		return []string{file}, nil
+	}
+	defer os.Remove(file)
+	return unzip(file, filepath.Join(dir, fmt.Sprintf("%s_%d", run.Report, run.ID)))
+}
+
+func writeFile(file string, r io.Reader) error {
+	f, err := os.Create(file)
+	if err != nil {
+		return fmt.Errorf("error creating %s: %w", file, err)
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		return fmt.Errorf("error writing %s: %w", file, err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("error writing %s: %w", file, err)
+	}
+	return nil
+}
+
+// unzip extracts the files of a ZIP archive into dir, flattening any folders in it.
+func unzip(archive, dir string) ([]string, error) {
+	zr, err := zip.OpenReader(archive)
+	if err != nil {
+		return nil, fmt.Errorf("error opening %s: %w", archive, err)
+	}
+	defer zr.Close()
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("error creating directory %s: %w", dir, err)
+	}
+	var files []string
+	for _, entry := range zr.File {
+		if entry.FileInfo().IsDir() {
+			continue
+		}
+		base := path.Base(entry.Name) // drops folders, so entries stay inside dir
+		if base == ".." || base == "." || base == "/" {
+			continue
+		}
+		file := filepath.Join(dir, base)
+		r, err := entry.Open()
+		if err != nil {
+			return files, fmt.Errorf("error reading %s from %s: %w", entry.Name, archive, err)
+		}
+		err = writeFile(file, r)
+		r.Close()
+		if err != nil {
+			return files, err
+		}
+		files = append(files, file)
+	}
+	return files, nil
+}
@@ -0,0 +1,36 @@
+package canvas
+
+import (
+	"context"
+	"fmt"
+)
+
+type Account struct {
+	ID              int    `json:"id"`
+	Name            string `json:"name"`
+	ParentAccountID *int   `json:"parent_account_id"` // nil for a root account
+	RootAccountID   *int   `json:"root_account_id"`
+	SISAccountID    string `json:"sis_account_id"`
+	WorkflowState   string `json:"workflow_state"`
+}
+
+type AccountsService struct {
+	service
+}
+
+func (api *APIManager) Accounts() *AccountsService {
+	return &AccountsService{service{api: api}}
+}
+
+// WithContext returns a copy of the service that sends its requests with ctx.
+func (as *AccountsService) WithContext(ctx context.Context) *AccountsService {
+	return &AccountsService{service{api: as.api, ctx: ctx}}
+}
+
+func (as *AccountsService) GetAccount(id int) (*Account, error) {
+	var account Account
+	if err := as.getJSON(fmt.Sprintf("accounts/%d", id), &account); err != nil {
+		return nil, fmt.Errorf("error fetching account %d: %w", id, err)
+	}
+	return &account, nil
+}
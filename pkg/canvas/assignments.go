@@ -0,0 +1,96 @@
+package canvas
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+type Assignment struct {
+	ID                int        `json:"id"`
+	CourseID          int        `json:"course_id"`
+	Name              string     `json:"name"`
+	Description       string     `json:"description"`
+	DueAt             *time.Time `json:"due_at"`
+	UnlockAt          *time.Time `json:"unlock_at"`
+	LockAt            *time.Time `json:"lock_at"`
+	PointsPossible    float64    `json:"points_possible"`
+	GradingType       string     `json:"grading_type"`
+	SubmissionTypes   []string   `json:"submission_types"`
+	Published         bool       `json:"published"`
+	AssignmentGroupID int        `json:"assignment_group_id"`
+	GroupCategoryID   *int       `json:"group_category_id"`
+	Position          int        `json:"position"`
+	HTMLURL           string     `json:"html_url"`
+}
+
+// AssignmentInput holds the assignment attributes to set on create or update. Nil fields are left out.
+type AssignmentInput struct {
+	Name              *string    `json:"name,omitempty"`
+	Description       *string    `json:"description,omitempty"`
+	DueAt             *time.Time `json:"due_at,omitempty"`
+	UnlockAt          *time.Time `json:"unlock_at,omitempty"`
+	LockAt            *time.Time `json:"lock_at,omitempty"`
+	PointsPossible    *float64   `json:"points_possible,omitempty"`
+	GradingType       *string    `json:"grading_type,omitempty"`
+	SubmissionTypes   []string   `json:"submission_types,omitempty"`
+	Published         *bool      `json:"published,omitempty"`
+	AssignmentGroupID *int       `json:"assignment_group_id,omitempty"`
+	GroupCategoryID   *int       `json:"group_category_id,omitempty"`
+}
+
+type AssignmentsService struct {
+	service
+}
+
+func (api *APIManager) Assignments() *AssignmentsService {
+	return &AssignmentsService{service{api: api}}
+}
+
+// WithContext returns a copy of the service that sends its requests with ctx.
+func (as *AssignmentsService) WithContext(ctx context.Context) *AssignmentsService {
+	return &AssignmentsService{service{api: as.api, ctx: ctx}}
+}
+
+// ListAssignments returns every assignment in a course, following pagination.
+func (as *AssignmentsService) ListAssignments(courseID int) ([]Assignment, error) {
+	var assignments []Assignment
+	if err := as.listJSON(fmt.Sprintf("courses/%d/assignments?per_page=100", courseID), &assignments); err != nil {
+		return nil, fmt.Errorf("error listing assignments for course %d: %w", courseID, err)
+	}
+	return assignments, nil
+}
+
+func (as *AssignmentsService) GetAssignment(courseID, id int) (*Assignment, error) {
+	var assignment Assignment
+	if err := as.getJSON(fmt.Sprintf("courses/%d/assignments/%d", courseID, id), &assignment); err != nil {
+		return nil, fmt.Errorf("error fetching assignment %d in course %d: %w", id, courseID, err)
+	}
+	return &assignment, nil
+}
+
+func (as *AssignmentsService) CreateAssignment(courseID int, input AssignmentInput) (*Assignment, error) {
+	body := map[string]any{"assignment": input}
+	var assignment Assignment
+	if err := as.sendJSON(http.MethodPost, fmt.Sprintf("courses/%d/assignments", courseID), body, &assignment); err != nil {
+		return nil, fmt.Errorf("error creating assignment in course %d: %w", courseID, err)
+	}
+	return &assignment, nil
+}
+
+func (as *AssignmentsService) UpdateAssignment(courseID, id int, input AssignmentInput) (*Assignment, error) {
+	body := map[string]any{"assignment": input}
+	var assignment Assignment
+	if err := as.sendJSON(http.MethodPut, fmt.Sprintf("courses/%d/assignments/%d", courseID, id), body, &assignment); err != nil {
+		return nil, fmt.Errorf("error updating assignment %d in course %d: %w", id, courseID, err)
+	}
+	return &assignment, nil
+}
+
+func (as *AssignmentsService) DeleteAssignment(courseID, id int) error {
+	if err := as.sendJSON(http.MethodDelete, fmt.Sprintf("courses/%d/assignments/%d", courseID, id), nil, nil); err != nil {
+		return fmt.Errorf("error deleting assignment %d in course %d: %w", id, courseID, err)
+	}
+	return nil
+}
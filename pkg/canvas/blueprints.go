@@ -0,0 +1,175 @@
+package canvas
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// DefaultBlueprintTemplate addresses a blueprint course's template. Canvas has one per blueprint course.
+const DefaultBlueprintTemplate = "default"
+
+// BlueprintTemplate is the template of a blueprint course: the content pushed to its associated courses.
+type BlueprintTemplate struct {
+	ID                    int                 `json:"id"`
+	CourseID              int                 `json:"course_id"`
+	LastExportCompletedAt *time.Time          `json:"last_export_completed_at"`
+	AssociatedCourseCount int                 `json:"associated_course_count"`
+	LatestMigration       *BlueprintMigration `json:"latest_migration"`
+}
+
+// BlueprintMigration is a sync of a blueprint's changes to its associated courses.
+type BlueprintMigration struct {
+	ID                 int        `json:"id"`
+	TemplateID         int        `json:"template_id"`
+	UserID             int        `json:"user_id"`
+	WorkflowState      string     `json:"workflow_state"` // queued, exporting, imports_queued, completed, exports_failed, or imports_failed
+	Comment            string     `json:"comment"`
+	CreatedAt          *time.Time `json:"created_at"`
+	ExportsStartedAt   *time.Time `json:"exports_started_at"`
+	ImportsQueuedAt    *time.Time `json:"imports_queued_at"`
+	ImportsCompletedAt *time.Time `json:"imports_completed_at"`
+}
+
+// Done reports whether the sync has finished, successfully or not.
+func (bm BlueprintMigration) Done() bool {
+	return bm.WorkflowState == "completed" || bm.Failed()
+}
+
+func (bm BlueprintMigration) Failed() bool {
+	return bm.WorkflowState == "exports_failed" || bm.WorkflowState == "imports_failed"
+}
+
+// BlueprintSyncOptions are the settings of a blueprint sync.
+type BlueprintSyncOptions struct {
+	Comment                 string `json:"comment,omitempty"`
+	SendNotification        bool   `json:"send_notification,omitempty"`
+	CopySettings            bool   `json:"copy_settings,omitempty"` // also push the course settings
+	PublishAfterInitialSync bool   `json:"publish_after_initial_sync,omitempty"`
+}
+
+// BlueprintSubscription is the blueprint an associated course receives content from.
+type BlueprintSubscription struct {
+	ID              int `json:"id"`
+	TemplateID      int `json:"template_id"`
+	BlueprintCourse struct {
+		ID         int    `json:"id"`
+		Name       string `json:"name"`
+		CourseCode string `json:"course_code"`
+		TermName   string `json:"term_name"`
+	} `json:"blueprint_course"`
+}
+
+type BlueprintService struct {
+	service
+}
+
+func (api *APIManager) Blueprints() *BlueprintService {
+	return &BlueprintService{service{api: api}}
+}
+
+// WithContext returns a copy of the service that sends its requests with ctx.
+func (bs *BlueprintService) WithContext(ctx context.Context) *BlueprintService {
+	return &BlueprintService{service{api: bs.api, ctx: ctx}}
+}
+
+// GetTemplate returns a blueprint course's template. Courses that aren't blueprints return an
+// APIError matching ErrNotFound.
+func (bs *BlueprintService) GetTemplate(courseID int) (*BlueprintTemplate, error) {
+	var template BlueprintTemplate
+	if err := bs.getJSON(fmt.Sprintf("courses/%d/blueprint_templates/%s", courseID, DefaultBlueprintTemplate), &template); err != nil {
+		return nil, fmt.Errorf("error fetching blueprint template of course %d: %w", courseID, err)
+	}
+	return &template, nil
+}
+
+// ListAssociatedCourses returns the courses a blueprint syncs to.
+func (bs *BlueprintService) ListAssociatedCourses(courseID int) ([]Course, error) {
+	var courses []Course
+	if err := bs.listJSON(fmt.Sprintf("courses/%d/blueprint_templates/%s/associated_courses?per_page=100", courseID, DefaultBlueprintTemplate), &courses); err != nil {
+		return nil, fmt.Errorf("error listing courses associated with blueprint %d: %w", courseID, err)
+	}
+	return courses, nil
+}
+
+// UpdateAssociations adds courses to and removes courses from a blueprint. New associations get
+// the blueprint's content on the next sync. Canvas rejects the whole update if a course to add is
+// a blueprint itself or already associated with another blueprint.
+func (bs *BlueprintService) UpdateAssociations(courseID int, add, remove []int) error {
+	body := map[string][]int{}
+	if len(add) > 0 {
+		body["course_ids_to_add"] = add
+	}
+	if len(remove) > 0 {
+		body["course_ids_to_remove"] = remove
+	}
+	if len(body) == 0 {
+		return nil
+	}
+	if err := bs.sendJSON(http.MethodPut, fmt.Sprintf("courses/%d/blueprint_templates/%s/update_associations", courseID, DefaultBlueprintTemplate), body, nil); err != nil {
+		return fmt.Errorf("error updating associations of blueprint %d: %w", courseID, err)
+	}
+	return nil
+}
+
+func (bs *BlueprintService) AddAssociations(courseID int, courseIDs ...int) error {
+	return bs.UpdateAssociations(courseID, courseIDs, nil)
+}
+
+func (bs *BlueprintService) RemoveAssociations(courseID int, courseIDs ...int) error {
+	return bs.UpdateAssociations(courseID, nil, courseIDs)
+}
+
+// StartSync queues a sync of the blueprint's unsynced changes to every associated course, and
+// its full content to newly associated ones.
+func (bs *BlueprintService) StartSync(courseID int, opts BlueprintSyncOptions) (*BlueprintMigration, error) {
+	var migration BlueprintMigration
+	if err := bs.sendJSON(http.MethodPost, fmt.Sprintf("courses/%d/blueprint_templates/%s/migrations", courseID, DefaultBlueprintTemplate), opts, &migration); err != nil {
+		return nil, fmt.Errorf("error starting sync of blueprint %d: %w", courseID, err)
+	}
+	return &migration, nil
+}
+
+func (bs *BlueprintService) GetMigration(courseID, migrationID int) (*BlueprintMigration, error) {
+	var migration BlueprintMigration
+	if err := bs.getJSON(fmt.Sprintf("courses/%d/blueprint_templates/%s/migrations/%d", courseID, DefaultBlueprintTemplate, migrationID), &migration); err != nil {
+		return nil, fmt.Errorf("error fetching sync %d of blueprint %d: %w", migrationID, courseID, err)
+	}
+	return &migration, nil
+}
+
+// ListMigrations returns a blueprint's syncs, newest first.
+func (bs *BlueprintService) ListMigrations(courseID int) ([]BlueprintMigration, error) {
+	var migrations []BlueprintMigration
+	if err := bs.listJSON(fmt.Sprintf("courses/%d/blueprint_templates/%s/migrations?per_page=100", courseID, DefaultBlueprintTemplate), &migrations); err != nil {
+		return nil, fmt.Errorf("error listing syncs of blueprint %d: %w", courseID, err)
+	}
+	return migrations, nil
+}
+
+// WaitMigration polls a sync every interval until it finishes and returns its final state. It ends
+// early with the context error if the service's context is cancelled.
+func (bs *BlueprintService) WaitMigration(courseID, migrationID int, interval time.Duration) (*BlueprintMigration, error) {
+	for {
+		migration, err := bs.GetMigration(courseID, migrationID)
+		if err != nil {
+			return nil, err
+		}
+		if migration.Done() {
+			return migration, nil
+		}
+		if err := sleepCtx(bs.context(), interval); err != nil {
+			return migration, err
+		}
+	}
+}
+
+// ListSubscriptions returns the blueprint an associated course receives content from, if any.
+func (bs *BlueprintService) ListSubscriptions(courseID int) ([]BlueprintSubscription, error) {
+	var subscriptions []BlueprintSubscription
+	if err := bs.listJSON(fmt.Sprintf("courses/%d/blueprint_subscriptions?per_page=100", courseID), &subscriptions); err != nil {
+		return nil, fmt.Errorf("error listing blueprint subscriptions of course %d: %w", courseID, err)
+	}
+	return subscriptions, nil
+}
@@ -0,0 +1,154 @@
+package canvas
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// CacheOptions configures the on-disk cache of GET responses.
+type CacheOptions struct {
+	Dir string        // one file per endpoint and token
+	TTL time.Duration // entries older than this are fetched again in full and removed; 0 keeps them
+}
+
+// cachedHeaders are the response headers kept with a cached body. Link carries the pagination;
+// the rate limit headers always come from the 304.
+var cachedHeaders = []string{"Content-Type", "Link", "Deprecation", "Sunset"}
+
+type responseCache struct {
+	opts CacheOptions
+	hits atomic.Int64 // 304s answered from the cache
+}
+
+type cacheEntry struct {
+	Endpoint string      `json:"endpoint"`
+	ETag     string      `json:"etag"`
+	StoredAt time.Time   `json:"stored_at"`
+	Header   http.Header `json:"header"`
+	Body     []byte      `json:"body"`
+}
+
+// SetCache keeps GET responses that have an ETag on disk. The next request for the same endpoint
+// sends If-None-Match, and when Canvas answers 304 Not Modified the stored body is returned as a
+// 200, so an unchanged list page isn't downloaded again. Canvas still decides whether an entry is
+// current, so results are never stale; the 304s count against the rate limit, but cost less.
+// Entries are keyed by the token too, since what a token can see depends on its user. Expired
+// entries are removed when the cache is set.
+func (api *APIManager) SetCache(opts CacheOptions) error {
+	if err := os.MkdirAll(opts.Dir, 0o700); err != nil {
+		return fmt.Errorf("error creating cache directory: %w", err)
+	}
+	if opts.TTL > 0 {
+		files, _ := filepath.Glob(filepath.Join(opts.Dir, "*.json"))
+		for _, file := range files {
+			if info, err := os.Stat(file); err == nil && time.Since(info.ModTime()) > opts.TTL {
+				os.Remove(file)
+			}
+		}
+	}
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	api.cache = &responseCache{opts: opts}
+	return nil
+}
+
+func (api *APIManager) responseCache() *responseCache {
+	api.mu.RLock()
+	defer api.mu.RUnlock()
+	return api.cache
+}
+
+func (c *responseCache) file(token, url string) string {
+	sum := sha256.Sum256([]byte(token + "\n" + url))
+	return filepath.Join(c.opts.Dir, hex.EncodeToString(sum[:16])+".json")
+}
+
+// load returns the entry in file, or nil when there is none or it has expired.
+func (c *responseCache) load(file string) *cacheEntry {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil
+	}
+	var entry cacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil || entry.ETag == "" {
+		return nil
+	}
+	if c.opts.TTL > 0 && time.Since(entry.StoredAt) > c.opts.TTL {
+		return nil
+	}
+	return &entry
+}
+
+// update answers a 304 from entry, and stores a 200 with an ETag for the next request. Other
+// responses are returned as they are.
+func (c *responseCache) update(file, endpoint string, entry *cacheEntry, resp *http.Response) (*http.Response, error) {
+	switch {
+	case resp.StatusCode == http.StatusNotModified && entry != nil:
+		resp.Body.Close()
+		c.hits.Add(1)
+		header := resp.Header.Clone()
+		for name, values := range entry.Header {
+			header[name] = values
+		}
+		return &http.Response{
+			Status:        "200 OK",
+			StatusCode:    http.StatusOK,
+			Proto:         resp.Proto,
+			ProtoMajor:    resp.ProtoMajor,
+			ProtoMinor:    resp.ProtoMinor,
+			Header:        header,
+			Body:          io.NopCloser(bytes.NewReader(entry.Body)),
+			ContentLength: int64(len(entry.Body)),
+			Request:       resp.Request,
+		}, nil
+	case resp.StatusCode == http.StatusOK && resp.Header.Get("ETag") != "":
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+		stored := cacheEntry{Endpoint: endpoint, ETag: resp.Header.Get("ETag"), StoredAt: time.Now(), Header: http.Header{}, Body: body}
+		for _, name := range cachedHeaders {
+			if values := resp.Header.Values(name); len(values) > 0 {
+				stored.Header[name] = values
+			}
+		}
+		c.store(file, stored) // a cache that can't be written only costs the saving
+		return resp, nil
+	}
+	return resp, nil
+}
+
+// store writes the entry through a temporary file, so concurrent requests for the same endpoint
+// never read half an entry.
+func (c *responseCache) store(file string, entry cacheEntry) {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+	tmp, err := os.CreateTemp(c.opts.Dir, strings.TrimSuffix(filepath.Base(file), ".json")+"-*.tmp")
+	if err != nil {
+		return
+	}
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), file)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+	}
+}
@@ -0,0 +1,159 @@
+package canvas_test
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/coraxwolf/CCTA_3-4/pkg/canvas"
+)
+
+// cachedAPI gives api the test retry policy and a response cache in dir.
+func cachedAPI(t *testing.T, api *canvas.APIManager, dir string) *canvas.APIManager {
+	t.Helper()
+	api.SetRetryPolicy(fastRetry)
+	if err := api.SetCache(canvas.CacheOptions{Dir: dir}); err != nil {
+		t.Fatal(err)
+	}
+	return api
+}
+
+func TestCacheServesBodyOnNotModified(t *testing.T) {
+	srv, api := newServer(t)
+	api = cachedAPI(t, api, t.TempDir())
+	c := srv.AddCourse(canvas.Course{Name: "Biology 101", CourseCode: "BIO-101"})
+
+	first, err := api.Courses().GetCourse(c.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := api.Courses().GetCourse(c.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second.Name != first.Name || second.CourseCode != "BIO-101" {
+		t.Errorf("cached response is %+v, want %+v", second, first)
+	}
+	requests := srv.Requests()
+	if len(requests) != 2 {
+		t.Fatalf("sent %d requests, want 2", len(requests))
+	}
+	if requests[0].Header.Get("If-None-Match") != "" || requests[1].Header.Get("If-None-Match") == "" {
+		t.Errorf("If-None-Match = %q then %q, want none then the ETag",
+			requests[0].Header.Get("If-None-Match"), requests[1].Header.Get("If-None-Match"))
+	}
+	if hits := api.Stats().CacheHits; hits != 1 {
+		t.Errorf("cache hits = %d, want 1", hits)
+	}
+}
+
+func TestCacheKeyedByTokenAndURL(t *testing.T) {
+	srv, api := newServer(t)
+	srv.Token = "" // accept both tokens
+	dir := t.TempDir()
+	api = cachedAPI(t, api, dir)
+	other := cachedAPI(t, canvas.NewAPI(slog.New(slog.NewTextHandler(io.Discard, nil)), "other-token", srv.BaseURL(), 700, 60), dir)
+	bio := srv.AddCourse(canvas.Course{Name: "Biology 101"})
+	chem := srv.AddCourse(canvas.Course{Name: "Chemistry 101"})
+
+	if _, err := api.Courses().GetCourse(bio.ID); err != nil {
+		t.Fatal(err)
+	}
+	// another course is another URL, and the same course with another token another key
+	got, err := api.Courses().GetCourse(chem.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Name != "Chemistry 101" {
+		t.Errorf("course %d is %q, want Chemistry 101", chem.ID, got.Name)
+	}
+	if _, err := other.Courses().GetCourse(bio.ID); err != nil {
+		t.Fatal(err)
+	}
+	for i, r := range srv.Requests() {
+		if etag := r.Header.Get("If-None-Match"); etag != "" {
+			t.Errorf("request %d for %s sent If-None-Match %s from another entry", i, r.Path, etag)
+		}
+	}
+	if hits := api.Stats().CacheHits + other.Stats().CacheHits; hits != 0 {
+		t.Errorf("cache hits = %d, want 0", hits)
+	}
+
+	if _, err := other.Courses().GetCourse(bio.ID); err != nil {
+		t.Fatal(err)
+	}
+	if hits := other.Stats().CacheHits; hits != 1 {
+		t.Errorf("cache hits for the second token = %d, want 1 once it has its own entry", hits)
+	}
+}
+
+func TestCacheReplacesStaleEntry(t *testing.T) {
+	srv, api := newServer(t)
+	api = cachedAPI(t, api, t.TempDir())
+	c := srv.AddCourse(canvas.Course{Name: "Biology 101"})
+
+	if _, err := api.Courses().GetCourse(c.ID); err != nil {
+		t.Fatal(err)
+	}
+	name := "Biology 101 (Fall)"
+	if _, err := api.Courses().UpdateCourse(c.ID, canvas.CourseUpdate{Name: &name}); err != nil {
+		t.Fatal(err)
+	}
+	// the ETag no longer matches, so Canvas answers 200 with the new course
+	changed, err := api.Courses().GetCourse(c.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if changed.Name != name {
+		t.Fatalf("after the update got %q, want %q", changed.Name, name)
+	}
+	if hits := api.Stats().CacheHits; hits != 0 {
+		t.Fatalf("cache hits = %d after the course changed, want 0", hits)
+	}
+	// and the new response replaced the stale entry
+	cached, err := api.Courses().GetCourse(c.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cached.Name != name {
+		t.Errorf("cached course is %q, want %q", cached.Name, name)
+	}
+	if hits := api.Stats().CacheHits; hits != 1 {
+		t.Errorf("cache hits = %d, want 1", hits)
+	}
+}
+
+func TestCacheRecordsDeprecationOfCachedResponse(t *testing.T) {
+	// Canvas sends the deprecation headers with the full response, not with the 304
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.Header().Set("Deprecation", "true")
+		w.Header().Set("Sunset", "Wed, 01 Jul 2026 00:00:00 GMT")
+		fmt.Fprint(w, `{"id": 1, "name": "Biology 101"}`)
+	}))
+	defer srv.Close()
+	api := cachedAPI(t, canvas.NewAPI(slog.New(slog.NewTextHandler(io.Discard, nil)), "secret", srv.URL+"/api/v1/", 700, 60), t.TempDir())
+
+	for range 2 {
+		if _, err := api.Courses().GetCourse(1); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if hits := api.Stats().CacheHits; hits != 1 {
+		t.Fatalf("cache hits = %d, want 1", hits)
+	}
+	deprecations := api.Deprecations()
+	if len(deprecations) != 1 {
+		t.Fatalf("got %d deprecations, want 1", len(deprecations))
+	}
+	if d := deprecations[0]; d.Requests != 2 || d.Sunset == nil {
+		t.Errorf("got %+v, want both requests counted with the sunset date", d)
+	}
+}
@@ -0,0 +1,181 @@
+package canvas
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+type CalendarEvent struct {
+	ID            int        `json:"id"`
+	Title         string     `json:"title"`
+	Description   string     `json:"description"`
+	StartAt       *time.Time `json:"start_at"`
+	EndAt         *time.Time `json:"end_at"`
+	LocationName  string     `json:"location_name"`
+	ContextCode   string     `json:"context_code"` // e.g. course_123 or course_section_456
+	RRule         string     `json:"rrule"`
+	SeriesUUID    string     `json:"series_uuid"`
+	WorkflowState string     `json:"workflow_state"`
+	HTMLURL       string     `json:"html_url"`
+}
+
+// CalendarEventInput holds the event attributes to set on create or update. Empty fields are left out.
+type CalendarEventInput struct {
+	ContextCode  string     `json:"context_code,omitempty"`
+	Title        string     `json:"title,omitempty"`
+	Description  string     `json:"description,omitempty"`
+	StartAt      *time.Time `json:"start_at,omitempty"`
+	EndAt        *time.Time `json:"end_at,omitempty"`
+	LocationName string     `json:"location_name,omitempty"`
+	RRule        string     `json:"rrule,omitempty"` // makes the event a series, e.g. FREQ=WEEKLY;BYDAY=MO,WE;UNTIL=20251212T235959Z
+}
+
+type CalendarEventListOptions struct {
+	ContextCodes []string // e.g. course_123 or course_section_456
+	StartDate    string   // YYYY-MM-DD
+	EndDate      string
+	AllEvents    bool // every event regardless of date
+}
+
+// Which series events an update or delete applies to.
+const (
+	SeriesOne       = "one"
+	SeriesAll       = "all"
+	SeriesFollowing = "following"
+)
+
+type CalendarService struct {
+	service
+}
+
+func (api *APIManager) Calendar() *CalendarService {
+	return &CalendarService{service{api: api}}
+}
+
+// WithContext returns a copy of the service that sends its requests with ctx.
+func (cs *CalendarService) WithContext(ctx context.Context) *CalendarService {
+	return &CalendarService{service{api: cs.api, ctx: ctx}}
+}
+
+// ListEvents returns the calendar events (not assignments) matching opts, following pagination.
+func (cs *CalendarService) ListEvents(opts CalendarEventListOptions) ([]CalendarEvent, error) {
+	var events []CalendarEvent
+	if err := cs.listJSON(withQuery("calendar_events", opts.query()), &events); err != nil {
+		return nil, fmt.Errorf("error listing calendar events: %w", err)
+	}
+	return events, nil
+}
+
+// ListCourseEvents returns a course's calendar events. Events in its sections' calendars are
+// not included; list those with ListEvents and their course_section_<id> context codes.
+func (cs *CalendarService) ListCourseEvents(courseID int, opts CalendarEventListOptions) ([]CalendarEvent, error) {
+	opts.ContextCodes = []string{fmt.Sprintf("course_%d", courseID)}
+	events, err := cs.ListEvents(opts)
+	if err != nil {
+		return nil, fmt.Errorf("error listing calendar events for course %d: %w", courseID, err)
+	}
+	return events, nil
+}
+
+// ListUserEvents returns the calendar events a user sees: their own and their courses' events.
+// opts.ContextCodes narrows them down. userID is a Canvas ID, "self", or "sis_user_id:..." form.
+func (cs *CalendarService) ListUserEvents(userID string, opts CalendarEventListOptions) ([]CalendarEvent, error) {
+	var events []CalendarEvent
+	if err := cs.listJSON(withQuery("users/"+userID+"/calendar_events", opts.query()), &events); err != nil {
+		return nil, fmt.Errorf("error listing calendar events for user %s: %w", userID, err)
+	}
+	return events, nil
+}
+
+func (cs *CalendarService) GetEvent(id int) (*CalendarEvent, error) {
+	var event CalendarEvent
+	if err := cs.getJSON(fmt.Sprintf("calendar_events/%d", id), &event); err != nil {
+		return nil, fmt.Errorf("error fetching calendar event %d: %w", id, err)
+	}
+	return &event, nil
+}
+
+func (opts CalendarEventListOptions) query() url.Values {
+	query := url.Values{}
+	query.Set("per_page", perPage(0))
+	query.Set("type", "event")
+	for _, code := range opts.ContextCodes {
+		query.Add("context_codes[]", code)
+	}
+	if opts.StartDate != "" {
+		query.Set("start_date", opts.StartDate)
+	}
+	if opts.EndDate != "" {
+		query.Set("end_date", opts.EndDate)
+	}
+	if opts.AllEvents {
+		query.Set("all_events", strconv.FormatBool(true))
+	}
+	return query
+}
+
+// CreateEvent creates an event in input.ContextCode's calendar: a course (course_<id>), a section
+// (course_section_<id>), or a user (user_<id>).
+func (cs *CalendarService) CreateEvent(input CalendarEventInput) (*CalendarEvent, error) {
+	body := map[string]any{"calendar_event": input}
+	var event CalendarEvent
+	if err := cs.sendJSON(http.MethodPost, "calendar_events", body, &event); err != nil {
+		return nil, fmt.Errorf("error creating calendar event: %w", err)
+	}
+	return &event, nil
+}
+
+// UpdateEvent changes an event. For events in a series, which is SeriesOne, SeriesAll, or SeriesFollowing.
+func (cs *CalendarService) UpdateEvent(id int, input CalendarEventInput, which string) (*CalendarEvent, error) {
+	body := map[string]any{"calendar_event": input}
+	if which != "" {
+		body["which"] = which
+	}
+	var event CalendarEvent
+	if err := cs.sendJSON(http.MethodPut, fmt.Sprintf("calendar_events/%d", id), body, &event); err != nil {
+		return nil, fmt.Errorf("error updating calendar event %d: %w", id, err)
+	}
+	return &event, nil
+}
+
+// DeleteEvent deletes an event. For events in a series, which is SeriesOne, SeriesAll, or SeriesFollowing.
+func (cs *CalendarService) DeleteEvent(id int, which string) error {
+	endpoint := fmt.Sprintf("calendar_events/%d", id)
+	if which != "" {
+		endpoint += "?which=" + url.QueryEscape(which)
+	}
+	if err := cs.sendJSON(http.MethodDelete, endpoint, nil, nil); err != nil {
+		return fmt.Errorf("error deleting calendar event %d: %w", id, err)
+	}
+	return nil
+}
+
+// DeleteCourseEvents deletes every calendar event of a course matching opts, e.g. a date range, and
+// returns how many were deleted. A series is deleted as a whole. It stops at the first error.
+func (cs *CalendarService) DeleteCourseEvents(courseID int, opts CalendarEventListOptions) (int, error) {
+	events, err := cs.ListCourseEvents(courseID, opts)
+	if err != nil {
+		return 0, err
+	}
+	deleted := 0
+	series := make(map[string]bool)
+	for _, event := range events {
+		which := ""
+		if event.SeriesUUID != "" {
+			if series[event.SeriesUUID] {
+				continue
+			}
+			series[event.SeriesUUID] = true
+			which = SeriesAll
+		}
+		if err := cs.DeleteEvent(event.ID, which); err != nil {
+			return deleted, err
+		}
+		deleted++
+	}
+	return deleted, nil
+}
@@ -0,0 +1,184 @@
+package canvas
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is matched by the CircuitOpenError returned, without sending the request, while
+// the circuit breaker is open.
+var ErrCircuitOpen = errors.New("canvas: circuit open")
+
+// CircuitOpenError is returned instead of sending a request after Canvas failed too many times in
+// a row. Use errors.Is with ErrCircuitOpen to check for it.
+type CircuitOpenError struct {
+	Failures int       // consecutive failures that opened the circuit
+	Until    time.Time // when probe requests are let through again
+}
+
+func (e *CircuitOpenError) Error() string {
+	return fmt.Sprintf("canvas: circuit open after %d consecutive server errors or timeouts, requests fail until %s",
+		e.Failures, e.Until.Format(time.TimeOnly))
+}
+
+func (e *CircuitOpenError) Is(target error) bool {
+	return target == ErrCircuitOpen
+}
+
+type CircuitBreakerOptions struct {
+	Failures int           // consecutive 5xx responses or timeouts that open the circuit, 0 turns the breaker off
+	Cooldown time.Duration // how long the circuit stays open before probing
+	Probes   int           // requests let through while half-open; all of them must succeed to close it
+}
+
+var DefaultCircuitBreaker = CircuitBreakerOptions{
+	Failures: 10,
+	Cooldown: time.Minute,
+	Probes:   1,
+}
+
+type circuitState int
+
+const (
+	circuitClosed circuitState = iota
+	circuitOpen
+	circuitHalfOpen
+)
+
+// circuitBreaker stops requests to a Canvas instance that keeps failing. Every attempt counts,
+// retries included, so a bulk job stops after a few requests instead of retrying each one.
+type circuitBreaker struct {
+	mu        sync.Mutex
+	opts      CircuitBreakerOptions
+	logger    *slog.Logger
+	state     circuitState
+	failures  int // consecutive, while closed
+	until     time.Time
+	probing   int // probes in flight
+	succeeded int // probes that succeeded since the circuit half-opened
+	opens     int
+	now       func() time.Time // time.Now when nil; tests replace it
+}
+
+// SetCircuitBreaker replaces the circuit breaker options, by default DefaultCircuitBreaker. The
+// breaker starts closed.
+func (api *APIManager) SetCircuitBreaker(opts CircuitBreakerOptions) {
+	if opts.Probes < 1 {
+		opts.Probes = 1
+	}
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	api.breaker = &circuitBreaker{opts: opts, logger: api.logger}
+}
+
+func (api *APIManager) circuitBreaker() *circuitBreaker {
+	api.mu.RLock()
+	defer api.mu.RUnlock()
+	return api.breaker
+}
+
+// allow reports whether a request may be sent, and whether it is a probe of a half-open circuit.
+func (cb *circuitBreaker) allow() (probe bool, err error) {
+	if cb == nil || cb.opts.Failures <= 0 {
+		return false, nil
+	}
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	switch cb.state {
+	case circuitOpen:
+		if cb.clock().Before(cb.until) {
+			return false, &CircuitOpenError{Failures: cb.opts.Failures, Until: cb.until}
+		}
+		cb.state, cb.succeeded = circuitHalfOpen, 0
+		cb.logger.Info("Circuit half-open, sending probe requests", "probes", cb.opts.Probes)
+		fallthrough
+	case circuitHalfOpen:
+		if cb.probing+cb.succeeded >= cb.opts.Probes {
+			return false, &CircuitOpenError{Failures: cb.opts.Failures, Until: cb.clock()} // until the probes are answered
+		}
+		cb.probing++
+		return true, nil
+	}
+	return false, nil
+}
+
+// record counts the outcome of a request that allow let through. Only 5xx responses and timeouts
+// are failures. Requests whose context was cancelled, or that failed for another reason (a refused
+// connection, a bad URL, a rate limit wait that gave up), say nothing about Canvas and only release
+// their probe.
+func (cb *circuitBreaker) record(ctx context.Context, probe bool, resp *http.Response, err error) {
+	if cb == nil || cb.opts.Failures <= 0 {
+		return
+	}
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	if probe {
+		cb.probing--
+	}
+	if ctx.Err() != nil {
+		return
+	}
+	if err != nil && !isTimeout(err) {
+		return
+	}
+	failed := err != nil || resp.StatusCode >= 500
+	switch {
+	case !failed && cb.state == circuitHalfOpen:
+		if !probe {
+			return
+		}
+		if cb.succeeded++; cb.succeeded >= cb.opts.Probes {
+			cb.state, cb.failures = circuitClosed, 0
+			cb.logger.Info("Circuit closed, Canvas is answering again")
+		}
+	case !failed:
+		cb.failures = 0
+	case cb.state == circuitHalfOpen:
+		if probe {
+			cb.open()
+		}
+	case cb.state == circuitClosed:
+		if cb.failures++; cb.failures >= cb.opts.Failures {
+			cb.open()
+		}
+	}
+}
+
+// open opens the circuit for the cool-down. The caller holds cb.mu.
+func (cb *circuitBreaker) open() {
+	cb.state, cb.until, cb.succeeded = circuitOpen, cb.clock().Add(cb.opts.Cooldown), 0
+	cb.opens++
+	cb.logger.Warn("Circuit open, failing requests fast", "failures", cb.opts.Failures, "cooldown", cb.opts.Cooldown)
+}
+
+func (cb *circuitBreaker) clock() time.Time {
+	if cb.now != nil {
+		return cb.now()
+	}
+	return time.Now()
+}
+
+// isTimeout reports whether err is a deadline or network timeout rather than another transport error.
+func isTimeout(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// opened returns how many times the circuit has opened.
+func (cb *circuitBreaker) opened() int {
+	if cb == nil {
+		return 0
+	}
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	return cb.opens
+}
@@ -0,0 +1,161 @@
+package canvas
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// fakeClock is a clock the tests move by hand.
+type fakeClock struct{ t time.Time }
+
+func (c *fakeClock) now() time.Time          { return c.t }
+func (c *fakeClock) advance(d time.Duration) { c.t = c.t.Add(d) }
+
+func newTestBreaker(opts CircuitBreakerOptions) (*circuitBreaker, *fakeClock) {
+	clock := &fakeClock{t: time.Date(2025, 1, 6, 9, 0, 0, 0, time.UTC)}
+	cb := &circuitBreaker{opts: opts, logger: slog.New(slog.NewTextHandler(io.Discard, nil)), now: clock.now}
+	return cb, clock
+}
+
+// timeoutError is a net.Error that timed out, like a client timeout from http.Client.
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func response(code int) *http.Response { return &http.Response{StatusCode: code} }
+
+// sendThrough runs one request through the breaker and returns allow's error.
+func sendThrough(cb *circuitBreaker, resp *http.Response, err error) error {
+	probe, allowErr := cb.allow()
+	if allowErr != nil {
+		return allowErr
+	}
+	cb.record(context.Background(), probe, resp, err)
+	return nil
+}
+
+func TestCircuitBreakerClosed(t *testing.T) {
+	cb, _ := newTestBreaker(CircuitBreakerOptions{Failures: 3, Cooldown: time.Minute, Probes: 1})
+	outcomes := []struct {
+		name string
+		resp *http.Response
+		err  error
+	}{
+		{"server error", response(http.StatusBadGateway), nil},
+		{"timeout", nil, timeoutError{}},
+		{"success resets", response(http.StatusOK), nil},
+		{"server error", response(http.StatusInternalServerError), nil},
+		{"deadline", nil, fmt.Errorf("sending request: %w", context.DeadlineExceeded)},
+		{"client error", response(http.StatusNotFound), nil},
+		{"refused connection", nil, errors.New("dial tcp: connection refused")},
+	}
+	for _, o := range outcomes {
+		if err := sendThrough(cb, o.resp, o.err); err != nil {
+			t.Fatalf("%s: %v", o.name, err)
+		}
+	}
+	// the 404 reset the count and the refused connection is not counted
+	if cb.state != circuitClosed || cb.failures != 0 {
+		t.Fatalf("state = %d with %d failures, want closed with 0", cb.state, cb.failures)
+	}
+	for i := 0; i < 2; i++ {
+		sendThrough(cb, response(http.StatusServiceUnavailable), nil)
+	}
+	if cb.state != circuitClosed {
+		t.Fatalf("opened after %d failures, want %d", cb.failures, cb.opts.Failures)
+	}
+	sendThrough(cb, response(http.StatusServiceUnavailable), nil)
+	if cb.state != circuitOpen || cb.opened() != 1 {
+		t.Fatalf("state = %d after %d failures, want open", cb.state, cb.opts.Failures)
+	}
+}
+
+func TestCircuitBreakerOpen(t *testing.T) {
+	cb, clock := newTestBreaker(CircuitBreakerOptions{Failures: 1, Cooldown: time.Minute, Probes: 1})
+	sendThrough(cb, response(http.StatusInternalServerError), nil)
+
+	clock.advance(59 * time.Second)
+	err := sendThrough(cb, response(http.StatusOK), nil)
+	var openErr *CircuitOpenError
+	if !errors.Is(err, ErrCircuitOpen) || !errors.As(err, &openErr) {
+		t.Fatalf("err = %v, want a CircuitOpenError", err)
+	}
+	if want := clock.t.Add(time.Second); !openErr.Until.Equal(want) {
+		t.Errorf("Until = %s, want %s", openErr.Until, want)
+	}
+	if cb.state != circuitOpen {
+		t.Errorf("state = %d before the cool-down ended, want open", cb.state)
+	}
+}
+
+func TestCircuitBreakerHalfOpen(t *testing.T) {
+	cb, clock := newTestBreaker(CircuitBreakerOptions{Failures: 1, Cooldown: time.Minute, Probes: 2})
+	sendThrough(cb, response(http.StatusInternalServerError), nil)
+	clock.advance(time.Minute)
+
+	// a failed probe opens the circuit for another cool-down
+	if err := sendThrough(cb, response(http.StatusBadGateway), nil); err != nil {
+		t.Fatalf("probe: %v", err)
+	}
+	if cb.state != circuitOpen || cb.opened() != 2 {
+		t.Fatalf("state = %d after a failed probe, want open", cb.state)
+	}
+	if err := sendThrough(cb, response(http.StatusOK), nil); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("err = %v right after a failed probe, want ErrCircuitOpen", err)
+	}
+	clock.advance(time.Minute)
+
+	// only Probes requests are let through while half-open
+	first, err := cb.allow()
+	if err != nil || !first {
+		t.Fatalf("first probe: probe = %t, err = %v", first, err)
+	}
+	second, err := cb.allow()
+	if err != nil || !second {
+		t.Fatalf("second probe: probe = %t, err = %v", second, err)
+	}
+	if _, err := cb.allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("third request: err = %v, want ErrCircuitOpen while the probes are in flight", err)
+	}
+
+	cb.record(context.Background(), first, response(http.StatusOK), nil)
+	if cb.state != circuitHalfOpen {
+		t.Fatalf("state = %d after one of two probes succeeded, want half-open", cb.state)
+	}
+	cb.record(context.Background(), second, response(http.StatusOK), nil)
+	if cb.state != circuitClosed {
+		t.Fatalf("state = %d after every probe succeeded, want closed", cb.state)
+	}
+	if err := sendThrough(cb, response(http.StatusOK), nil); err != nil {
+		t.Fatalf("after closing: %v", err)
+	}
+}
+
+func TestCircuitBreakerCancelledProbe(t *testing.T) {
+	cb, clock := newTestBreaker(CircuitBreakerOptions{Failures: 1, Cooldown: time.Minute, Probes: 1})
+	sendThrough(cb, response(http.StatusInternalServerError), nil)
+	clock.advance(time.Minute)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	probe, err := cb.allow()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cb.record(ctx, probe, nil, context.Canceled)
+	if cb.state != circuitHalfOpen || cb.probing != 0 {
+		t.Fatalf("state = %d with %d probes in flight, want half-open with none", cb.state, cb.probing)
+	}
+	// the released probe slot is used by the next request
+	if err := sendThrough(cb, response(http.StatusOK), nil); err != nil || cb.state != circuitClosed {
+		t.Fatalf("state = %d, err = %v, want closed", cb.state, err)
+	}
+}
@@ -0,0 +1,218 @@
+package canvas
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"time"
+)
+
+// Migration types for the packages and copies the tool starts. ListMigrators returns every type the
+// instance supports.
+const (
+	MigrationCourseCopy      = "course_copy_importer"
+	MigrationCommonCartridge = "common_cartridge_importer"
+	MigrationCanvasCartridge = "canvas_cartridge_importer"
+	MigrationZipFile         = "zip_file_importer"
+)
+
+// ContentMigration is a copy or import of content into a course.
+type ContentMigration struct {
+	ID                 int        `json:"id"`
+	MigrationType      string     `json:"migration_type"`
+	MigrationTypeTitle string     `json:"migration_type_title"`
+	WorkflowState      string     `json:"workflow_state"` // pre_processing, pre_processed, running, waiting_for_select, completed, or failed
+	ProgressURL        string     `json:"progress_url"`
+	MigrationIssuesURL string     `json:"migration_issues_url"`
+	UserID             int        `json:"user_id"`
+	StartedAt          *time.Time `json:"started_at"`
+	FinishedAt         *time.Time `json:"finished_at"`
+	Attachment         *struct {
+		URL string `json:"url"`
+	} `json:"attachment"`
+	PreAttachment *uploadTicket `json:"pre_attachment"` // set when the package still has to be uploaded
+}
+
+// Done reports whether the migration has finished, successfully or not.
+func (cm ContentMigration) Done() bool {
+	return cm.WorkflowState == "completed" || cm.Failed()
+}
+
+func (cm ContentMigration) Failed() bool {
+	return cm.WorkflowState == "failed"
+}
+
+// ContentMigrationInput holds the settings of a new migration. For course copies set
+// Settings.SourceCourseID; for packages use ImportPackage, which sets the upload fields.
+type ContentMigrationInput struct {
+	MigrationType    string                     `json:"migration_type"`
+	Settings         ContentMigrationSettings   `json:"settings"`
+	DateShiftOptions *ContentMigrationDateShift `json:"date_shift_options,omitempty"`
+	// SelectiveImport stops the migration in waiting_for_select so the content to copy can be chosen;
+	// without it everything is copied.
+	SelectiveImport bool           `json:"selective_import,omitempty"`
+	PreAttachment   map[string]any `json:"pre_attachment,omitempty"`
+}
+
+type ContentMigrationSettings struct {
+	SourceCourseID   int    `json:"source_course_id,omitempty"`
+	FolderID         int    `json:"folder_id,omitempty"` // where zip_file_importer extracts to
+	OverwriteQuizzes bool   `json:"overwrite_quizzes,omitempty"`
+	QuestionBankName string `json:"question_bank_name,omitempty"`
+}
+
+// ContentMigrationDateShift moves due and availability dates from the source term to the new one, or
+// removes them. Dates are YYYY-MM-DD.
+type ContentMigrationDateShift struct {
+	ShiftDates   bool   `json:"shift_dates,omitempty"`
+	RemoveDates  bool   `json:"remove_dates,omitempty"`
+	OldStartDate string `json:"old_start_date,omitempty"`
+	OldEndDate   string `json:"old_end_date,omitempty"`
+	NewStartDate string `json:"new_start_date,omitempty"`
+	NewEndDate   string `json:"new_end_date,omitempty"`
+}
+
+// MigrationIssue is a problem Canvas found while migrating, such as a missing link or an
+// unsupported question type.
+type MigrationIssue struct {
+	ID              int        `json:"id"`
+	Description     string     `json:"description"`
+	WorkflowState   string     `json:"workflow_state"` // active or resolved
+	IssueType       string     `json:"issue_type"`     // todo, warning, or error
+	ErrorMessage    string     `json:"error_message"`
+	FixIssueHTMLURL string     `json:"fix_issue_html_url"`
+	CreatedAt       *time.Time `json:"created_at"`
+}
+
+// Migrator is a migration type the instance supports.
+type Migrator struct {
+	Type             string   `json:"type"`
+	Name             string   `json:"name"`
+	RequiredSettings []string `json:"required_settings"`
+}
+
+type ContentMigrationsService struct {
+	service
+}
+
+func (api *APIManager) ContentMigrations() *ContentMigrationsService {
+	return &ContentMigrationsService{service{api: api}}
+}
+
+// WithContext returns a copy of the service that sends its requests with ctx.
+func (ms *ContentMigrationsService) WithContext(ctx context.Context) *ContentMigrationsService {
+	return &ContentMigrationsService{service{api: ms.api, ctx: ctx}}
+}
+
+// StartMigration queues a migration into a course. Use StartCourseCopy and ImportPackage for the
+// common cases.
+func (ms *ContentMigrationsService) StartMigration(courseID int, input ContentMigrationInput) (*ContentMigration, error) {
+	var migration ContentMigration
+	if err := ms.sendJSON(http.MethodPost, fmt.Sprintf("courses/%d/content_migrations", courseID), input, &migration); err != nil {
+		return nil, fmt.Errorf("error starting %s migration into course %d: %w", input.MigrationType, courseID, err)
+	}
+	return &migration, nil
+}
+
+// StartCourseCopy copies a course's content into another course, e.g. last term's section into
+// this term's. dateShift can be nil to keep the dates.
+func (ms *ContentMigrationsService) StartCourseCopy(courseID, sourceCourseID int, dateShift *ContentMigrationDateShift) (*ContentMigration, error) {
+	return ms.StartMigration(courseID, ContentMigrationInput{
+		MigrationType:    MigrationCourseCopy,
+		Settings:         ContentMigrationSettings{SourceCourseID: sourceCourseID},
+		DateShiftOptions: dateShift,
+	})
+}
+
+// ImportPackage starts a migration of an uploaded package, such as a Common Cartridge export, and
+// uploads the contents of r as filename. input.MigrationType says what the package is. Canvas
+// starts the import once the upload is confirmed.
+func (ms *ContentMigrationsService) ImportPackage(courseID int, input ContentMigrationInput, r io.Reader, filename string) (*ContentMigration, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("error reading %s: %w", filename, err)
+	}
+	input.PreAttachment = map[string]any{"name": filepath.Base(filename), "size": len(data)}
+	migration, err := ms.StartMigration(courseID, input)
+	if err != nil {
+		return nil, err
+	}
+	if ms.api.DryRun() {
+		return migration, nil
+	}
+	if migration.PreAttachment == nil {
+		return nil, fmt.Errorf("canvas returned no upload URL for migration %d", migration.ID)
+	}
+	if _, err := ms.sendUpload(*migration.PreAttachment, data, filename); err != nil {
+		return nil, fmt.Errorf("error uploading %s for migration %d: %w", filename, migration.ID, err)
+	}
+	return migration, nil
+}
+
+func (ms *ContentMigrationsService) GetMigration(courseID, migrationID int) (*ContentMigration, error) {
+	var migration ContentMigration
+	if err := ms.getJSON(fmt.Sprintf("courses/%d/content_migrations/%d", courseID, migrationID), &migration); err != nil {
+		return nil, fmt.Errorf("error fetching migration %d of course %d: %w", migrationID, courseID, err)
+	}
+	return &migration, nil
+}
+
+// ListMigrations returns the migrations into a course, newest first.
+func (ms *ContentMigrationsService) ListMigrations(courseID int) ([]ContentMigration, error) {
+	var migrations []ContentMigration
+	if err := ms.listJSON(fmt.Sprintf("courses/%d/content_migrations?per_page=100", courseID), &migrations); err != nil {
+		return nil, fmt.Errorf("error listing migrations of course %d: %w", courseID, err)
+	}
+	return migrations, nil
+}
+
+// Progress returns the progress object that tracks a migration, with its percent complete.
+func (ms *ContentMigrationsService) Progress(migration *ContentMigration) (*Progress, error) {
+	if migration.ProgressURL == "" {
+		return nil, fmt.Errorf("migration %d has no progress URL", migration.ID)
+	}
+	var progress Progress
+	if err := ms.getJSON(migration.ProgressURL, &progress); err != nil {
+		return nil, fmt.Errorf("error fetching progress of migration %d: %w", migration.ID, err)
+	}
+	return &progress, nil
+}
+
+// WaitMigration polls a migration every interval until it finishes and returns its final state. A
+// selective import waiting for its content to be chosen is returned as well, since it won't finish
+// on its own. It ends early with the context error if the service's context is cancelled.
+func (ms *ContentMigrationsService) WaitMigration(courseID, migrationID int, interval time.Duration) (*ContentMigration, error) {
+	for {
+		migration, err := ms.GetMigration(courseID, migrationID)
+		if err != nil {
+			return nil, err
+		}
+		if migration.Done() || migration.WorkflowState == "waiting_for_select" {
+			return migration, nil
+		}
+		if err := sleepCtx(ms.context(), interval); err != nil {
+			return migration, err
+		}
+	}
+}
+
+// ListIssues returns the problems Canvas recorded for a migration. Migrations that completed can
+// still have issues to fix by hand.
+func (ms *ContentMigrationsService) ListIssues(courseID, migrationID int) ([]MigrationIssue, error) {
+	var issues []MigrationIssue
+	if err := ms.listJSON(fmt.Sprintf("courses/%d/content_migrations/%d/migration_issues?per_page=100", courseID, migrationID), &issues); err != nil {
+		return nil, fmt.Errorf("error listing issues of migration %d in course %d: %w", migrationID, courseID, err)
+	}
+	return issues, nil
+}
+
+// ListMigrators returns the migration types a course can import.
+func (ms *ContentMigrationsService) ListMigrators(courseID int) ([]Migrator, error) {
+	var migrators []Migrator
+	if err := ms.listJSON(fmt.Sprintf("courses/%d/content_migrations/migrators?per_page=100", courseID), &migrators); err != nil {
+		return nil, fmt.Errorf("error listing migrators of course %d: %w", courseID, err)
+	}
+	return migrators, nil
+}
@@ -0,0 +1,151 @@
+package canvas
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+type Course struct {
+	ID               int        `json:"id"`
+	Name             string     `json:"name"`
+	CourseCode       string     `json:"course_code"`
+	WorkflowState    string     `json:"workflow_state"`
+	AccountID        int        `json:"account_id"`
+	EnrollmentTermID int        `json:"enrollment_term_id"`
+	SISCourseID      string     `json:"sis_course_id"`
+	DefaultView      string     `json:"default_view"`
+	CourseFormat     string     `json:"course_format"`
+	StartAt          *time.Time `json:"start_at"`
+	EndAt            *time.Time `json:"end_at"`
+	TimeZone         string     `json:"time_zone"`
+	IsPublic         bool       `json:"is_public"`
+	Blueprint        bool       `json:"blueprint"`
+	TotalStudents    int        `json:"total_students"`
+	SyllabusBody     string     `json:"syllabus_body"`
+}
+
+type CourseListOptions struct {
+	SearchTerm       string
+	EnrollmentTermID int
+	Published        *bool // nil lists both published and unpublished courses
+	Include          []string
+	PerPage          int
+}
+
+// CourseUpdate holds the course attributes to change. Nil fields are left untouched.
+type CourseUpdate struct {
+	Name         *string    `json:"name,omitempty"`
+	CourseCode   *string    `json:"course_code,omitempty"`
+	StartAt      *time.Time `json:"start_at,omitempty"`
+	EndAt        *time.Time `json:"end_at,omitempty"`
+	DefaultView  *string    `json:"default_view,omitempty"`
+	CourseFormat *string    `json:"course_format,omitempty"`
+	IsPublic     *bool      `json:"is_public,omitempty"`
+	SyllabusBody *string    `json:"syllabus_body,omitempty"`
+	EventAction  string     `json:"event,omitempty"` // "offer" publishes, "claim" unpublishes
+
+	OverrideSISStickiness *bool `json:"-"` // sent as override_sis_stickiness when set
+}
+
+type CoursesService struct {
+	service
+}
+
+func (api *APIManager) Courses() *CoursesService {
+	return &CoursesService{service{api: api}}
+}
+
+// WithContext returns a copy of the service that sends its requests with ctx.
+func (cs *CoursesService) WithContext(ctx context.Context) *CoursesService {
+	return &CoursesService{service{api: cs.api, ctx: ctx}}
+}
+
+// ListAccountCourses returns every course in the account matching opts, following pagination.
+func (cs *CoursesService) ListAccountCourses(accountID int, opts CourseListOptions) ([]Course, error) {
+	query := url.Values{}
+	query.Set("per_page", perPage(opts.PerPage))
+	if opts.SearchTerm != "" {
+		query.Set("search_term", opts.SearchTerm)
+	}
+	if opts.EnrollmentTermID > 0 {
+		query.Set("enrollment_term_id", strconv.Itoa(opts.EnrollmentTermID))
+	}
+	if opts.Published != nil {
+		query.Set("published", strconv.FormatBool(*opts.Published))
+	}
+	for _, inc := range opts.Include {
+		query.Add("include[]", inc)
+	}
+	var courses []Course
+	if err := cs.listJSON(withQuery(fmt.Sprintf("accounts/%d/courses", accountID), query), &courses); err != nil {
+		return nil, fmt.Errorf("error listing courses for account %d: %w", accountID, err)
+	}
+	return courses, nil
+}
+
+func (cs *CoursesService) GetCourse(id int, include ...string) (*Course, error) {
+	query := url.Values{}
+	for _, inc := range include {
+		query.Add("include[]", inc)
+	}
+	var course Course
+	if err := cs.getJSON(withQuery(fmt.Sprintf("courses/%d", id), query), &course); err != nil {
+		return nil, fmt.Errorf("error fetching course %d: %w", id, err)
+	}
+	return &course, nil
+}
+
+func (cs *CoursesService) UpdateCourse(id int, update CourseUpdate) (*Course, error) {
+	body := updateBody("course", update, update.OverrideSISStickiness)
+	var course Course
+	if err := cs.sendJSON(http.MethodPut, fmt.Sprintf("courses/%d", id), body, &course); err != nil {
+		return nil, fmt.Errorf("error updating course %d: %w", id, err)
+	}
+	return &course, nil
+}
+
+// CreateCourse creates an unpublished course in an account with the set fields of input.
+func (cs *CoursesService) CreateCourse(accountID int, input CourseUpdate) (*Course, error) {
+	body := map[string]any{"course": input}
+	var course Course
+	if err := cs.sendJSON(http.MethodPost, fmt.Sprintf("accounts/%d/courses", accountID), body, &course); err != nil {
+		return nil, fmt.Errorf("error creating course in account %d: %w", accountID, err)
+	}
+	return &course, nil
+}
+
+// Events for DeleteCourse.
+const (
+	CourseDelete   = "delete"   // moves it to deleted; an admin can restore it from the account's Restore Courses page
+	CourseConclude = "conclude" // ends it and makes it read-only
+)
+
+// DeleteCourse deletes or concludes a course, depending on event.
+func (cs *CoursesService) DeleteCourse(id int, event string) error {
+	endpoint := fmt.Sprintf("courses/%d?event=%s", id, url.QueryEscape(event))
+	if err := cs.sendJSON(http.MethodDelete, endpoint, nil, nil); err != nil {
+		return fmt.Errorf("error deleting course %d: %w", id, err)
+	}
+	return nil
+}
+
+// GetSyllabus returns a course's syllabus HTML, which is empty when none was written.
+func (cs *CoursesService) GetSyllabus(id int) (string, error) {
+	course, err := cs.GetCourse(id, "syllabus_body")
+	if err != nil {
+		return "", err
+	}
+	return course.SyllabusBody, nil
+}
+
+// UpdateSyllabus replaces a course's syllabus HTML.
+func (cs *CoursesService) UpdateSyllabus(id int, body string) error {
+	if _, err := cs.UpdateCourse(id, CourseUpdate{SyllabusBody: &body}); err != nil {
+		return err
+	}
+	return nil
+}
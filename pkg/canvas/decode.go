@@ -0,0 +1,100 @@
+package canvas
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+)
+
+// GetJSON fetches an object and decodes it into a T. A status other than 200 OK returns the
+// APIError, so callers can check for ErrNotFound and the like.
+func GetJSON[T any](api *APIManager, endpoint string, opts ...RequestOption) (T, error) {
+	return GetJSONCtx[T](context.Background(), api, endpoint, opts...)
+}
+
+func GetJSONCtx[T any](ctx context.Context, api *APIManager, endpoint string, opts ...RequestOption) (T, error) {
+	var v T
+	err := getInto(ctx, api, endpoint, &v, opts...)
+	return v, err
+}
+
+// GetAllPagesJSON fetches every page of a list endpoint and decodes the items into Ts as the pages
+// arrive. An empty list returns an empty slice, not nil.
+func GetAllPagesJSON[T any](api *APIManager, endpoint string, opts ...RequestOption) ([]T, error) {
+	return GetAllPagesJSONCtx[T](context.Background(), api, endpoint, opts...)
+}
+
+func GetAllPagesJSONCtx[T any](ctx context.Context, api *APIManager, endpoint string, opts ...RequestOption) ([]T, error) {
+	items := []T{}
+	err := eachItem(ctx, api, endpoint, func(raw json.RawMessage) error {
+		var item T
+		if err := json.Unmarshal(raw, &item); err != nil {
+			return err
+		}
+		items = append(items, item)
+		return nil
+	}, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// eachItem fetches every page of a list endpoint and calls decode with each item as the pages
+// arrive. It stops at the first error, from a page or from decode.
+func eachItem(ctx context.Context, api *APIManager, endpoint string, decode func(raw json.RawMessage) error, opts ...RequestOption) error {
+	streamCtx, cancel := context.WithCancel(ctx)
+	defer cancel() // stops the page fetching when an item doesn't decode
+	for page := range api.StreamPagesCtx(streamCtx, endpoint, opts...) {
+		if page.Err != nil {
+			return page.Err
+		}
+		for _, raw := range page.Items {
+			if err := decode(raw); err != nil {
+				return fmt.Errorf("error decoding %s page %d: %w", endpoint, page.Number, err)
+			}
+		}
+	}
+	return ctx.Err()
+}
+
+// listInto is GetAllPagesJSON for a v that points to a slice of any type, such as the argument of
+// service.listJSON. An empty list sets an empty slice, not nil.
+func listInto(ctx context.Context, api *APIManager, endpoint string, v any, opts ...RequestOption) error {
+	list := reflect.ValueOf(v)
+	if list.Kind() != reflect.Pointer || list.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf("error listing %s: %T is not a pointer to a slice", endpoint, v)
+	}
+	items := reflect.MakeSlice(list.Elem().Type(), 0, 0)
+	err := eachItem(ctx, api, endpoint, func(raw json.RawMessage) error {
+		item := reflect.New(items.Type().Elem())
+		if err := json.Unmarshal(raw, item.Interface()); err != nil {
+			return err
+		}
+		items = reflect.Append(items, item.Elem())
+		return nil
+	}, opts...)
+	if err != nil {
+		return err
+	}
+	list.Elem().Set(items)
+	return nil
+}
+
+// getInto is GetJSON for a v that is already allocated, such as the argument of service.getJSON.
+func getInto(ctx context.Context, api *APIManager, endpoint string, v any, opts ...RequestOption) error {
+	resp, err := api.GetCtx(ctx, endpoint, opts...)
+	if err != nil {
+		return fmt.Errorf("error fetching %s: %w", endpoint, err)
+	}
+	defer resp.Body.Close() // Ensure the response body is closed after reading
+	if resp.StatusCode != http.StatusOK {
+		return NewAPIError(resp)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("error decoding %s: %w", endpoint, err)
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+package canvas
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Deprecation is an endpoint that Canvas marked as deprecated or due to be removed, found in the
+// Deprecation, Sunset, Link, or Warning headers of its responses.
+type Deprecation struct {
+	Method     string     `json:"method"`
+	Endpoint   string     `json:"endpoint"`             // with IDs folded, e.g. courses/:id/modules
+	Deprecated string     `json:"deprecated,omitempty"` // the Deprecation header: a date, or "true"
+	Sunset     *time.Time `json:"sunset,omitempty"`     // when the endpoint stops working
+	Link       string     `json:"link,omitempty"`       // documentation of the change
+	Message    string     `json:"message,omitempty"`    // from a Warning header
+	Requests   int        `json:"requests"`
+	FirstSeen  time.Time  `json:"first_seen"`
+}
+
+// deprecationTracker collects the deprecations seen during a run, one per endpoint.
+type deprecationTracker struct {
+	mu   sync.Mutex
+	seen map[string]*Deprecation
+}
+
+// Deprecations returns the deprecated endpoints this manager has called, soonest sunset first.
+func (api *APIManager) Deprecations() []Deprecation {
+	dt := &api.deprecations
+	dt.mu.Lock()
+	defer dt.mu.Unlock()
+	list := make([]Deprecation, 0, len(dt.seen))
+	for _, d := range dt.seen {
+		list = append(list, *d)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		a, b := list[i].Sunset, list[j].Sunset
+		switch {
+		case a != nil && b != nil && !a.Equal(*b):
+			return a.Before(*b)
+		case (a == nil) != (b == nil):
+			return a != nil
+		}
+		return list[i].Method+" "+list[i].Endpoint < list[j].Method+" "+list[j].Endpoint
+	})
+	return list
+}
+
+// recordDeprecation notes a response's deprecation headers. The first response from each deprecated
+// endpoint is logged as a warning.
+func (api *APIManager) recordDeprecation(method, endpoint string, resp *http.Response) {
+	d, ok := parseDeprecation(resp.Header)
+	if !ok {
+		return
+	}
+	dt := &api.deprecations
+	dt.mu.Lock()
+	defer dt.mu.Unlock()
+	d.Method, d.Endpoint = method, endpointPattern(endpoint)
+	key := d.Method + " " + d.Endpoint
+	if prev, ok := dt.seen[key]; ok {
+		prev.Requests++
+		return
+	}
+	if dt.seen == nil {
+		dt.seen = make(map[string]*Deprecation)
+	}
+	d.Requests, d.FirstSeen = 1, time.Now()
+	dt.seen[key] = &d
+	args := []any{"method", d.Method, "endpoint", d.Endpoint}
+	if d.Sunset != nil {
+		args = append(args, "sunset", d.Sunset.Format(time.DateOnly))
+	}
+	if d.Message != "" {
+		args = append(args, "message", d.Message)
+	}
+	api.logger.Warn("Canvas marked this endpoint as deprecated", args...)
+}
+
+// parseDeprecation reads the deprecation headers: Deprecation and Sunset (RFC 9745 and RFC 8594),
+// Link with rel="deprecation" or rel="sunset", and Warning 299 messages that mention a deprecation.
+func parseDeprecation(h http.Header) (Deprecation, bool) {
+	var d Deprecation
+	if v := h.Get("Deprecation"); v != "" {
+		d.Deprecated = v
+		if unix, err := strconv.ParseInt(strings.TrimPrefix(v, "@"), 10, 64); err == nil && strings.HasPrefix(v, "@") {
+			d.Deprecated = time.Unix(unix, 0).UTC().Format(time.DateOnly)
+		}
+	}
+	if v := h.Get("Sunset"); v != "" {
+		if t, err := http.ParseTime(v); err == nil {
+			d.Sunset = &t
+		}
+	}
+	for _, link := range h.Values("Link") {
+		for _, part := range strings.Split(link, ",") {
+			target, params, ok := strings.Cut(part, ";")
+			if !ok {
+				continue
+			}
+			if strings.Contains(params, `rel="deprecation"`) || strings.Contains(params, `rel="sunset"`) {
+				d.Link = strings.Trim(strings.TrimSpace(target), "<>")
+			}
+		}
+	}
+	for _, warning := range h.Values("Warning") {
+		if strings.HasPrefix(warning, "299") && strings.Contains(strings.ToLower(warning), "deprecat") {
+			if _, text, ok := strings.Cut(warning, `"`); ok {
+				warning, _, _ = strings.Cut(text, `"`)
+			}
+			d.Message = warning
+		}
+	}
+	return d, d.Deprecated != "" || d.Sunset != nil || d.Link != "" || d.Message != ""
+}
@@ -0,0 +1,270 @@
+package canvas
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// DiscussionTopic is a course discussion or announcement. Announcements are discussion topics that
+// Canvas lists separately.
+type DiscussionTopic struct {
+	ID                      int        `json:"id"`
+	Title                   string     `json:"title"`
+	Message                 string     `json:"message"` // HTML
+	HTMLURL                 string     `json:"html_url"`
+	ContextCode             string     `json:"context_code"` // e.g. course_123; only set when listing announcements
+	PostedAt                *time.Time `json:"posted_at"`
+	DelayedPostAt           *time.Time `json:"delayed_post_at"` // announcements post themselves at this time
+	LastReplyAt             *time.Time `json:"last_reply_at"`
+	LockAt                  *time.Time `json:"lock_at"`
+	DiscussionType          string     `json:"discussion_type"` // side_comment (not threaded) or threaded
+	Published               bool       `json:"published"`
+	Locked                  bool       `json:"locked"`
+	Pinned                  bool       `json:"pinned"`
+	RequireInitialPost      bool       `json:"require_initial_post"`
+	AssignmentID            *int       `json:"assignment_id"` // set for graded discussions
+	DiscussionSubentryCount int        `json:"discussion_subentry_count"`
+	ReadState               string     `json:"read_state"` // read or unread, for the token's user
+	UnreadCount             int        `json:"unread_count"`
+	UserName                string     `json:"user_name"`
+}
+
+// Delayed reports whether the topic is waiting to be posted.
+func (t DiscussionTopic) Delayed() bool {
+	return t.DelayedPostAt != nil && t.DelayedPostAt.After(time.Now())
+}
+
+// DiscussionTopicInput holds the topic attributes to set on create or update. Nil fields are left out.
+type DiscussionTopicInput struct {
+	Title              *string    `json:"title,omitempty"`
+	Message            *string    `json:"message,omitempty"`
+	DiscussionType     *string    `json:"discussion_type,omitempty"`
+	Published          *bool      `json:"published,omitempty"`
+	DelayedPostAt      *time.Time `json:"delayed_post_at,omitempty"`
+	LockAt             *time.Time `json:"lock_at,omitempty"`
+	IsAnnouncement     *bool      `json:"is_announcement,omitempty"`
+	Pinned             *bool      `json:"pinned,omitempty"`
+	RequireInitialPost *bool      `json:"require_initial_post,omitempty"`
+	SpecificSections   *string    `json:"specific_sections,omitempty"` // comma separated section IDs, or all
+}
+
+// DiscussionListOptions narrows a course's topic list.
+type DiscussionListOptions struct {
+	OnlyAnnouncements bool
+	SearchTerm        string
+	Scope             string // locked, unlocked, pinned, or unpinned
+	OrderBy           string // position, recent_activity, or title
+}
+
+// AnnouncementListOptions selects announcements across courses.
+type AnnouncementListOptions struct {
+	CourseIDs  []int
+	StartDate  *time.Time // Canvas defaults to 14 days ago
+	EndDate    *time.Time // Canvas defaults to 28 days from the start date
+	ActiveOnly bool       // skip delayed and deleted announcements
+	LatestOnly bool       // only the newest announcement of each course
+}
+
+// DiscussionEntry is a post in a discussion topic, or a reply to one.
+type DiscussionEntry struct {
+	ID        int               `json:"id"`
+	UserID    int               `json:"user_id"`
+	UserName  string            `json:"user_name"`
+	ParentID  *int              `json:"parent_id"` // set on replies
+	Message   string            `json:"message"`   // HTML
+	ReadState string            `json:"read_state"`
+	CreatedAt *time.Time        `json:"created_at"`
+	UpdatedAt *time.Time        `json:"updated_at"`
+	Deleted   bool              `json:"deleted"`
+	Replies   []DiscussionEntry `json:"replies"` // only filled by GetTopicView
+}
+
+// DiscussionView is a whole discussion: every entry with its replies nested, and the people who
+// posted. Entries in the view carry no UserName; look it up in Participants.
+type DiscussionView struct {
+	Participants []struct {
+		ID          int    `json:"id"`
+		DisplayName string `json:"display_name"`
+	} `json:"participants"`
+	View []DiscussionEntry `json:"view"`
+}
+
+type DiscussionsService struct {
+	service
+}
+
+func (api *APIManager) Discussions() *DiscussionsService {
+	return &DiscussionsService{service{api: api}}
+}
+
+// WithContext returns a copy of the service that sends its requests with ctx.
+func (ds *DiscussionsService) WithContext(ctx context.Context) *DiscussionsService {
+	return &DiscussionsService{service{api: ds.api, ctx: ctx}}
+}
+
+// ListTopics returns a course's discussion topics, or its announcements with OnlyAnnouncements.
+// Delayed and unpublished topics are included when the token can see them.
+func (ds *DiscussionsService) ListTopics(courseID int, opts DiscussionListOptions) ([]DiscussionTopic, error) {
+	query := url.Values{}
+	query.Set("per_page", perPage(0))
+	if opts.OnlyAnnouncements {
+		query.Set("only_announcements", "true")
+	}
+	if opts.SearchTerm != "" {
+		query.Set("search_term", opts.SearchTerm)
+	}
+	if opts.Scope != "" {
+		query.Set("scope", opts.Scope)
+	}
+	if opts.OrderBy != "" {
+		query.Set("order_by", opts.OrderBy)
+	}
+	var topics []DiscussionTopic
+	if err := ds.listJSON(withQuery(fmt.Sprintf("courses/%d/discussion_topics", courseID), query), &topics); err != nil {
+		return nil, fmt.Errorf("error listing discussion topics for course %d: %w", courseID, err)
+	}
+	return topics, nil
+}
+
+// ListCourseAnnouncements returns every announcement in a course, including delayed ones.
+func (ds *DiscussionsService) ListCourseAnnouncements(courseID int) ([]DiscussionTopic, error) {
+	return ds.ListTopics(courseID, DiscussionListOptions{OnlyAnnouncements: true})
+}
+
+// ListAnnouncements returns the announcements of several courses posted between the start and end dates.
+func (ds *DiscussionsService) ListAnnouncements(opts AnnouncementListOptions) ([]DiscussionTopic, error) {
+	if len(opts.CourseIDs) == 0 {
+		return nil, fmt.Errorf("error listing announcements: no courses given")
+	}
+	query := url.Values{}
+	query.Set("per_page", perPage(0))
+	for _, id := range opts.CourseIDs {
+		query.Add("context_codes[]", fmt.Sprintf("course_%d", id))
+	}
+	if opts.StartDate != nil {
+		query.Set("start_date", opts.StartDate.Format(time.RFC3339))
+	}
+	if opts.EndDate != nil {
+		query.Set("end_date", opts.EndDate.Format(time.RFC3339))
+	}
+	if opts.ActiveOnly {
+		query.Set("active_only", "true")
+	}
+	if opts.LatestOnly {
+		query.Set("latest_only", "true")
+	}
+	var topics []DiscussionTopic
+	if err := ds.listJSON(withQuery("announcements", query), &topics); err != nil {
+		return nil, fmt.Errorf("error listing announcements: %w", err)
+	}
+	return topics, nil
+}
+
+func (ds *DiscussionsService) GetTopic(courseID, topicID int) (*DiscussionTopic, error) {
+	var topic DiscussionTopic
+	if err := ds.getJSON(fmt.Sprintf("courses/%d/discussion_topics/%d", courseID, topicID), &topic); err != nil {
+		return nil, fmt.Errorf("error fetching discussion topic %d in course %d: %w", topicID, courseID, err)
+	}
+	return &topic, nil
+}
+
+func (ds *DiscussionsService) CreateTopic(courseID int, input DiscussionTopicInput) (*DiscussionTopic, error) {
+	var topic DiscussionTopic
+	if err := ds.sendJSON(http.MethodPost, fmt.Sprintf("courses/%d/discussion_topics", courseID), input, &topic); err != nil {
+		return nil, fmt.Errorf("error creating discussion topic in course %d: %w", courseID, err)
+	}
+	return &topic, nil
+}
+
+// CreateAnnouncement posts an announcement, or schedules it when DelayedPostAt is set.
+func (ds *DiscussionsService) CreateAnnouncement(courseID int, input DiscussionTopicInput) (*DiscussionTopic, error) {
+	announcement := true
+	input.IsAnnouncement = &announcement
+	var topic DiscussionTopic
+	if err := ds.sendJSON(http.MethodPost, fmt.Sprintf("courses/%d/discussion_topics", courseID), input, &topic); err != nil {
+		return nil, fmt.Errorf("error creating announcement in course %d: %w", courseID, err)
+	}
+	return &topic, nil
+}
+
+func (ds *DiscussionsService) UpdateTopic(courseID, topicID int, input DiscussionTopicInput) (*DiscussionTopic, error) {
+	var topic DiscussionTopic
+	if err := ds.sendJSON(http.MethodPut, fmt.Sprintf("courses/%d/discussion_topics/%d", courseID, topicID), input, &topic); err != nil {
+		return nil, fmt.Errorf("error updating discussion topic %d in course %d: %w", topicID, courseID, err)
+	}
+	return &topic, nil
+}
+
+// DeleteTopic deletes a discussion topic or announcement with its entries.
+func (ds *DiscussionsService) DeleteTopic(courseID, topicID int) error {
+	if err := ds.sendJSON(http.MethodDelete, fmt.Sprintf("courses/%d/discussion_topics/%d", courseID, topicID), nil, nil); err != nil {
+		return fmt.Errorf("error deleting discussion topic %d in course %d: %w", topicID, courseID, err)
+	}
+	return nil
+}
+
+// ListEntries returns the top-level entries of a topic, newest first. Replies are listed with ListReplies.
+func (ds *DiscussionsService) ListEntries(courseID, topicID int) ([]DiscussionEntry, error) {
+	var entries []DiscussionEntry
+	if err := ds.listJSON(fmt.Sprintf("courses/%d/discussion_topics/%d/entries?per_page=100", courseID, topicID), &entries); err != nil {
+		return nil, fmt.Errorf("error listing entries of discussion topic %d in course %d: %w", topicID, courseID, err)
+	}
+	return entries, nil
+}
+
+// GetTopicView returns a topic's full entry tree in one request, which is much cheaper than listing
+// the replies of every entry. Canvas builds the view in the background and answers 503 until it is ready.
+func (ds *DiscussionsService) GetTopicView(courseID, topicID int) (*DiscussionView, error) {
+	var view DiscussionView
+	if err := ds.getJSON(fmt.Sprintf("courses/%d/discussion_topics/%d/view", courseID, topicID), &view); err != nil {
+		return nil, fmt.Errorf("error fetching view of discussion topic %d in course %d: %w", topicID, courseID, err)
+	}
+	return &view, nil
+}
+
+func (ds *DiscussionsService) ListReplies(courseID, topicID, entryID int) ([]DiscussionEntry, error) {
+	var entries []DiscussionEntry
+	if err := ds.listJSON(fmt.Sprintf("courses/%d/discussion_topics/%d/entries/%d/replies?per_page=100", courseID, topicID, entryID), &entries); err != nil {
+		return nil, fmt.Errorf("error listing replies to entry %d of discussion topic %d in course %d: %w", entryID, topicID, courseID, err)
+	}
+	return entries, nil
+}
+
+// PostEntry adds a top-level entry to a topic as the token's user.
+func (ds *DiscussionsService) PostEntry(courseID, topicID int, message string) (*DiscussionEntry, error) {
+	var entry DiscussionEntry
+	body := map[string]string{"message": message}
+	if err := ds.sendJSON(http.MethodPost, fmt.Sprintf("courses/%d/discussion_topics/%d/entries", courseID, topicID), body, &entry); err != nil {
+		return nil, fmt.Errorf("error posting to discussion topic %d in course %d: %w", topicID, courseID, err)
+	}
+	return &entry, nil
+}
+
+func (ds *DiscussionsService) DeleteEntry(courseID, topicID, entryID int) error {
+	if err := ds.sendJSON(http.MethodDelete, fmt.Sprintf("courses/%d/discussion_topics/%d/entries/%d", courseID, topicID, entryID), nil, nil); err != nil {
+		return fmt.Errorf("error deleting entry %d of discussion topic %d in course %d: %w", entryID, topicID, courseID, err)
+	}
+	return nil
+}
+
+// MarkTopicRead marks a topic read for the token's user. With allEntries its entries are marked read too.
+func (ds *DiscussionsService) MarkTopicRead(courseID, topicID int, allEntries bool) error {
+	endpoint := fmt.Sprintf("courses/%d/discussion_topics/%d/read", courseID, topicID)
+	if allEntries {
+		endpoint += "_all"
+	}
+	if err := ds.sendJSON(http.MethodPut, endpoint, nil, nil); err != nil {
+		return fmt.Errorf("error marking discussion topic %d in course %d read: %w", topicID, courseID, err)
+	}
+	return nil
+}
+
+func (ds *DiscussionsService) MarkEntryRead(courseID, topicID, entryID int) error {
+	if err := ds.sendJSON(http.MethodPut, fmt.Sprintf("courses/%d/discussion_topics/%d/entries/%d/read", courseID, topicID, entryID), nil, nil); err != nil {
+		return fmt.Errorf("error marking entry %d of discussion topic %d in course %d read: %w", entryID, topicID, courseID, err)
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+package canvas
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// ErrInvalidEndpoint is returned, wrapped, for an endpoint that can't be sent, such as one with
+// a "#" or a ".." segment. Nothing is sent for it.
+var ErrInvalidEndpoint = errors.New("invalid endpoint")
+
+// buildURL joins the base URL and an endpoint relative to it. Slashes are joined once whatever
+// the base URL ends with or the endpoint starts with, and characters that aren't valid in a URL,
+// such as the spaces of a search term, are escaped. Escapes already in the endpoint, like the %2F
+// of an SIS ID passed through url.PathEscape, are kept. Absolute URLs, such as next page links,
+// are only checked.
+func buildURL(base, endpoint string) (string, error) {
+	invalid := func(reason string) error {
+		return fmt.Errorf("%w %q: %s", ErrInvalidEndpoint, endpoint, reason)
+	}
+	if strings.HasPrefix(endpoint, "https://") || strings.HasPrefix(endpoint, "http://") {
+		u, err := url.Parse(endpoint)
+		if err != nil {
+			return "", invalid(err.Error())
+		}
+		if u.Host == "" {
+			return "", invalid("no host")
+		}
+		return endpoint, nil
+	}
+	if strings.Contains(endpoint, "#") {
+		return "", invalid("fragments are not sent to the server")
+	}
+	rawPath, rawQuery, _ := strings.Cut(endpoint, "?")
+	ref, err := url.Parse(strings.TrimLeft(rawPath, "/"))
+	if err != nil {
+		return "", invalid(err.Error())
+	}
+	if ref.Scheme != "" || ref.Host != "" || ref.Path == "" {
+		return "", invalid("not a path relative to the API base URL")
+	}
+	for _, segment := range strings.Split(ref.Path, "/") {
+		if segment == "." || segment == ".." {
+			return "", invalid("relative path segments")
+		}
+	}
+	query, err := encodeQuery(rawQuery)
+	if err != nil {
+		return "", invalid(err.Error())
+	}
+
+	u, err := url.Parse(base)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return "", fmt.Errorf("invalid API base URL %q", base)
+	}
+	joined := strings.TrimSuffix(u.EscapedPath(), "/") + "/" + ref.EscapedPath()
+	for strings.Contains(joined, "//") {
+		joined = strings.ReplaceAll(joined, "//", "/")
+	}
+	if u.Path, err = url.PathUnescape(joined); err != nil {
+		return "", invalid(err.Error())
+	}
+	u.RawPath = joined
+	u.RawQuery = query
+	return u.String(), nil
+}
+
+// encodeQuery escapes each parameter of a raw query, keeping their order. Parameters that are
+// already escaped come out the same, and a "%" that doesn't start an escape, as in
+// search_term=100%, is taken literally.
+func encodeQuery(raw string) (string, error) {
+	if raw == "" {
+		return "", nil
+	}
+	var params []string
+	for _, param := range strings.Split(raw, "&") {
+		if param == "" {
+			continue
+		}
+		key, value, hasValue := strings.Cut(param, "=")
+		key = queryUnescape(key)
+		if key == "" {
+			return "", fmt.Errorf("parameter %q has no name", param)
+		}
+		if !hasValue {
+			params = append(params, url.QueryEscape(key))
+			continue
+		}
+		params = append(params, url.QueryEscape(key)+"="+url.QueryEscape(queryUnescape(value)))
+	}
+	return strings.Join(params, "&"), nil
+}
+
+// queryUnescape is url.QueryUnescape that keeps a "%" not followed by two hex digits instead of
+// failing on it.
+func queryUnescape(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '%' && i+2 < len(s) && isHex(s[i+1]) && isHex(s[i+2]):
+			b.WriteByte(unhex(s[i+1])<<4 | unhex(s[i+2]))
+			i += 2
+		case c == '+':
+			b.WriteByte(' ')
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+func isHex(c byte) bool {
+	return '0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F'
+}
+
+func unhex(c byte) byte {
+	switch {
+	case c >= 'a':
+		return c - 'a' + 10
+	case c >= 'A':
+		return c - 'A' + 10
+	}
+	return c - '0'
+}
@@ -0,0 +1,50 @@
+package canvas
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestBuildURL(t *testing.T) {
+	const base = "https://school.instructure.com/api/v1/"
+	tests := []struct {
+		name, endpoint, want string
+	}{
+		{"path", "courses/1", base + "courses/1"},
+		{"leading slash", "/courses/1", base + "courses/1"},
+		{"space in value", "accounts/1/courses?search_term=Biology 101", base + "accounts/1/courses?search_term=Biology+101"},
+		{"plus is a space", "accounts/1/courses?search_term=Biology+101", base + "accounts/1/courses?search_term=Biology+101"},
+		{"escaped plus", "accounts/1/courses?search_term=C%2B%2B", base + "accounts/1/courses?search_term=C%2B%2B"},
+		{"literal percent", "accounts/1/courses?search_term=100%", base + "accounts/1/courses?search_term=100%25"},
+		{"invalid escape", "accounts/1/courses?search_term=%zz", base + "accounts/1/courses?search_term=%25zz"},
+		{"escaped ampersand", "accounts/1/courses?search_term=R%26D", base + "accounts/1/courses?search_term=R%26D"},
+		{"repeated keys", "courses/1?include[]=term&include[]=teachers", base + "courses/1?include%5B%5D=term&include%5B%5D=teachers"},
+		{"key without value", "courses/1?blueprint", base + "courses/1?blueprint"},
+		{"escaped path", "courses/sis_course_id:2025%2FBIO-101", base + "courses/sis_course_id:2025%2FBIO-101"},
+		{"absolute", "https://school.instructure.com/api/v1/courses?page=2&per_page=10", "https://school.instructure.com/api/v1/courses?page=2&per_page=10"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := buildURL(base, tt.endpoint)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("buildURL(%q) = %q, want %q", tt.endpoint, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildURLRejects(t *testing.T) {
+	for _, endpoint := range []string{
+		"courses/1#modules",
+		"courses/../accounts/1",
+		"https://",
+		"courses/1?=value",
+	} {
+		if _, err := buildURL("https://school.instructure.com/api/v1/", endpoint); !errors.Is(err, ErrInvalidEndpoint) {
+			t.Errorf("buildURL(%q) gave %v, want ErrInvalidEndpoint", endpoint, err)
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package canvas
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+type Enrollment struct {
+	ID              int    `json:"id"`
+	CourseID        int    `json:"course_id"`
+	SectionID       int    `json:"course_section_id"`
+	UserID          int    `json:"user_id"`
+	Type            string `json:"type"` // StudentEnrollment, TeacherEnrollment, TaEnrollment, DesignerEnrollment, or ObserverEnrollment
+	Role            string `json:"role"` // the custom role name, or the type for the base roles
+	RoleID          int    `json:"role_id"`
+	EnrollmentState string `json:"enrollment_state"`
+	User            struct {
+		ID           int    `json:"id"`
+		Name         string `json:"name"`
+		SortableName string `json:"sortable_name"`
+		SISUserID    string `json:"sis_user_id"`
+		LoginID      string `json:"login_id"`
+	} `json:"user"`
+	Grades *EnrollmentGrades `json:"grades"` // student enrollments only
+}
+
+// EnrollmentGrades are a student's course totals. The unposted scores count grades students can't see yet.
+type EnrollmentGrades struct {
+	CurrentScore         *float64 `json:"current_score"` // ungraded work left out
+	FinalScore           *float64 `json:"final_score"`   // ungraded work counted as zero
+	UnpostedCurrentScore *float64 `json:"unposted_current_score"`
+	UnpostedFinalScore   *float64 `json:"unposted_final_score"`
+	CurrentGrade         string   `json:"current_grade"` // letter grades, when the course has a grading scheme
+	FinalGrade           string   `json:"final_grade"`
+	UnpostedCurrentGrade string   `json:"unposted_current_grade"`
+	UnpostedFinalGrade   string   `json:"unposted_final_grade"`
+}
+
+type EnrollmentListOptions struct {
+	Types  []string // e.g. TeacherEnrollment; all types when empty
+	States []string // e.g. active, invited; Canvas defaults to active and invited
+}
+
+func (opts EnrollmentListOptions) query() url.Values {
+	query := url.Values{}
+	query.Set("per_page", perPage(0))
+	for _, t := range opts.Types {
+		query.Add("type[]", t)
+	}
+	for _, s := range opts.States {
+		query.Add("state[]", s)
+	}
+	return query
+}
+
+type EnrollmentsService struct {
+	service
+}
+
+func (api *APIManager) Enrollments() *EnrollmentsService {
+	return &EnrollmentsService{service{api: api}}
+}
+
+// WithContext returns a copy of the service that sends its requests with ctx.
+func (es *EnrollmentsService) WithContext(ctx context.Context) *EnrollmentsService {
+	return &EnrollmentsService{service{api: es.api, ctx: ctx}}
+}
+
+// ListCourseEnrollments returns the enrollments in a course matching opts, following pagination.
+func (es *EnrollmentsService) ListCourseEnrollments(courseID int, opts EnrollmentListOptions) ([]Enrollment, error) {
+	query := opts.query()
+	var enrollments []Enrollment
+	if err := es.listJSON(withQuery(fmt.Sprintf("courses/%d/enrollments", courseID), query), &enrollments); err != nil {
+		return nil, fmt.Errorf("error listing enrollments for course %d: %w", courseID, err)
+	}
+	return enrollments, nil
+}
+
+// ListUserEnrollments returns a user's enrollments matching opts. userID can be a Canvas ID or
+// any ID Canvas accepts in the path, e.g. "sis_user_id:ABC".
+func (es *EnrollmentsService) ListUserEnrollments(userID string, opts EnrollmentListOptions) ([]Enrollment, error) {
+	query := opts.query()
+	var enrollments []Enrollment
+	if err := es.listJSON(withQuery("users/"+userID+"/enrollments", query), &enrollments); err != nil {
+		return nil, fmt.Errorf("error listing enrollments for user %s: %w", userID, err)
+	}
+	return enrollments, nil
+}
+
+// GetEnrollment fetches one enrollment by ID from an account, whatever its course.
+func (es *EnrollmentsService) GetEnrollment(accountID, id int) (*Enrollment, error) {
+	var enrollment Enrollment
+	if err := es.getJSON(fmt.Sprintf("accounts/%d/enrollments/%d", accountID, id), &enrollment); err != nil {
+		return nil, fmt.Errorf("error fetching enrollment %d: %w", id, err)
+	}
+	return &enrollment, nil
+}
+
+// How DeleteEnrollment ends an enrollment.
+const (
+	EnrollmentDelete     = "delete"     // removes it; restore by enrolling the user again
+	EnrollmentConclude   = "conclude"   // keeps it as completed, read-only
+	EnrollmentDeactivate = "deactivate" // makes it inactive; ReactivateEnrollment undoes it
+)
+
+// DeleteEnrollment ends an enrollment with task, one of EnrollmentDelete, EnrollmentConclude, or
+// EnrollmentDeactivate, and returns it as it was changed.
+func (es *EnrollmentsService) DeleteEnrollment(courseID, id int, task string) (*Enrollment, error) {
+	var enrollment Enrollment
+	endpoint := fmt.Sprintf("courses/%d/enrollments/%d?task=%s", courseID, id, url.QueryEscape(task))
+	if err := es.sendJSON(http.MethodDelete, endpoint, nil, &enrollment); err != nil {
+		return nil, fmt.Errorf("error removing enrollment %d from course %d: %w", id, courseID, err)
+	}
+	return &enrollment, nil
+}
+
+// ReactivateEnrollment makes a deactivated enrollment active again.
+func (es *EnrollmentsService) ReactivateEnrollment(courseID, id int) (*Enrollment, error) {
+	var enrollment Enrollment
+	if err := es.sendJSON(http.MethodPut, fmt.Sprintf("courses/%d/enrollments/%d/reactivate", courseID, id), nil, &enrollment); err != nil {
+		return nil, fmt.Errorf("error reactivating enrollment %d in course %d: %w", id, courseID, err)
+	}
+	return &enrollment, nil
+}
+
+// EnrollmentInput describes a user to enroll in a section.
+type EnrollmentInput struct {
+	UserID          int    `json:"user_id"`
+	Type            string `json:"type"`                       // e.g. StudentEnrollment
+	RoleID          int    `json:"role_id,omitempty"`          // for custom roles
+	EnrollmentState string `json:"enrollment_state,omitempty"` // active skips the invitation
+	Notify          bool   `json:"notify"`
+}
+
+// EnrollInSection enrolls a user in a section.
+func (es *EnrollmentsService) EnrollInSection(sectionID int, input EnrollmentInput) (*Enrollment, error) {
+	var enrollment Enrollment
+	body := map[string]any{"enrollment": input}
+	if err := es.sendJSON(http.MethodPost, fmt.Sprintf("sections/%d/enrollments", sectionID), body, &enrollment); err != nil {
+		return nil, fmt.Errorf("error enrolling user %d in section %d: %w", input.UserID, sectionID, err)
+	}
+	return &enrollment, nil
+}
@@ -0,0 +1,118 @@
+package canvas
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+var (
+	ErrUnauthorized = errors.New("canvas: unauthorized")
+	ErrForbidden    = errors.New("canvas: forbidden")
+	ErrNotFound     = errors.New("canvas: not found")
+	ErrRateLimited  = errors.New("canvas: rate limited")
+)
+
+type APIErrorDetail struct {
+	Field   string `json:"field,omitempty"`
+	Message string `json:"message"`
+}
+
+// APIError is returned when Canvas answers with a non-success status.
+// Use errors.Is with ErrUnauthorized, ErrForbidden, ErrNotFound, or ErrRateLimited to check the kind,
+// or errors.As to read the details.
+type APIError struct {
+	StatusCode int
+	Method     string
+	Endpoint   string
+	RequestID  string
+	Message    string
+	Errors     []APIErrorDetail
+	Body       string // raw body when it could not be decoded as a Canvas error
+}
+
+// NewAPIError builds an APIError from a response, reading (but not closing) its body.
+func NewAPIError(resp *http.Response) *APIError {
+	apiErr := &APIError{
+		StatusCode: resp.StatusCode,
+		RequestID:  resp.Header.Get("X-Request-Context-Id"),
+	}
+	if resp.Request != nil {
+		apiErr.Method = resp.Request.Method
+		apiErr.Endpoint = resp.Request.URL.Path
+	}
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	if !apiErr.decode(data) {
+		apiErr.Body = strings.TrimSpace(string(data))
+	}
+	return apiErr
+}
+
+// decode reads the Canvas error formats: {"errors":[{"message":..}]}, {"errors":{"field":[{"message":..}]}},
+// and {"message":..}. It reports whether anything useful was found.
+func (e *APIError) decode(data []byte) bool {
+	var payload struct {
+		Message string          `json:"message"`
+		Errors  json.RawMessage `json:"errors"`
+	}
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return false
+	}
+	e.Message = payload.Message
+	var list []APIErrorDetail
+	var fields map[string][]APIErrorDetail
+	var message string
+	switch {
+	case json.Unmarshal(payload.Errors, &list) == nil:
+		e.Errors = list
+	case json.Unmarshal(payload.Errors, &fields) == nil:
+		for field, details := range fields {
+			for _, d := range details {
+				d.Field = field
+				e.Errors = append(e.Errors, d)
+			}
+		}
+	case json.Unmarshal(payload.Errors, &message) == nil:
+		e.Errors = []APIErrorDetail{{Message: message}}
+	}
+	return e.Message != "" || len(e.Errors) > 0
+}
+
+func (e *APIError) Error() string {
+	var msgs []string
+	if e.Message != "" {
+		msgs = append(msgs, e.Message)
+	}
+	for _, d := range e.Errors {
+		if d.Field != "" {
+			msgs = append(msgs, d.Field+": "+d.Message)
+		} else {
+			msgs = append(msgs, d.Message)
+		}
+	}
+	if len(msgs) == 0 && e.Body != "" {
+		msgs = append(msgs, e.Body)
+	}
+	detail := ""
+	if len(msgs) > 0 {
+		detail = ": " + strings.Join(msgs, "; ")
+	}
+	return fmt.Sprintf("canvas %s %s returned %d%s", e.Method, e.Endpoint, e.StatusCode, detail)
+}
+
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized
+	case ErrForbidden:
+		return e.StatusCode == http.StatusForbidden
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrRateLimited:
+		return e.StatusCode == http.StatusTooManyRequests
+	}
+	return false
+}
@@ -0,0 +1,49 @@
+package canvas
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+)
+
+// SetFallbackTokens sets the tokens to switch to, in order, when Canvas rejects the current token
+// as invalid, for example because it was revoked or expired during a long run.
+func (api *APIManager) SetFallbackTokens(tokens ...string) {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	api.fallbacks = append([]string(nil), tokens...)
+}
+
+func (api *APIManager) token() string {
+	api.mu.RLock()
+	defer api.mu.RUnlock()
+	return api.config.Token
+}
+
+// failover switches to the next fallback token when resp, a 401 to a request sent with used,
+// says the token is invalid. It reports whether the request should be sent again: true when it
+// switched, or when another request already switched away from used. resp's body is left readable.
+func (api *APIManager) failover(used string, resp *http.Response) bool {
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(data))
+	probe := *resp
+	probe.Body = io.NopCloser(bytes.NewReader(data))
+	if Diagnose(NewAPIError(&probe)).Kind != KindInvalidToken {
+		return false
+	}
+
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	if api.config.Token != used {
+		return true
+	}
+	if len(api.fallbacks) == 0 {
+		return false
+	}
+	api.config.Token, api.fallbacks = api.fallbacks[0], api.fallbacks[1:]
+	api.failovers++
+	api.logger.Warn("API token was rejected as invalid, switching to the next fallback token",
+		"failover", api.failovers, "fallbacks_left", len(api.fallbacks))
+	return true
+}
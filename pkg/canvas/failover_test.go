@@ -0,0 +1,97 @@
+package canvas_test
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"testing"
+
+	"github.com/coraxwolf/CCTA_3-4/pkg/canvas"
+	"github.com/coraxwolf/CCTA_3-4/pkg/canvastest"
+)
+
+// revokedAPI is a client for srv whose token the server rejects as invalid.
+func revokedAPI(srv *canvastest.Server, fallbacks ...string) *canvas.APIManager {
+	api := canvas.NewAPI(slog.New(slog.NewTextHandler(io.Discard, nil)), "revoked-token", srv.BaseURL(), 700, 60)
+	api.SetRetryPolicy(fastRetry)
+	api.SetFallbackTokens(fallbacks...)
+	return api
+}
+
+// tokensSent lists the bearer tokens of the requests the server received, in order.
+func tokensSent(srv *canvastest.Server) []string {
+	var tokens []string
+	for _, r := range srv.Requests() {
+		tokens = append(tokens, r.Header.Get("Authorization"))
+	}
+	return tokens
+}
+
+func TestFailoverToFallbackToken(t *testing.T) {
+	srv, _ := newServer(t)
+	c := srv.AddCourse(canvas.Course{Name: "Biology 101"})
+	api := revokedAPI(srv, canvastest.Token)
+
+	got, err := api.Courses().GetCourse(c.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Name != "Biology 101" {
+		t.Errorf("got %+v", got)
+	}
+	if _, err := api.Courses().GetCourse(c.ID); err != nil {
+		t.Fatal(err)
+	}
+	// the rejected request is sent once more with the fallback, which is then kept
+	tokens := tokensSent(srv)
+	want := []string{"Bearer revoked-token", "Bearer " + canvastest.Token, "Bearer " + canvastest.Token}
+	if len(tokens) != len(want) {
+		t.Fatalf("sent tokens %q, want %q", tokens, want)
+	}
+	for i := range want {
+		if tokens[i] != want[i] {
+			t.Errorf("request %d sent %q, want %q", i, tokens[i], want[i])
+		}
+	}
+	if n := api.Stats().TokenFailovers; n != 1 {
+		t.Errorf("token failovers = %d, want 1", n)
+	}
+}
+
+func TestFailoverStopsWhenFallbacksFail(t *testing.T) {
+	srv, _ := newServer(t)
+	c := srv.AddCourse(canvas.Course{Name: "Biology 101"})
+	api := revokedAPI(srv, "also-revoked")
+
+	_, err := api.Courses().GetCourse(c.ID)
+	if diag := canvas.Diagnose(err); diag.Kind != canvas.KindInvalidToken {
+		t.Fatalf("err = %v (%s), want an invalid token error", err, diag.Kind)
+	}
+	tokens := tokensSent(srv)
+	if len(tokens) != 2 || tokens[0] != "Bearer revoked-token" || tokens[1] != "Bearer also-revoked" {
+		t.Errorf("sent tokens %q, want each token once", tokens)
+	}
+	if n := api.Stats().TokenFailovers; n != 1 {
+		t.Errorf("token failovers = %d, want 1", n)
+	}
+}
+
+func TestNoFailoverForMissingPermission(t *testing.T) {
+	srv, api := newServer(t)
+	c := srv.AddCourse(canvas.Course{Name: "Biology 101"})
+	api.SetFallbackTokens("fallback-token")
+	srv.FailNext(http.StatusUnauthorized)
+
+	_, err := api.Courses().GetCourse(c.ID)
+	var apiErr *canvas.APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("err = %v, want the 401", err)
+	}
+	if n := len(srv.Requests()); n != 1 {
+		t.Errorf("sent %d requests, want 1", n)
+	}
+	if n := api.Stats().TokenFailovers; n != 0 {
+		t.Errorf("token failovers = %d, want 0", n)
+	}
+}
@@ -0,0 +1,28 @@
+package canvas
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrFeatureDisabled is returned when a request needs an experimental feature that is switched off.
+var ErrFeatureDisabled = errors.New("experimental feature is disabled")
+
+// SetFeatures sets the check for experimental features, usually config.Config.Enabled.
+// NewAPIFromConfig sets it from the config; an APIManager without one allows every feature.
+func (api *APIManager) SetFeatures(enabled func(feature string) bool) {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	api.features = enabled
+}
+
+// requireFeature returns ErrFeatureDisabled unless the experimental feature is switched on.
+func (api *APIManager) requireFeature(feature string) error {
+	api.mu.RLock()
+	enabled := api.features
+	api.mu.RUnlock()
+	if enabled == nil || enabled(feature) {
+		return nil
+	}
+	return fmt.Errorf("%w: %s (set experimental: {%s: true} in the config file)", ErrFeatureDisabled, feature, feature)
+}
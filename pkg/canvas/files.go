@@ -0,0 +1,433 @@
+package canvas
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+type File struct {
+	ID            int        `json:"id"`
+	UUID          string     `json:"uuid"`
+	FolderID      int        `json:"folder_id"`
+	DisplayName   string     `json:"display_name"`
+	Filename      string     `json:"filename"`
+	ContentType   string     `json:"content-type"`
+	URL           string     `json:"url"` // download URL
+	Size          int64      `json:"size"`
+	CreatedAt     *time.Time `json:"created_at"`
+	UpdatedAt     *time.Time `json:"updated_at"`
+	ModifiedAt    *time.Time `json:"modified_at"`
+	UnlockAt      *time.Time `json:"unlock_at"`
+	LockAt        *time.Time `json:"lock_at"`
+	Locked        bool       `json:"locked"`
+	Hidden        bool       `json:"hidden"`
+	LockedForUser bool       `json:"locked_for_user"`
+	HiddenForUser bool       `json:"hidden_for_user"`
+	MimeClass     string     `json:"mime_class"`
+	ThumbnailURL  string     `json:"thumbnail_url"`
+}
+
+// Folder is a folder of course, user, or group files.
+type Folder struct {
+	ID             int        `json:"id"`
+	Name           string     `json:"name"`
+	FullName       string     `json:"full_name"` // path from the context's root, e.g. "course files/syllabi"
+	ContextID      int        `json:"context_id"`
+	ContextType    string     `json:"context_type"` // Course, User, or Group
+	ParentFolderID *int       `json:"parent_folder_id"`
+	FilesCount     int        `json:"files_count"`
+	FoldersCount   int        `json:"folders_count"`
+	Position       int        `json:"position"`
+	CreatedAt      *time.Time `json:"created_at"`
+	UpdatedAt      *time.Time `json:"updated_at"`
+	UnlockAt       *time.Time `json:"unlock_at"`
+	LockAt         *time.Time `json:"lock_at"`
+	Locked         bool       `json:"locked"`
+	Hidden         bool       `json:"hidden"`
+	LockedForUser  bool       `json:"locked_for_user"`
+	HiddenForUser  bool       `json:"hidden_for_user"`
+}
+
+// FileListOptions narrows the file lists.
+type FileListOptions struct {
+	SearchTerm   string   // at least two characters
+	ContentTypes []string // e.g. application/pdf or image
+	Sort         string   // name, size, created_at, updated_at, content_type, or user
+	Order        string   // asc or desc
+	Include      []string // e.g. user
+}
+
+func (opts FileListOptions) query() url.Values {
+	query := url.Values{}
+	query.Set("per_page", perPage(0))
+	if opts.SearchTerm != "" {
+		query.Set("search_term", opts.SearchTerm)
+	}
+	for _, ct := range opts.ContentTypes {
+		query.Add("content_types[]", ct)
+	}
+	if opts.Sort != "" {
+		query.Set("sort", opts.Sort)
+	}
+	if opts.Order != "" {
+		query.Set("order", opts.Order)
+	}
+	for _, inc := range opts.Include {
+		query.Add("include[]", inc)
+	}
+	return query
+}
+
+// FileUpdate moves, renames, locks, or hides a file. Nil fields are left untouched.
+type FileUpdate struct {
+	Name           *string    `json:"name,omitempty"`
+	ParentFolderID *int       `json:"parent_folder_id,omitempty"`
+	OnDuplicate    string     `json:"on_duplicate,omitempty"` // OnDuplicateOverwrite or OnDuplicateRename when the name is taken
+	LockAt         *time.Time `json:"lock_at,omitempty"`
+	UnlockAt       *time.Time `json:"unlock_at,omitempty"`
+	Locked         *bool      `json:"locked,omitempty"`
+	Hidden         *bool      `json:"hidden,omitempty"`
+}
+
+// Quota is a context's file storage quota, in bytes.
+type Quota struct {
+	Quota     int64 `json:"quota"`
+	QuotaUsed int64 `json:"quota_used"`
+}
+
+// UsedPercent returns how much of the quota is used.
+func (q Quota) UsedPercent() float64 {
+	if q.Quota <= 0 {
+		return 0
+	}
+	return 100 * float64(q.QuotaUsed) / float64(q.Quota)
+}
+
+// Upload conflict handling: what Canvas does when the folder already has a file with the name.
+const (
+	OnDuplicateOverwrite = "overwrite"
+	OnDuplicateRename    = "rename"
+)
+
+type FilesService struct {
+	service
+}
+
+func (api *APIManager) Files() *FilesService {
+	return &FilesService{service{api: api}}
+}
+
+// WithContext returns a copy of the service that sends its requests with ctx.
+func (fs *FilesService) WithContext(ctx context.Context) *FilesService {
+	return &FilesService{service{api: fs.api, ctx: ctx}}
+}
+
+// ListCourseFiles returns every file in a course, in all its folders.
+func (fs *FilesService) ListCourseFiles(courseID int, opts FileListOptions) ([]File, error) {
+	var files []File
+	if err := fs.listJSON(withQuery(fmt.Sprintf("courses/%d/files", courseID), opts.query()), &files); err != nil {
+		return nil, fmt.Errorf("error listing files for course %d: %w", courseID, err)
+	}
+	return files, nil
+}
+
+// ListUserFiles returns a user's personal files. userID may be a Canvas ID, sis_user_id:<id>, or self.
+func (fs *FilesService) ListUserFiles(userID string, opts FileListOptions) ([]File, error) {
+	var files []File
+	if err := fs.listJSON(withQuery(fmt.Sprintf("users/%s/files", url.PathEscape(userID)), opts.query()), &files); err != nil {
+		return nil, fmt.Errorf("error listing files for user %s: %w", userID, err)
+	}
+	return files, nil
+}
+
+// ListFolderFiles returns the files directly in a folder.
+func (fs *FilesService) ListFolderFiles(folderID int, opts FileListOptions) ([]File, error) {
+	var files []File
+	if err := fs.listJSON(withQuery(fmt.Sprintf("folders/%d/files", folderID), opts.query()), &files); err != nil {
+		return nil, fmt.Errorf("error listing files in folder %d: %w", folderID, err)
+	}
+	return files, nil
+}
+
+// GetFile returns a file. Its URL is a download link that works without the token for a while.
+func (fs *FilesService) GetFile(id int) (*File, error) {
+	var file File
+	if err := fs.getJSON(fmt.Sprintf("files/%d", id), &file); err != nil {
+		return nil, fmt.Errorf("error fetching file %d: %w", id, err)
+	}
+	return &file, nil
+}
+
+// DownloadURL returns a current download link for a file.
+func (fs *FilesService) DownloadURL(id int) (string, error) {
+	file, err := fs.GetFile(id)
+	if err != nil {
+		return "", err
+	}
+	if file.URL == "" {
+		return "", fmt.Errorf("file %d has no download URL (it may be locked for the token's user)", id)
+	}
+	return file.URL, nil
+}
+
+// Download writes a file's contents to w.
+func (fs *FilesService) Download(file *File, w io.Writer) error {
+	resp, err := fs.api.download(fs.context(), file.URL)
+	if err != nil {
+		return fmt.Errorf("error downloading file %d: %w", file.ID, err)
+	}
+	defer resp.Body.Close()
+	if _, err := io.Copy(w, resp.Body); err != nil {
+		return fmt.Errorf("error downloading file %d: %w", file.ID, err)
+	}
+	return nil
+}
+
+func (fs *FilesService) UpdateFile(id int, update FileUpdate) (*File, error) {
+	var file File
+	if err := fs.sendJSON(http.MethodPut, fmt.Sprintf("files/%d", id), update, &file); err != nil {
+		return nil, fmt.Errorf("error updating file %d: %w", id, err)
+	}
+	return &file, nil
+}
+
+// RenameFile renames a file in its folder. It fails if the folder has a file with the new name.
+func (fs *FilesService) RenameFile(id int, name string) (*File, error) {
+	return fs.UpdateFile(id, FileUpdate{Name: &name})
+}
+
+// MoveFile moves a file to another folder of the same course or user. onDuplicate says what to
+// do when the folder has a file with the same name; empty fails instead.
+func (fs *FilesService) MoveFile(id, folderID int, onDuplicate string) (*File, error) {
+	return fs.UpdateFile(id, FileUpdate{ParentFolderID: &folderID, OnDuplicate: onDuplicate})
+}
+
+// ListCourseFolders returns every folder in a course, at any depth.
+func (fs *FilesService) ListCourseFolders(courseID int) ([]Folder, error) {
+	var folders []Folder
+	if err := fs.listJSON(fmt.Sprintf("courses/%d/folders?per_page=100", courseID), &folders); err != nil {
+		return nil, fmt.Errorf("error listing folders for course %d: %w", courseID, err)
+	}
+	return folders, nil
+}
+
+// ListUserFolders returns every folder in a user's files. userID may be a Canvas ID, sis_user_id:<id>, or self.
+func (fs *FilesService) ListUserFolders(userID string) ([]Folder, error) {
+	var folders []Folder
+	if err := fs.listJSON(fmt.Sprintf("users/%s/folders?per_page=100", url.PathEscape(userID)), &folders); err != nil {
+		return nil, fmt.Errorf("error listing folders for user %s: %w", userID, err)
+	}
+	return folders, nil
+}
+
+// ListSubfolders returns the folders directly in a folder.
+func (fs *FilesService) ListSubfolders(folderID int) ([]Folder, error) {
+	var folders []Folder
+	if err := fs.listJSON(fmt.Sprintf("folders/%d/folders?per_page=100", folderID), &folders); err != nil {
+		return nil, fmt.Errorf("error listing subfolders of folder %d: %w", folderID, err)
+	}
+	return folders, nil
+}
+
+func (fs *FilesService) GetFolder(id int) (*Folder, error) {
+	var folder Folder
+	if err := fs.getJSON(fmt.Sprintf("folders/%d", id), &folder); err != nil {
+		return nil, fmt.Errorf("error fetching folder %d: %w", id, err)
+	}
+	return &folder, nil
+}
+
+// GetCourseFolderByPath returns the folder at a path under the course's files, e.g. "syllabi/2025".
+// An empty path returns the course's root folder.
+func (fs *FilesService) GetCourseFolderByPath(courseID int, folderPath string) (*Folder, error) {
+	endpoint := fmt.Sprintf("courses/%d/folders/by_path", courseID)
+	for _, name := range strings.Split(strings.Trim(folderPath, "/"), "/") {
+		if name != "" {
+			endpoint += "/" + url.PathEscape(name)
+		}
+	}
+	// Canvas returns the folders from the root down to the path
+	var folders []Folder
+	if err := fs.getJSON(endpoint, &folders); err != nil {
+		return nil, fmt.Errorf("error fetching folder %q in course %d: %w", folderPath, courseID, err)
+	}
+	if len(folders) == 0 {
+		return nil, fmt.Errorf("folder %q not found in course %d", folderPath, courseID)
+	}
+	return &folders[len(folders)-1], nil
+}
+
+func (fs *FilesService) GetCourseQuota(courseID int) (*Quota, error) {
+	var quota Quota
+	if err := fs.getJSON(fmt.Sprintf("courses/%d/files/quota", courseID), &quota); err != nil {
+		return nil, fmt.Errorf("error fetching file quota for course %d: %w", courseID, err)
+	}
+	return &quota, nil
+}
+
+// GetUserQuota returns a user's personal file quota. userID may be a Canvas ID, sis_user_id:<id>, or self.
+func (fs *FilesService) GetUserQuota(userID string) (*Quota, error) {
+	var quota Quota
+	if err := fs.getJSON(fmt.Sprintf("users/%s/files/quota", url.PathEscape(userID)), &quota); err != nil {
+		return nil, fmt.Errorf("error fetching file quota for user %s: %w", userID, err)
+	}
+	return &quota, nil
+}
+
+// SetCourseQuota changes a course's file storage quota. It needs an account admin token.
+func (fs *FilesService) SetCourseQuota(courseID int, megabytes int) error {
+	body := map[string]any{"course": map[string]int{"storage_quota_mb": megabytes}}
+	if err := fs.sendJSON(http.MethodPut, fmt.Sprintf("courses/%d", courseID), body, nil); err != nil {
+		return fmt.Errorf("error setting file quota for course %d: %w", courseID, err)
+	}
+	return nil
+}
+
+// UploadFile uploads the contents of r as filename into a course's files. folderPath is the
+// folder under the course's files, e.g. "syllabi/2025", created if needed; empty uploads to the
+// top folder. A file with the same name is overwritten.
+func (fs *FilesService) UploadFile(courseID int, folderPath string, r io.Reader, filename string) (*File, error) {
+	params := map[string]any{"on_duplicate": OnDuplicateOverwrite}
+	if folderPath != "" {
+		params["parent_folder_path"] = folderPath
+	}
+	file, err := fs.upload(fmt.Sprintf("courses/%d/files", courseID), params, r, filename)
+	if err != nil {
+		return nil, fmt.Errorf("error uploading %s to course %d: %w", filename, courseID, err)
+	}
+	return file, nil
+}
+
+// UploadToFolder uploads the contents of r as filename into a folder. A file with the same name
+// is overwritten.
+func (fs *FilesService) UploadToFolder(folderID int, r io.Reader, filename string) (*File, error) {
+	file, err := fs.upload(fmt.Sprintf("folders/%d/files", folderID), map[string]any{"on_duplicate": OnDuplicateOverwrite}, r, filename)
+	if err != nil {
+		return nil, fmt.Errorf("error uploading %s to folder %d: %w", filename, folderID, err)
+	}
+	return file, nil
+}
+
+// uploadTicket is Canvas' answer to the upload pre-flight: where to send the file and the form
+// fields to send with it.
+type uploadTicket struct {
+	UploadURL    string         `json:"upload_url"`
+	UploadParams map[string]any `json:"upload_params"`
+	FileParam    string         `json:"file_param"`
+}
+
+// upload runs the three steps of a Canvas file upload: the pre-flight POST to endpoint, the
+// multipart POST of the file to the returned upload URL, and the confirmation request.
+func (fs *FilesService) upload(endpoint string, params map[string]any, r io.Reader, filename string) (*File, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("error reading file: %w", err)
+	}
+	contentType := mime.TypeByExtension(filepath.Ext(filename))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	params["name"] = filepath.Base(filename)
+	params["size"] = len(data)
+	params["content_type"] = contentType
+
+	// 1. pre-flight
+	var ticket uploadTicket
+	if err := fs.sendJSON(http.MethodPost, endpoint, params, &ticket); err != nil {
+		return nil, err
+	}
+	if fs.api.DryRun() {
+		return &File{DisplayName: filepath.Base(filename), Filename: filepath.Base(filename), ContentType: contentType, Size: int64(len(data))}, nil
+	}
+	return fs.sendUpload(ticket, data, filename)
+}
+
+// sendUpload runs the last two steps of an upload: the multipart POST of the file to the ticket's
+// upload URL, and the confirmation request. It returns the Canvas file.
+func (s service) sendUpload(ticket uploadTicket, data []byte, filename string) (*File, error) {
+	if ticket.UploadURL == "" {
+		return nil, fmt.Errorf("canvas returned no upload URL")
+	}
+
+	// 2. upload; the file must be the last form field
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	names := make([]string, 0, len(ticket.UploadParams))
+	for name := range ticket.UploadParams {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := mw.WriteField(name, fmt.Sprint(ticket.UploadParams[name])); err != nil {
+			return nil, err
+		}
+	}
+	fileParam := ticket.FileParam
+	if fileParam == "" {
+		fileParam = "file"
+	}
+	part, err := mw.CreateFormFile(fileParam, filepath.Base(filename))
+	if err != nil {
+		return nil, err
+	}
+	part.Write(data)
+	if err := mw.Close(); err != nil {
+		return nil, err
+	}
+	resp, err := s.api.postUpload(s.context(), ticket.UploadURL, mw.FormDataContentType(), buf.Bytes())
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	// 3. confirm: a redirect or a Location header points at the Canvas file, unless the
+	// upload service already answered with it
+	location := resp.Header.Get("Location")
+	switch {
+	case resp.StatusCode >= 300 && resp.StatusCode < 400 && location != "":
+	case resp.StatusCode == http.StatusOK || resp.StatusCode == http.StatusCreated:
+		var file File
+		body, _ := io.ReadAll(resp.Body)
+		if json.Unmarshal(body, &file) == nil && file.ID != 0 {
+			return &file, nil
+		}
+		if location == "" {
+			return nil, fmt.Errorf("upload returned %d without the file or its location", resp.StatusCode)
+		}
+	default:
+		return nil, NewAPIError(resp)
+	}
+	var file File
+	if err := s.getJSON(location, &file); err != nil {
+		return nil, fmt.Errorf("error confirming upload: %w", err)
+	}
+	return &file, nil
+}
+
+// postUpload sends a file to an upload URL from a file upload pre-flight. The URL is often on a
+// storage service, so the token isn't sent (the form carries its own signature), redirects are
+// returned rather than followed, and the response isn't rate tracked.
+func (api *APIManager) postUpload(ctx context.Context, uploadURL, contentType string, body []byte) (*http.Response, error) {
+	start := time.Now()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, uploadURL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", contentType)
+	client := *api.client
+	client.CheckRedirect = func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }
+	resp, err := client.Do(req)
+	api.logRequest(start, http.MethodPost, uploadURL, nil, resp, err)
+	return resp, err
+}
@@ -0,0 +1,172 @@
+package canvas
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// GraphQLError is one entry of the errors list of a GraphQL response.
+type GraphQLError struct {
+	Message   string `json:"message"`
+	Path      []any  `json:"path,omitempty"`
+	Locations []struct {
+		Line   int `json:"line"`
+		Column int `json:"column"`
+	} `json:"locations,omitempty"`
+}
+
+// GraphQLErrors is returned when a GraphQL response has errors. The data that could be resolved
+// is still returned with it.
+type GraphQLErrors []GraphQLError
+
+func (e GraphQLErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Message
+		if len(err.Path) > 0 {
+			messages[i] += fmt.Sprintf(" (at %v)", err.Path)
+		}
+	}
+	return "canvas graphql: " + strings.Join(messages, "; ")
+}
+
+// graphqlURL is the GraphQL endpoint of the instance: https://school.instructure.com/api/graphql.
+func (api *APIManager) graphqlURL() string {
+	base, err := url.Parse(api.config.BaseURL)
+	if err != nil {
+		return strings.TrimSuffix(api.config.BaseURL, "v1/") + "graphql"
+	}
+	base.Path = "/api/graphql"
+	base.RawQuery = ""
+	return base.String()
+}
+
+// isGraphQLQuery reports whether a request is a GraphQL query rather than a mutation, so that it
+// is still sent in dry-run mode.
+func (api *APIManager) isGraphQLQuery(endpoint string, body []byte) bool {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return false
+	}
+	u.RawQuery = "" // masquerading adds as_user_id
+	if u.String() != api.graphqlURL() {
+		return false
+	}
+	var req struct {
+		Query string `json:"query"`
+	}
+	if err := json.Unmarshal(body, &req); err != nil {
+		return false
+	}
+	return !strings.HasPrefix(strings.TrimSpace(req.Query), "mutation")
+}
+
+// GraphQL runs a query (or mutation) against the Canvas GraphQL API and returns its data.
+// It needs the experimental graphql feature when the APIManager was created from a config.
+// Use Canvas IDs (_id) and legacy IDs as the schema expects, e.g.
+//
+//	data, err := api.GraphQL(`query($id: ID!) { course(id: $id) { name } }`, map[string]any{"id": "123"})
+func (api *APIManager) GraphQL(query string, variables map[string]any) (json.RawMessage, error) {
+	return api.GraphQLCtx(context.Background(), query, variables)
+}
+
+func (api *APIManager) GraphQLCtx(ctx context.Context, query string, variables map[string]any) (json.RawMessage, error) {
+	if err := api.requireFeature("graphql"); err != nil {
+		return nil, err
+	}
+	body, err := json.Marshal(map[string]any{"query": query, "variables": variables})
+	if err != nil {
+		return nil, fmt.Errorf("error encoding graphql request: %w", err)
+	}
+	resp, err := api.do(ctx, http.MethodPost, api.graphqlURL(), body)
+	if err != nil {
+		return nil, fmt.Errorf("error sending graphql request: %w", err)
+	}
+	defer resp.Body.Close() // Ensure the response body is closed after reading
+	if resp.StatusCode != http.StatusOK {
+		return nil, NewAPIError(resp)
+	}
+	var result struct {
+		Data   json.RawMessage `json:"data"`
+		Errors GraphQLErrors   `json:"errors"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("error decoding graphql response: %w", err)
+	}
+	if len(result.Errors) > 0 {
+		return result.Data, result.Errors
+	}
+	return result.Data, nil
+}
+
+// GraphQLInto runs a query and decodes its data into v.
+func (api *APIManager) GraphQLInto(ctx context.Context, query string, variables map[string]any, v any) error {
+	data, err := api.GraphQLCtx(ctx, query, variables)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("error decoding graphql data: %w", err)
+	}
+	return nil
+}
+
+// GraphQLConnection pages through a connection and returns every node. The query must take an
+// $after: String variable, pass it to the connection as after: $after, and select
+// pageInfo { hasNextPage endCursor } with either nodes or edges { node }. path names the
+// connection in the data, e.g. []string{"course", "enrollmentsConnection"}.
+func (api *APIManager) GraphQLConnection(ctx context.Context, query string, variables map[string]any, path ...string) ([]json.RawMessage, error) {
+	vars := make(map[string]any, len(variables)+1)
+	for k, v := range variables {
+		vars[k] = v
+	}
+	var nodes []json.RawMessage
+	for {
+		data, err := api.GraphQLCtx(ctx, query, vars)
+		if err != nil {
+			return nil, err
+		}
+		conn, err := connectionAt(data, path)
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, conn.Nodes...)
+		for _, edge := range conn.Edges {
+			nodes = append(nodes, edge.Node)
+		}
+		if !conn.PageInfo.HasNextPage || conn.PageInfo.EndCursor == "" {
+			return nodes, nil
+		}
+		vars["after"] = conn.PageInfo.EndCursor
+	}
+}
+
+type connection struct {
+	Nodes []json.RawMessage `json:"nodes"`
+	Edges []struct {
+		Node json.RawMessage `json:"node"`
+	} `json:"edges"`
+	PageInfo struct {
+		HasNextPage bool   `json:"hasNextPage"`
+		EndCursor   string `json:"endCursor"`
+	} `json:"pageInfo"`
+}
+
+func connectionAt(data json.RawMessage, path []string) (connection, error) {
+	var conn connection
+	for _, key := range path {
+		var obj map[string]json.RawMessage
+		if err := json.Unmarshal(data, &obj); err != nil || obj == nil {
+			return conn, fmt.Errorf("graphql data has no %s", strings.Join(path, "."))
+		}
+		data = obj[key]
+	}
+	if err := json.Unmarshal(data, &conn); err != nil {
+		return conn, fmt.Errorf("error decoding graphql connection %s: %w", strings.Join(path, "."), err)
+	}
+	return conn, nil
+}
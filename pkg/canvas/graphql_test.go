@@ -0,0 +1,23 @@
+package canvas_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/coraxwolf/CCTA_3-4/pkg/canvas"
+)
+
+// Queries are sent in dry-run mode, also while masquerading, which adds as_user_id to the URL.
+func TestGraphQLQuerySentInDryRun(t *testing.T) {
+	srv, api := newServer(t)
+	api.SetDryRun(true)
+	ctx := canvas.WithAsUser(context.Background(), "42")
+
+	api.GraphQLCtx(ctx, `query { course(id: "1") { name } }`, nil) // canvastest has no GraphQL endpoint
+	api.GraphQLCtx(ctx, `mutation { updateCourse { name } }`, nil)
+
+	requests := srv.Requests()
+	if len(requests) != 1 || requests[0].Path != "/api/graphql" || requests[0].Query.Get("as_user_id") != "42" {
+		t.Errorf("sent %+v, want only the query as user 42", requests)
+	}
+}
@@ -0,0 +1,33 @@
+package canvas_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/coraxwolf/CCTA_3-4/pkg/canvas"
+	"github.com/coraxwolf/CCTA_3-4/pkg/canvastest"
+)
+
+// fastRetry keeps retry backoff short enough for tests.
+var fastRetry = canvas.RetryPolicy{MaxAttempts: 4, BaseDelay: 10 * time.Millisecond, MaxDelay: 50 * time.Millisecond}
+
+// newServer starts a canvastest server, closed when the test ends, and a client for it.
+func newServer(t *testing.T) (*canvastest.Server, *canvas.APIManager) {
+	t.Helper()
+	srv := canvastest.NewServer()
+	t.Cleanup(srv.Close)
+	api := srv.API()
+	api.SetRetryPolicy(fastRetry)
+	return srv, api
+}
+
+// requestsTo counts the requests the server received for a path.
+func requestsTo(srv *canvastest.Server, path string) int {
+	n := 0
+	for _, r := range srv.Requests() {
+		if r.Path == path {
+			n++
+		}
+	}
+	return n
+}
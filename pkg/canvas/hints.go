@@ -0,0 +1,126 @@
+package canvas
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// ErrorKind is a broad class of Canvas failure, used to pick a remediation hint.
+type ErrorKind string
+
+const (
+	KindUnknown            ErrorKind = "unknown"
+	KindInvalidToken       ErrorKind = "invalid_token"
+	KindInsufficientScopes ErrorKind = "insufficient_scopes"
+	KindNotAuthorized      ErrorKind = "not_authorized"
+	KindRateLimited        ErrorKind = "rate_limited"
+	KindNotFound           ErrorKind = "not_found"
+	KindDeleted            ErrorKind = "deleted"
+	KindTermProtected      ErrorKind = "term_protected"
+	KindSISConflict        ErrorKind = "sis_conflict"
+	KindValidation         ErrorKind = "validation"
+	KindServer             ErrorKind = "server_error"
+	KindNetwork            ErrorKind = "network"
+	KindCircuitOpen        ErrorKind = "circuit_open"
+)
+
+// Remediation describes what kind of failure an error is and what the user can do about it.
+type Remediation struct {
+	Kind  ErrorKind
+	Hint  string
+	Scope string // the developer key scope the request needed, for KindInsufficientScopes
+}
+
+type hintRule struct {
+	kind  ErrorKind
+	hint  string
+	match func(e *APIError, msg string) bool
+}
+
+// hintRules are checked in order, so the more specific messages come before the plain status checks.
+var hintRules = []hintRule{
+	{KindInsufficientScopes, "The developer key for this token is scoped and does not include this endpoint. Add the scope to the key or use an unscoped token.",
+		func(e *APIError, msg string) bool { return strings.Contains(msg, "insufficient scope") }},
+	{KindInvalidToken, "The API token is invalid, expired, or revoked. Generate a new token and update the configuration.",
+		func(e *APIError, msg string) bool {
+			return e.StatusCode == http.StatusUnauthorized && (strings.Contains(msg, "invalid access token") || strings.Contains(msg, "expired"))
+		}},
+	{KindRateLimited, "Canvas is throttling this token. Wait a few minutes or lower the request concurrency.",
+		func(e *APIError, msg string) bool {
+			// Canvas answers throttled requests with a plain text 403, which ends up in Body.
+			return e.StatusCode == http.StatusTooManyRequests || strings.Contains(msg, "rate limit exceeded") ||
+				strings.Contains(strings.ToLower(e.Body), "rate limit exceeded")
+		}},
+	{KindTermProtected, "The course or its term has concluded, so Canvas blocks changes. Reopen the term or course dates, or ask an admin with term override permission.",
+		func(e *APIError, msg string) bool {
+			return strings.Contains(msg, "concluded") || strings.Contains(msg, "read-only") || strings.Contains(msg, "soft-concluded")
+		}},
+	{KindSISConflict, "The SIS ID is already used by another object or is locked by the SIS import. Check the SIS data or resend with override_sis_stickiness.",
+		func(e *APIError, msg string) bool {
+			return strings.Contains(msg, "sis") && (strings.Contains(msg, "already in use") || strings.Contains(msg, "sticky"))
+		}},
+	{KindDeleted, "The object has been deleted in Canvas. Restore it from the course's undelete page or remove it from the input.",
+		func(e *APIError, msg string) bool { return strings.Contains(msg, "deleted") }},
+	{KindNotAuthorized, "The token's user lacks the permission for this action in this account or course. Check the user's account role permissions.",
+		func(e *APIError, msg string) bool {
+			return e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden
+		}},
+	{KindNotFound, "The object does not exist or is not visible to the token's user. Check the ID and the account it belongs to.",
+		func(e *APIError, msg string) bool { return e.StatusCode == http.StatusNotFound }},
+	{KindValidation, "Canvas rejected the request data. Check the listed fields.",
+		func(e *APIError, msg string) bool {
+			return e.StatusCode == http.StatusBadRequest || e.StatusCode == http.StatusUnprocessableEntity
+		}},
+	{KindServer, "Canvas had a server error. Retry later and check status.instructure.com if it persists.",
+		func(e *APIError, msg string) bool { return e.StatusCode >= 500 }},
+}
+
+// Diagnose classifies err and returns a remediation hint. Errors that are not from Canvas
+// get KindNetwork or KindUnknown.
+func Diagnose(err error) Remediation {
+	if err == nil {
+		return Remediation{Kind: KindUnknown}
+	}
+	if errors.Is(err, ErrCircuitOpen) {
+		return Remediation{Kind: KindCircuitOpen, Hint: "Canvas failed repeatedly, so requests are paused for a while. Check status.instructure.com and run the command again later."}
+	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		msg := errorMessages(apiErr)
+		for _, rule := range hintRules {
+			if !rule.match(apiErr, msg) {
+				continue
+			}
+			r := Remediation{Kind: rule.kind, Hint: rule.hint}
+			if rule.kind == KindInsufficientScopes {
+				if r.Scope = ScopeFor(apiErr.Method, apiErr.Endpoint); r.Scope != "" {
+					r.Hint = "The developer key for this token is scoped and is missing " + r.Scope + ". Add the scope to the key or use an unscoped token."
+				}
+			}
+			return r
+		}
+		return Remediation{Kind: KindUnknown}
+	}
+	if errors.Is(err, context.DeadlineExceeded) || retryableError(err) {
+		return Remediation{Kind: KindNetwork, Hint: "The request timed out. Check the network connection and base URL, or raise the read timeout."}
+	}
+	return Remediation{Kind: KindUnknown}
+}
+
+// errorMessages returns the lowercased messages Canvas sent with an error, without the method
+// and endpoint that APIError.Error adds, so an ID like "sis_course_id:deleted-2024" in the path
+// can't match a rule.
+func errorMessages(e *APIError) string {
+	msgs := []string{e.Message}
+	for _, d := range e.Errors {
+		msgs = append(msgs, d.Field+" "+d.Message)
+	}
+	return strings.ToLower(strings.Join(msgs, "\n"))
+}
+
+// Hint returns the remediation hint for err, or "" when there is none.
+func Hint(err error) string {
+	return Diagnose(err).Hint
+}
@@ -0,0 +1,31 @@
+package canvas_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/coraxwolf/CCTA_3-4/pkg/canvas"
+)
+
+func TestDiagnose(t *testing.T) {
+	tests := []struct {
+		name string
+		err  *canvas.APIError
+		want canvas.ErrorKind
+	}{
+		{"invalid token", &canvas.APIError{StatusCode: http.StatusUnauthorized, Errors: []canvas.APIErrorDetail{{Message: "Invalid access token."}}}, canvas.KindInvalidToken},
+		{"throttled 403", &canvas.APIError{StatusCode: http.StatusForbidden, Body: "403 Forbidden (Rate Limit Exceeded)"}, canvas.KindRateLimited},
+		{"concluded", &canvas.APIError{StatusCode: http.StatusUnauthorized, Message: "Course is concluded"}, canvas.KindTermProtected},
+		{"sis field", &canvas.APIError{StatusCode: http.StatusBadRequest, Errors: []canvas.APIErrorDetail{{Field: "sis_source_id", Message: "is already in use"}}}, canvas.KindSISConflict},
+		// The endpoint is not part of the message, whatever it contains.
+		{"path with keywords", &canvas.APIError{StatusCode: http.StatusNotFound, Method: http.MethodGet, Endpoint: "/api/v1/courses/sis_course_id:deleted-concluded", Message: "The specified resource does not exist."}, canvas.KindNotFound},
+		{"validation", &canvas.APIError{StatusCode: http.StatusBadRequest, Method: http.MethodPut, Endpoint: "/api/v1/courses/1/read-only"}, canvas.KindValidation},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := canvas.Diagnose(tt.err).Kind; got != tt.want {
+				t.Errorf("Diagnose(%v) = %s, want %s", tt.err, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,132 @@
+package canvas
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// HTTPLogOptions configures the logging of every HTTP round trip, including retries.
+type HTTPLogOptions struct {
+	Level   slog.Level // level of the per-request line; transport errors are logged at Warn or above
+	DumpDir string     // when set, every response body is also written to a file in this directory
+}
+
+// sensitiveParams are query parameters whose values are replaced in logs.
+var sensitiveParams = []string{"access_token", "token", "password", "client_secret", "code", "refresh_token"}
+
+// sensitiveHeaders are headers whose values are replaced in logs and dumps.
+var sensitiveHeaders = []string{"Authorization", "Cookie", "Set-Cookie", "X-Csrf-Token"}
+
+// SetHTTPLogging wraps the HTTP client so every round trip is logged with its method, redacted URL,
+// status, duration, request cost, and the rate limit remaining. Call it before sending requests.
+func (api *APIManager) SetHTTPLogging(opts HTTPLogOptions) error {
+	if opts.DumpDir != "" {
+		if err := os.MkdirAll(opts.DumpDir, 0o700); err != nil {
+			return fmt.Errorf("error creating dump directory: %w", err)
+		}
+	}
+	next := api.client.Transport
+	if lt, ok := next.(*loggingTransport); ok {
+		next = lt.next // replace an earlier logging layer instead of stacking them
+	}
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	api.client.Transport = &loggingTransport{next: next, logger: api.logger, opts: opts}
+	return nil
+}
+
+type loggingTransport struct {
+	next   http.RoundTripper
+	logger *slog.Logger
+	opts   HTTPLogOptions
+	seq    atomic.Int64
+}
+
+func (lt *loggingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := lt.next.RoundTrip(req)
+	duration := time.Since(start)
+	target := redactURL(req.URL)
+	if err != nil {
+		level := max(lt.opts.Level, slog.LevelWarn)
+		lt.logger.Log(req.Context(), level, "canvas request failed", "method", req.Method, "url", target, "duration", duration, "error", err)
+		return nil, err
+	}
+	lt.logger.Log(req.Context(), lt.opts.Level, "canvas request",
+		"method", req.Method,
+		"url", target,
+		"status", resp.StatusCode,
+		"duration", duration,
+		"cost", resp.Header.Get("X-Request-Cost"),
+		"rate_limit_remaining", resp.Header.Get("X-Rate-Limit-Remaining"),
+	)
+	if lt.opts.DumpDir != "" {
+		lt.dump(req, resp)
+	}
+	return resp, nil
+}
+
+var unsafeFileChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// dump writes the response status, redacted headers, and body to a file and gives the response a fresh body.
+func (lt *loggingTransport) dump(req *http.Request, resp *http.Response) {
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		lt.logger.Warn("error reading response body for dump", "error", err)
+		return
+	}
+	name := fmt.Sprintf("%05d_%s_%s.txt", lt.seq.Add(1), req.Method, unsafeFileChars.ReplaceAllString(strings.Trim(req.URL.Path, "/"), "_"))
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "%s %s\n%s\n", req.Method, redactURL(req.URL), resp.Status)
+	redactHeaders(resp.Header).Write(&out)
+	out.WriteString("\n")
+	out.Write(body)
+	if err := os.WriteFile(filepath.Join(lt.opts.DumpDir, name), out.Bytes(), 0o600); err != nil {
+		lt.logger.Warn("error writing response dump", "error", err)
+	}
+}
+
+// redactURL returns the URL with the values of sensitive query parameters replaced.
+func redactURL(u *url.URL) string {
+	if u == nil {
+		return ""
+	}
+	query := u.Query()
+	changed := false
+	for _, name := range sensitiveParams {
+		if query.Has(name) {
+			query.Set(name, "REDACTED")
+			changed = true
+		}
+	}
+	if !changed {
+		return u.Redacted()
+	}
+	copied := *u
+	copied.RawQuery = query.Encode()
+	return copied.Redacted()
+}
+
+// redactHeaders returns a copy of the headers with the values of sensitive headers replaced.
+func redactHeaders(h http.Header) http.Header {
+	copied := h.Clone()
+	for _, name := range sensitiveHeaders {
+		if copied.Get(name) != "" {
+			copied.Set(name, "REDACTED")
+		}
+	}
+	return copied
+}
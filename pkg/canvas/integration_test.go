@@ -0,0 +1,241 @@
+//go:build integration
+
+// The integration tests run against a real Canvas sandbox, so they are only built with
+// -tags integration and skipped unless the sandbox environment variables are set:
+//
+//	BETA_API_URL=https://school.beta.instructure.com/api/v1/ BETA_TOKEN=... INTEGRATION_ACCOUNT=123 \
+//		go test -tags integration -run Integration ./pkg/canvas
+//
+// They create a fixture course in the sandbox sub-account and delete it when they finish.
+
+package canvas_test
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"slices"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/coraxwolf/CCTA_3-4/pkg/canvas"
+)
+
+// integrationPrefix starts the name of every fixture course, so the ones a killed run left
+// behind can be found in the sandbox.
+const integrationPrefix = "CCTA integration"
+
+// sandbox returns a client for the beta instance and the sandbox sub-account, or skips the test
+// when they aren't configured. It refuses root accounts, where fixtures would land next to real
+// courses.
+func sandbox(t *testing.T) (*canvas.APIManager, *canvas.Account) {
+	t.Helper()
+	baseURL, token := os.Getenv("BETA_API_URL"), os.Getenv("BETA_TOKEN")
+	accountID, _ := strconv.Atoi(os.Getenv("INTEGRATION_ACCOUNT"))
+	if baseURL == "" || token == "" || accountID == 0 {
+		t.Skip("set BETA_API_URL, BETA_TOKEN, and INTEGRATION_ACCOUNT to run the integration tests")
+	}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	api := canvas.NewAPI(logger, token, baseURL, 700, 60)
+	account, err := api.Accounts().GetAccount(accountID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if account.ParentAccountID == nil {
+		t.Fatalf("account %d (%s) is a root account; use a dedicated sandbox sub-account", accountID, account.Name)
+	}
+	return api, account
+}
+
+// TestIntegration creates a fixture course and exercises the services in it. The subtests share
+// the course and run in order.
+func TestIntegration(t *testing.T) {
+	api, account := sandbox(t)
+	runID := time.Now().Format("20060102-150405")
+	name := integrationPrefix + " " + runID
+	code := "CCTA-IT-" + runID
+	course, err := api.Courses().CreateCourse(account.ID, canvas.CourseUpdate{Name: &name, CourseCode: &code})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if err := api.Courses().DeleteCourse(course.ID, canvas.CourseDelete); err != nil {
+			t.Errorf("deleting fixture course %d: %v", course.ID, err)
+		}
+	})
+	if course.Name != name || course.WorkflowState != "unpublished" {
+		t.Fatalf("created course %d is %q (%s), want %q (unpublished)", course.ID, course.Name, course.WorkflowState, name)
+	}
+	t.Logf("fixture course %d in %s (account %d)", course.ID, account.Name, account.ID)
+
+	t.Run("get_course", func(t *testing.T) {
+		got, err := api.Courses().GetCourse(course.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Name != name || got.AccountID != account.ID {
+			t.Errorf("got %q in account %d, want %q in account %d", got.Name, got.AccountID, name, account.ID)
+		}
+	})
+
+	t.Run("update_course", func(t *testing.T) {
+		updated, view := name+" (updated)", "modules"
+		if _, err := api.Courses().UpdateCourse(course.ID, canvas.CourseUpdate{Name: &updated, DefaultView: &view}); err != nil {
+			t.Fatal(err)
+		}
+		got, err := api.Courses().GetCourse(course.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Name != updated || got.DefaultView != view {
+			t.Errorf("after the update the course is %q with default view %s, want %q with %s", got.Name, got.DefaultView, updated, view)
+		}
+	})
+
+	t.Run("list_courses", func(t *testing.T) {
+		courses, err := api.Courses().ListAccountCourses(account.ID, canvas.CourseListOptions{SearchTerm: runID})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !slices.ContainsFunc(courses, func(c canvas.Course) bool { return c.ID == course.ID }) {
+			t.Errorf("course %d is not among the %d courses found by search_term %s", course.ID, len(courses), runID)
+		}
+	})
+
+	t.Run("sections", func(t *testing.T) {
+		if _, err := api.Sections().ListCourseSections(course.ID); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("modules", func(t *testing.T) {
+		modules, err := api.Modules().ListModules(course.ID, "items")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(modules) > 0 {
+			t.Errorf("a new course has %d modules, want none", len(modules))
+		}
+	})
+
+	t.Run("pages", func(t *testing.T) {
+		pages := api.Pages()
+		title, body := "Integration page", "<p>created</p>"
+		page, err := pages.CreatePage(course.ID, canvas.WikiPageInput{Title: &title, Body: &body})
+		if err != nil {
+			t.Fatal(err)
+		}
+		updated := "<p>updated</p>"
+		if _, err := pages.UpdatePage(course.ID, page.URL, canvas.WikiPageInput{Body: &updated}); err != nil {
+			t.Fatal(err)
+		}
+		got, err := pages.GetPage(course.ID, page.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Body != updated {
+			t.Errorf("page %s has body %q after the update, want %q", page.URL, got.Body, updated)
+		}
+		if err := pages.DeletePage(course.ID, page.URL); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := pages.GetPage(course.ID, page.URL); !errors.Is(err, canvas.ErrNotFound) {
+			t.Errorf("fetching deleted page %s gave %v, want not found", page.URL, err)
+		}
+	})
+
+	// Lists pages one per request to check that every Link header Canvas sends is followed.
+	t.Run("pagination", func(t *testing.T) {
+		const count = 3
+		for i := 1; i <= count; i++ {
+			title := fmt.Sprintf("Pagination page %d", i)
+			if _, err := api.Pages().CreatePage(course.ID, canvas.WikiPageInput{Title: &title}); err != nil {
+				t.Fatal(err)
+			}
+		}
+		items, err := api.GetAllPages(fmt.Sprintf("courses/%d/pages", course.ID), canvas.WithPerPage(1))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(items) != count {
+			t.Errorf("listed %d pages one per request, want %d", len(items), count)
+		}
+	})
+
+	t.Run("assignments", func(t *testing.T) {
+		assignments := api.Assignments()
+		name, points, published := "Integration assignment", 10.0, false
+		assignment, err := assignments.CreateAssignment(course.ID, canvas.AssignmentInput{Name: &name, PointsPossible: &points, Published: &published})
+		if err != nil {
+			t.Fatal(err)
+		}
+		points = 5
+		if _, err := assignments.UpdateAssignment(course.ID, assignment.ID, canvas.AssignmentInput{PointsPossible: &points}); err != nil {
+			t.Fatal(err)
+		}
+		got, err := assignments.GetAssignment(course.ID, assignment.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.PointsPossible != points || got.Published {
+			t.Errorf("assignment %d has %g points (published %t), want %g unpublished", got.ID, got.PointsPossible, got.Published, points)
+		}
+		list, err := assignments.ListAssignments(course.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(list) != 1 || list[0].ID != assignment.ID {
+			t.Errorf("listed %d assignments, want only %d", len(list), assignment.ID)
+		}
+		if err := assignments.DeleteAssignment(course.ID, assignment.ID); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("discussions", func(t *testing.T) {
+		discussions := api.Discussions()
+		title, message := "Integration discussion", "<p>hello</p>"
+		topic, err := discussions.CreateTopic(course.ID, canvas.DiscussionTopicInput{Title: &title, Message: &message})
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := discussions.GetTopic(course.ID, topic.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Title != title {
+			t.Errorf("topic %d is titled %q, want %q", topic.ID, got.Title, title)
+		}
+		if err := discussions.DeleteTopic(course.ID, topic.ID); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("calendar", func(t *testing.T) {
+		calendar := api.Calendar()
+		start := time.Now().Add(24 * time.Hour).Truncate(time.Hour)
+		end := start.Add(time.Hour)
+		event, err := calendar.CreateEvent(canvas.CalendarEventInput{
+			ContextCode: fmt.Sprintf("course_%d", course.ID),
+			Title:       "Integration event",
+			StartAt:     &start,
+			EndAt:       &end,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := calendar.GetEvent(event.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.StartAt == nil || !got.StartAt.Equal(start) {
+			t.Errorf("event %d starts at %v, want %s", event.ID, got.StartAt, start.Format(time.RFC3339))
+		}
+		if err := calendar.DeleteEvent(event.ID, ""); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
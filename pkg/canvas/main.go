@@ -1,23 +1,49 @@
 package canvas
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
 	"fmt"
+	"io"
 	"log/slog"
-	"math/rand"
 	"net/http"
-	"strconv"
+	"sync"
 	"time"
 )
 
+// APIManager is safe for concurrent use by multiple goroutines.
 type APIManager struct {
-	client                *http.Client
-	logger                *slog.Logger
-	maxRateLimit          int
-	rateLimitRemaining    float64
-	averageRateCost       float64
-	requestSendCount      int
-	responseReceivedCount int
-	config                APIConfig
+	mu           sync.RWMutex // guards retry, reqLog, metrics, dryRun, config.Token, fallbacks, failovers, features, cache, asUser, limiter, and breaker
+	client       *http.Client
+	logger       *slog.Logger
+	rate         *RateTracker
+	retry        RetryPolicy
+	reqLog       *requestLog
+	metrics      *MetricsStore
+	dryRun       bool
+	config       APIConfig
+	fallbacks    []string
+	failovers    int
+	deprecations deprecationTracker
+	features     func(feature string) bool
+	cache        *responseCache
+	asUser       string
+	limiter      RateLimiter
+	breaker      *circuitBreaker
+	httpStats    *httpMetrics
+}
+
+type APIStats struct {
+	RequestsSent       int           `json:"requests_sent"`
+	ResponsesReceived  int           `json:"responses_received"`
+	RateLimitRemaining float64       `json:"rate_limit_remaining"`
+	AverageRateCost    float64       `json:"average_rate_cost"`
+	ThrottleCount      int           `json:"throttle_count"`
+	ThrottleTime       time.Duration `json:"throttle_time_ns"`
+	TokenFailovers     int           `json:"token_failovers,omitempty"`
+	CacheHits          int           `json:"cache_hits,omitempty"` // 304s answered from the response cache
+	CircuitOpens       int           `json:"circuit_opens,omitempty"`
 }
 
 type APIConfig struct {
@@ -42,115 +68,271 @@ func NewAPI(
 		Token:   token,
 		BaseURL: baseURL,
 	}
+	rate := NewRateTracker(logger, rateLimitMax)
 	return &APIManager{
-		client:                client,
-		logger:                logger,
-		maxRateLimit:          rateLimitMax,
-		rateLimitRemaining:    float64(rateLimitMax),
-		averageRateCost:       0.0,
-		config:                cfg,
-		requestSendCount:      0,
-		responseReceivedCount: 0,
+		client:    client,
+		logger:    logger,
+		rate:      rate,
+		config:    cfg,
+		retry:     DefaultRetryPolicy,
+		limiter:   NewTokenBucket(rate, DefaultLeakRate),
+		breaker:   &circuitBreaker{opts: DefaultCircuitBreaker, logger: logger},
+		httpStats: newHTTPMetrics(),
 	}
 }
 
-func (api *APIManager) Get(endpoint string) (*http.Response, error) {
-	api.requestSendCount++
-	req, err := http.NewRequest("GET", api.config.BaseURL+endpoint, nil)
-	if err != nil {
-		return nil, err
+// Get fetches the endpoint. The options add query parameters and headers, see RequestOption.
+func (api *APIManager) Get(endpoint string, opts ...RequestOption) (*http.Response, error) {
+	return api.GetCtx(context.Background(), endpoint, opts...)
+}
+
+func (api *APIManager) GetCtx(ctx context.Context, endpoint string, opts ...RequestOption) (*http.Response, error) {
+	ctx, endpoint = applyOptions(ctx, endpoint, opts)
+	return api.do(ctx, http.MethodGet, endpoint, nil)
+}
+
+// Stats returns a snapshot of the request counters and rate limit state.
+func (api *APIManager) Stats() APIStats {
+	stats := api.rate.Stats()
+	api.mu.RLock()
+	stats.TokenFailovers = api.failovers
+	stats.CircuitOpens = api.breaker.opened()
+	if api.cache != nil {
+		stats.CacheHits = int(api.cache.hits.Load())
 	}
-	req.Header.Set("Authorization", "Bearer "+api.config.Token)
+	api.mu.RUnlock()
+	return stats
+}
 
-	resp, err := api.client.Do(req)
+// RateTracker returns the tracker shared by every request sent through this manager.
+func (api *APIManager) RateTracker() *RateTracker {
+	return api.rate
+}
+
+// Post sends body as JSON to the endpoint. The body should already be JSON encoded.
+func (api *APIManager) Post(endpoint string, body []byte, opts ...RequestOption) (*http.Response, error) {
+	return api.PostCtx(context.Background(), endpoint, body, opts...)
+}
+
+func (api *APIManager) PostCtx(ctx context.Context, endpoint string, body []byte, opts ...RequestOption) (*http.Response, error) {
+	ctx, endpoint = applyOptions(ctx, endpoint, opts)
+	return api.do(ctx, http.MethodPost, endpoint, body)
+}
+
+// Put sends body as JSON to the endpoint. The body should already be JSON encoded.
+func (api *APIManager) Put(endpoint string, body []byte, opts ...RequestOption) (*http.Response, error) {
+	return api.PutCtx(context.Background(), endpoint, body, opts...)
+}
+
+func (api *APIManager) PutCtx(ctx context.Context, endpoint string, body []byte, opts ...RequestOption) (*http.Response, error) {
+	ctx, endpoint = applyOptions(ctx, endpoint, opts)
+	return api.do(ctx, http.MethodPut, endpoint, body)
+}
+
+func (api *APIManager) Delete(endpoint string, opts ...RequestOption) (*http.Response, error) {
+	return api.DeleteCtx(context.Background(), endpoint, opts...)
+}
+
+func (api *APIManager) DeleteCtx(ctx context.Context, endpoint string, opts ...RequestOption) (*http.Response, error) {
+	ctx, endpoint = applyOptions(ctx, endpoint, opts)
+	return api.do(ctx, http.MethodDelete, endpoint, nil)
+}
+
+// PostJSON encodes v as JSON and posts it to the endpoint.
+func (api *APIManager) PostJSON(endpoint string, v any, opts ...RequestOption) (*http.Response, error) {
+	return api.PostJSONCtx(context.Background(), endpoint, v, opts...)
+}
+
+func (api *APIManager) PostJSONCtx(ctx context.Context, endpoint string, v any, opts ...RequestOption) (*http.Response, error) {
+	body, err := json.Marshal(v)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("error encoding request body: %w", err)
 	}
+	return api.PostCtx(ctx, endpoint, body, opts...)
+}
 
-	api.responseReceivedCount++
-	if err := api.checkRateLimit(resp); err != nil {
-		api.logger.Error("error checking rate limit", "error", err)
-		return nil, err
-	}
-	return resp, nil
+// PutJSON encodes v as JSON and puts it to the endpoint.
+func (api *APIManager) PutJSON(endpoint string, v any, opts ...RequestOption) (*http.Response, error) {
+	return api.PutJSONCtx(context.Background(), endpoint, v, opts...)
 }
 
-func (api *APIManager) Post(endpoint string, body []byte) (*http.Response, error) {
-	return nil, fmt.Errorf("post method not implemented yet")
+func (api *APIManager) PutJSONCtx(ctx context.Context, endpoint string, v any, opts ...RequestOption) (*http.Response, error) {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("error encoding request body: %w", err)
+	}
+	return api.PutCtx(ctx, endpoint, body, opts...)
 }
 
-func (api *APIManager) Put(endpoint string, body []byte) (*http.Response, error) {
-	return nil, fmt.Errorf("put method not implemented yet")
+// do sends a request with the Authorization header and runs the same rate limit tracking for every method.
+// The outcome is written to the request log, if one is set. In dry-run mode writes are not sent.
+// Masqueraded requests are also logged at Info level, for the audit trail.
+func (api *APIManager) do(ctx context.Context, method, endpoint string, body []byte) (*http.Response, error) {
+	return api.doContent(ctx, method, endpoint, "application/json", body)
 }
 
-func (api *APIManager) Delete(endpoint string) (*http.Response, error) {
-	return nil, fmt.Errorf("delete method not implemented yet")
+// doContent is do for a body that isn't JSON, such as a multipart file upload.
+func (api *APIManager) doContent(ctx context.Context, method, endpoint, contentType string, body []byte) (*http.Response, error) {
+	start := time.Now()
+	asUser := api.asUserID(ctx)
+	if asUser != "" {
+		endpoint = masquerade(endpoint, asUser)
+	}
+	if _, err := api.resolve(endpoint); err != nil { // checked before dry-run too, so a dry run catches it
+		api.logRequest(start, method, endpoint, body, nil, err)
+		return nil, err
+	}
+	if method != http.MethodGet && api.DryRun() && !api.isGraphQLQuery(endpoint, body) {
+		resp := api.dryRunResponse(method, endpoint, body)
+		api.logRequest(start, method, endpoint, body, resp, nil)
+		api.logMasquerade(asUser, method, endpoint, resp, nil)
+		return resp, nil
+	}
+	resp, err := api.doRetry(ctx, method, endpoint, contentType, body)
+	api.logRequest(start, method, endpoint, body, resp, err)
+	api.logMasquerade(asUser, method, endpoint, resp, err)
+	return resp, err
 }
 
-func debugHeaders(headers http.Header) {
-	fmt.Println("DEBUG: Headers:")
-	for key, values := range headers {
-		fmt.Printf("  %s: %s\n", key, values)
+// doRetry retries transient failures (429, 500, 502, 503, timeouts) according to the retry policy.
+func (api *APIManager) doRetry(ctx context.Context, method, endpoint, contentType string, body []byte) (*http.Response, error) {
+	policy := api.retryPolicy(ctx)
+	for attempt := 1; ; attempt++ {
+		resp, err := api.send(ctx, method, endpoint, contentType, body)
+		lastAttempt := attempt >= policy.MaxAttempts || ctx.Err() != nil
+		if err != nil {
+			if lastAttempt || !retryableError(err) || method == http.MethodPost {
+				return nil, err
+			}
+			delay := policy.backoff(attempt)
+			api.logger.Warn("request timed out, retrying", "method", method, "endpoint", endpoint, "attempt", attempt, "delay", delay)
+			if err := sleepCtx(ctx, delay); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if lastAttempt || !retryableStatus(resp.StatusCode) || (method == http.MethodPost && !safeToRepost(resp.StatusCode)) {
+			return resp, nil
+		}
+		delay, ok := retryAfter(resp)
+		if !ok {
+			if resp.StatusCode == http.StatusTooManyRequests && api.rateLimiter() != nil {
+				delay = 0 // the rate limiter waits for the bucket to refill before the next attempt
+			} else {
+				delay = policy.backoff(attempt)
+			}
+		}
+		resp.Body.Close()
+		api.logger.Warn("transient response, retrying", "method", method, "endpoint", endpoint, "status", resp.StatusCode, "attempt", attempt, "delay", delay)
+		if err := sleepCtx(ctx, delay); err != nil {
+			return nil, err
+		}
 	}
 }
 
-func (api *APIManager) checkRateLimit(resp *http.Response) error {
-	var delay time.Duration
-	previousCost := api.averageRateCost
-	// Get Rate Limit Information
-	limit, err := strconv.ParseFloat(resp.Header.Get("X-Rate-Limit-Remaining"), 64)
+// send makes a single attempt at the request.
+func (api *APIManager) send(ctx context.Context, method, endpoint, contentType string, body []byte) (*http.Response, error) {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	target, err := api.resolve(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, method, target, reader)
+	if err != nil {
+		return nil, err
+	}
+	token := api.token()
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", contentType)
+	}
+	for key, values := range requestHeader(ctx) {
+		if key == "Authorization" || key == "Accept" {
+			continue // see WithHeader
+		}
+		req.Header[key] = values
+	}
+	cache := api.responseCache()
+	var cacheFile string
+	var cached *cacheEntry
+	if cache != nil && method == http.MethodGet {
+		cacheFile = cache.file(token, req.URL.String())
+		if cached = cache.load(cacheFile); cached != nil {
+			req.Header.Set("If-None-Match", cached.ETag)
+		}
+	}
+
+	breaker := api.circuitBreaker()
+	probe, err := breaker.allow()
 	if err != nil {
-		api.logger.Error("failed to parse RateLimit-Remaining header", "error", err, "HeaderData", resp.Header.Get("RateLimit-Remaining"))
-		debugHeaders(resp.Header)
-		limit = float64(api.maxRateLimit / 2) // set to 50% since we do not know the actual limit
+		return nil, err
 	}
-	cost, err := strconv.ParseFloat(resp.Header.Get("X-Request-Cost"), 64)
+	if err := api.waitRateLimit(ctx, method, endpoint); err != nil {
+		breaker.record(ctx, probe, nil, err)
+		return nil, err
+	}
+	api.rate.RequestSent()
+	start := time.Now()
+	resp, err := api.client.Do(req)
+	api.httpStats.observe(method, resp, err, time.Since(start))
+	breaker.record(ctx, probe, resp, err)
 	if err != nil {
-		api.logger.Error("failed to parse Request-Cost header", "error", err, "HeaderData", resp.Header.Get("Request-Cost"))
-		cost = api.averageRateCost // use the average rate cost if we cannot parse the header
-	}
-	if limit < float64(api.maxRateLimit) {
-		api.rateLimitRemaining = limit
-	} else {
-		api.rateLimitRemaining = float64(api.maxRateLimit)
-	}
-	if cost > 0 {
-		api.averageRateCost = api.averageRateCost + (cost-api.averageRateCost)/float64(api.requestSendCount)
-	} else {
-		api.logger.Warn("Request Cost is zero, cannot update average rate cost", "cost", cost)
-	}
-	// Plan Allowance for Rate Limit to Recharge and avoid hitting the limit
-	if api.rateLimitRemaining <= float64(api.maxRateLimit)*0.25 {
-		api.logger.Warn("Rate Limit is Extremely Low!! Under 25% of limit", "remaining", api.rateLimitRemaining)
-		delay = time.Duration(api.maxRateLimit) * time.Second / 2 // Sleep for half the value of the max rate limit
-	} else if api.rateLimitRemaining <= float64(api.maxRateLimit)*0.5 {
-		api.logger.Warn("Rate Limit is Low Below 50%", "remaining", api.rateLimitRemaining)
-		delay = time.Duration(api.maxRateLimit) * time.Second / 4 // Sleep for a quarter of the value of the max rate limit
-	} else if api.rateLimitRemaining <= float64(api.maxRateLimit)*0.75 {
-		api.logger.Info("Rate Limit is moderate between 50% and 75%", "remaining", api.rateLimitRemaining)
-		delay = time.Duration(api.maxRateLimit) * time.Second / 8 // Sleep for an eighth of the value of the max rate limit
-	} else {
-		api.logger.Info("Rate Limit is healthy above 75%", "remaining", api.rateLimitRemaining)
-	}
-	if resp.StatusCode == http.StatusTooManyRequests {
-		api.logger.Warn("Rate limit exceeded", "remaining", resp.Header.Get("X-RateLimit-Remaining"))
-		delay = time.Duration(5) * time.Minute // Sleep for 5 minutes if rate limit is exceeded
-	}
-
-	// Extra Check if cost of last request is more than 20% higher than the average cost pause for 30 seconds
-	if previousCost > 0 && cost > previousCost*1.2 {
-		if api.rateLimitRemaining >= float64(api.maxRateLimit)*0.95 {
-			// Remaining limit is high, so we can skip the delay
-		} else {
-			api.logger.Warn("Request cost is a significant increase from previous requests, adding an extra delay", "previousAverageCost", previousCost, "currentCost", cost)
-			delay = 30 * time.Second // Sleep for 30 seconds if the cost is significantly higher
+		return nil, err
+	}
+
+	if ms := api.metricsStore(); ms != nil {
+		ms.recordResponse(method, endpoint, resp)
+	}
+	api.rate.Observe(resp)
+	if limiter := api.rateLimiter(); limiter != nil {
+		limiter.Observe(resp)
+	}
+	if resp.StatusCode == http.StatusUnauthorized && api.failover(token, resp) {
+		resp.Body.Close()
+		return api.send(ctx, method, endpoint, contentType, body) // Canvas didn't act on the request, so it's safe to resend
+	}
+	if cacheFile != "" {
+		// after a 304 the cached response, with its Deprecation and Sunset headers, stands in for it
+		if resp, err = cache.update(cacheFile, endpoint, cached, resp); err != nil {
+			return nil, err
 		}
 	}
-	if delay > 0 {
-		jitter := time.Duration(rand.Int63n(int64(delay)/4)) * time.Millisecond // Add jitter to the delay
-		api.logger.Info("Delaying request due to rate limit or cost increase", "delay", delay)
-		time.Sleep(delay + jitter) // Add jitter to make sure every delay is slightly different from the others
-		api.logger.Info("Resuming after delay", "delay", delay+jitter)
+	api.recordDeprecation(method, endpoint, resp)
+	return resp, nil
+}
+
+// resolve returns the URL for an endpoint relative to the base URL. Absolute URLs, such as the
+// GraphQL endpoint, are used as they are.
+func (api *APIManager) resolve(endpoint string) (string, error) {
+	return buildURL(api.config.BaseURL, endpoint)
+}
+
+// download fetches a file, such as a report attachment, with the token. The file is usually
+// served from a storage host after a redirect; those responses have no rate limit headers, so
+// they aren't rate tracked. The caller closes the body.
+func (api *APIManager) download(ctx context.Context, fileURL string) (*http.Response, error) {
+	start := time.Now()
+	target, err := api.resolve(fileURL)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+api.token()) // not forwarded when redirected to another host
+	resp, err := api.client.Do(req)
+	api.logRequest(start, http.MethodGet, fileURL, nil, resp, err)
+	if err != nil {
+		return nil, err
 	}
-	return nil
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		return nil, NewAPIError(resp)
+	}
+	return resp, nil
 }
@@ -0,0 +1,188 @@
+package canvas
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxMetricsRuns is how many run summaries the metrics store keeps for trend reports.
+const maxMetricsRuns = 120
+
+// EndpointMetrics are the totals for one endpoint pattern, e.g. "GET courses/:id/modules".
+type EndpointMetrics struct {
+	Requests    int     `json:"requests"`
+	Errors      int     `json:"errors"` // non-2xx responses
+	TotalCost   float64 `json:"total_cost"`
+	CostSamples int     `json:"cost_samples"`
+	Throttles   int     `json:"throttles"`
+}
+
+func (em EndpointMetrics) AverageCost() float64 {
+	if em.CostSamples == 0 {
+		return 0
+	}
+	return em.TotalCost / float64(em.CostSamples)
+}
+
+// MetricsRun summarizes the API use of one run.
+type MetricsRun struct {
+	StartedAt    time.Time     `json:"started_at"`
+	FinishedAt   time.Time     `json:"finished_at"`
+	Command      string        `json:"command,omitempty"`
+	Requests     int           `json:"requests"`
+	AverageCost  float64       `json:"average_cost"`
+	Throttles    int           `json:"throttles"`
+	ThrottleTime time.Duration `json:"throttle_time_ns"`
+}
+
+// MetricsStore persists request metrics across runs in a JSON file. It is safe for concurrent use.
+type MetricsStore struct {
+	mu          sync.Mutex
+	path        string
+	started     time.Time
+	Endpoints   map[string]*EndpointMetrics `json:"endpoints"`
+	AverageCost float64                     `json:"average_cost"`
+	CostSamples int                         `json:"cost_samples"`
+	Runs        []MetricsRun                `json:"runs"`
+}
+
+// LoadMetricsStore reads the store at path. A missing file gives an empty store that is created on Save.
+func LoadMetricsStore(path string) (*MetricsStore, error) {
+	ms := &MetricsStore{path: path, started: time.Now(), Endpoints: make(map[string]*EndpointMetrics)}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return ms, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading metrics store %s: %w", path, err)
+	}
+	if err := json.Unmarshal(data, ms); err != nil {
+		return nil, fmt.Errorf("error decoding metrics store %s: %w", path, err)
+	}
+	if ms.Endpoints == nil {
+		ms.Endpoints = make(map[string]*EndpointMetrics)
+	}
+	return ms, nil
+}
+
+// SetMetricsStore records every request in ms and seeds the average cost estimate from earlier runs.
+func (api *APIManager) SetMetricsStore(ms *MetricsStore) {
+	ms.mu.Lock()
+	api.rate.SeedAverageCost(ms.AverageCost, ms.CostSamples)
+	ms.mu.Unlock()
+	api.mu.Lock()
+	api.metrics = ms
+	api.mu.Unlock()
+}
+
+func (api *APIManager) metricsStore() *MetricsStore {
+	api.mu.RLock()
+	defer api.mu.RUnlock()
+	return api.metrics
+}
+
+var idSegment = regexp.MustCompile(`^(\d+|sis_[a-z_]+:.+|self)$`)
+
+// endpointPattern turns "courses/123/modules?per_page=100" into "courses/:id/modules".
+func endpointPattern(endpoint string) string {
+	endpoint, _, _ = strings.Cut(endpoint, "?")
+	if _, rest, ok := strings.Cut(endpoint, "://"); ok {
+		_, endpoint, _ = strings.Cut(rest, "/") // absolute URLs such as the GraphQL endpoint
+	}
+	parts := strings.Split(strings.Trim(endpoint, "/"), "/")
+	for i, part := range parts {
+		if idSegment.MatchString(part) {
+			parts[i] = ":id"
+		}
+	}
+	return strings.Join(parts, "/")
+}
+
+func (ms *MetricsStore) endpoint(method, endpoint string) *EndpointMetrics {
+	key := method + " " + endpointPattern(endpoint)
+	em, ok := ms.Endpoints[key]
+	if !ok {
+		em = &EndpointMetrics{}
+		ms.Endpoints[key] = em
+	}
+	return em
+}
+
+// recordResponse counts one attempt at a request and its cost.
+func (ms *MetricsStore) recordResponse(method, endpoint string, resp *http.Response) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	em := ms.endpoint(method, endpoint)
+	em.Requests++
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		em.Errors++
+	}
+	if cost, err := strconv.ParseFloat(resp.Header.Get("X-Request-Cost"), 64); err == nil && cost > 0 {
+		em.TotalCost += cost
+		em.CostSamples++
+	}
+}
+
+func (ms *MetricsStore) recordThrottle(method, endpoint string) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	ms.endpoint(method, endpoint).Throttles++
+}
+
+// Save adds a summary of this run to the store and writes it back to its file.
+func (ms *MetricsStore) Save(command string, stats APIStats) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	if stats.AverageRateCost > 0 {
+		ms.AverageCost = stats.AverageRateCost
+		ms.CostSamples += stats.ResponsesReceived
+	}
+	ms.Runs = append(ms.Runs, MetricsRun{
+		StartedAt:    ms.started,
+		FinishedAt:   time.Now(),
+		Command:      command,
+		Requests:     stats.RequestsSent,
+		AverageCost:  stats.AverageRateCost,
+		Throttles:    stats.ThrottleCount,
+		ThrottleTime: stats.ThrottleTime,
+	})
+	if len(ms.Runs) > maxMetricsRuns {
+		ms.Runs = ms.Runs[len(ms.Runs)-maxMetricsRuns:]
+	}
+	data, err := json.MarshalIndent(ms, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error encoding metrics store: %w", err)
+	}
+	tmp := ms.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("error writing metrics store: %w", err)
+	}
+	return os.Rename(tmp, ms.path)
+}
+
+// EndpointKeys returns the recorded endpoint patterns, busiest first.
+func (ms *MetricsStore) EndpointKeys() []string {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	keys := make([]string, 0, len(ms.Endpoints))
+	for key := range ms.Endpoints {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := ms.Endpoints[keys[i]], ms.Endpoints[keys[j]]
+		if a.Requests != b.Requests {
+			return a.Requests > b.Requests
+		}
+		return keys[i] < keys[j]
+	})
+	return keys
+}
@@ -0,0 +1,91 @@
+package canvas
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+type Module struct {
+	ID                        int          `json:"id"`
+	Name                      string       `json:"name"`
+	Position                  int          `json:"position"`
+	WorkflowState             string       `json:"workflow_state"`
+	State                     string       `json:"state"` // the requesting user's progress: locked, unlocked, started, or completed
+	Published                 bool         `json:"published"`
+	UnlockAt                  *time.Time   `json:"unlock_at"`
+	RequireSequentialProgress bool         `json:"require_sequential_progress"`
+	PrerequisiteModuleIDs     []int        `json:"prerequisite_module_ids"`
+	ItemsCount                int          `json:"items_count"`
+	ItemsURL                  string       `json:"items_url"`
+	Items                     []ModuleItem `json:"items"` // only set when listed with include items
+}
+
+type ModuleItem struct {
+	ID          int    `json:"id"`
+	ModuleID    int    `json:"module_id"`
+	Position    int    `json:"position"`
+	Title       string `json:"title"`
+	Indent      int    `json:"indent"`
+	Type        string `json:"type"` // File, Page, Discussion, Assignment, Quiz, SubHeader, ExternalUrl, or ExternalTool
+	ContentID   int    `json:"content_id"`
+	PageURL     string `json:"page_url"`
+	ExternalURL string `json:"external_url"`
+	HTMLURL     string `json:"html_url"`
+	Published   bool   `json:"published"`
+}
+
+type ModulesService struct {
+	service
+}
+
+func (api *APIManager) Modules() *ModulesService {
+	return &ModulesService{service{api: api}}
+}
+
+// WithContext returns a copy of the service that sends its requests with ctx.
+func (ms *ModulesService) WithContext(ctx context.Context) *ModulesService {
+	return &ModulesService{service{api: ms.api, ctx: ctx}}
+}
+
+// ListModules returns the modules of a course in order. Pass "items" in include to get the items
+// of each module as well (Canvas leaves them out for modules with many items).
+func (ms *ModulesService) ListModules(courseID int, include ...string) ([]Module, error) {
+	query := url.Values{}
+	query.Set("per_page", perPage(0))
+	for _, inc := range include {
+		query.Add("include[]", inc)
+	}
+	var modules []Module
+	if err := ms.listJSON(withQuery(fmt.Sprintf("courses/%d/modules", courseID), query), &modules); err != nil {
+		return nil, fmt.Errorf("error listing modules for course %d: %w", courseID, err)
+	}
+	return modules, nil
+}
+
+func (ms *ModulesService) ListModuleItems(courseID, moduleID int) ([]ModuleItem, error) {
+	var items []ModuleItem
+	if err := ms.listJSON(fmt.Sprintf("courses/%d/modules/%d/items?per_page=100", courseID, moduleID), &items); err != nil {
+		return nil, fmt.Errorf("error listing items for module %d in course %d: %w", moduleID, courseID, err)
+	}
+	return items, nil
+}
+
+func (ms *ModulesService) PublishModule(courseID, moduleID int) (*Module, error) {
+	return ms.setModulePublished(courseID, moduleID, true)
+}
+
+func (ms *ModulesService) UnpublishModule(courseID, moduleID int) (*Module, error) {
+	return ms.setModulePublished(courseID, moduleID, false)
+}
+
+func (ms *ModulesService) setModulePublished(courseID, moduleID int, published bool) (*Module, error) {
+	body := map[string]any{"module": map[string]bool{"published": published}}
+	var module Module
+	if err := ms.sendJSON(http.MethodPut, fmt.Sprintf("courses/%d/modules/%d", courseID, moduleID), body, &module); err != nil {
+		return nil, fmt.Errorf("error updating module %d in course %d: %w", moduleID, courseID, err)
+	}
+	return &module, nil
+}
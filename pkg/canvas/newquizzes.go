@@ -0,0 +1,140 @@
+package canvas
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// NewQuiz is a New Quizzes quiz. Its ID is the ID of the assignment that holds it.
+type NewQuiz struct {
+	ID                json.Number      `json:"id"` // a string in the API's responses, but decoded either way
+	Title             string           `json:"title"`
+	Instructions      string           `json:"instructions"` // HTML
+	AssignmentGroupID json.Number      `json:"assignment_group_id"`
+	PointsPossible    *float64         `json:"points_possible"`
+	DueAt             *time.Time       `json:"due_at"`
+	LockAt            *time.Time       `json:"lock_at"`
+	UnlockAt          *time.Time       `json:"unlock_at"`
+	Published         bool             `json:"published"`
+	GradingType       string           `json:"grading_type"`
+	QuizSettings      *NewQuizSettings `json:"quiz_settings"`
+}
+
+// NewQuizSettings are the quiz's delivery settings that reports look at.
+type NewQuizSettings struct {
+	HasTimeLimit     bool   `json:"has_time_limit"`
+	SessionTimeLimit *int   `json:"session_time_limit_in_seconds"`
+	ShuffleQuestions bool   `json:"shuffle_questions"`
+	ShuffleAnswers   bool   `json:"shuffle_answers"`
+	OneAtATimeType   string `json:"one_at_a_time_type"` // none or question
+}
+
+// NewQuizInput holds the quiz attributes to set on create or update. Nil fields are left out.
+type NewQuizInput struct {
+	Title             *string    `json:"title,omitempty"`
+	Instructions      *string    `json:"instructions,omitempty"`
+	AssignmentGroupID *int       `json:"assignment_group_id,omitempty"`
+	PointsPossible    *float64   `json:"points_possible,omitempty"`
+	DueAt             *time.Time `json:"due_at,omitempty"`
+	LockAt            *time.Time `json:"lock_at,omitempty"`
+	UnlockAt          *time.Time `json:"unlock_at,omitempty"`
+	GradingType       *string    `json:"grading_type,omitempty"`
+}
+
+// NewQuizItem is an entry of a New Quizzes quiz: a question, a stimulus, or a bank reference.
+type NewQuizItem struct {
+	ID             string   `json:"id"`
+	Position       int      `json:"position"`
+	PointsPossible *float64 `json:"points_possible"`
+	EntryType      string   `json:"entry_type"` // Item, Stimulus, or BankEntry
+	Status         string   `json:"status"`
+	Entry          struct {
+		Title               string `json:"title"`
+		InteractionTypeSlug string `json:"interaction_type_slug"` // e.g. choice, essay, or true-false
+	} `json:"entry"`
+}
+
+// NewQuizzesService calls the New Quizzes API, which Canvas serves under /api/quiz/v1 instead of
+// /api/v1. The same token works for both.
+type NewQuizzesService struct {
+	service
+}
+
+func (api *APIManager) NewQuizzes() *NewQuizzesService {
+	return &NewQuizzesService{service{api: api}}
+}
+
+// WithContext returns a copy of the service that sends its requests with ctx.
+func (nq *NewQuizzesService) WithContext(ctx context.Context) *NewQuizzesService {
+	return &NewQuizzesService{service{api: nq.api, ctx: ctx}}
+}
+
+// endpoint returns the New Quizzes URL for a path, e.g. https://school.instructure.com/api/quiz/v1/courses/1/quizzes.
+func (nq *NewQuizzesService) endpoint(format string, args ...any) string {
+	p := fmt.Sprintf(format, args...)
+	base, err := url.Parse(nq.api.config.BaseURL)
+	if err != nil {
+		return strings.TrimSuffix(nq.api.config.BaseURL, "v1/") + "quiz/v1/" + p
+	}
+	p, query, _ := strings.Cut(p, "?")
+	base.Path = "/api/quiz/v1/" + p
+	base.RawQuery = query
+	return base.String()
+}
+
+// ListQuizzes returns a course's New Quizzes. Instances without New Quizzes answer with an APIError
+// matching ErrNotFound.
+func (nq *NewQuizzesService) ListQuizzes(courseID int) ([]NewQuiz, error) {
+	var quizzes []NewQuiz
+	if err := nq.listJSON(nq.endpoint("courses/%d/quizzes?per_page=100", courseID), &quizzes); err != nil {
+		return nil, fmt.Errorf("error listing New Quizzes for course %d: %w", courseID, err)
+	}
+	return quizzes, nil
+}
+
+func (nq *NewQuizzesService) GetQuiz(courseID int, assignmentID string) (*NewQuiz, error) {
+	var quiz NewQuiz
+	if err := nq.getJSON(nq.endpoint("courses/%d/quizzes/%s", courseID, assignmentID), &quiz); err != nil {
+		return nil, fmt.Errorf("error fetching New Quiz %s in course %d: %w", assignmentID, courseID, err)
+	}
+	return &quiz, nil
+}
+
+// CreateQuiz creates an empty New Quiz, with the assignment that holds it.
+func (nq *NewQuizzesService) CreateQuiz(courseID int, input NewQuizInput) (*NewQuiz, error) {
+	body := map[string]any{"quiz": input}
+	var quiz NewQuiz
+	if err := nq.sendJSON(http.MethodPost, nq.endpoint("courses/%d/quizzes", courseID), body, &quiz); err != nil {
+		return nil, fmt.Errorf("error creating New Quiz in course %d: %w", courseID, err)
+	}
+	return &quiz, nil
+}
+
+// ListItems returns a quiz's items. Items drawn from an item bank are one BankEntry each.
+func (nq *NewQuizzesService) ListItems(courseID int, assignmentID string) ([]NewQuizItem, error) {
+	var items []NewQuizItem
+	if err := nq.listJSON(nq.endpoint("courses/%d/quizzes/%s/items?per_page=100", courseID, assignmentID), &items); err != nil {
+		return nil, fmt.Errorf("error listing items of New Quiz %s in course %d: %w", assignmentID, courseID, err)
+	}
+	return items, nil
+}
+
+// QuestionCount returns how many questions a quiz has: its items other than stimuli.
+func (nq *NewQuizzesService) QuestionCount(courseID int, assignmentID string) (int, error) {
+	items, err := nq.ListItems(courseID, assignmentID)
+	if err != nil {
+		return 0, err
+	}
+	var n int
+	for _, item := range items {
+		if item.EntryType != "Stimulus" {
+			n++
+		}
+	}
+	return n, nil
+}
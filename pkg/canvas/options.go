@@ -0,0 +1,89 @@
+package canvas
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// RequestOption adds query parameters or headers to a single request, e.g.
+//
+//	api.Get("courses/1", canvas.WithInclude("term", "teachers"))
+//
+// instead of building the query into the endpoint by hand.
+type RequestOption func(*requestOptions)
+
+type requestOptions struct {
+	query  url.Values
+	header http.Header
+}
+
+// WithParam adds the values of a query parameter. Array parameters keep their brackets, e.g.
+// WithParam("enrollment_type[]", "teacher", "ta").
+func WithParam(key string, values ...string) RequestOption {
+	return func(o *requestOptions) {
+		for _, value := range values {
+			o.query.Add(key, value)
+		}
+	}
+}
+
+// WithParams adds every value in query.
+func WithParams(query url.Values) RequestOption {
+	return func(o *requestOptions) {
+		for key, values := range query {
+			o.query[key] = append(o.query[key], values...)
+		}
+	}
+}
+
+// WithInclude asks for associations the endpoint leaves out by default, sent as include[].
+func WithInclude(values ...string) RequestOption {
+	return WithParam("include[]", values...)
+}
+
+// WithPerPage sets the page size of a list endpoint. Canvas caps it at 100 for most endpoints.
+func WithPerPage(n int) RequestOption {
+	return func(o *requestOptions) {
+		o.query.Set("per_page", strconv.Itoa(n))
+	}
+}
+
+// WithHeader sets a request header. Authorization and Accept are always set by the APIManager.
+func WithHeader(key, value string) RequestOption {
+	return func(o *requestOptions) {
+		o.header.Set(key, value)
+	}
+}
+
+type requestHeaderKey struct{}
+
+// applyOptions adds the options' query to the endpoint and carries their headers in the
+// context, so they are sent with every attempt and every page of a list.
+func applyOptions(ctx context.Context, endpoint string, opts []RequestOption) (context.Context, string) {
+	if len(opts) == 0 {
+		return ctx, endpoint
+	}
+	o := requestOptions{query: url.Values{}, header: http.Header{}}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if len(o.header) > 0 {
+		if prev, ok := ctx.Value(requestHeaderKey{}).(http.Header); ok {
+			for key, values := range prev {
+				if _, ok := o.header[key]; !ok {
+					o.header[key] = values
+				}
+			}
+		}
+		ctx = context.WithValue(ctx, requestHeaderKey{}, o.header)
+	}
+	return ctx, withQuery(endpoint, o.query)
+}
+
+// requestHeader returns the headers set with WithHeader for requests sent with ctx.
+func requestHeader(ctx context.Context) http.Header {
+	header, _ := ctx.Value(requestHeaderKey{}).(http.Header)
+	return header
+}
@@ -0,0 +1,247 @@
+package canvas
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"time"
+)
+
+// CourseContext and AccountContext name the course or account that owns the outcome groups and imports
+// an OutcomesService call works on, e.g. CourseContext(123) is "courses/123".
+func CourseContext(id int) string  { return fmt.Sprintf("courses/%d", id) }
+func AccountContext(id int) string { return fmt.Sprintf("accounts/%d", id) }
+
+// OutcomeGroup is a folder of outcomes. Every course and account has a root group.
+type OutcomeGroup struct {
+	ID                 int    `json:"id"`
+	Title              string `json:"title"`
+	Description        string `json:"description"`
+	VendorGUID         string `json:"vendor_guid"`
+	ContextID          int    `json:"context_id"`
+	ContextType        string `json:"context_type"` // Course or Account; empty for global groups
+	ParentOutcomeGroup *struct {
+		ID    int    `json:"id"`
+		Title string `json:"title"`
+	} `json:"parent_outcome_group"`
+	CanEdit bool `json:"can_edit"`
+}
+
+// Outcome is a learning outcome with its mastery scale.
+type Outcome struct {
+	ID                int             `json:"id"`
+	Title             string          `json:"title"`
+	DisplayName       string          `json:"display_name"`
+	Description       string          `json:"description"` // HTML
+	VendorGUID        string          `json:"vendor_guid"`
+	ContextID         int             `json:"context_id"`
+	ContextType       string          `json:"context_type"`
+	MasteryPoints     float64         `json:"mastery_points"`
+	PointsPossible    float64         `json:"points_possible"`
+	CalculationMethod string          `json:"calculation_method"` // decaying_average, n_mastery, latest, highest, or average
+	CalculationInt    *int            `json:"calculation_int"`
+	Ratings           []OutcomeRating `json:"ratings"`
+	CanEdit           bool            `json:"can_edit"`
+}
+
+type OutcomeRating struct {
+	Description string  `json:"description"`
+	Points      float64 `json:"points"`
+}
+
+// OutcomeLink places an outcome in a group. An outcome can be linked into many groups and contexts.
+type OutcomeLink struct {
+	ContextID    int          `json:"context_id"`
+	ContextType  string       `json:"context_type"`
+	OutcomeGroup OutcomeGroup `json:"outcome_group"`
+	Outcome      Outcome      `json:"outcome"`
+	Assessed     bool         `json:"assessed"`
+}
+
+// OutcomeInput holds the attributes of a new outcome. Empty fields are left out.
+type OutcomeInput struct {
+	Title             string          `json:"title"`
+	DisplayName       string          `json:"display_name,omitempty"`
+	Description       string          `json:"description,omitempty"`
+	VendorGUID        string          `json:"vendor_guid,omitempty"`
+	MasteryPoints     *float64        `json:"mastery_points,omitempty"`
+	Ratings           []OutcomeRating `json:"ratings,omitempty"`
+	CalculationMethod string          `json:"calculation_method,omitempty"`
+	CalculationInt    *int            `json:"calculation_int,omitempty"`
+}
+
+// OutcomeImport is a CSV import of outcomes and groups.
+type OutcomeImport struct {
+	ID               int        `json:"id"`
+	WorkflowState    string     `json:"workflow_state"` // created, importing, succeeded, or failed
+	Progress         float64    `json:"progress"`       // percent complete
+	CreatedAt        *time.Time `json:"created_at"`
+	EndedAt          *time.Time `json:"ended_at"`
+	ProcessingErrors [][]any    `json:"processing_errors"` // pairs of CSV line number and message
+}
+
+// Done reports whether the import has finished, successfully or not.
+func (oi OutcomeImport) Done() bool {
+	return oi.WorkflowState == "succeeded" || oi.WorkflowState == "failed"
+}
+
+type OutcomesService struct {
+	service
+}
+
+func (api *APIManager) Outcomes() *OutcomesService {
+	return &OutcomesService{service{api: api}}
+}
+
+// WithContext returns a copy of the service that sends its requests with ctx.
+func (os *OutcomesService) WithContext(ctx context.Context) *OutcomesService {
+	return &OutcomesService{service{api: os.api, ctx: ctx}}
+}
+
+// GetRootGroup returns the top outcome group of a course or account.
+func (os *OutcomesService) GetRootGroup(owner string) (*OutcomeGroup, error) {
+	var group OutcomeGroup
+	if err := os.getJSON(owner+"/root_outcome_group", &group); err != nil {
+		return nil, fmt.Errorf("error fetching root outcome group of %s: %w", owner, err)
+	}
+	return &group, nil
+}
+
+// ListGroups returns every outcome group of a course or account, at any depth.
+func (os *OutcomesService) ListGroups(owner string) ([]OutcomeGroup, error) {
+	var groups []OutcomeGroup
+	if err := os.listJSON(owner+"/outcome_groups?per_page=100", &groups); err != nil {
+		return nil, fmt.Errorf("error listing outcome groups of %s: %w", owner, err)
+	}
+	return groups, nil
+}
+
+func (os *OutcomesService) ListSubgroups(owner string, groupID int) ([]OutcomeGroup, error) {
+	var groups []OutcomeGroup
+	if err := os.listJSON(fmt.Sprintf("%s/outcome_groups/%d/subgroups?per_page=100", owner, groupID), &groups); err != nil {
+		return nil, fmt.Errorf("error listing subgroups of outcome group %d in %s: %w", groupID, owner, err)
+	}
+	return groups, nil
+}
+
+// ListGroupOutcomes returns the outcomes linked directly into a group. The outcomes are abbreviated;
+// use GetOutcome for the mastery scale.
+func (os *OutcomesService) ListGroupOutcomes(owner string, groupID int) ([]OutcomeLink, error) {
+	var links []OutcomeLink
+	if err := os.listJSON(fmt.Sprintf("%s/outcome_groups/%d/outcomes?per_page=100", owner, groupID), &links); err != nil {
+		return nil, fmt.Errorf("error listing outcomes of group %d in %s: %w", groupID, owner, err)
+	}
+	return links, nil
+}
+
+// ListLinks returns every outcome link of a course or account, which is every outcome it uses.
+func (os *OutcomesService) ListLinks(owner string) ([]OutcomeLink, error) {
+	var links []OutcomeLink
+	if err := os.listJSON(owner+"/outcome_group_links?per_page=100", &links); err != nil {
+		return nil, fmt.Errorf("error listing outcome links of %s: %w", owner, err)
+	}
+	return links, nil
+}
+
+func (os *OutcomesService) GetOutcome(id int) (*Outcome, error) {
+	var outcome Outcome
+	if err := os.getJSON(fmt.Sprintf("outcomes/%d", id), &outcome); err != nil {
+		return nil, fmt.Errorf("error fetching outcome %d: %w", id, err)
+	}
+	return &outcome, nil
+}
+
+// CreateOutcome creates an outcome in a group.
+func (os *OutcomesService) CreateOutcome(owner string, groupID int, input OutcomeInput) (*OutcomeLink, error) {
+	var link OutcomeLink
+	if err := os.sendJSON(http.MethodPost, fmt.Sprintf("%s/outcome_groups/%d/outcomes", owner, groupID), input, &link); err != nil {
+		return nil, fmt.Errorf("error creating outcome in group %d of %s: %w", groupID, owner, err)
+	}
+	return &link, nil
+}
+
+// LinkOutcome adds an existing outcome, such as an account outcome, to a group.
+func (os *OutcomesService) LinkOutcome(owner string, groupID, outcomeID int) (*OutcomeLink, error) {
+	var link OutcomeLink
+	if err := os.sendJSON(http.MethodPut, fmt.Sprintf("%s/outcome_groups/%d/outcomes/%d", owner, groupID, outcomeID), nil, &link); err != nil {
+		return nil, fmt.Errorf("error linking outcome %d into group %d of %s: %w", outcomeID, groupID, owner, err)
+	}
+	return &link, nil
+}
+
+// ImportGroup copies an outcome group, such as an account's or a state standards group, with its
+// outcomes and subgroups into one of owner's groups.
+func (os *OutcomesService) ImportGroup(owner string, groupID, sourceGroupID int) (*OutcomeGroup, error) {
+	body := map[string]int{"source_outcome_group_id": sourceGroupID}
+	var group OutcomeGroup
+	if err := os.sendJSON(http.MethodPost, fmt.Sprintf("%s/outcome_groups/%d/import", owner, groupID), body, &group); err != nil {
+		return nil, fmt.Errorf("error importing outcome group %d into %s: %w", sourceGroupID, owner, err)
+	}
+	return &group, nil
+}
+
+// ImportCSV uploads outcomes in Canvas' outcomes CSV format read from r. Canvas processes the file
+// in the background; wait for the result with WaitImport.
+func (os *OutcomesService) ImportCSV(owner, filename string, r io.Reader) (*OutcomeImport, error) {
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	if err := mw.WriteField("import_type", "instructure_csv"); err != nil {
+		return nil, err
+	}
+	part, err := mw.CreateFormFile("attachment", filename)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := io.Copy(part, r); err != nil {
+		return nil, fmt.Errorf("error reading %s: %w", filename, err)
+	}
+	if err := mw.Close(); err != nil {
+		return nil, err
+	}
+	endpoint := owner + "/outcome_imports"
+	resp, err := os.api.doContent(os.context(), http.MethodPost, endpoint, mw.FormDataContentType(), buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("error uploading outcomes %s: %w", filename, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, NewAPIError(resp)
+	}
+	var outcomeImport OutcomeImport
+	if err := json.NewDecoder(resp.Body).Decode(&outcomeImport); err != nil {
+		return nil, fmt.Errorf("error decoding response from %s: %w", endpoint, err)
+	}
+	return &outcomeImport, nil
+}
+
+func (os *OutcomesService) GetImport(owner string, id int) (*OutcomeImport, error) {
+	var outcomeImport OutcomeImport
+	if err := os.getJSON(fmt.Sprintf("%s/outcome_imports/%d", owner, id), &outcomeImport); err != nil {
+		return nil, fmt.Errorf("error fetching outcome import %d of %s: %w", id, owner, err)
+	}
+	return &outcomeImport, nil
+}
+
+// WaitImport polls an outcome import every interval until it finishes and returns its final state.
+// A failed import returns an error; check ProcessingErrors for the lines Canvas rejected.
+func (os *OutcomesService) WaitImport(owner string, id int, interval time.Duration) (*OutcomeImport, error) {
+	for {
+		outcomeImport, err := os.GetImport(owner, id)
+		if err != nil {
+			return nil, err
+		}
+		if outcomeImport.WorkflowState == "failed" {
+			return outcomeImport, fmt.Errorf("outcome import %d of %s failed", id, owner)
+		}
+		if outcomeImport.Done() {
+			return outcomeImport, nil
+		}
+		if err := sleepCtx(os.context(), interval); err != nil {
+			return outcomeImport, err
+		}
+	}
+}
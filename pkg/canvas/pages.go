@@ -0,0 +1,98 @@
+package canvas
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// WikiPage is a course page (a "wiki page" in the Canvas API).
+type WikiPage struct {
+	PageID       int        `json:"page_id"`
+	URL          string     `json:"url"` // the page's slug, used to address it
+	Title        string     `json:"title"`
+	Body         string     `json:"body"` // not included when listing pages
+	EditingRoles string     `json:"editing_roles"`
+	Published    bool       `json:"published"`
+	FrontPage    bool       `json:"front_page"`
+	CreatedAt    *time.Time `json:"created_at"`
+	UpdatedAt    *time.Time `json:"updated_at"`
+	HTMLURL      string     `json:"html_url"`
+}
+
+// WikiPageInput holds the page attributes to set on create or update. Nil fields are left out.
+type WikiPageInput struct {
+	Title        *string `json:"title,omitempty"`
+	Body         *string `json:"body,omitempty"`
+	EditingRoles *string `json:"editing_roles,omitempty"` // comma separated: teachers, students, members, public
+	Published    *bool   `json:"published,omitempty"`
+	FrontPage    *bool   `json:"front_page,omitempty"`
+}
+
+type PagesService struct {
+	service
+}
+
+func (api *APIManager) Pages() *PagesService {
+	return &PagesService{service{api: api}}
+}
+
+// WithContext returns a copy of the service that sends its requests with ctx.
+func (ps *PagesService) WithContext(ctx context.Context) *PagesService {
+	return &PagesService{service{api: ps.api, ctx: ctx}}
+}
+
+// GetFrontPage returns the course's front page. Courses without one return an APIError matching ErrNotFound.
+func (ps *PagesService) GetFrontPage(courseID int) (*WikiPage, error) {
+	var page WikiPage
+	if err := ps.getJSON(fmt.Sprintf("courses/%d/front_page", courseID), &page); err != nil {
+		return nil, fmt.Errorf("error fetching front page for course %d: %w", courseID, err)
+	}
+	return &page, nil
+}
+
+// ListPages returns every page in a course without their bodies.
+func (ps *PagesService) ListPages(courseID int) ([]WikiPage, error) {
+	var pages []WikiPage
+	if err := ps.listJSON(fmt.Sprintf("courses/%d/pages?per_page=100", courseID), &pages); err != nil {
+		return nil, fmt.Errorf("error listing pages for course %d: %w", courseID, err)
+	}
+	return pages, nil
+}
+
+// GetPage fetches a page by its URL slug or "page_id:<id>".
+func (ps *PagesService) GetPage(courseID int, pageURL string) (*WikiPage, error) {
+	var page WikiPage
+	if err := ps.getJSON(fmt.Sprintf("courses/%d/pages/%s", courseID, url.PathEscape(pageURL)), &page); err != nil {
+		return nil, fmt.Errorf("error fetching page %s in course %d: %w", pageURL, courseID, err)
+	}
+	return &page, nil
+}
+
+func (ps *PagesService) CreatePage(courseID int, input WikiPageInput) (*WikiPage, error) {
+	body := map[string]any{"wiki_page": input}
+	var page WikiPage
+	if err := ps.sendJSON(http.MethodPost, fmt.Sprintf("courses/%d/pages", courseID), body, &page); err != nil {
+		return nil, fmt.Errorf("error creating page in course %d: %w", courseID, err)
+	}
+	return &page, nil
+}
+
+func (ps *PagesService) UpdatePage(courseID int, pageURL string, input WikiPageInput) (*WikiPage, error) {
+	body := map[string]any{"wiki_page": input}
+	var page WikiPage
+	if err := ps.sendJSON(http.MethodPut, fmt.Sprintf("courses/%d/pages/%s", courseID, url.PathEscape(pageURL)), body, &page); err != nil {
+		return nil, fmt.Errorf("error updating page %s in course %d: %w", pageURL, courseID, err)
+	}
+	return &page, nil
+}
+
+// DeletePage deletes a page. Canvas refuses to delete the front page; unset it first.
+func (ps *PagesService) DeletePage(courseID int, pageURL string) error {
+	if err := ps.sendJSON(http.MethodDelete, fmt.Sprintf("courses/%d/pages/%s", courseID, url.PathEscape(pageURL)), nil, nil); err != nil {
+		return fmt.Errorf("error deleting page %s in course %d: %w", pageURL, courseID, err)
+	}
+	return nil
+}
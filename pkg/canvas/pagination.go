@@ -0,0 +1,133 @@
+package canvas
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+type Page struct {
+	Number int
+	Items  []json.RawMessage
+	Err    error
+}
+
+// GetAllPages follows the rel="next" Link headers starting at endpoint and returns every item from every page.
+// The options apply to the first request; Canvas keeps the query in the next page links.
+func (api *APIManager) GetAllPages(endpoint string, opts ...RequestOption) ([]json.RawMessage, error) {
+	return api.GetAllPagesCtx(context.Background(), endpoint, opts...)
+}
+
+func (api *APIManager) GetAllPagesCtx(ctx context.Context, endpoint string, opts ...RequestOption) ([]json.RawMessage, error) {
+	var items []json.RawMessage
+	for page := range api.StreamPagesCtx(ctx, endpoint, opts...) {
+		if page.Err != nil {
+			return nil, page.Err
+		}
+		items = append(items, page.Items...)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err // stream stopped early because the context was cancelled
+	}
+	return items, nil
+}
+
+// StreamPages fetches pages in the background and sends each one on the returned channel.
+// The channel is closed after the last page or after the first page that fails.
+func (api *APIManager) StreamPages(endpoint string, opts ...RequestOption) <-chan Page {
+	return api.StreamPagesCtx(context.Background(), endpoint, opts...)
+}
+
+// StreamPagesCtx is StreamPages with a context. Cancelling ctx stops the fetching and closes the channel,
+// so callers that stop reading early should cancel it.
+func (api *APIManager) StreamPagesCtx(ctx context.Context, endpoint string, opts ...RequestOption) <-chan Page {
+	ctx, endpoint = applyOptions(ctx, endpoint, opts)
+	pages := make(chan Page)
+	go func() {
+		defer close(pages)
+		number := 1
+		for endpoint != "" {
+			items, next, err := api.getPage(ctx, endpoint)
+			page := Page{Number: number, Items: items, Err: err}
+			select {
+			case pages <- page:
+			case <-ctx.Done():
+				return
+			}
+			if err != nil {
+				return
+			}
+			endpoint = next
+			number++
+		}
+	}()
+	return pages
+}
+
+func (api *APIManager) getPage(ctx context.Context, endpoint string) ([]json.RawMessage, string, error) {
+	resp, err := api.GetCtx(ctx, endpoint)
+	if err != nil {
+		return nil, "", fmt.Errorf("error fetching %s: %w", endpoint, err)
+	}
+	defer resp.Body.Close() // Ensure the response body is closed after reading
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", NewAPIError(resp)
+	}
+	var raw json.RawMessage
+	if err := json.NewDecoder(resp.Body).Decode(&raw); err != nil {
+		return nil, "", fmt.Errorf("error decoding page from %s: %w", endpoint, err)
+	}
+	items, err := pageItems(raw)
+	if err != nil {
+		return nil, "", fmt.Errorf("error decoding page from %s: %w", endpoint, err)
+	}
+	next, err := api.nextLink(resp.Header)
+	if err != nil {
+		return nil, "", fmt.Errorf("error paging %s: %w", endpoint, err)
+	}
+	return items, next, nil
+}
+
+// nextLink returns the rel="next" endpoint from the Link header with the base URL removed, or "" on the last page.
+// A link to another scheme or host is an error: following it would send the token there.
+func (api *APIManager) nextLink(headers http.Header) (string, error) {
+	for _, part := range strings.Split(headers.Get("Link"), ",") {
+		if !strings.Contains(part, `rel="next"`) {
+			continue
+		}
+		link := strings.Trim(strings.Split(part, ";")[0], " <>")
+		u, err := url.Parse(link)
+		if err != nil {
+			return "", fmt.Errorf("invalid next page link %q: %w", link, err)
+		}
+		if u.Host != "" {
+			base, err := url.Parse(api.config.BaseURL)
+			if err != nil || !strings.EqualFold(u.Scheme, base.Scheme) || !strings.EqualFold(u.Host, base.Host) {
+				return "", fmt.Errorf("next page link %q is not on the API base URL %s", link, api.config.BaseURL)
+			}
+		}
+		ep, _ := strings.CutPrefix(link, api.config.BaseURL) // Remove base URL from the link
+		return ep, nil
+	}
+	return "", nil
+}
+
+// pageItems returns the items of a page. A few Canvas list endpoints wrap the list in an object
+// with a single key, e.g. {"enrollment_terms": [...]}, which is unwrapped.
+func pageItems(raw json.RawMessage) ([]json.RawMessage, error) {
+	var items []json.RawMessage
+	if err := json.Unmarshal(raw, &items); err == nil {
+		return items, nil
+	}
+	var wrapped map[string][]json.RawMessage
+	if err := json.Unmarshal(raw, &wrapped); err != nil || len(wrapped) != 1 {
+		return nil, fmt.Errorf("expected a JSON list")
+	}
+	for _, list := range wrapped {
+		items = list
+	}
+	return items, nil
+}
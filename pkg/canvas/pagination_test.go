@@ -0,0 +1,112 @@
+package canvas_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/coraxwolf/CCTA_3-4/pkg/canvas"
+)
+
+func TestGetAllPagesFollowsLinkHeaders(t *testing.T) {
+	srv, api := newServer(t)
+	for i := range 25 {
+		srv.AddCourse(canvas.Course{Name: fmt.Sprintf("Course %d", i), AccountID: 1})
+	}
+
+	items, err := api.GetAllPages("accounts/1/courses", canvas.WithPerPage(10))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 25 {
+		t.Fatalf("got %d courses, want 25", len(items))
+	}
+	if n := requestsTo(srv, "accounts/1/courses"); n != 3 {
+		t.Errorf("sent %d requests, want one per page (3)", n)
+	}
+	var last canvas.Course
+	if err := json.Unmarshal(items[24], &last); err != nil {
+		t.Fatal(err)
+	}
+	if last.Name != "Course 24" {
+		t.Errorf("last course is %q, want %q", last.Name, "Course 24")
+	}
+}
+
+func TestGetAllPagesUnwrapsSingleKeyObjects(t *testing.T) {
+	srv, api := newServer(t)
+	srv.PageSize = 2
+	for _, name := range []string{"Fall 2025", "Spring 2026", "Summer 2026"} {
+		srv.AddTerm(canvas.Term{Name: name})
+	}
+
+	terms, err := canvas.GetAllPagesJSON[canvas.Term](api, "accounts/1/terms")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(terms) != 3 {
+		t.Fatalf("got %d terms, want 3 from the enrollment_terms pages", len(terms))
+	}
+	if terms[2].Name != "Summer 2026" {
+		t.Errorf("third term is %q, want %q", terms[2].Name, "Summer 2026")
+	}
+}
+
+func TestGetAllPagesEmptyList(t *testing.T) {
+	_, api := newServer(t)
+
+	courses, err := canvas.GetAllPagesJSON[canvas.Course](api, "accounts/1/courses")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if courses == nil || len(courses) != 0 {
+		t.Errorf("got %#v, want an empty slice", courses)
+	}
+}
+
+func TestStreamPagesNumbersPages(t *testing.T) {
+	srv, api := newServer(t)
+	for i := range 5 {
+		srv.AddCourse(canvas.Course{Name: fmt.Sprintf("Course %d", i), AccountID: 1})
+	}
+
+	var sizes []int
+	for page := range api.StreamPages("accounts/1/courses", canvas.WithPerPage(2)) {
+		if page.Err != nil {
+			t.Fatal(page.Err)
+		}
+		if page.Number != len(sizes)+1 {
+			t.Errorf("page %d numbered %d", len(sizes)+1, page.Number)
+		}
+		sizes = append(sizes, len(page.Items))
+	}
+	if fmt.Sprint(sizes) != "[2 2 1]" {
+		t.Errorf("page sizes %v, want [2 2 1]", sizes)
+	}
+}
+
+func TestGetAllPagesRefusesForeignNextLink(t *testing.T) {
+	var leaked []string
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		leaked = append(leaked, r.Header.Get("Authorization"))
+		fmt.Fprint(w, "[]")
+	}))
+	defer other.Close()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Link", fmt.Sprintf(`<%s/api/v1/courses?page=2>; rel="next"`, other.URL))
+		fmt.Fprint(w, `[{"id": 1}]`)
+	}))
+	defer srv.Close()
+	api := canvas.NewAPI(slog.New(slog.NewTextHandler(io.Discard, nil)), "secret", srv.URL+"/api/v1/", 700, 60)
+
+	if _, err := api.GetAllPages("accounts/1/courses"); err == nil {
+		t.Error("followed a next link to another host")
+	}
+	if len(leaked) > 0 {
+		t.Errorf("sent %d requests to the other host", len(leaked))
+	}
+}
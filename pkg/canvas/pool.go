@@ -0,0 +1,115 @@
+package canvas
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+)
+
+type Request struct {
+	Key      string // identifies the result, e.g. "modules:1234"
+	Method   string // defaults to GET
+	Endpoint string
+	Body     []byte
+}
+
+type Result struct {
+	Key        string
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+	Err        error
+}
+
+// Pool sends requests through one APIManager from several goroutines. All workers share the
+// manager's rate limit budget, and fewer of them run at once as the remaining limit drops.
+type Pool struct {
+	api      *APIManager
+	workers  int
+	mu       sync.Mutex
+	cond     *sync.Cond
+	inFlight int
+}
+
+func NewPool(api *APIManager, workers int) *Pool {
+	if workers < 1 {
+		workers = 1
+	}
+	p := &Pool{api: api, workers: workers}
+	p.cond = sync.NewCond(&p.mu)
+	return p
+}
+
+// Do sends every request and returns the results keyed by Request.Key.
+// Requests still queued when ctx is cancelled get the context error as their result.
+func (p *Pool) Do(ctx context.Context, requests []Request) map[string]Result {
+	queue := make(chan Request)
+	results := make(map[string]Result, len(requests))
+	var resultsMu sync.Mutex
+	var wg sync.WaitGroup
+	for i := 0; i < p.workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for req := range queue {
+				res := p.send(ctx, req)
+				resultsMu.Lock()
+				results[req.Key] = res
+				resultsMu.Unlock()
+			}
+		}()
+	}
+	for _, req := range requests {
+		queue <- req
+	}
+	close(queue)
+	wg.Wait()
+	return results
+}
+
+// allowed returns how many requests may be in flight given the remaining rate limit.
+func (p *Pool) allowed() int {
+	return p.api.rate.Share(p.workers)
+}
+
+func (p *Pool) acquire() {
+	p.mu.Lock()
+	for p.inFlight >= p.allowed() {
+		p.cond.Wait()
+	}
+	p.inFlight++
+	p.mu.Unlock()
+}
+
+func (p *Pool) release() {
+	p.mu.Lock()
+	p.inFlight--
+	p.mu.Unlock()
+	p.cond.Broadcast()
+}
+
+func (p *Pool) send(ctx context.Context, req Request) Result {
+	res := Result{Key: req.Key}
+	if err := ctx.Err(); err != nil {
+		res.Err = err
+		return res
+	}
+	method := req.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+	p.acquire()
+	defer p.release()
+	resp, err := p.api.do(ctx, method, req.Endpoint, req.Body)
+	if err != nil {
+		res.Err = fmt.Errorf("error sending %s %s: %w", method, req.Endpoint, err)
+		return res
+	}
+	defer resp.Body.Close() // Ensure the response body is closed after reading
+	res.StatusCode = resp.StatusCode
+	res.Header = resp.Header
+	res.Body, res.Err = io.ReadAll(resp.Body)
+	return res
+}
@@ -0,0 +1,29 @@
+package canvas
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/coraxwolf/CCTA_3-4/pkg/config"
+)
+
+// NewAPIFromConfig creates an APIManager for the Canvas instance described by cfg.
+func NewAPIFromConfig(logger *slog.Logger, cfg config.Config) *APIManager {
+	api := NewAPI(logger.With("profile", cfg.Profile), cfg.Token, cfg.BaseURL, cfg.RateLimit, cfg.Timeout)
+	api.SetFallbackTokens(cfg.FallbackTokens...)
+	api.SetFeatures(cfg.Enabled)
+	return api
+}
+
+// NewAPIFromProfile loads the named profile from configFile and the environment and creates
+// an APIManager for it. See config.Load for how empty arguments are handled.
+func NewAPIFromProfile(logger *slog.Logger, configFile, profile string) (*APIManager, error) {
+	cfg, err := config.Load(configFile, profile)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Token == "" || cfg.BaseURL == "" {
+		return nil, fmt.Errorf("profile %q needs a token and base URL (set %s_TOKEN and %s_API_URL or add them to the config file)", cfg.Profile, config.EnvPrefix(cfg.Profile), config.EnvPrefix(cfg.Profile))
+	}
+	return NewAPIFromConfig(logger, cfg), nil
+}
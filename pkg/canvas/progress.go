@@ -0,0 +1,54 @@
+package canvas
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Progress tracks an asynchronous Canvas job, such as a bulk grade update.
+type Progress struct {
+	ID            int        `json:"id"`
+	ContextID     int        `json:"context_id"`
+	ContextType   string     `json:"context_type"`
+	Tag           string     `json:"tag"`
+	Completion    float64    `json:"completion"`     // percent complete
+	WorkflowState string     `json:"workflow_state"` // queued, running, completed, or failed
+	Message       string     `json:"message"`
+	CreatedAt     *time.Time `json:"created_at"`
+	UpdatedAt     *time.Time `json:"updated_at"`
+	URL           string     `json:"url"`
+}
+
+// Done reports whether the job has finished, successfully or not.
+func (p Progress) Done() bool {
+	return p.WorkflowState == "completed" || p.WorkflowState == "failed"
+}
+
+func (api *APIManager) GetProgress(ctx context.Context, id int) (*Progress, error) {
+	var progress Progress
+	if err := (service{api: api, ctx: ctx}).getJSON(fmt.Sprintf("progress/%d", id), &progress); err != nil {
+		return nil, fmt.Errorf("error fetching progress %d: %w", id, err)
+	}
+	return &progress, nil
+}
+
+// WaitProgress polls a job every interval until it completes or fails, and returns its final state.
+// A failed job returns an error with the job's message.
+func (api *APIManager) WaitProgress(ctx context.Context, id int, interval time.Duration) (*Progress, error) {
+	for {
+		progress, err := api.GetProgress(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if progress.WorkflowState == "failed" {
+			return progress, fmt.Errorf("canvas job %d failed: %s", id, progress.Message)
+		}
+		if progress.Done() {
+			return progress, nil
+		}
+		if err := sleepCtx(ctx, interval); err != nil {
+			return progress, err
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package canvas
+
+import (
+	"bufio"
+	"cmp"
+	"fmt"
+	"maps"
+	"net/http"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// durationBuckets are the upper bounds, in seconds, of the request duration histogram.
+var durationBuckets = []float64{0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60}
+
+// httpMetrics counts every attempt sent to Canvas for the Prometheus exporter.
+type httpMetrics struct {
+	mu        sync.Mutex
+	responses map[responseKey]int
+	failures  map[string]int             // by method, requests that got no response
+	durations map[string]*durationSeries // by method
+}
+
+type responseKey struct {
+	method string
+	status int
+}
+
+type durationSeries struct {
+	buckets []int // cumulative counts, one per durationBuckets entry
+	count   int
+	sum     float64
+}
+
+func newHTTPMetrics() *httpMetrics {
+	return &httpMetrics{
+		responses: make(map[responseKey]int),
+		failures:  make(map[string]int),
+		durations: make(map[string]*durationSeries),
+	}
+}
+
+func (m *httpMetrics) observe(method string, resp *http.Response, err error, d time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err != nil {
+		m.failures[method]++
+	} else {
+		m.responses[responseKey{method, resp.StatusCode}]++
+	}
+	series := m.durations[method]
+	if series == nil {
+		series = &durationSeries{buckets: make([]int, len(durationBuckets))}
+		m.durations[method] = series
+	}
+	secs := d.Seconds()
+	for i, le := range durationBuckets {
+		if secs <= le {
+			series.buckets[i]++
+		}
+	}
+	series.count++
+	series.sum += secs
+}
+
+// MetricsHandler serves the APIManager's counters in the Prometheus text format: requests sent,
+// responses by method and status, requests without a response, rate limit throttles, the rate
+// limit remaining and average request cost, circuit breaker opens, cache hits, token failovers,
+// and a histogram of request durations. Every attempt counts, retries included.
+func (api *APIManager) MetricsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		bw := bufio.NewWriter(w)
+		api.writeMetrics(bw)
+		bw.Flush()
+	})
+}
+
+func (api *APIManager) writeMetrics(w *bufio.Writer) {
+	stats := api.Stats()
+	metric := func(name, kind, help string) {
+		fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+	}
+	value := func(v float64) string {
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	}
+
+	metric("canvas_requests_sent_total", "counter", "Requests sent to Canvas, retries included.")
+	fmt.Fprintf(w, "canvas_requests_sent_total %d\n", stats.RequestsSent)
+	metric("canvas_throttles_total", "counter", "Requests delayed for the rate limit.")
+	fmt.Fprintf(w, "canvas_throttles_total %d\n", stats.ThrottleCount)
+	metric("canvas_throttle_seconds_total", "counter", "Time spent waiting for the rate limit.")
+	fmt.Fprintf(w, "canvas_throttle_seconds_total %s\n", value(stats.ThrottleTime.Seconds()))
+	metric("canvas_rate_limit_remaining", "gauge", "X-Rate-Limit-Remaining of the last response.")
+	fmt.Fprintf(w, "canvas_rate_limit_remaining %s\n", value(stats.RateLimitRemaining))
+	metric("canvas_request_cost_average", "gauge", "Moving average of X-Request-Cost.")
+	fmt.Fprintf(w, "canvas_request_cost_average %s\n", value(stats.AverageRateCost))
+	metric("canvas_circuit_opens_total", "counter", "Times the circuit breaker opened.")
+	fmt.Fprintf(w, "canvas_circuit_opens_total %d\n", stats.CircuitOpens)
+	metric("canvas_cache_hits_total", "counter", "Responses served from the response cache after a 304.")
+	fmt.Fprintf(w, "canvas_cache_hits_total %d\n", stats.CacheHits)
+	metric("canvas_token_failovers_total", "counter", "Switches to a fallback token.")
+	fmt.Fprintf(w, "canvas_token_failovers_total %d\n", stats.TokenFailovers)
+
+	m := api.httpStats
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	metric("canvas_responses_total", "counter", "Responses from Canvas by method and status code.")
+	keys := make([]responseKey, 0, len(m.responses))
+	for key := range m.responses {
+		keys = append(keys, key)
+	}
+	slices.SortFunc(keys, func(a, b responseKey) int {
+		return cmp.Or(cmp.Compare(a.method, b.method), cmp.Compare(a.status, b.status))
+	})
+	for _, key := range keys {
+		fmt.Fprintf(w, "canvas_responses_total{method=%q,code=\"%d\"} %d\n", key.method, key.status, m.responses[key])
+	}
+	metric("canvas_request_failures_total", "counter", "Requests that got no response, such as timeouts, by method.")
+	for _, method := range slices.Sorted(maps.Keys(m.failures)) {
+		fmt.Fprintf(w, "canvas_request_failures_total{method=%q} %d\n", method, m.failures[method])
+	}
+	metric("canvas_request_duration_seconds", "histogram", "Time from sending a request to its response headers, by method.")
+	for _, method := range slices.Sorted(maps.Keys(m.durations)) {
+		series := m.durations[method]
+		for i, le := range durationBuckets {
+			fmt.Fprintf(w, "canvas_request_duration_seconds_bucket{method=%q,le=%q} %d\n", method, value(le), series.buckets[i])
+		}
+		fmt.Fprintf(w, "canvas_request_duration_seconds_bucket{method=%q,le=\"+Inf\"} %d\n", method, series.count)
+		fmt.Fprintf(w, "canvas_request_duration_seconds_sum{method=%q} %s\n", method, value(series.sum))
+		fmt.Fprintf(w, "canvas_request_duration_seconds_count{method=%q} %d\n", method, series.count)
+	}
+}
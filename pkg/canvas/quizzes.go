@@ -0,0 +1,109 @@
+package canvas
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Quiz is a classic quiz. New Quizzes are assignments and are not listed here.
+type Quiz struct {
+	ID              int        `json:"id"`
+	Title           string     `json:"title"`
+	QuizType        string     `json:"quiz_type"`  // practice_quiz, assignment, graded_survey, or survey
+	TimeLimit       *int       `json:"time_limit"` // minutes, nil when untimed
+	AllowedAttempts int        `json:"allowed_attempts"`
+	QuestionCount   int        `json:"question_count"`
+	PointsPossible  *float64   `json:"points_possible"`
+	Published       bool       `json:"published"`
+	DueAt           *time.Time `json:"due_at"`
+	UnlockAt        *time.Time `json:"unlock_at"`
+	LockAt          *time.Time `json:"lock_at"`
+	AssignmentID    *int       `json:"assignment_id"`
+	HTMLURL         string     `json:"html_url"`
+}
+
+// QuizInput holds the classic quiz attributes to set on create or update. Nil fields are left out.
+type QuizInput struct {
+	Title           *string    `json:"title,omitempty"`
+	Description     *string    `json:"description,omitempty"` // HTML
+	QuizType        *string    `json:"quiz_type,omitempty"`
+	TimeLimit       *int       `json:"time_limit,omitempty"`
+	AllowedAttempts *int       `json:"allowed_attempts,omitempty"` // -1 for unlimited
+	Published       *bool      `json:"published,omitempty"`
+	DueAt           *time.Time `json:"due_at,omitempty"`
+	UnlockAt        *time.Time `json:"unlock_at,omitempty"`
+	LockAt          *time.Time `json:"lock_at,omitempty"`
+}
+
+type QuizSubmission struct {
+	ID            int        `json:"id"`
+	QuizID        int        `json:"quiz_id"`
+	UserID        int        `json:"user_id"`
+	Attempt       int        `json:"attempt"`
+	ExtraAttempts int        `json:"extra_attempts"`
+	ExtraTime     int        `json:"extra_time"` // minutes
+	WorkflowState string     `json:"workflow_state"`
+	StartedAt     *time.Time `json:"started_at"`
+	FinishedAt    *time.Time `json:"finished_at"`
+}
+
+type QuizzesService struct {
+	service
+}
+
+func (api *APIManager) Quizzes() *QuizzesService {
+	return &QuizzesService{service{api: api}}
+}
+
+// WithContext returns a copy of the service that sends its requests with ctx.
+func (qs *QuizzesService) WithContext(ctx context.Context) *QuizzesService {
+	return &QuizzesService{service{api: qs.api, ctx: ctx}}
+}
+
+func (qs *QuizzesService) ListQuizzes(courseID int) ([]Quiz, error) {
+	var quizzes []Quiz
+	if err := qs.listJSON(fmt.Sprintf("courses/%d/quizzes?per_page=100", courseID), &quizzes); err != nil {
+		return nil, fmt.Errorf("error listing quizzes for course %d: %w", courseID, err)
+	}
+	return quizzes, nil
+}
+
+func (qs *QuizzesService) GetQuiz(courseID, quizID int) (*Quiz, error) {
+	var quiz Quiz
+	if err := qs.getJSON(fmt.Sprintf("courses/%d/quizzes/%d", courseID, quizID), &quiz); err != nil {
+		return nil, fmt.Errorf("error fetching quiz %d in course %d: %w", quizID, courseID, err)
+	}
+	return &quiz, nil
+}
+
+// CreateQuiz creates an empty classic quiz. Canvas creates it unpublished unless Published is set.
+func (qs *QuizzesService) CreateQuiz(courseID int, input QuizInput) (*Quiz, error) {
+	body := map[string]any{"quiz": input}
+	var quiz Quiz
+	if err := qs.sendJSON(http.MethodPost, fmt.Sprintf("courses/%d/quizzes", courseID), body, &quiz); err != nil {
+		return nil, fmt.Errorf("error creating quiz in course %d: %w", courseID, err)
+	}
+	return &quiz, nil
+}
+
+func (qs *QuizzesService) UpdateQuiz(courseID, quizID int, input QuizInput) (*Quiz, error) {
+	body := map[string]any{"quiz": input}
+	var quiz Quiz
+	if err := qs.sendJSON(http.MethodPut, fmt.Sprintf("courses/%d/quizzes/%d", courseID, quizID), body, &quiz); err != nil {
+		return nil, fmt.Errorf("error updating quiz %d in course %d: %w", quizID, courseID, err)
+	}
+	return &quiz, nil
+}
+
+// ListQuizSubmissions returns the quiz's submissions, including the placeholder submissions Canvas
+// creates when a student is given extra time or attempts before starting.
+func (qs *QuizzesService) ListQuizSubmissions(courseID, quizID int) ([]QuizSubmission, error) {
+	// each page is {"quiz_submissions": [...]}, which the pagination unwraps
+	var submissions []QuizSubmission
+	if err := qs.listJSON(fmt.Sprintf("courses/%d/quizzes/%d/submissions?per_page=100", courseID, quizID), &submissions); err != nil {
+		return nil, fmt.Errorf("error listing submissions for quiz %d in course %d: %w", quizID, courseID, err)
+	}
+	return submissions, nil
+}
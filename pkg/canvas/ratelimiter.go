@@ -0,0 +1,152 @@
+package canvas
+
+import (
+	"context"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RateLimiter decides when a request may be sent. The APIManager calls Wait before every request
+// and Observe with every response, from many goroutines at once.
+type RateLimiter interface {
+	// Wait blocks until the next request may be sent and returns how long it waited. It returns
+	// early with the context error if ctx is cancelled.
+	Wait(ctx context.Context) (time.Duration, error)
+	// Observe updates the limiter from a response's rate limit headers.
+	Observe(resp *http.Response)
+}
+
+// DefaultLeakRate is how many units of the rate limit bucket Canvas frees each second.
+const DefaultLeakRate = 10.0
+
+// preflightCost is what Canvas charges a request up front, until its actual cost is known. It
+// is the estimate for the requests sent before the first response.
+const preflightCost = 50.0
+
+// TokenBucket is the default RateLimiter. It models Canvas' leaky bucket from the client side:
+// the bucket holds what X-Rate-Limit-Remaining last reported and refills at the leak rate
+// between responses. Before a request is sent, its cost is estimated from the moving average of
+// X-Request-Cost and taken from the bucket; when that would leave less than the reserve, the
+// request waits until enough has leaked back. So requests slow down gradually as the bucket
+// empties, instead of sleeping for minutes after Canvas has already throttled them.
+type TokenBucket struct {
+	mu        sync.Mutex
+	tracker   *RateTracker // cost estimates
+	capacity  float64
+	leakRate  float64   // units per second
+	reserve   float64   // kept free for the pre-flight charge of requests in flight
+	available float64   // as of updated
+	updated   time.Time // when available was last set or refilled
+}
+
+// NewTokenBucket returns a TokenBucket as big as the tracker's rate limit that refills at
+// leakRate units per second (DefaultLeakRate when leakRate is 0). A tracker without a rate limit
+// never waits.
+func NewTokenBucket(tracker *RateTracker, leakRate float64) *TokenBucket {
+	if leakRate <= 0 {
+		leakRate = DefaultLeakRate
+	}
+	capacity := float64(tracker.Max())
+	return &TokenBucket{
+		tracker:   tracker,
+		capacity:  capacity,
+		leakRate:  leakRate,
+		reserve:   capacity / 10,
+		available: capacity,
+		updated:   time.Now(),
+	}
+}
+
+func (b *TokenBucket) Wait(ctx context.Context) (time.Duration, error) {
+	if b.capacity <= 0 {
+		return 0, nil
+	}
+	cost := b.tracker.AverageCost()
+	if cost <= 0 {
+		cost = preflightCost
+	}
+	cost = min(cost, b.capacity-b.reserve) // a request always fits in a full bucket
+	var waited time.Duration
+	for {
+		delay := b.take(cost)
+		if delay == 0 {
+			return waited, nil
+		}
+		// Jitter keeps workers that wait for the same refill from waking up at once.
+		delay += time.Duration(rand.Int63n(int64(delay)/4 + 1))
+		start := time.Now()
+		err := sleepCtx(ctx, delay)
+		waited += time.Since(start)
+		if err != nil {
+			return waited, err
+		}
+	}
+}
+
+// take removes cost from the bucket and returns 0, or returns how long until it can.
+func (b *TokenBucket) take(cost float64) time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.refill(time.Now())
+	if b.available-cost >= b.reserve {
+		b.available -= cost
+		return 0
+	}
+	short := b.reserve + cost - b.available
+	return time.Duration(short / b.leakRate * float64(time.Second))
+}
+
+// refill adds what has leaked out of Canvas' bucket since the last update. The caller holds b.mu.
+func (b *TokenBucket) refill(now time.Time) {
+	b.available = min(b.capacity, b.available+now.Sub(b.updated).Seconds()*b.leakRate)
+	b.updated = now
+}
+
+func (b *TokenBucket) Observe(resp *http.Response) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if resp.StatusCode == http.StatusTooManyRequests {
+		b.available, b.updated = 0, time.Now() // Canvas' count is what matters now, whatever the header says
+		return
+	}
+	remaining, err := strconv.ParseFloat(resp.Header.Get("X-Rate-Limit-Remaining"), 64)
+	if err != nil {
+		return // keep the estimate, e.g. for a response from a proxy
+	}
+	b.available, b.updated = min(b.capacity, remaining), time.Now()
+}
+
+// SetRateLimiter replaces the limiter that paces requests, by default a TokenBucket. A nil
+// limiter sends requests without waiting; Canvas' 429s are still retried, after the retry policy's
+// backoff.
+func (api *APIManager) SetRateLimiter(limiter RateLimiter) {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	api.limiter = limiter
+}
+
+func (api *APIManager) rateLimiter() RateLimiter {
+	api.mu.RLock()
+	defer api.mu.RUnlock()
+	return api.limiter
+}
+
+// waitRateLimit waits for the limiter before a request is sent and counts the wait as a throttle.
+func (api *APIManager) waitRateLimit(ctx context.Context, method, endpoint string) error {
+	limiter := api.rateLimiter()
+	if limiter == nil {
+		return nil
+	}
+	waited, err := limiter.Wait(ctx)
+	if waited > 0 {
+		api.rate.RecordThrottle(waited)
+		if ms := api.metricsStore(); ms != nil {
+			ms.recordThrottle(method, endpoint)
+		}
+		api.logger.Info("Delayed request for the rate limit", "method", method, "endpoint", endpoint, "delay", waited.Round(time.Millisecond))
+	}
+	return err
+}
@@ -0,0 +1,148 @@
+package canvas
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RateTracker keeps the request counters and rate limit estimates for an APIManager.
+// It is safe for concurrent use.
+type RateTracker struct {
+	mu                sync.Mutex
+	logger            *slog.Logger
+	max               int
+	remaining         float64
+	averageCost       float64
+	costSamples       int
+	requestsSent      int
+	responsesReceived int
+	throttleCount     int
+	throttleTime      time.Duration
+}
+
+func NewRateTracker(logger *slog.Logger, rateLimitMax int) *RateTracker {
+	return &RateTracker{
+		logger:    logger,
+		max:       rateLimitMax,
+		remaining: float64(rateLimitMax),
+	}
+}
+
+func (rt *RateTracker) Max() int {
+	return rt.max // never changes after construction
+}
+
+func (rt *RateTracker) Remaining() float64 {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	return rt.remaining
+}
+
+// AverageCost returns the moving average of the X-Request-Cost of recent responses, or 0 before
+// the first one.
+func (rt *RateTracker) AverageCost() float64 {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	return rt.averageCost
+}
+
+// Share returns how many of n workers may run at once given the remaining rate limit: all of them
+// with a full bucket, fewer as it drains, and always at least one so the limit can be re-read.
+func (rt *RateTracker) Share(n int) int {
+	if rt.max <= 0 {
+		return n
+	}
+	share := int(float64(n) * rt.Remaining() / float64(rt.max))
+	if share < 1 {
+		share = 1
+	}
+	if share > n {
+		share = n
+	}
+	return share
+}
+
+func (rt *RateTracker) RequestSent() {
+	rt.mu.Lock()
+	rt.requestsSent++
+	rt.mu.Unlock()
+}
+
+func (rt *RateTracker) RecordThrottle(d time.Duration) {
+	rt.mu.Lock()
+	rt.throttleCount++
+	rt.throttleTime += d
+	rt.mu.Unlock()
+}
+
+// SeedAverageCost starts the average cost estimate from an earlier run. The seed counts as at most
+// seedWeight samples so the estimate still follows the costs seen in this run.
+func (rt *RateTracker) SeedAverageCost(cost float64, samples int) {
+	const seedWeight = 20
+	if cost <= 0 || samples <= 0 {
+		return
+	}
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	if rt.costSamples > 0 {
+		return // this run already has its own samples
+	}
+	rt.averageCost = cost
+	rt.costSamples = min(samples, seedWeight)
+}
+
+func (rt *RateTracker) Stats() APIStats {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	return APIStats{
+		RequestsSent:       rt.requestsSent,
+		ResponsesReceived:  rt.responsesReceived,
+		RateLimitRemaining: rt.remaining,
+		AverageRateCost:    rt.averageCost,
+		ThrottleCount:      rt.throttleCount,
+		ThrottleTime:       rt.throttleTime,
+	}
+}
+
+// costWindow is how many recent responses the average cost follows.
+const costWindow = 50
+
+// Observe records a response's rate limit headers. Pacing the requests is up to the RateLimiter.
+func (rt *RateTracker) Observe(resp *http.Response) {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	rt.responsesReceived++
+	max := float64(rt.max)
+	// Get Rate Limit Information
+	limit, err := strconv.ParseFloat(resp.Header.Get("X-Rate-Limit-Remaining"), 64)
+	if err != nil {
+		rt.logger.Error("failed to parse RateLimit-Remaining header", "error", err, "HeaderData", resp.Header.Get("RateLimit-Remaining"))
+		rt.logger.Debug("response headers", "headers", redactHeaders(resp.Header))
+		limit = float64(rt.max / 2) // set to 50% since we do not know the actual limit
+	}
+	cost, err := strconv.ParseFloat(resp.Header.Get("X-Request-Cost"), 64)
+	if err != nil {
+		rt.logger.Error("failed to parse Request-Cost header", "error", err, "HeaderData", resp.Header.Get("Request-Cost"))
+		cost = rt.averageCost // use the average rate cost if we cannot parse the header
+	}
+	if limit < max {
+		rt.remaining = limit
+	} else {
+		rt.remaining = max
+	}
+	if cost > 0 {
+		rt.costSamples++
+		rt.averageCost = rt.averageCost + (cost-rt.averageCost)/float64(min(rt.costSamples, costWindow))
+	} else {
+		rt.logger.Warn("Request Cost is zero, cannot update average rate cost", "cost", cost)
+	}
+	if rt.remaining <= max*0.25 {
+		rt.logger.Warn("Rate Limit is Extremely Low!! Under 25% of limit", "remaining", rt.remaining)
+	}
+	if resp.StatusCode == http.StatusTooManyRequests {
+		rt.logger.Warn("Rate limit exceeded", "remaining", resp.Header.Get("X-Rate-Limit-Remaining"))
+	}
+}
@@ -0,0 +1,44 @@
+package canvas_test
+
+import (
+	"testing"
+
+	"github.com/coraxwolf/CCTA_3-4/pkg/canvas"
+)
+
+func TestRateTrackerReadsHeaders(t *testing.T) {
+	srv, api := newServer(t)
+	srv.RequestCost = 25
+	srv.RefillRate = 0
+	c := srv.AddCourse(canvas.Course{Name: "Biology 101"})
+
+	for range 3 {
+		if _, err := api.Courses().GetCourse(c.ID); err != nil {
+			t.Fatal(err)
+		}
+	}
+	stats := api.Stats()
+	if stats.RequestsSent != 3 || stats.ResponsesReceived != 3 {
+		t.Errorf("counted %d sent and %d received, want 3 each", stats.RequestsSent, stats.ResponsesReceived)
+	}
+	if want := srv.RateLimit - 3*srv.RequestCost; stats.RateLimitRemaining != want {
+		t.Errorf("remaining is %v, want %v from X-Rate-Limit-Remaining", stats.RateLimitRemaining, want)
+	}
+	if stats.AverageRateCost != srv.RequestCost {
+		t.Errorf("average cost is %v, want %v from X-Request-Cost", stats.AverageRateCost, srv.RequestCost)
+	}
+}
+
+func TestRateTrackerSeesThrottling(t *testing.T) {
+	srv, api := newServer(t)
+	api.SetRateLimiter(nil)
+	c := srv.AddCourse(canvas.Course{Name: "Biology 101"})
+	srv.ThrottleNext(1)
+
+	if _, err := api.Courses().GetCourse(c.ID); err != nil {
+		t.Fatal(err)
+	}
+	if remaining := api.RateTracker().Remaining(); remaining >= srv.RateLimit/2 {
+		t.Errorf("remaining is %v after a 429 emptied the bucket", remaining)
+	}
+}
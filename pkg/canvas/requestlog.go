@@ -0,0 +1,118 @@
+package canvas
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// RequestRecord is one line of the request log: a request as sent and how it ended.
+// The token is never recorded.
+type RequestRecord struct {
+	Time        time.Time       `json:"time"`
+	Method      string          `json:"method"`
+	Endpoint    string          `json:"endpoint"`
+	Body        json.RawMessage `json:"body,omitempty"`
+	BodyOmitted bool            `json:"body_omitted,omitempty"` // a body was sent but isn't JSON, e.g. a file upload
+	Status      int             `json:"status,omitempty"`
+	Error       string          `json:"error,omitempty"`
+	DurationMS  int64           `json:"duration_ms"`
+	DryRun      bool            `json:"dry_run,omitempty"` // logged but not sent
+}
+
+// Failed reports whether the request ended in an error or a non-2xx status.
+func (r RequestRecord) Failed() bool {
+	return r.Error != "" || r.Status < 200 || r.Status > 299
+}
+
+type requestLog struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+// SetRequestLog writes a RequestRecord as a JSON line to w for every request, after retries.
+// A nil w turns the log off.
+func (api *APIManager) SetRequestLog(w io.Writer) {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	if w == nil {
+		api.reqLog = nil
+		return
+	}
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false) // keep & in endpoints readable
+	api.reqLog = &requestLog{enc: enc}
+}
+
+func (api *APIManager) logRequest(start time.Time, method, endpoint string, body []byte, resp *http.Response, err error) {
+	api.mu.RLock()
+	log := api.reqLog
+	api.mu.RUnlock()
+	if log == nil {
+		return
+	}
+	rec := RequestRecord{
+		Time:       start,
+		Method:     method,
+		Endpoint:   endpoint,
+		DurationMS: time.Since(start).Milliseconds(),
+	}
+	if json.Valid(body) {
+		rec.Body = body
+	} else if len(body) > 0 {
+		rec.BodyOmitted = true
+	}
+	if resp != nil {
+		rec.Status = resp.StatusCode
+		rec.DryRun = IsDryRun(resp)
+	}
+	if err != nil {
+		rec.Error = err.Error()
+	}
+	log.mu.Lock()
+	defer log.mu.Unlock()
+	if err := log.enc.Encode(rec); err != nil {
+		api.logger.Error("error writing request log", "error", err)
+	}
+}
+
+// ReadRequestLog decodes every record in a request log.
+func ReadRequestLog(r io.Reader) ([]RequestRecord, error) {
+	var records []RequestRecord
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var rec RequestRecord
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			return nil, fmt.Errorf("error decoding request log line %d: %w", line, err)
+		}
+		records = append(records, rec)
+	}
+	return records, scanner.Err()
+}
+
+// ErrBodyNotLogged is returned by Replay for a request whose body the log doesn't have.
+var ErrBodyNotLogged = errors.New("request body was not logged")
+
+// Replay sends a logged request again with the same method, endpoint, and body. The log only
+// keeps JSON bodies, so requests sent with another body, such as multipart file uploads and SIS
+// imports, are refused with ErrBodyNotLogged instead of being sent without it.
+func (api *APIManager) Replay(ctx context.Context, rec RequestRecord) (*http.Response, error) {
+	if rec.BodyOmitted {
+		return nil, fmt.Errorf("%s %s: %w, only JSON bodies are", rec.Method, rec.Endpoint, ErrBodyNotLogged)
+	}
+	var body []byte
+	if len(rec.Body) > 0 {
+		body = rec.Body
+	}
+	return api.do(ctx, rec.Method, rec.Endpoint, body)
+}
@@ -0,0 +1,53 @@
+package canvas_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/coraxwolf/CCTA_3-4/pkg/canvas"
+)
+
+func TestReplayLoggedRequest(t *testing.T) {
+	srv, api := newServer(t)
+	c := srv.AddCourse(canvas.Course{Name: "Biology 101"})
+	var log bytes.Buffer
+	api.SetRequestLog(&log)
+	name := "Biology 101 (Fall)"
+	if _, err := api.Courses().UpdateCourse(c.ID, canvas.CourseUpdate{Name: &name}); err != nil {
+		t.Fatal(err)
+	}
+	records, err := canvas.ReadRequestLog(&log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 1 || records[0].Method != http.MethodPut || records[0].Endpoint != fmt.Sprintf("courses/%d", c.ID) {
+		t.Fatalf("logged %+v", records)
+	}
+
+	resp, err := api.Replay(context.Background(), records[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	requests := srv.Requests()
+	if len(requests) != 2 || !bytes.Equal(requests[0].Body, requests[1].Body) {
+		t.Errorf("replay sent %q, want the logged body %q", requests[len(requests)-1].Body, requests[0].Body)
+	}
+}
+
+func TestReplayRefusesUnloggedBody(t *testing.T) {
+	srv, api := newServer(t)
+	rec := canvas.RequestRecord{Method: http.MethodPost, Endpoint: "accounts/1/sis_imports", BodyOmitted: true}
+
+	_, err := api.Replay(context.Background(), rec)
+	if !errors.Is(err, canvas.ErrBodyNotLogged) {
+		t.Fatalf("got %v, want ErrBodyNotLogged", err)
+	}
+	if n := len(srv.Requests()); n != 0 {
+		t.Errorf("sent %d requests", n)
+	}
+}
@@ -0,0 +1,96 @@
+package canvas_test
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/coraxwolf/CCTA_3-4/pkg/canvas"
+)
+
+func TestRetriesThrottledRequests(t *testing.T) {
+	srv, api := newServer(t)
+	// A fast leak keeps the wait for the emptied bucket short.
+	api.SetRateLimiter(canvas.NewTokenBucket(api.RateTracker(), 1000))
+	c := srv.AddCourse(canvas.Course{Name: "Biology 101"})
+	srv.ThrottleNext(2)
+
+	course, err := api.Courses().GetCourse(c.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if course.Name != "Biology 101" {
+		t.Errorf("got course %q", course.Name)
+	}
+	if n := len(srv.Requests()); n != 3 {
+		t.Errorf("sent %d requests, want 2 throttled and 1 answered", n)
+	}
+	if stats := api.Stats(); stats.ThrottleCount == 0 {
+		t.Error("waits for the emptied bucket were not counted as throttles")
+	}
+}
+
+// Without a rate limiter nothing waits for the bucket, so 429s back off like other retries.
+func TestRetriesThrottledRequestsWithoutLimiter(t *testing.T) {
+	srv, api := newServer(t)
+	api.SetRateLimiter(nil)
+	c := srv.AddCourse(canvas.Course{Name: "Biology 101"})
+	srv.ThrottleNext(2)
+
+	start := time.Now()
+	if _, err := api.Courses().GetCourse(c.ID); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(srv.Requests()); n != 3 {
+		t.Errorf("sent %d requests, want 3", n)
+	}
+	if elapsed, want := time.Since(start), 3*fastRetry.BaseDelay; elapsed < want {
+		t.Errorf("retried after %s, want a backoff of at least %s", elapsed, want)
+	}
+}
+
+func TestRetriesServiceUnavailableWithBackoff(t *testing.T) {
+	srv, api := newServer(t)
+	c := srv.AddCourse(canvas.Course{Name: "Biology 101"})
+	srv.FailNext(http.StatusServiceUnavailable, http.StatusServiceUnavailable)
+
+	start := time.Now()
+	if _, err := api.Courses().GetCourse(c.ID); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(srv.Requests()); n != 3 {
+		t.Errorf("sent %d requests, want 3", n)
+	}
+	// Two retries wait BaseDelay and then twice that.
+	if elapsed, want := time.Since(start), 3*fastRetry.BaseDelay; elapsed < want {
+		t.Errorf("retried after %s, want a backoff of at least %s", elapsed, want)
+	}
+}
+
+func TestGivesUpAfterMaxAttempts(t *testing.T) {
+	srv, api := newServer(t)
+	c := srv.AddCourse(canvas.Course{Name: "Biology 101"})
+	srv.FailNext(503, 503, 503, 503, 503)
+
+	_, err := api.Courses().GetCourse(c.ID)
+	var apiErr *canvas.APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("got %v, want the 503 as an APIError", err)
+	}
+	if n := len(srv.Requests()); n != fastRetry.MaxAttempts {
+		t.Errorf("sent %d requests, want %d", n, fastRetry.MaxAttempts)
+	}
+}
+
+func TestDoesNotRetryClientErrors(t *testing.T) {
+	srv, api := newServer(t)
+
+	_, err := api.Courses().GetCourse(42)
+	if !errors.Is(err, canvas.ErrNotFound) {
+		t.Fatalf("got %v, want ErrNotFound", err)
+	}
+	if n := len(srv.Requests()); n != 1 {
+		t.Errorf("sent %d requests, want 1", n)
+	}
+}
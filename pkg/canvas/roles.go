@@ -0,0 +1,55 @@
+package canvas
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+)
+
+// Role is an account role, either one of the base roles (Teacher, StudentEnrollment, AccountAdmin,
+// ...) or a custom role built on one.
+type Role struct {
+	ID            int                       `json:"id"`
+	Label         string                    `json:"label"`
+	Role          string                    `json:"role"`           // the role's name, e.g. TeacherEnrollment
+	BaseRoleType  string                    `json:"base_role_type"` // AccountMembership for account roles
+	WorkflowState string                    `json:"workflow_state"`
+	Permissions   map[string]RolePermission `json:"permissions"` // keyed by permission name, e.g. manage_courses
+}
+
+// RolePermission is one permission's setting for a role.
+type RolePermission struct {
+	Enabled              bool `json:"enabled"`
+	Locked               bool `json:"locked"`   // sub-accounts can't change it
+	Readonly             bool `json:"readonly"` // can't be changed at this account at all
+	Explicit             bool `json:"explicit"` // set on this account rather than inherited
+	AppliesToSelf        bool `json:"applies_to_self"`
+	AppliesToDescendants bool `json:"applies_to_descendants"`
+}
+
+type RolesService struct {
+	service
+}
+
+func (api *APIManager) Roles() *RolesService {
+	return &RolesService{service{api: api}}
+}
+
+// WithContext returns a copy of the service that sends its requests with ctx.
+func (rs *RolesService) WithContext(ctx context.Context) *RolesService {
+	return &RolesService{service{api: rs.api, ctx: ctx}}
+}
+
+// ListRoles returns the account's active roles with their permissions as they apply at the
+// account, including the roles and settings it inherits from its parent accounts.
+func (rs *RolesService) ListRoles(accountID int) ([]Role, error) {
+	query := url.Values{}
+	query.Set("per_page", perPage(0))
+	query.Set("show_inherited", "true")
+	query.Add("state[]", "active")
+	var roles []Role
+	if err := rs.listJSON(withQuery(fmt.Sprintf("accounts/%d/roles", accountID), query), &roles); err != nil {
+		return nil, fmt.Errorf("error listing roles for account %d: %w", accountID, err)
+	}
+	return roles, nil
+}
@@ -0,0 +1,127 @@
+package canvas
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// Rubric is a grading rubric. Data is the criteria; Associations is only filled by GetRubric with
+// include "associations".
+type Rubric struct {
+	ID                        int                 `json:"id"`
+	Title                     string              `json:"title"`
+	ContextID                 int                 `json:"context_id"`
+	ContextType               string              `json:"context_type"`
+	PointsPossible            float64             `json:"points_possible"`
+	Reusable                  bool                `json:"reusable"`
+	ReadOnly                  bool                `json:"read_only"`
+	FreeFormCriterionComments bool                `json:"free_form_criterion_comments"`
+	HideScoreTotal            bool                `json:"hide_score_total"`
+	Data                      []RubricCriterion   `json:"data"`
+	Associations              []RubricAssociation `json:"associations"`
+}
+
+// RubricCriterion is a row of a rubric. Criteria aligned to an outcome carry its ID.
+type RubricCriterion struct {
+	ID                string         `json:"id"`
+	Description       string         `json:"description"`
+	LongDescription   string         `json:"long_description"`
+	Points            float64        `json:"points"`
+	CriterionUseRange bool           `json:"criterion_use_range"`
+	LearningOutcomeID json.Number    `json:"learning_outcome_id"` // a string in the API's responses, but decoded either way
+	MasteryPoints     *float64       `json:"mastery_points"`      // aligned criteria only
+	Ratings           []RubricRating `json:"ratings"`
+}
+
+type RubricRating struct {
+	ID              string  `json:"id"`
+	Description     string  `json:"description"`
+	LongDescription string  `json:"long_description"`
+	Points          float64 `json:"points"`
+}
+
+// RubricAssociation attaches a rubric to an assignment, or to a course for bookmarking.
+type RubricAssociation struct {
+	ID              int    `json:"id"`
+	RubricID        int    `json:"rubric_id"`
+	AssociationID   int    `json:"association_id"`
+	AssociationType string `json:"association_type"` // Assignment, Course, or Account
+	UseForGrading   bool   `json:"use_for_grading"`
+	Purpose         string `json:"purpose"` // grading or bookmark
+	HideScoreTotal  bool   `json:"hide_score_total"`
+	HidePoints      bool   `json:"hide_points"`
+}
+
+type RubricsService struct {
+	service
+}
+
+func (api *APIManager) Rubrics() *RubricsService {
+	return &RubricsService{service{api: api}}
+}
+
+// WithContext returns a copy of the service that sends its requests with ctx.
+func (rs *RubricsService) WithContext(ctx context.Context) *RubricsService {
+	return &RubricsService{service{api: rs.api, ctx: ctx}}
+}
+
+// ListRubrics returns the rubrics of a course, including the account rubrics it has used.
+func (rs *RubricsService) ListRubrics(courseID int) ([]Rubric, error) {
+	var rubrics []Rubric
+	if err := rs.listJSON(fmt.Sprintf("courses/%d/rubrics?per_page=100", courseID), &rubrics); err != nil {
+		return nil, fmt.Errorf("error listing rubrics for course %d: %w", courseID, err)
+	}
+	return rubrics, nil
+}
+
+func (rs *RubricsService) ListAccountRubrics(accountID int) ([]Rubric, error) {
+	var rubrics []Rubric
+	if err := rs.listJSON(fmt.Sprintf("accounts/%d/rubrics?per_page=100", accountID), &rubrics); err != nil {
+		return nil, fmt.Errorf("error listing rubrics for account %d: %w", accountID, err)
+	}
+	return rubrics, nil
+}
+
+// GetRubric returns a course rubric. include can ask for "associations" (every assignment it is
+// attached to), "assignment_associations", "course_associations", or "assessments".
+func (rs *RubricsService) GetRubric(courseID, id int, include ...string) (*Rubric, error) {
+	query := url.Values{}
+	for _, inc := range include {
+		query.Add("include[]", inc)
+	}
+	var rubric Rubric
+	if err := rs.getJSON(withQuery(fmt.Sprintf("courses/%d/rubrics/%d", courseID, id), query), &rubric); err != nil {
+		return nil, fmt.Errorf("error fetching rubric %d for course %d: %w", id, courseID, err)
+	}
+	return &rubric, nil
+}
+
+// AttachToAssignment attaches a rubric to an assignment, replacing any rubric it had. With
+// useForGrading the rubric score becomes the assignment's grade.
+func (rs *RubricsService) AttachToAssignment(courseID, rubricID, assignmentID int, useForGrading bool) (*RubricAssociation, error) {
+	body := map[string]any{"rubric_association": map[string]any{
+		"rubric_id":        rubricID,
+		"association_id":   assignmentID,
+		"association_type": "Assignment",
+		"use_for_grading":  useForGrading,
+		"purpose":          "grading",
+	}}
+	var resp struct {
+		Association RubricAssociation `json:"rubric_association"` // the response also has the rubric
+	}
+	if err := rs.sendJSON(http.MethodPost, fmt.Sprintf("courses/%d/rubric_associations", courseID), body, &resp); err != nil {
+		return nil, fmt.Errorf("error attaching rubric %d to assignment %d in course %d: %w", rubricID, assignmentID, courseID, err)
+	}
+	return &resp.Association, nil
+}
+
+// DeleteAssociation detaches a rubric from its assignment. The rubric itself is kept.
+func (rs *RubricsService) DeleteAssociation(courseID, id int) error {
+	if err := rs.sendJSON(http.MethodDelete, fmt.Sprintf("courses/%d/rubric_associations/%d", courseID, id), nil, nil); err != nil {
+		return fmt.Errorf("error deleting rubric association %d in course %d: %w", id, courseID, err)
+	}
+	return nil
+}
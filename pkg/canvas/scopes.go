@@ -0,0 +1,174 @@
+package canvas
+
+import "strings"
+
+// knownScopes are the developer key scopes for the endpoints this package calls, in the format
+// Canvas lists them on the developer key page.
+var knownScopes = []string{
+	"url:GET|/api/v1/accounts/:id",
+	"url:GET|/api/v1/accounts/:account_id/courses",
+	"url:POST|/api/v1/accounts/:account_id/courses",
+	"url:GET|/api/v1/accounts/:account_id/enrollments/:id",
+	"url:GET|/api/v1/accounts/:account_id/outcome_group_links",
+	"url:GET|/api/v1/accounts/:account_id/outcome_groups",
+	"url:GET|/api/v1/accounts/:account_id/outcome_groups/:id/outcomes",
+	"url:POST|/api/v1/accounts/:account_id/outcome_groups/:id/outcomes",
+	"url:PUT|/api/v1/accounts/:account_id/outcome_groups/:id/outcomes/:outcome_id",
+	"url:GET|/api/v1/accounts/:account_id/outcome_groups/:id/subgroups",
+	"url:POST|/api/v1/accounts/:account_id/outcome_groups/:id/import",
+	"url:POST|/api/v1/accounts/:account_id/outcome_imports",
+	"url:GET|/api/v1/accounts/:account_id/outcome_imports/:id",
+	"url:GET|/api/v1/accounts/:account_id/reports",
+	"url:POST|/api/v1/accounts/:account_id/reports/:report",
+	"url:GET|/api/v1/accounts/:account_id/reports/:report/:id",
+	"url:GET|/api/v1/accounts/:account_id/root_outcome_group",
+	"url:GET|/api/v1/accounts/:account_id/roles",
+	"url:GET|/api/v1/accounts/:account_id/rubrics",
+	"url:GET|/api/v1/accounts/:account_id/sis_imports",
+	"url:POST|/api/v1/accounts/:account_id/sis_imports",
+	"url:GET|/api/v1/accounts/:account_id/sis_imports/:id",
+	"url:PUT|/api/v1/accounts/:account_id/sis_imports/:id/abort",
+	"url:GET|/api/v1/accounts/:account_id/sis_imports/:id/errors",
+	"url:GET|/api/v1/accounts/:account_id/terms",
+	"url:GET|/api/v1/accounts/:account_id/terms/:id",
+	"url:GET|/api/v1/accounts/:account_id/users",
+	"url:GET|/api/v1/announcements",
+	"url:GET|/api/v1/calendar_events",
+	"url:POST|/api/v1/calendar_events",
+	"url:GET|/api/v1/calendar_events/:id",
+	"url:PUT|/api/v1/calendar_events/:id",
+	"url:DELETE|/api/v1/calendar_events/:id",
+	"url:GET|/api/v1/courses/:id",
+	"url:PUT|/api/v1/courses/:id",
+	"url:DELETE|/api/v1/courses/:id",
+	"url:GET|/api/v1/courses/:course_id/assignments",
+	"url:POST|/api/v1/courses/:course_id/assignments",
+	"url:GET|/api/v1/courses/:course_id/assignments/:id",
+	"url:GET|/api/v1/courses/:course_id/assignments/:assignment_id/submissions",
+	"url:POST|/api/v1/courses/:course_id/assignments/:assignment_id/submissions/update_grades",
+	"url:GET|/api/v1/courses/:course_id/assignments/:assignment_id/submissions/:user_id",
+	"url:PUT|/api/v1/courses/:course_id/assignments/:assignment_id/submissions/:user_id",
+	"url:PUT|/api/v1/courses/:course_id/assignments/:id",
+	"url:DELETE|/api/v1/courses/:course_id/assignments/:id",
+	"url:GET|/api/v1/courses/:course_id/blueprint_subscriptions",
+	"url:GET|/api/v1/courses/:course_id/blueprint_templates/:template_id",
+	"url:GET|/api/v1/courses/:course_id/blueprint_templates/:template_id/associated_courses",
+	"url:PUT|/api/v1/courses/:course_id/blueprint_templates/:template_id/update_associations",
+	"url:GET|/api/v1/courses/:course_id/blueprint_templates/:template_id/migrations",
+	"url:POST|/api/v1/courses/:course_id/blueprint_templates/:template_id/migrations",
+	"url:GET|/api/v1/courses/:course_id/blueprint_templates/:template_id/migrations/:id",
+	"url:GET|/api/v1/courses/:course_id/content_migrations",
+	"url:POST|/api/v1/courses/:course_id/content_migrations",
+	"url:GET|/api/v1/courses/:course_id/content_migrations/migrators",
+	"url:GET|/api/v1/courses/:course_id/content_migrations/:id",
+	"url:GET|/api/v1/courses/:course_id/content_migrations/:content_migration_id/migration_issues",
+	"url:GET|/api/v1/courses/:course_id/discussion_topics",
+	"url:POST|/api/v1/courses/:course_id/discussion_topics",
+	"url:GET|/api/v1/courses/:course_id/discussion_topics/:topic_id",
+	"url:PUT|/api/v1/courses/:course_id/discussion_topics/:topic_id",
+	"url:DELETE|/api/v1/courses/:course_id/discussion_topics/:topic_id",
+	"url:GET|/api/v1/courses/:course_id/discussion_topics/:topic_id/entries",
+	"url:POST|/api/v1/courses/:course_id/discussion_topics/:topic_id/entries",
+	"url:DELETE|/api/v1/courses/:course_id/discussion_topics/:topic_id/entries/:id",
+	"url:PUT|/api/v1/courses/:course_id/discussion_topics/:topic_id/entries/:entry_id/read",
+	"url:GET|/api/v1/courses/:course_id/discussion_topics/:topic_id/entries/:entry_id/replies",
+	"url:PUT|/api/v1/courses/:course_id/discussion_topics/:topic_id/read",
+	"url:PUT|/api/v1/courses/:course_id/discussion_topics/:topic_id/read_all",
+	"url:GET|/api/v1/courses/:course_id/discussion_topics/:topic_id/view",
+	"url:GET|/api/v1/courses/:course_id/enrollments",
+	"url:DELETE|/api/v1/courses/:course_id/enrollments/:id",
+	"url:PUT|/api/v1/courses/:course_id/enrollments/:id/reactivate",
+	"url:POST|/api/v1/courses/:course_id/files",
+	"url:GET|/api/v1/courses/:course_id/files",
+	"url:GET|/api/v1/courses/:course_id/files/quota",
+	"url:GET|/api/v1/courses/:course_id/folders",
+	"url:GET|/api/v1/courses/:course_id/folders/by_path",
+	"url:GET|/api/v1/courses/:course_id/front_page",
+	"url:GET|/api/v1/courses/:course_id/modules",
+	"url:PUT|/api/v1/courses/:course_id/modules/:id",
+	"url:GET|/api/v1/courses/:course_id/modules/:module_id/items",
+	"url:GET|/api/v1/courses/:course_id/outcome_group_links",
+	"url:GET|/api/v1/courses/:course_id/outcome_groups",
+	"url:GET|/api/v1/courses/:course_id/outcome_groups/:id/outcomes",
+	"url:POST|/api/v1/courses/:course_id/outcome_groups/:id/outcomes",
+	"url:PUT|/api/v1/courses/:course_id/outcome_groups/:id/outcomes/:outcome_id",
+	"url:GET|/api/v1/courses/:course_id/outcome_groups/:id/subgroups",
+	"url:POST|/api/v1/courses/:course_id/outcome_groups/:id/import",
+	"url:POST|/api/v1/courses/:course_id/outcome_imports",
+	"url:GET|/api/v1/courses/:course_id/outcome_imports/:id",
+	"url:GET|/api/v1/courses/:course_id/pages",
+	"url:POST|/api/v1/courses/:course_id/pages",
+	"url:GET|/api/v1/courses/:course_id/pages/:url_or_id",
+	"url:PUT|/api/v1/courses/:course_id/pages/:url_or_id",
+	"url:DELETE|/api/v1/courses/:course_id/pages/:url_or_id",
+	"url:GET|/api/v1/courses/:course_id/quizzes",
+	"url:POST|/api/v1/courses/:course_id/quizzes",
+	"url:GET|/api/v1/courses/:course_id/quizzes/:id",
+	"url:PUT|/api/v1/courses/:course_id/quizzes/:id",
+	"url:GET|/api/v1/courses/:course_id/root_outcome_group",
+	"url:POST|/api/v1/courses/:course_id/rubric_associations",
+	"url:DELETE|/api/v1/courses/:course_id/rubric_associations/:id",
+	"url:GET|/api/v1/courses/:course_id/rubrics",
+	"url:GET|/api/v1/courses/:course_id/rubrics/:id",
+	"url:GET|/api/v1/courses/:course_id/sections",
+	"url:GET|/api/v1/courses/:course_id/quizzes/:quiz_id/submissions",
+	"url:GET|/api/v1/courses/:course_id/student_view_student",
+	"url:GET|/api/v1/courses/:course_id/students/submissions",
+	"url:GET|/api/v1/courses/:course_id/users",
+	"url:GET|/api/v1/files/:id",
+	"url:PUT|/api/v1/files/:id",
+	"url:GET|/api/v1/folders/:id",
+	"url:GET|/api/v1/folders/:id/files",
+	"url:POST|/api/v1/folders/:folder_id/files",
+	"url:GET|/api/v1/folders/:id/folders",
+	"url:GET|/api/v1/group_categories/:group_category_id",
+	"url:GET|/api/v1/group_categories/:group_category_id/users",
+	"url:POST|/api/v1/group_categories/:group_category_id/assign_unassigned_members",
+	"url:GET|/api/v1/outcomes/:id",
+	"url:GET|/api/v1/progress/:id",
+	"url:GET|/api/v1/sections/:id",
+	"url:PUT|/api/v1/sections/:id",
+	"url:POST|/api/v1/sections/:section_id/enrollments",
+	"url:POST|/api/v1/sections/:id/crosslist/:new_course_id",
+	"url:DELETE|/api/v1/sections/:id/crosslist",
+	"url:GET|/api/v1/users/:id",
+	"url:PUT|/api/v1/users/:id",
+	"url:GET|/api/v1/users/:user_id/calendar_events",
+	"url:GET|/api/v1/users/:user_id/enrollments",
+	"url:GET|/api/v1/users/:user_id/files",
+	"url:GET|/api/v1/users/:user_id/files/quota",
+	"url:GET|/api/v1/users/:user_id/folders",
+	"url:POST|/api/graphql",
+	"url:GET|/api/quiz/v1/courses/:course_id/quizzes",
+	"url:POST|/api/quiz/v1/courses/:course_id/quizzes",
+	"url:GET|/api/quiz/v1/courses/:course_id/quizzes/:assignment_id",
+	"url:GET|/api/quiz/v1/courses/:course_id/quizzes/:assignment_id/items",
+}
+
+// ScopeFor returns the developer key scope that covers a request, e.g. GET /api/v1/courses/123/modules
+// gives "url:GET|/api/v1/courses/:course_id/modules". It returns "" for endpoints it doesn't know.
+func ScopeFor(method, path string) string {
+	path, _, _ = strings.Cut(path, "?")
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	for _, scope := range knownScopes {
+		scopeMethod, pattern, _ := strings.Cut(strings.TrimPrefix(scope, "url:"), "|")
+		if scopeMethod != method {
+			continue
+		}
+		segments := strings.Split(strings.Trim(pattern, "/"), "/")
+		if len(segments) != len(parts) {
+			continue
+		}
+		match := true
+		for i, segment := range segments {
+			if !strings.HasPrefix(segment, ":") && segment != parts[i] {
+				match = false
+				break
+			}
+		}
+		if match {
+			return scope
+		}
+	}
+	return ""
+}
@@ -0,0 +1,116 @@
+package canvas
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+type Section struct {
+	ID                                int        `json:"id"`
+	Name                              string     `json:"name"`
+	CourseID                          int        `json:"course_id"`
+	SISCourseID                       string     `json:"sis_course_id"`
+	SISSectionID                      string     `json:"sis_section_id"`
+	IntegrationID                     string     `json:"integration_id"`
+	StartAt                           *time.Time `json:"start_at"`
+	EndAt                             *time.Time `json:"end_at"`
+	RestrictEnrollmentsToSectionDates bool       `json:"restrict_enrollments_to_section_dates"`
+	NonxlistCourseID                  *int       `json:"nonxlist_course_id"` // the original course while cross-listed
+	TotalStudents                     int        `json:"total_students"`     // only set when fetched with include total_students
+}
+
+// CrossListed reports whether the section has been moved out of the course it was created in.
+func (s Section) CrossListed() bool {
+	return s.NonxlistCourseID != nil
+}
+
+// SectionUpdate holds the section attributes to change. Nil fields are left untouched.
+type SectionUpdate struct {
+	Name                              *string    `json:"name,omitempty"`
+	SISSectionID                      *string    `json:"sis_section_id,omitempty"`
+	StartAt                           *time.Time `json:"start_at,omitempty"`
+	EndAt                             *time.Time `json:"end_at,omitempty"`
+	RestrictEnrollmentsToSectionDates *bool      `json:"restrict_enrollments_to_section_dates,omitempty"`
+
+	OverrideSISStickiness *bool `json:"-"` // sent as override_sis_stickiness when set
+}
+
+type SectionsService struct {
+	service
+}
+
+func (api *APIManager) Sections() *SectionsService {
+	return &SectionsService{service{api: api}}
+}
+
+// WithContext returns a copy of the service that sends its requests with ctx.
+func (ss *SectionsService) WithContext(ctx context.Context) *SectionsService {
+	return &SectionsService{service{api: ss.api, ctx: ctx}}
+}
+
+// ListCourseSections returns the sections of a course, cross-listed sections included. Pass
+// "total_students" in include for the enrollment counts.
+func (ss *SectionsService) ListCourseSections(courseID int, include ...string) ([]Section, error) {
+	query := url.Values{}
+	query.Set("per_page", perPage(0))
+	for _, inc := range include {
+		query.Add("include[]", inc)
+	}
+	var sections []Section
+	if err := ss.listJSON(withQuery(fmt.Sprintf("courses/%d/sections", courseID), query), &sections); err != nil {
+		return nil, fmt.Errorf("error listing sections for course %d: %w", courseID, err)
+	}
+	return sections, nil
+}
+
+func (ss *SectionsService) GetSection(id int, include ...string) (*Section, error) {
+	query := url.Values{}
+	for _, inc := range include {
+		query.Add("include[]", inc)
+	}
+	var section Section
+	if err := ss.getJSON(withQuery(fmt.Sprintf("sections/%d", id), query), &section); err != nil {
+		return nil, fmt.Errorf("error fetching section %d: %w", id, err)
+	}
+	return &section, nil
+}
+
+// GetSectionBySISID fetches a section by its SIS section ID.
+func (ss *SectionsService) GetSectionBySISID(sisID string) (*Section, error) {
+	var section Section
+	if err := ss.getJSON("sections/sis_section_id:"+url.PathEscape(sisID), &section); err != nil {
+		return nil, fmt.Errorf("error fetching section %s: %w", sisID, err)
+	}
+	return &section, nil
+}
+
+func (ss *SectionsService) UpdateSection(id int, update SectionUpdate) (*Section, error) {
+	body := updateBody("course_section", update, update.OverrideSISStickiness)
+	var section Section
+	if err := ss.sendJSON(http.MethodPut, fmt.Sprintf("sections/%d", id), body, &section); err != nil {
+		return nil, fmt.Errorf("error updating section %d: %w", id, err)
+	}
+	return &section, nil
+}
+
+// CrossListSection moves a section into another course. Its enrollments move with it, and
+// UnCrossListSection moves it back.
+func (ss *SectionsService) CrossListSection(id, newCourseID int) (*Section, error) {
+	var section Section
+	if err := ss.sendJSON(http.MethodPost, fmt.Sprintf("sections/%d/crosslist/%d", id, newCourseID), nil, &section); err != nil {
+		return nil, fmt.Errorf("error cross-listing section %d into course %d: %w", id, newCourseID, err)
+	}
+	return &section, nil
+}
+
+// UnCrossListSection returns a cross-listed section to the course it was created in.
+func (ss *SectionsService) UnCrossListSection(id int) (*Section, error) {
+	var section Section
+	if err := ss.sendJSON(http.MethodDelete, fmt.Sprintf("sections/%d/crosslist", id), nil, &section); err != nil {
+		return nil, fmt.Errorf("error removing cross-listing of section %d: %w", id, err)
+	}
+	return &section, nil
+}
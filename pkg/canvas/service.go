@@ -0,0 +1,89 @@
+package canvas
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// service holds what every typed service needs: the APIManager to send requests with and an optional context.
+type service struct {
+	api *APIManager
+	ctx context.Context
+}
+
+func (s service) context() context.Context {
+	if s.ctx == nil {
+		return context.Background()
+	}
+	return s.ctx
+}
+
+// getJSON fetches a single object and decodes it into v.
+func (s service) getJSON(endpoint string, v any) error {
+	return getInto(s.context(), s.api, endpoint, v)
+}
+
+// listJSON fetches every page of a list endpoint and decodes the items into v, which must be a
+// pointer to a slice, as the pages arrive.
+func (s service) listJSON(endpoint string, v any) error {
+	return listInto(s.context(), s.api, endpoint, v)
+}
+
+// sendJSON sends body (if any) with the given method and decodes the response into v (if not nil).
+func (s service) sendJSON(method, endpoint string, body any, v any) error {
+	var data []byte
+	if body != nil {
+		var err error
+		data, err = json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("error encoding request body for %s: %w", endpoint, err)
+		}
+	}
+	resp, err := s.api.do(s.context(), method, endpoint, data)
+	if err != nil {
+		return fmt.Errorf("error sending %s %s: %w", method, endpoint, err)
+	}
+	defer resp.Body.Close() // Ensure the response body is closed after reading
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return NewAPIError(resp)
+	}
+	if v == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("error decoding response from %s: %w", endpoint, err)
+	}
+	return nil
+}
+
+// updateBody wraps an update under key, e.g. {"course": update}, and adds override_sis_stickiness when it is set.
+// Fields changed by a SIS import are "sticky" and Canvas can skip them on update without reporting an error.
+func updateBody(key string, update any, overrideSticky *bool) map[string]any {
+	body := map[string]any{key: update}
+	if overrideSticky != nil {
+		body["override_sis_stickiness"] = *overrideSticky
+	}
+	return body
+}
+
+// withQuery appends the encoded query values to the endpoint, after any query it already has.
+func withQuery(endpoint string, query url.Values) string {
+	if len(query) == 0 {
+		return endpoint
+	}
+	if strings.Contains(endpoint, "?") {
+		return endpoint + "&" + query.Encode()
+	}
+	return endpoint + "?" + query.Encode()
+}
+
+func perPage(n int) string {
+	if n <= 0 {
+		n = 100
+	}
+	return strconv.Itoa(n)
+}
@@ -0,0 +1,194 @@
+package canvas_test
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/coraxwolf/CCTA_3-4/pkg/canvas"
+)
+
+func TestListAccountCoursesFilters(t *testing.T) {
+	srv, api := newServer(t)
+	srv.AddCourse(canvas.Course{Name: "Biology 101", EnrollmentTermID: 5, WorkflowState: "available"})
+	srv.AddCourse(canvas.Course{Name: "Biology 102", EnrollmentTermID: 5})
+	srv.AddCourse(canvas.Course{Name: "Biology 101", EnrollmentTermID: 6, WorkflowState: "available"})
+	srv.AddCourse(canvas.Course{Name: "Chemistry 101", EnrollmentTermID: 5, WorkflowState: "available"})
+
+	published := true
+	courses, err := api.Courses().ListAccountCourses(1, canvas.CourseListOptions{
+		SearchTerm:       "biology",
+		EnrollmentTermID: 5,
+		Published:        &published,
+		PerPage:          1,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(courses) != 1 || courses[0].Name != "Biology 101" || courses[0].EnrollmentTermID != 5 {
+		t.Errorf("got %+v, want the published Biology 101 of term 5", courses)
+	}
+	query := srv.Requests()[0].Query
+	if query.Get("search_term") != "biology" || query.Get("enrollment_term_id") != "5" || query.Get("published") != "true" {
+		t.Errorf("sent query %v", query)
+	}
+}
+
+func TestGetAndUpdateCourse(t *testing.T) {
+	srv, api := newServer(t)
+	c := srv.AddCourse(canvas.Course{Name: "Biology 101", CourseCode: "BIO-101"})
+
+	got, err := api.Courses().GetCourse(c.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.CourseCode != "BIO-101" || got.WorkflowState != "unpublished" {
+		t.Errorf("got %+v", got)
+	}
+
+	name := "Biology 101 (Fall)"
+	updated, err := api.Courses().UpdateCourse(c.ID, canvas.CourseUpdate{Name: &name, EventAction: "offer"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.Name != name || updated.WorkflowState != "available" {
+		t.Errorf("update returned %+v", updated)
+	}
+	stored, _ := srv.Course(c.ID)
+	if stored.Name != name || stored.CourseCode != "BIO-101" {
+		t.Errorf("server has %+v, want the new name and the code untouched", stored)
+	}
+}
+
+func TestFindTerm(t *testing.T) {
+	srv, api := newServer(t)
+	srv.AddTerm(canvas.Term{Name: "Fall 2025", SISTermID: "2025FA"})
+	spring := srv.AddTerm(canvas.Term{Name: "Spring 2026", SISTermID: "2026SP"})
+
+	term, err := api.Terms().FindTerm(1, "Spring 2026")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if term.ID != spring.ID {
+		t.Errorf("found term %d, want %d", term.ID, spring.ID)
+	}
+}
+
+func TestModulesWithItems(t *testing.T) {
+	srv, api := newServer(t)
+	c := srv.AddCourse(canvas.Course{Name: "Biology 101"})
+	week1 := srv.AddModule(c.ID, canvas.Module{Name: "Week 1"})
+	srv.AddModuleItem(week1.ID, canvas.ModuleItem{Title: "Syllabus", Type: "Page"})
+	srv.AddModuleItem(week1.ID, canvas.ModuleItem{Title: "Quiz 1", Type: "Quiz"})
+	srv.AddModule(c.ID, canvas.Module{Name: "Week 2", Published: true})
+
+	modules, err := api.Modules().ListModules(c.ID, "items")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(modules) != 2 || len(modules[0].Items) != 2 || modules[0].Items[1].Title != "Quiz 1" {
+		t.Fatalf("got %+v", modules)
+	}
+	if modules[0].Published || !modules[1].Published {
+		t.Errorf("published flags are %v and %v, want false and true", modules[0].Published, modules[1].Published)
+	}
+
+	items, err := api.Modules().ListModuleItems(c.ID, week1.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 2 || items[0].Position != 1 {
+		t.Errorf("got items %+v", items)
+	}
+
+	module, err := api.Modules().PublishModule(c.ID, week1.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !module.Published || module.WorkflowState != "active" {
+		t.Errorf("publish returned %+v", module)
+	}
+}
+
+func TestGetAndUpdateUser(t *testing.T) {
+	srv, api := newServer(t)
+	u := srv.AddUser(canvas.User{Name: "Ada Lovelace", SISUserID: "A001"})
+
+	got, err := api.Users().GetUser("sis_user_id:A001")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.ID != u.ID {
+		t.Errorf("got user %d, want %d", got.ID, u.ID)
+	}
+
+	email := "ada@example.edu"
+	if _, err := api.Users().UpdateUser(strconv.Itoa(got.ID), canvas.UserUpdate{Email: &email}); err != nil {
+		t.Fatal(err)
+	}
+	stored, _ := srv.User(u.ID)
+	if stored.Email != email || stored.Name != "Ada Lovelace" {
+		t.Errorf("server has %+v", stored)
+	}
+}
+
+func TestUploadAndRenameFile(t *testing.T) {
+	srv, api := newServer(t)
+	c := srv.AddCourse(canvas.Course{Name: "Biology 101"})
+
+	file, err := api.Files().UploadFile(c.ID, "", strings.NewReader("hello"), "notes.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	stored, data, ok := srv.File(file.ID)
+	if !ok || string(data) != "hello" || stored.ContentType != "text/plain; charset=utf-8" {
+		t.Fatalf("server has %+v with %q", stored, data)
+	}
+
+	renamed, err := api.Files().RenameFile(file.ID, "lecture-notes.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if renamed.DisplayName != "lecture-notes.txt" {
+		t.Errorf("rename returned %q", renamed.DisplayName)
+	}
+	files, err := api.Files().ListCourseFiles(c.ID, canvas.FileListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 || files[0].DisplayName != "lecture-notes.txt" {
+		t.Errorf("course files are %+v", files)
+	}
+}
+
+func TestWithHeaderKeepsAuthorization(t *testing.T) {
+	srv, api := newServer(t)
+	c := srv.AddCourse(canvas.Course{Name: "Biology 101"})
+
+	course, err := canvas.GetJSON[canvas.Course](api, fmt.Sprintf("courses/%d", c.ID),
+		canvas.WithHeader("Authorization", "Bearer someone-else"), canvas.WithHeader("Accept", "text/html"))
+	if err != nil {
+		t.Fatalf("the option headers replaced the token: %v", err)
+	}
+	if course.ID != c.ID {
+		t.Errorf("got course %d", course.ID)
+	}
+}
+
+func TestListEmptyCourse(t *testing.T) {
+	srv, api := newServer(t)
+	c := srv.AddCourse(canvas.Course{Name: "Biology 101"})
+
+	files, err := api.Files().ListCourseFiles(c.ID, canvas.FileListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if files == nil || len(files) != 0 {
+		t.Errorf("got %#v, want an empty slice", files)
+	}
+	if _, err := api.Modules().ListModules(c.ID + 1); !errors.Is(err, canvas.ErrNotFound) {
+		t.Errorf("listing the modules of a missing course gave %v, want ErrNotFound", err)
+	}
+}
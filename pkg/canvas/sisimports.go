@@ -0,0 +1,249 @@
+package canvas
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SISImport is a SIS data import and its processing state. ProcessingWarnings and ProcessingErrors
+// hold [file, message] pairs; Canvas truncates them, so use ListImportErrors for every row.
+type SISImport struct {
+	ID                 int            `json:"id"`
+	WorkflowState      string         `json:"workflow_state"` // created, importing, imported, imported_with_messages, failed, failed_with_messages, aborted, ...
+	Progress           int            `json:"progress"`       // percent complete
+	CreatedAt          *time.Time     `json:"created_at"`
+	UpdatedAt          *time.Time     `json:"updated_at"`
+	EndedAt            *time.Time     `json:"ended_at"`
+	BatchMode          bool           `json:"batch_mode"`
+	BatchModeTermID    *int           `json:"batch_mode_term_id"`
+	OverrideSticky     bool           `json:"override_sis_stickiness"`
+	Data               SISImportData  `json:"data"`
+	ProcessingWarnings [][]string     `json:"processing_warnings"`
+	ProcessingErrors   [][]string     `json:"processing_errors"`
+	ErrorsAttachment   *SISAttachment `json:"errors_attachment"` // CSV of every warning and error
+}
+
+type SISImportData struct {
+	ImportType      string         `json:"import_type"`
+	SuppliedBatches []string       `json:"supplied_batches"` // e.g. user, course, enrollment
+	Counts          map[string]int `json:"counts"`           // rows imported per kind
+}
+
+type SISAttachment struct {
+	ID          int    `json:"id"`
+	DisplayName string `json:"display_name"`
+	URL         string `json:"url"`
+}
+
+// Done reports whether the import has finished, successfully or not.
+func (si SISImport) Done() bool {
+	switch si.WorkflowState {
+	case "imported", "imported_with_messages", "failed", "failed_with_messages", "aborted", "restored", "partially_restored":
+		return true
+	}
+	return false
+}
+
+// Failed reports whether the import ended without importing its data.
+func (si SISImport) Failed() bool {
+	return si.WorkflowState == "failed" || si.WorkflowState == "failed_with_messages" || si.WorkflowState == "aborted"
+}
+
+// SISImportError is one warning or error row of an import.
+type SISImportError struct {
+	SISImportID int    `json:"sis_import_id"`
+	File        string `json:"file"`
+	Message     string `json:"message"`
+	Row         *int   `json:"row"`
+	RowInfo     string `json:"row_info"` // the offending CSV line
+}
+
+// SISImportOptions are the settings sent with an import.
+type SISImportOptions struct {
+	ImportType      string // default instructure_csv
+	BatchMode       bool   // delete term data not in the import; requires BatchModeTermID
+	BatchModeTermID string // Canvas term ID or sis_term_id:<id>
+	OverrideSticky  bool   // overwrite values changed in Canvas since the last import
+	AddSticky       bool
+	ClearSticky     bool
+	DiffingDataSet  string // diff against the last import with this identifier and only apply changes
+	ChangeThreshold int    // with DiffingDataSet, abort when more than this percent of rows would be deleted
+}
+
+// SISImportListOptions narrows ListImports.
+type SISImportListOptions struct {
+	CreatedSince  *time.Time
+	WorkflowState []string
+}
+
+type SISImportsService struct {
+	service
+}
+
+func (api *APIManager) SISImports() *SISImportsService {
+	return &SISImportsService{service{api: api}}
+}
+
+// WithContext returns a copy of the service that sends its requests with ctx.
+func (ss *SISImportsService) WithContext(ctx context.Context) *SISImportsService {
+	return &SISImportsService{service{api: ss.api, ctx: ctx}}
+}
+
+// ImportFile uploads a SIS CSV file, or a ZIP of CSV files, to the account. Canvas processes it in
+// the background; wait for the result with WaitImport.
+func (ss *SISImportsService) ImportFile(accountID int, path string, opts SISImportOptions) (*SISImport, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("error opening SIS import file %s: %w", path, err)
+	}
+	defer f.Close()
+	return ss.Import(accountID, filepath.Base(path), f, opts)
+}
+
+// Import uploads SIS data read from r as a multipart attachment named filename. The extension of
+// filename (.csv or .zip) tells Canvas how to read it.
+func (ss *SISImportsService) Import(accountID int, filename string, r io.Reader, opts SISImportOptions) (*SISImport, error) {
+	body, contentType, err := opts.multipart(filename, r)
+	if err != nil {
+		return nil, fmt.Errorf("error preparing SIS import %s: %w", filename, err)
+	}
+	endpoint := fmt.Sprintf("accounts/%d/sis_imports", accountID)
+	resp, err := ss.api.doContent(ss.context(), http.MethodPost, endpoint, contentType, body)
+	if err != nil {
+		return nil, fmt.Errorf("error uploading SIS import %s: %w", filename, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, NewAPIError(resp)
+	}
+	var sisImport SISImport
+	if err := json.NewDecoder(resp.Body).Decode(&sisImport); err != nil {
+		return nil, fmt.Errorf("error decoding response from %s: %w", endpoint, err)
+	}
+	return &sisImport, nil
+}
+
+func (opts SISImportOptions) multipart(filename string, r io.Reader) ([]byte, string, error) {
+	ext := strings.TrimPrefix(strings.ToLower(filepath.Ext(filename)), ".")
+	if ext != "csv" && ext != "zip" {
+		return nil, "", fmt.Errorf("expected a .csv or .zip file")
+	}
+	importType := opts.ImportType
+	if importType == "" {
+		importType = "instructure_csv"
+	}
+	fields := [][2]string{{"import_type", importType}, {"extension", ext}}
+	if opts.BatchMode {
+		if opts.BatchModeTermID == "" {
+			return nil, "", fmt.Errorf("batch mode needs a term")
+		}
+		fields = append(fields, [2]string{"batch_mode", "true"}, [2]string{"batch_mode_term_id", opts.BatchModeTermID})
+	}
+	if opts.OverrideSticky {
+		fields = append(fields, [2]string{"override_sis_stickiness", "true"})
+		if opts.AddSticky {
+			fields = append(fields, [2]string{"add_sis_stickiness", "true"})
+		}
+		if opts.ClearSticky {
+			fields = append(fields, [2]string{"clear_sis_stickiness", "true"})
+		}
+	}
+	if opts.DiffingDataSet != "" {
+		fields = append(fields, [2]string{"diffing_data_set_identifier", opts.DiffingDataSet})
+		if opts.ChangeThreshold > 0 {
+			fields = append(fields, [2]string{"change_threshold", strconv.Itoa(opts.ChangeThreshold)})
+		}
+	}
+
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	for _, field := range fields {
+		if err := mw.WriteField(field[0], field[1]); err != nil {
+			return nil, "", err
+		}
+	}
+	part, err := mw.CreateFormFile("attachment", filename)
+	if err != nil {
+		return nil, "", err
+	}
+	if _, err := io.Copy(part, r); err != nil {
+		return nil, "", err
+	}
+	if err := mw.Close(); err != nil {
+		return nil, "", err
+	}
+	return buf.Bytes(), mw.FormDataContentType(), nil
+}
+
+func (ss *SISImportsService) GetImport(accountID, id int) (*SISImport, error) {
+	var sisImport SISImport
+	if err := ss.getJSON(fmt.Sprintf("accounts/%d/sis_imports/%d", accountID, id), &sisImport); err != nil {
+		return nil, fmt.Errorf("error fetching SIS import %d: %w", id, err)
+	}
+	return &sisImport, nil
+}
+
+// ListImports returns the account's SIS imports, newest first.
+func (ss *SISImportsService) ListImports(accountID int, opts SISImportListOptions) ([]SISImport, error) {
+	query := url.Values{}
+	query.Set("per_page", perPage(0))
+	if opts.CreatedSince != nil {
+		query.Set("created_since", opts.CreatedSince.Format(time.RFC3339))
+	}
+	for _, state := range opts.WorkflowState {
+		query.Add("workflow_state[]", state)
+	}
+	var imports []SISImport
+	if err := ss.listJSON(withQuery(fmt.Sprintf("accounts/%d/sis_imports", accountID), query), &imports); err != nil {
+		return nil, fmt.Errorf("error listing SIS imports for account %d: %w", accountID, err)
+	}
+	return imports, nil
+}
+
+// ListImportErrors returns every warning and error row of an import.
+func (ss *SISImportsService) ListImportErrors(accountID, id int) ([]SISImportError, error) {
+	query := url.Values{}
+	query.Set("per_page", perPage(0))
+	var errs []SISImportError
+	if err := ss.listJSON(withQuery(fmt.Sprintf("accounts/%d/sis_imports/%d/errors", accountID, id), query), &errs); err != nil {
+		return nil, fmt.Errorf("error listing errors of SIS import %d: %w", id, err)
+	}
+	return errs, nil
+}
+
+// AbortImport stops an import that hasn't finished.
+func (ss *SISImportsService) AbortImport(accountID, id int) (*SISImport, error) {
+	var sisImport SISImport
+	if err := ss.sendJSON(http.MethodPut, fmt.Sprintf("accounts/%d/sis_imports/%d/abort", accountID, id), nil, &sisImport); err != nil {
+		return nil, fmt.Errorf("error aborting SIS import %d: %w", id, err)
+	}
+	return &sisImport, nil
+}
+
+// WaitImport polls an import every interval until it finishes and returns its final state. It ends
+// early with the context error if the service's context is cancelled.
+func (ss *SISImportsService) WaitImport(accountID, id int, interval time.Duration) (*SISImport, error) {
+	for {
+		sisImport, err := ss.GetImport(accountID, id)
+		if err != nil {
+			return nil, err
+		}
+		if sisImport.Done() {
+			return sisImport, nil
+		}
+		if err := sleepCtx(ss.context(), interval); err != nil {
+			return sisImport, err
+		}
+	}
+}
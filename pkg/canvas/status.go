@@ -0,0 +1,120 @@
+package canvas
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// DefaultStatusURL is the summary feed of the Instructure status page.
+const DefaultStatusURL = "https://status.instructure.com/api/v2/summary.json"
+
+// ServiceStatus is the Canvas part of the status page: unresolved incidents and maintenance.
+// Incidents and maintenance for other Instructure products are left out.
+type ServiceStatus struct {
+	Incidents   []StatusNotice
+	Maintenance []StatusNotice // in progress or scheduled
+}
+
+type StatusNotice struct {
+	Name   string
+	Status string // e.g. investigating, identified, scheduled, in_progress
+	Impact string // none, minor, major, critical, or maintenance
+	Start  time.Time
+	End    time.Time // zero when unknown
+}
+
+// Disrupted returns the first incident or maintenance that makes a run pointless: maintenance
+// in progress, or an incident with major or critical impact.
+func (s ServiceStatus) Disrupted() (StatusNotice, bool) {
+	for _, m := range s.Maintenance {
+		if m.Status == "in_progress" {
+			return m, true
+		}
+	}
+	for _, i := range s.Incidents {
+		if i.Impact == "major" || i.Impact == "critical" {
+			return i, true
+		}
+	}
+	return StatusNotice{}, false
+}
+
+type statusSummary struct {
+	Incidents             []statusIncident `json:"incidents"`
+	ScheduledMaintenances []statusIncident `json:"scheduled_maintenances"`
+}
+
+type statusIncident struct {
+	Name           string     `json:"name"`
+	Status         string     `json:"status"`
+	Impact         string     `json:"impact"`
+	CreatedAt      time.Time  `json:"created_at"`
+	ScheduledFor   *time.Time `json:"scheduled_for"`
+	ScheduledUntil *time.Time `json:"scheduled_until"`
+	Components     []struct {
+		Name string `json:"name"`
+	} `json:"components"`
+}
+
+// affectsCanvas reports whether the incident lists a Canvas component, or no components at all.
+func (i statusIncident) affectsCanvas() bool {
+	if len(i.Components) == 0 {
+		return true
+	}
+	for _, c := range i.Components {
+		if strings.Contains(strings.ToLower(c.Name), "canvas") {
+			return true
+		}
+	}
+	return false
+}
+
+func (i statusIncident) notice() StatusNotice {
+	n := StatusNotice{Name: i.Name, Status: i.Status, Impact: i.Impact, Start: i.CreatedAt}
+	if i.ScheduledFor != nil {
+		n.Start = *i.ScheduledFor
+	}
+	if i.ScheduledUntil != nil {
+		n.End = *i.ScheduledUntil
+	}
+	return n
+}
+
+// CheckStatus reads the status page summary at url, e.g. DefaultStatusURL.
+func CheckStatus(ctx context.Context, url string) (ServiceStatus, error) {
+	var status ServiceStatus
+	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return status, err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return status, fmt.Errorf("error reading status page: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return status, fmt.Errorf("error reading status page: %s", resp.Status)
+	}
+	var summary statusSummary
+	if err := json.NewDecoder(resp.Body).Decode(&summary); err != nil {
+		return status, fmt.Errorf("error decoding status page: %w", err)
+	}
+	for _, i := range summary.Incidents {
+		if i.Status != "resolved" && i.Status != "postmortem" && i.affectsCanvas() {
+			status.Incidents = append(status.Incidents, i.notice())
+		}
+	}
+	for _, m := range summary.ScheduledMaintenances {
+		if m.Status != "completed" && m.affectsCanvas() {
+			status.Maintenance = append(status.Maintenance, m.notice())
+		}
+	}
+	return status, nil
+}
@@ -0,0 +1,174 @@
+package canvas
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+type Submission struct {
+	ID                            int                 `json:"id"`
+	AssignmentID                  int                 `json:"assignment_id"`
+	UserID                        int                 `json:"user_id"`
+	Score                         *float64            `json:"score"` // nil until graded
+	Grade                         string              `json:"grade"`
+	EnteredScore                  *float64            `json:"entered_score"` // before late policy deductions
+	EnteredGrade                  string              `json:"entered_grade"`
+	WorkflowState                 string              `json:"workflow_state"` // unsubmitted, submitted, pending_review, or graded
+	SubmissionType                string              `json:"submission_type"`
+	Attempt                       int                 `json:"attempt"`
+	SubmittedAt                   *time.Time          `json:"submitted_at"`
+	GradedAt                      *time.Time          `json:"graded_at"`
+	PostedAt                      *time.Time          `json:"posted_at"`
+	GraderID                      *int                `json:"grader_id"`
+	Late                          bool                `json:"late"`
+	Missing                       bool                `json:"missing"`
+	Excused                       bool                `json:"excused"`
+	LatePolicyStatus              string              `json:"late_policy_status"` // late, missing, extended, none, or empty
+	PointsDeducted                *float64            `json:"points_deducted"`
+	SecondsLate                   int                 `json:"seconds_late"`
+	GradeMatchesCurrentSubmission bool                `json:"grade_matches_current_submission"`
+	SubmissionComments            []SubmissionComment `json:"submission_comments"` // with include submission_comments
+}
+
+type SubmissionComment struct {
+	ID         int        `json:"id"`
+	AuthorID   int        `json:"author_id"`
+	AuthorName string     `json:"author_name"`
+	Comment    string     `json:"comment"` // plain text
+	CreatedAt  *time.Time `json:"created_at"`
+}
+
+// SubmissionListOptions narrows ListStudentSubmissions.
+type SubmissionListOptions struct {
+	StudentIDs    []string // user IDs or sis_user_id:<id>; empty lists every student's submissions
+	AssignmentIDs []int
+	WorkflowState string
+	Include       []string // e.g. assignment, submission_comments
+}
+
+// GradeInput is a grade change for one submission. Nil fields are left untouched.
+type GradeInput struct {
+	PostedGrade         *string `json:"posted_grade,omitempty"` // points, percentage ("85%"), letter grade, or pass/fail
+	Excuse              *bool   `json:"excuse,omitempty"`
+	LatePolicyStatus    *string `json:"late_policy_status,omitempty"` // late, missing, extended, or none
+	SecondsLateOverride *int    `json:"seconds_late_override,omitempty"`
+	Comment             string  `json:"-"` // added as a submission comment when set
+}
+
+func (g GradeInput) body() map[string]any {
+	body := map[string]any{"submission": g}
+	if g.Comment != "" {
+		body["comment"] = map[string]string{"text_comment": g.Comment}
+	}
+	return body
+}
+
+type SubmissionsService struct {
+	service
+}
+
+func (api *APIManager) Submissions() *SubmissionsService {
+	return &SubmissionsService{service{api: api}}
+}
+
+// WithContext returns a copy of the service that sends its requests with ctx.
+func (ss *SubmissionsService) WithContext(ctx context.Context) *SubmissionsService {
+	return &SubmissionsService{service{api: ss.api, ctx: ctx}}
+}
+
+// ListAssignmentSubmissions returns the submissions for an assignment, one per student.
+func (ss *SubmissionsService) ListAssignmentSubmissions(courseID, assignmentID int, include ...string) ([]Submission, error) {
+	query := url.Values{}
+	query.Set("per_page", perPage(0))
+	for _, inc := range include {
+		query.Add("include[]", inc)
+	}
+	var submissions []Submission
+	endpoint := fmt.Sprintf("courses/%d/assignments/%d/submissions", courseID, assignmentID)
+	if err := ss.listJSON(withQuery(endpoint, query), &submissions); err != nil {
+		return nil, fmt.Errorf("error listing submissions for assignment %d in course %d: %w", assignmentID, courseID, err)
+	}
+	return submissions, nil
+}
+
+// ListStudentSubmissions returns the submissions of the given students (all students when none
+// are given) across the course's assignments.
+func (ss *SubmissionsService) ListStudentSubmissions(courseID int, opts SubmissionListOptions) ([]Submission, error) {
+	query := url.Values{}
+	query.Set("per_page", perPage(0))
+	if len(opts.StudentIDs) == 0 {
+		query.Add("student_ids[]", "all")
+	}
+	for _, id := range opts.StudentIDs {
+		query.Add("student_ids[]", id)
+	}
+	for _, id := range opts.AssignmentIDs {
+		query.Add("assignment_ids[]", strconv.Itoa(id))
+	}
+	if opts.WorkflowState != "" {
+		query.Set("workflow_state", opts.WorkflowState)
+	}
+	for _, inc := range opts.Include {
+		query.Add("include[]", inc)
+	}
+	var submissions []Submission
+	if err := ss.listJSON(withQuery(fmt.Sprintf("courses/%d/students/submissions", courseID), query), &submissions); err != nil {
+		return nil, fmt.Errorf("error listing student submissions in course %d: %w", courseID, err)
+	}
+	return submissions, nil
+}
+
+// GetSubmission returns a student's submission for an assignment. userID may be a Canvas ID or sis_user_id:<id>.
+func (ss *SubmissionsService) GetSubmission(courseID, assignmentID int, userID string) (*Submission, error) {
+	var submission Submission
+	if err := ss.getJSON(fmt.Sprintf("courses/%d/assignments/%d/submissions/%s", courseID, assignmentID, url.PathEscape(userID)), &submission); err != nil {
+		return nil, fmt.Errorf("error fetching submission of user %s for assignment %d: %w", userID, assignmentID, err)
+	}
+	return &submission, nil
+}
+
+// GradeSubmission sets a student's grade, excused state, or late policy status for an assignment.
+func (ss *SubmissionsService) GradeSubmission(courseID, assignmentID int, userID string, grade GradeInput) (*Submission, error) {
+	var submission Submission
+	endpoint := fmt.Sprintf("courses/%d/assignments/%d/submissions/%s", courseID, assignmentID, url.PathEscape(userID))
+	if err := ss.sendJSON(http.MethodPut, endpoint, grade.body(), &submission); err != nil {
+		return nil, fmt.Errorf("error grading submission of user %s for assignment %d: %w", userID, assignmentID, err)
+	}
+	return &submission, nil
+}
+
+// UpdateGrades grades many students for one assignment in a single request. grades is keyed by
+// Canvas user ID or sis_user_id:<id>. Canvas applies the grades in the background; wait for the
+// returned job with WaitProgress.
+func (ss *SubmissionsService) UpdateGrades(courseID, assignmentID int, grades map[string]GradeInput) (*Progress, error) {
+	data := make(map[string]map[string]any, len(grades))
+	for userID, grade := range grades {
+		entry := make(map[string]any)
+		if grade.PostedGrade != nil {
+			entry["posted_grade"] = *grade.PostedGrade
+		}
+		if grade.Excuse != nil {
+			entry["excuse"] = *grade.Excuse
+		}
+		if grade.LatePolicyStatus != nil {
+			entry["late_policy_status"] = *grade.LatePolicyStatus
+		}
+		if grade.SecondsLateOverride != nil {
+			entry["seconds_late_override"] = *grade.SecondsLateOverride
+		}
+		if grade.Comment != "" {
+			entry["text_comment"] = grade.Comment
+		}
+		data[userID] = entry
+	}
+	var progress Progress
+	endpoint := fmt.Sprintf("courses/%d/assignments/%d/submissions/update_grades", courseID, assignmentID)
+	if err := ss.sendJSON(http.MethodPost, endpoint, map[string]any{"grade_data": data}, &progress); err != nil {
+		return nil, fmt.Errorf("error updating grades for assignment %d in course %d: %w", assignmentID, courseID, err)
+	}
+	return &progress, nil
+}
@@ -0,0 +1,89 @@
+package canvas
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Term struct {
+	ID            int        `json:"id"`
+	Name          string     `json:"name"`
+	SISTermID     string     `json:"sis_term_id"`
+	WorkflowState string     `json:"workflow_state"`
+	StartAt       *time.Time `json:"start_at"`
+	EndAt         *time.Time `json:"end_at"`
+}
+
+type TermsService struct {
+	service
+}
+
+func (api *APIManager) Terms() *TermsService {
+	return &TermsService{service{api: api}}
+}
+
+// WithContext returns a copy of the service that sends its requests with ctx.
+func (ts *TermsService) WithContext(ctx context.Context) *TermsService {
+	return &TermsService{service{api: ts.api, ctx: ctx}}
+}
+
+// ListTerms returns the enrollment terms of a root account.
+func (ts *TermsService) ListTerms(accountID int) ([]Term, error) {
+	var terms []Term
+	if err := ts.listJSON(fmt.Sprintf("accounts/%d/terms?per_page=100", accountID), &terms); err != nil {
+		return nil, fmt.Errorf("error listing terms for account %d: %w", accountID, err)
+	}
+	return terms, nil
+}
+
+// GetTerm fetches a term from the root account of the Canvas instance.
+func (ts *TermsService) GetTerm(id int) (*Term, error) {
+	var term Term
+	if err := ts.getJSON(fmt.Sprintf("accounts/self/terms/%d", id), &term); err != nil {
+		return nil, fmt.Errorf("error fetching term %d: %w", id, err)
+	}
+	return &term, nil
+}
+
+// FindTerm lists the account's terms and returns the one matching query. See ResolveTerm.
+func (ts *TermsService) FindTerm(accountID int, query string) (*Term, error) {
+	terms, err := ts.ListTerms(accountID)
+	if err != nil {
+		return nil, err
+	}
+	return ResolveTerm(terms, query)
+}
+
+// ResolveTerm finds the term named by query, which can be a Canvas term ID, a SIS term ID
+// such as "6253" (a trailing "-" from a SIS prefix is ignored), or a term name such as "Summer 2025".
+func ResolveTerm(terms []Term, query string) (*Term, error) {
+	query = strings.TrimSuffix(strings.TrimSpace(query), "-")
+	if query == "" {
+		return nil, fmt.Errorf("empty term")
+	}
+	matchers := []func(Term) bool{
+		func(t Term) bool { return t.SISTermID == query },
+		func(t Term) bool { return strings.EqualFold(t.Name, query) },
+		func(t Term) bool { return strconv.Itoa(t.ID) == query },
+	}
+	for _, match := range matchers {
+		var found []Term
+		for _, t := range terms {
+			if match(t) {
+				found = append(found, t)
+			}
+		}
+		switch len(found) {
+		case 0:
+			continue
+		case 1:
+			return &found[0], nil
+		default:
+			return nil, fmt.Errorf("term %q matches %d terms", query, len(found))
+		}
+	}
+	return nil, fmt.Errorf("no term matches %q", query)
+}
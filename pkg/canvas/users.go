@@ -0,0 +1,89 @@
+package canvas
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+type User struct {
+	ID           int    `json:"id"`
+	Name         string `json:"name"`
+	SortableName string `json:"sortable_name"`
+	ShortName    string `json:"short_name"`
+	SISUserID    string `json:"sis_user_id"`
+	LoginID      string `json:"login_id"`
+	Email        string `json:"email"`
+}
+
+// UserUpdate holds the user attributes to change. Nil fields are left untouched.
+type UserUpdate struct {
+	Name         *string `json:"name,omitempty"`
+	ShortName    *string `json:"short_name,omitempty"`
+	SortableName *string `json:"sortable_name,omitempty"`
+	Email        *string `json:"email,omitempty"`
+
+	OverrideSISStickiness *bool `json:"-"` // sent as override_sis_stickiness when set
+}
+
+type UsersService struct {
+	service
+}
+
+func (api *APIManager) Users() *UsersService {
+	return &UsersService{service{api: api}}
+}
+
+// WithContext returns a copy of the service that sends its requests with ctx.
+func (us *UsersService) WithContext(ctx context.Context) *UsersService {
+	return &UsersService{service{api: us.api, ctx: ctx}}
+}
+
+// GetUser fetches a user by Canvas ID or by any ID Canvas accepts in the path, e.g. "sis_user_id:ABC".
+func (us *UsersService) GetUser(id string) (*User, error) {
+	var user User
+	if err := us.getJSON("users/"+id, &user); err != nil {
+		return nil, fmt.Errorf("error fetching user %s: %w", id, err)
+	}
+	return &user, nil
+}
+
+func (us *UsersService) UpdateUser(id string, update UserUpdate) (*User, error) {
+	body := updateBody("user", update, update.OverrideSISStickiness)
+	var user User
+	if err := us.sendJSON(http.MethodPut, "users/"+id, body, &user); err != nil {
+		return nil, fmt.Errorf("error updating user %s: %w", id, err)
+	}
+	return &user, nil
+}
+
+// ListAccountUsers returns the users in an account, optionally only those whose name, login, or
+// email matches searchTerm. Listing a large account takes many requests.
+func (us *UsersService) ListAccountUsers(accountID int, searchTerm string) ([]User, error) {
+	query := url.Values{}
+	query.Set("per_page", perPage(0))
+	if searchTerm != "" {
+		query.Set("search_term", searchTerm)
+	}
+	var users []User
+	if err := us.listJSON(withQuery(fmt.Sprintf("accounts/%d/users", accountID), query), &users); err != nil {
+		return nil, fmt.Errorf("error listing users for account %d: %w", accountID, err)
+	}
+	return users, nil
+}
+
+// ListCourseUsers returns a course's users with the given enrollment types (teacher, student, ta,
+// observer, designer), or every user when none are given.
+func (us *UsersService) ListCourseUsers(courseID int, enrollmentTypes ...string) ([]User, error) {
+	query := url.Values{}
+	query.Set("per_page", perPage(0))
+	for _, t := range enrollmentTypes {
+		query.Add("enrollment_type[]", t)
+	}
+	var users []User
+	if err := us.listJSON(withQuery(fmt.Sprintf("courses/%d/users", courseID), query), &users); err != nil {
+		return nil, fmt.Errorf("error listing users for course %d: %w", courseID, err)
+	}
+	return users, nil
+}
@@ -0,0 +1,44 @@
+package canvas
+
+import (
+	"net/url"
+	"strconv"
+)
+
+// WebURL returns the link to a path in the Canvas web UI on the API's host, e.g.
+// WebURL("/courses/123/modules") -> https://school.instructure.com/courses/123/modules.
+// It returns "" when the base URL has no host.
+func (api *APIManager) WebURL(path string) string {
+	u, err := url.Parse(api.config.BaseURL)
+	if err != nil || u.Host == "" {
+		return ""
+	}
+	u.Path = path
+	u.RawQuery = ""
+	u.Fragment = ""
+	return u.String()
+}
+
+// CourseURL returns the link to a course, or to one of its pages when page is given, e.g. "modules".
+func (api *APIManager) CourseURL(courseID int, page string) string {
+	path := "/courses/" + strconv.Itoa(courseID)
+	if page != "" {
+		path += "/" + page
+	}
+	return api.WebURL(path)
+}
+
+// AssignmentURL returns the link to an assignment.
+func (api *APIManager) AssignmentURL(courseID, assignmentID int) string {
+	return api.CourseURL(courseID, "assignments/"+strconv.Itoa(assignmentID))
+}
+
+// UserURL returns the link to a user's account profile, which needs admin rights to view.
+func (api *APIManager) UserURL(userID int) string {
+	return api.WebURL("/users/" + strconv.Itoa(userID))
+}
+
+// CourseUserURL returns the link to a user's page in a course, which teachers can view too.
+func (api *APIManager) CourseUserURL(courseID, userID int) string {
+	return api.CourseURL(courseID, "users/"+strconv.Itoa(userID))
+}
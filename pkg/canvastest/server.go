@@ -0,0 +1,739 @@
+// Package canvastest runs an in-memory Canvas API for tests of the canvas package and the tools built
+// on it. It serves courses, users, terms, modules, and course file uploads with Canvas' pagination
+// Link headers, rate limit headers, and ETags, and can be told to throttle or fail the next requests.
+//
+//	srv := canvastest.NewServer()
+//	defer srv.Close()
+//	srv.AddCourse(canvas.Course{Name: "ENGL 101", SISCourseID: "6253-01-ENGL-101"})
+//	courses, err := srv.API().Courses().ListAccountCourses(1, canvas.CourseListOptions{})
+package canvastest
+
+import (
+	"bytes"
+	"cmp"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/coraxwolf/CCTA_3-4/pkg/canvas"
+)
+
+// Token is the API token the server accepts unless Server.Token is changed.
+const Token = "canvastest-token"
+
+// Request is a request the server received, for checking what a client sent.
+type Request struct {
+	Method string
+	Path   string // without the /api/v1/ prefix, e.g. "courses/1"
+	Query  url.Values
+	Header http.Header
+	Body   []byte
+}
+
+// Server is a fake Canvas instance. Configure it before sending requests; the fixtures can be
+// added or read at any time. It is safe for concurrent use.
+type Server struct {
+	*httptest.Server
+
+	Token       string  // expected bearer token; empty accepts any
+	PageSize    int     // items per page when the request has no per_page (Canvas' default is 10)
+	MaxPageSize int     // largest per_page honoured (Canvas caps it at 100)
+	RateLimit   float64 // size of the rate limit bucket
+	RequestCost float64 // cost charged for every request
+	RefillRate  float64 // bucket units restored per second
+
+	mu          sync.Mutex
+	remaining   float64 // set to RateLimit on the first request
+	lastRefill  time.Time
+	throttle    int
+	failures    []int
+	requests    []Request
+	nextID      int
+	courses     []*canvas.Course
+	users       []*canvas.User
+	terms       []canvas.Term
+	modules     map[int][]*canvas.Module // by course ID
+	moduleItems map[int][]canvas.ModuleItem
+	files       []*storedFile
+	uploads     map[string]*storedFile // pre-flighted uploads by ticket
+}
+
+type storedFile struct {
+	canvas.File
+	courseID int
+	data     []byte
+}
+
+// NewServer starts a server with no data and Canvas-like defaults.
+func NewServer() *Server {
+	s := &Server{
+		Token:       Token,
+		PageSize:    10,
+		MaxPageSize: 100,
+		RateLimit:   700,
+		RequestCost: 1,
+		RefillRate:  10,
+		nextID:      1,
+		modules:     make(map[int][]*canvas.Module),
+		moduleItems: make(map[int][]canvas.ModuleItem),
+		uploads:     make(map[string]*storedFile),
+	}
+	s.remaining = -1
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/accounts/{account}/courses", s.listCourses)
+	mux.HandleFunc("GET /api/v1/accounts/{account}/terms", s.listTerms)
+	mux.HandleFunc("GET /api/v1/courses/{course}", s.getCourse)
+	mux.HandleFunc("PUT /api/v1/courses/{course}", s.updateCourse)
+	mux.HandleFunc("GET /api/v1/courses/{course}/modules", s.listModules)
+	mux.HandleFunc("PUT /api/v1/courses/{course}/modules/{module}", s.updateModule)
+	mux.HandleFunc("GET /api/v1/courses/{course}/modules/{module}/items", s.listModuleItems)
+	mux.HandleFunc("GET /api/v1/courses/{course}/files", s.listFiles)
+	mux.HandleFunc("POST /api/v1/courses/{course}/files", s.startUpload)
+	mux.HandleFunc("POST /upload/{ticket}", s.receiveUpload)
+	mux.HandleFunc("GET /api/v1/files/{file}", s.getFile)
+	mux.HandleFunc("PUT /api/v1/files/{file}", s.updateFile)
+	mux.HandleFunc("GET /api/v1/users/{user}", s.getUser)
+	mux.HandleFunc("PUT /api/v1/users/{user}", s.updateUser)
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) { writeError(w, http.StatusNotFound, notFound) })
+	s.Server = httptest.NewServer(s.middleware(mux))
+	return s
+}
+
+// BaseURL is the API base URL to configure clients with.
+func (s *Server) BaseURL() string {
+	return s.URL + "/api/v1/"
+}
+
+// API returns a client for the server that logs nothing.
+func (s *Server) API() *canvas.APIManager {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	return canvas.NewAPI(logger, s.Token, s.BaseURL(), int(s.RateLimit), 60)
+}
+
+// ThrottleNext answers the next n requests with 429 Too Many Requests and an empty rate limit bucket.
+func (s *Server) ThrottleNext(n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.throttle += n
+}
+
+// FailNext answers the next requests with these statuses, one each, e.g. FailNext(503, 503).
+func (s *Server) FailNext(statuses ...int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures = append(s.failures, statuses...)
+}
+
+// Requests returns every request received so far, throttled and failed ones included.
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.requests)
+}
+
+// id returns c if it is set, or the next unused ID. Callers hold mu.
+func (s *Server) id(c int) int {
+	if c == 0 {
+		c = s.nextID
+	}
+	s.nextID = max(s.nextID, c+1)
+	return c
+}
+
+// AddCourse adds a course and returns it with its ID set. The course is unpublished unless
+// WorkflowState says otherwise.
+func (s *Server) AddCourse(c canvas.Course) canvas.Course {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c.ID = s.id(c.ID)
+	if c.WorkflowState == "" {
+		c.WorkflowState = "unpublished"
+	}
+	if c.AccountID == 0 {
+		c.AccountID = 1
+	}
+	s.courses = append(s.courses, &c)
+	return c
+}
+
+// Course returns the current state of a course, e.g. to check an update.
+func (s *Server) Course(id int) (canvas.Course, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if c := s.findCourse(id); c != nil {
+		return *c, true
+	}
+	return canvas.Course{}, false
+}
+
+func (s *Server) AddUser(u canvas.User) canvas.User {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	u.ID = s.id(u.ID)
+	s.users = append(s.users, &u)
+	return u
+}
+
+// User returns the current state of a user.
+func (s *Server) User(id int) (canvas.User, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, u := range s.users {
+		if u.ID == id {
+			return *u, true
+		}
+	}
+	return canvas.User{}, false
+}
+
+func (s *Server) AddTerm(t canvas.Term) canvas.Term {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t.ID = s.id(t.ID)
+	if t.WorkflowState == "" {
+		t.WorkflowState = "active"
+	}
+	s.terms = append(s.terms, t)
+	return t
+}
+
+// AddModule adds a module to the end of a course's modules.
+func (s *Server) AddModule(courseID int, m canvas.Module) canvas.Module {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	m.ID = s.id(m.ID)
+	m.Position = len(s.modules[courseID]) + 1
+	if m.WorkflowState == "" {
+		m.WorkflowState = "unpublished"
+		if m.Published {
+			m.WorkflowState = "active"
+		}
+	}
+	m.Published = m.WorkflowState == "active"
+	m.ItemsURL = fmt.Sprintf("%scourses/%d/modules/%d/items", s.BaseURL(), courseID, m.ID)
+	s.modules[courseID] = append(s.modules[courseID], &m)
+	return m
+}
+
+// AddModuleItem adds an item to the end of a module.
+func (s *Server) AddModuleItem(moduleID int, item canvas.ModuleItem) canvas.ModuleItem {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	item.ID = s.id(item.ID)
+	item.ModuleID = moduleID
+	item.Position = len(s.moduleItems[moduleID]) + 1
+	s.moduleItems[moduleID] = append(s.moduleItems[moduleID], item)
+	for _, modules := range s.modules {
+		for _, m := range modules {
+			if m.ID == moduleID {
+				m.ItemsCount++
+			}
+		}
+	}
+	return item
+}
+
+// File returns an uploaded file and its contents.
+func (s *Server) File(id int) (canvas.File, []byte, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, f := range s.files {
+		if f.ID == id {
+			return f.File, slices.Clone(f.data), true
+		}
+	}
+	return canvas.File{}, nil, false
+}
+
+// middleware records the request, checks the token, charges the rate limit, and applies
+// ThrottleNext and FailNext before the endpoint runs. Successful GETs get an ETag, and a request
+// whose If-None-Match matches it gets 304 Not Modified without the body, as Canvas does.
+func (s *Server) middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		s.mu.Lock()
+		s.requests = append(s.requests, Request{
+			Method: r.Method,
+			Path:   strings.TrimPrefix(r.URL.Path, "/api/v1/"),
+			Query:  r.URL.Query(),
+			Header: r.Header.Clone(),
+			Body:   body,
+		})
+		s.refill()
+		throttled := s.throttle > 0
+		if throttled {
+			s.throttle--
+			s.remaining = 0
+		} else {
+			s.remaining = max(s.remaining-s.RequestCost, 0)
+		}
+		failure := 0
+		if !throttled && len(s.failures) > 0 {
+			failure, s.failures = s.failures[0], s.failures[1:]
+		}
+		w.Header().Set("X-Rate-Limit-Remaining", strconv.FormatFloat(s.remaining, 'f', 3, 64))
+		w.Header().Set("X-Request-Cost", strconv.FormatFloat(s.RequestCost, 'f', 3, 64))
+		token := s.Token
+		s.mu.Unlock()
+
+		switch {
+		case token != "" && !strings.HasPrefix(r.URL.Path, "/upload/") && r.Header.Get("Authorization") != "Bearer "+token:
+			w.Header().Set("WWW-Authenticate", `Bearer realm="canvas-lms"`)
+			writeJSON(w, http.StatusUnauthorized, map[string]any{"errors": []apiMessage{{"Invalid access token."}}, "status": "unauthenticated"})
+		case throttled:
+			w.WriteHeader(http.StatusTooManyRequests)
+			io.WriteString(w, "403 Forbidden (Rate Limit Exceeded)\n")
+		case failure != 0:
+			writeError(w, failure, http.StatusText(failure))
+		case r.Method == http.MethodGet:
+			ew := &etagWriter{ResponseWriter: w}
+			next.ServeHTTP(ew, r)
+			ew.finish(r.Header.Get("If-None-Match"))
+		default:
+			r.Body = io.NopCloser(strings.NewReader(string(body)))
+			next.ServeHTTP(w, r)
+		}
+	})
+}
+
+// etagWriter holds a GET response back until the body is complete, so its ETag can be set.
+type etagWriter struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (w *etagWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+}
+
+func (w *etagWriter) Write(p []byte) (int, error) {
+	w.WriteHeader(http.StatusOK)
+	return w.body.Write(p)
+}
+
+// finish sends the response, or 304 when it is a 200 whose ETag is ifNoneMatch.
+func (w *etagWriter) finish(ifNoneMatch string) {
+	status := cmp.Or(w.status, http.StatusOK)
+	if status == http.StatusOK {
+		sum := sha256.Sum256(w.body.Bytes())
+		etag := `W/"` + hex.EncodeToString(sum[:16]) + `"`
+		w.Header().Set("ETag", etag)
+		if ifNoneMatch == etag {
+			w.Header().Del("Content-Type")
+			w.ResponseWriter.WriteHeader(http.StatusNotModified)
+			return
+		}
+	}
+	w.ResponseWriter.WriteHeader(status)
+	w.ResponseWriter.Write(w.body.Bytes())
+}
+
+// refill restores the bucket for the time since the last request. Callers hold mu.
+func (s *Server) refill() {
+	now := time.Now()
+	if s.remaining < 0 {
+		s.remaining, s.lastRefill = s.RateLimit, now
+	}
+	s.remaining = min(s.remaining+now.Sub(s.lastRefill).Seconds()*s.RefillRate, s.RateLimit)
+	s.lastRefill = now
+}
+
+const notFound = "The specified resource does not exist."
+
+type apiMessage struct {
+	Message string `json:"message"`
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]any{"errors": []apiMessage{{message}}})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// writePage writes the page of items the request asks for with Canvas' Link header. When key is
+// set the page is wrapped in an object under it, as Canvas does for a few endpoints.
+func writePage[T any](s *Server, w http.ResponseWriter, r *http.Request, key string, items []T) {
+	query := r.URL.Query()
+	page, err := strconv.Atoi(query.Get("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+	size, err := strconv.Atoi(query.Get("per_page"))
+	if err != nil || size < 1 {
+		size = s.PageSize
+	}
+	size = min(size, s.MaxPageSize)
+	last := max((len(items)+size-1)/size, 1)
+	link := func(n int, rel string) string {
+		query.Set("page", strconv.Itoa(n))
+		query.Set("per_page", strconv.Itoa(size))
+		return fmt.Sprintf(`<%s%s?%s>; rel="%s"`, s.URL, r.URL.Path, query.Encode(), rel)
+	}
+	links := []string{link(page, "current")}
+	if page < last {
+		links = append(links, link(page+1, "next"))
+	}
+	if page > 1 {
+		links = append(links, link(page-1, "prev"))
+	}
+	links = append(links, link(1, "first"), link(last, "last"))
+	w.Header().Set("Link", strings.Join(links, ","))
+
+	start := min((page-1)*size, len(items))
+	end := min(start+size, len(items))
+	pageItems := items[start:end]
+	if pageItems == nil {
+		pageItems = []T{}
+	}
+	if key != "" {
+		writeJSON(w, http.StatusOK, map[string][]T{key: pageItems})
+		return
+	}
+	writeJSON(w, http.StatusOK, pageItems)
+}
+
+// decodeUpdate reads the object under key in a Canvas update body, e.g. {"course": {...}}.
+func decodeUpdate(r *http.Request, key string) (map[string]json.RawMessage, error) {
+	var body map[string]json.RawMessage
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		return nil, err
+	}
+	var fields map[string]json.RawMessage
+	if raw, ok := body[key]; ok {
+		if err := json.Unmarshal(raw, &fields); err != nil {
+			return nil, err
+		}
+	}
+	return fields, nil
+}
+
+// setString decodes fields[name] into dst when it is present.
+func setString(fields map[string]json.RawMessage, name string, dst *string) {
+	if raw, ok := fields[name]; ok {
+		json.Unmarshal(raw, dst)
+	}
+}
+
+func pathID(r *http.Request, name string) int {
+	id, _ := strconv.Atoi(r.PathValue(name))
+	return id
+}
+
+// findCourse looks up a course by ID. Callers hold mu.
+func (s *Server) findCourse(id int) *canvas.Course {
+	for _, c := range s.courses {
+		if c.ID == id {
+			return c
+		}
+	}
+	return nil
+}
+
+func (s *Server) listCourses(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	account := pathID(r, "account")
+	search := strings.ToLower(query.Get("search_term"))
+	term, _ := strconv.Atoi(query.Get("enrollment_term_id"))
+	s.mu.Lock()
+	var courses []canvas.Course
+	for _, c := range s.courses {
+		switch {
+		case account != 1 && c.AccountID != account:
+		case search != "" && !strings.Contains(strings.ToLower(c.Name+"\n"+c.CourseCode+"\n"+c.SISCourseID), search):
+		case term != 0 && c.EnrollmentTermID != term:
+		case query.Get("published") == "true" && c.WorkflowState != "available":
+		case query.Get("published") == "false" && c.WorkflowState == "available":
+		default:
+			courses = append(courses, *c)
+		}
+	}
+	s.mu.Unlock()
+	writePage(s, w, r, "", courses)
+}
+
+func (s *Server) getCourse(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c := s.findCourse(pathID(r, "course"))
+	if c == nil {
+		writeError(w, http.StatusNotFound, notFound)
+		return
+	}
+	writeJSON(w, http.StatusOK, c)
+}
+
+func (s *Server) updateCourse(w http.ResponseWriter, r *http.Request) {
+	fields, err := decodeUpdate(r, "course")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c := s.findCourse(pathID(r, "course"))
+	if c == nil {
+		writeError(w, http.StatusNotFound, notFound)
+		return
+	}
+	setString(fields, "name", &c.Name)
+	setString(fields, "course_code", &c.CourseCode)
+	setString(fields, "default_view", &c.DefaultView)
+	setString(fields, "syllabus_body", &c.SyllabusBody)
+	var event string
+	setString(fields, "event", &event)
+	switch event {
+	case "offer":
+		c.WorkflowState = "available"
+	case "claim":
+		c.WorkflowState = "unpublished"
+	}
+	writeJSON(w, http.StatusOK, c)
+}
+
+func (s *Server) listTerms(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	terms := slices.Clone(s.terms)
+	s.mu.Unlock()
+	writePage(s, w, r, "enrollment_terms", terms)
+}
+
+// findUser looks up a user by ID or by "sis_user_id:<id>". Callers hold mu.
+func (s *Server) findUser(id string) *canvas.User {
+	for _, u := range s.users {
+		if sisID, ok := strings.CutPrefix(id, "sis_user_id:"); ok && u.SISUserID == sisID || strconv.Itoa(u.ID) == id {
+			return u
+		}
+	}
+	return nil
+}
+
+func (s *Server) getUser(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	u := s.findUser(r.PathValue("user"))
+	if u == nil {
+		writeError(w, http.StatusNotFound, notFound)
+		return
+	}
+	writeJSON(w, http.StatusOK, u)
+}
+
+func (s *Server) updateUser(w http.ResponseWriter, r *http.Request) {
+	fields, err := decodeUpdate(r, "user")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	u := s.findUser(r.PathValue("user"))
+	if u == nil {
+		writeError(w, http.StatusNotFound, notFound)
+		return
+	}
+	setString(fields, "name", &u.Name)
+	setString(fields, "short_name", &u.ShortName)
+	setString(fields, "sortable_name", &u.SortableName)
+	setString(fields, "email", &u.Email)
+	writeJSON(w, http.StatusOK, u)
+}
+
+func (s *Server) listModules(w http.ResponseWriter, r *http.Request) {
+	courseID := pathID(r, "course")
+	withItems := slices.Contains(r.URL.Query()["include[]"], "items")
+	s.mu.Lock()
+	if s.findCourse(courseID) == nil {
+		s.mu.Unlock()
+		writeError(w, http.StatusNotFound, notFound)
+		return
+	}
+	var modules []canvas.Module
+	for _, m := range s.modules[courseID] {
+		module := *m
+		if withItems {
+			module.Items = slices.Clone(s.moduleItems[m.ID])
+		}
+		modules = append(modules, module)
+	}
+	s.mu.Unlock()
+	writePage(s, w, r, "", modules)
+}
+
+// findModule looks up a module in a course. Callers hold mu.
+func (s *Server) findModule(courseID, moduleID int) *canvas.Module {
+	for _, m := range s.modules[courseID] {
+		if m.ID == moduleID {
+			return m
+		}
+	}
+	return nil
+}
+
+func (s *Server) listModuleItems(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	m := s.findModule(pathID(r, "course"), pathID(r, "module"))
+	if m == nil {
+		s.mu.Unlock()
+		writeError(w, http.StatusNotFound, notFound)
+		return
+	}
+	items := slices.Clone(s.moduleItems[m.ID])
+	s.mu.Unlock()
+	writePage(s, w, r, "", items)
+}
+
+func (s *Server) updateModule(w http.ResponseWriter, r *http.Request) {
+	fields, err := decodeUpdate(r, "module")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	m := s.findModule(pathID(r, "course"), pathID(r, "module"))
+	if m == nil {
+		writeError(w, http.StatusNotFound, notFound)
+		return
+	}
+	setString(fields, "name", &m.Name)
+	if raw, ok := fields["published"]; ok {
+		json.Unmarshal(raw, &m.Published)
+		m.WorkflowState = "unpublished"
+		if m.Published {
+			m.WorkflowState = "active"
+		}
+	}
+	writeJSON(w, http.StatusOK, m)
+}
+
+// startUpload answers the pre-flight of a course file upload with a one-time upload URL on the
+// server's storage path, which takes the file without a token, like Canvas' file storage.
+func (s *Server) startUpload(w http.ResponseWriter, r *http.Request) {
+	var params struct {
+		Name        string `json:"name"`
+		ContentType string `json:"content_type"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil || params.Name == "" {
+		writeError(w, http.StatusBadRequest, "name is required")
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	courseID := pathID(r, "course")
+	if s.findCourse(courseID) == nil {
+		writeError(w, http.StatusNotFound, notFound)
+		return
+	}
+	ticket := strconv.Itoa(s.id(0))
+	s.uploads[ticket] = &storedFile{courseID: courseID, File: canvas.File{DisplayName: params.Name, Filename: params.Name, ContentType: params.ContentType}}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"upload_url":    s.URL + "/upload/" + ticket,
+		"upload_params": map[string]string{"filename": params.Name, "content_type": params.ContentType},
+		"file_param":    "file",
+	})
+}
+
+// receiveUpload stores the uploaded file and answers 201 Created with the file's API location.
+func (s *Server) receiveUpload(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	pending := s.uploads[r.PathValue("ticket")]
+	delete(s.uploads, r.PathValue("ticket"))
+	s.mu.Unlock()
+	if pending == nil {
+		writeError(w, http.StatusBadRequest, "unknown upload")
+		return
+	}
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	defer file.Close()
+	data, _ := io.ReadAll(file)
+	s.mu.Lock()
+	pending.ID = s.id(0)
+	pending.Size = int64(len(data))
+	pending.URL = fmt.Sprintf("%s/files/%d/download", s.URL, pending.ID)
+	pending.data = data
+	s.files = append(s.files, pending)
+	s.mu.Unlock()
+	w.Header().Set("Location", fmt.Sprintf("%sfiles/%d", s.BaseURL(), pending.ID))
+	w.WriteHeader(http.StatusCreated)
+}
+
+func (s *Server) listFiles(w http.ResponseWriter, r *http.Request) {
+	courseID := pathID(r, "course")
+	s.mu.Lock()
+	if s.findCourse(courseID) == nil {
+		s.mu.Unlock()
+		writeError(w, http.StatusNotFound, notFound)
+		return
+	}
+	files := []canvas.File{}
+	for _, f := range s.files {
+		if f.courseID == courseID {
+			files = append(files, f.File)
+		}
+	}
+	s.mu.Unlock()
+	writePage(s, w, r, "", files)
+}
+
+// updateFile renames, moves, locks, or hides a file. Moving only changes its folder ID.
+func (s *Server) updateFile(w http.ResponseWriter, r *http.Request) {
+	var fields map[string]json.RawMessage
+	if err := json.NewDecoder(r.Body).Decode(&fields); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, f := range s.files {
+		if f.ID != pathID(r, "file") {
+			continue
+		}
+		setString(fields, "name", &f.DisplayName)
+		if raw, ok := fields["parent_folder_id"]; ok {
+			json.Unmarshal(raw, &f.FolderID)
+		}
+		if raw, ok := fields["locked"]; ok {
+			json.Unmarshal(raw, &f.Locked)
+		}
+		if raw, ok := fields["hidden"]; ok {
+			json.Unmarshal(raw, &f.Hidden)
+		}
+		writeJSON(w, http.StatusOK, f.File)
+		return
+	}
+	writeError(w, http.StatusNotFound, notFound)
+}
+
+func (s *Server) getFile(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, f := range s.files {
+		if f.ID == pathID(r, "file") {
+			writeJSON(w, http.StatusOK, f.File)
+			return
+		}
+	}
+	writeError(w, http.StatusNotFound, notFound)
+}
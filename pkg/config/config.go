@@ -0,0 +1,271 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultFile is read when no config file is given and it exists in the working directory.
+const DefaultFile = "config.yaml"
+
+// DefaultProfile is used when neither the command line nor the config file names a profile.
+const DefaultProfile = "beta"
+
+// Config holds the settings needed to talk to a Canvas instance and write reports.
+// Settings are merged with this precedence: flags, then environment, then config file, then defaults.
+type Config struct {
+	Profile   string `yaml:"-"`
+	Token     string `yaml:"token"`
+	BaseURL   string `yaml:"base_url"`
+	AccountID int    `yaml:"account_id"`
+	RateLimit int    `yaml:"rate_limit"`
+	Timeout   int    `yaml:"timeout"` // read timeout in seconds
+	OutputDir string `yaml:"output_dir"`
+	// FallbackTokens are tried in order when Canvas rejects the token as invalid, e.g. revoked mid-run.
+	FallbackTokens []string `yaml:"fallback_tokens"`
+	// Maintenance lists known downtime, such as beta refreshes, when runs should wait or be skipped.
+	Maintenance []MaintenanceWindow `yaml:"maintenance"`
+	// SISPatterns are the institution's documented SIS ID formats, checked by sis validate.
+	SISPatterns SISPatterns `yaml:"sis_patterns"`
+	// KeywordFlags configures care scan, which flags student comments and posts for student-care teams.
+	KeywordFlags KeywordFlags `yaml:"keyword_flags"`
+	// Experimental switches on subsystems that ship disabled, e.g. experimental: {graphql: true}.
+	Experimental Features `yaml:"experimental"`
+}
+
+// KeywordFlags holds the keywords care scan looks for and who may run it. The scan is disabled
+// until Operators is set. None of it can be set from the environment or the command line.
+type KeywordFlags struct {
+	Keywords     []string `yaml:"keywords"`
+	KeywordsFile string   `yaml:"keywords_file"` // one keyword or phrase per line; # starts a comment
+	Operators    []string `yaml:"operators"`     // Canvas login IDs of the token users allowed to run the scan
+	AuditLog     string   `yaml:"audit_log"`     // default data/audit/keyword_flags.log
+	OutputDir    string   `yaml:"output_dir"`    // default data/care
+}
+
+// SISPatterns holds a regular expression per kind of SIS ID. Each must match the whole ID; an
+// empty pattern isn't checked.
+type SISPatterns struct {
+	Course  string `yaml:"course"`
+	Section string `yaml:"section"`
+	User    string `yaml:"user"`
+}
+
+// MaintenanceWindow is a period when the Canvas instance is unavailable or unreliable.
+type MaintenanceWindow struct {
+	Start  time.Time `yaml:"start"`
+	End    time.Time `yaml:"end"`
+	Reason string    `yaml:"reason"`
+}
+
+// ActiveMaintenance returns the maintenance window that contains now, if any.
+func (c Config) ActiveMaintenance(now time.Time) (MaintenanceWindow, bool) {
+	for _, w := range c.Maintenance {
+		if !now.Before(w.Start) && now.Before(w.End) {
+			return w, true
+		}
+	}
+	return MaintenanceWindow{}, false
+}
+
+// File is the layout of the config file. Top-level settings apply to every profile
+// and each profile overrides them for one Canvas instance.
+type File struct {
+	Config         `yaml:",inline"`
+	DefaultProfile string            `yaml:"default_profile"`
+	Profiles       map[string]Config `yaml:"profiles"`
+}
+
+func Default() Config {
+	return Config{
+		AccountID: 1,
+		RateLimit: 700,
+		Timeout:   120,
+	}
+}
+
+// Load builds a Config for the named profile from the defaults, the config file at path, and
+// the environment. An empty path reads DefaultFile if it exists; a path that was given must exist.
+// An empty profile uses the file's default_profile, or DefaultProfile.
+func Load(path, profile string) (Config, error) {
+	cfg := Default()
+	optional := path == ""
+	if optional {
+		path = DefaultFile
+	}
+	file, err := readFile(path)
+	if err != nil && !(optional && errors.Is(err, os.ErrNotExist)) {
+		return cfg, err
+	}
+	explicit := profile != ""
+	if !explicit {
+		profile = file.DefaultProfile
+	}
+	if profile == "" {
+		profile = DefaultProfile
+	}
+	cfg.Profile = profile
+	cfg.Merge(file.Config)
+	settings, found := file.Profiles[profile]
+	cfg.Merge(settings)
+	for _, w := range cfg.Maintenance {
+		if !w.End.After(w.Start) {
+			return cfg, fmt.Errorf("maintenance window %q in %s must end after it starts", w.Reason, path)
+		}
+	}
+	if err := cfg.Experimental.validate(); err != nil {
+		return cfg, fmt.Errorf("%w in %s", err, path)
+	}
+
+	env, err := FromEnv(os.LookupEnv, "CANVAS")
+	if err != nil {
+		return cfg, err
+	}
+	cfg.Merge(env)
+	env, err = FromEnv(os.LookupEnv, EnvPrefix(profile))
+	if err != nil {
+		return cfg, err
+	}
+	cfg.Merge(env)
+	if err := cfg.Experimental.validate(); err != nil {
+		return cfg, fmt.Errorf("%w in CANVAS_EXPERIMENTAL or %s_EXPERIMENTAL", err, EnvPrefix(profile))
+	}
+	if explicit && !found && env.Token == "" {
+		return cfg, fmt.Errorf("unknown profile %q: not in %s and %s_TOKEN is not set", profile, path, EnvPrefix(profile))
+	}
+	if dir, ok := os.LookupEnv("OUTPUT_DIR"); ok && dir != "" {
+		cfg.OutputDir = dir
+	}
+	return cfg, nil
+}
+
+func readFile(path string) (File, error) {
+	var file File
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return file, err
+	}
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return file, fmt.Errorf("error parsing config file %s: %w", path, err)
+	}
+	return file, nil
+}
+
+// EnvPrefix returns the environment variable prefix for a profile, e.g. "beta" -> "BETA".
+func EnvPrefix(profile string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToUpper(r)
+		}
+		return '_'
+	}, profile)
+}
+
+// FromEnv reads the settings that are set in the environment under prefix,
+// e.g. BETA_TOKEN, BETA_FALLBACK_TOKENS (comma separated), BETA_API_URL, BETA_ACCOUNT_ID,
+// BETA_RATE_LIMIT, BETA_TIMEOUT, and BETA_EXPERIMENTAL (features to switch on, comma separated;
+// a leading "-" switches one off).
+func FromEnv(lookup func(string) (string, bool), prefix string) (Config, error) {
+	var cfg Config
+	cfg.Token, _ = lookup(prefix + "_TOKEN")
+	if s, _ := lookup(prefix + "_FALLBACK_TOKENS"); s != "" {
+		for _, token := range strings.Split(s, ",") {
+			if token = strings.TrimSpace(token); token != "" {
+				cfg.FallbackTokens = append(cfg.FallbackTokens, token)
+			}
+		}
+	}
+	cfg.BaseURL, _ = lookup(prefix + "_API_URL")
+	if s, _ := lookup(prefix + "_EXPERIMENTAL"); s != "" {
+		cfg.Experimental = parseFeatures(s)
+	}
+	ints := []struct {
+		name string
+		dst  *int
+	}{
+		{prefix + "_ACCOUNT_ID", &cfg.AccountID},
+		{prefix + "_RATE_LIMIT", &cfg.RateLimit},
+		{prefix + "_TIMEOUT", &cfg.Timeout},
+	}
+	for _, v := range ints {
+		s, _ := lookup(v.name)
+		if s == "" {
+			continue
+		}
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			return cfg, fmt.Errorf("invalid %s %q: %w", v.name, s, err)
+		}
+		*v.dst = n
+	}
+	return cfg, nil
+}
+
+// Merge overrides c with every setting that is set (non-zero) in other.
+// Maintenance windows are added to c's rather than replacing them, and experimental features
+// are switched one by one.
+func (c *Config) Merge(other Config) {
+	if other.Token != "" {
+		c.Token = other.Token
+	}
+	if len(other.FallbackTokens) > 0 {
+		c.FallbackTokens = other.FallbackTokens
+	}
+	if other.BaseURL != "" {
+		c.BaseURL = other.BaseURL
+	}
+	if other.AccountID != 0 {
+		c.AccountID = other.AccountID
+	}
+	if other.RateLimit != 0 {
+		c.RateLimit = other.RateLimit
+	}
+	if other.Timeout != 0 {
+		c.Timeout = other.Timeout
+	}
+	if other.OutputDir != "" {
+		c.OutputDir = other.OutputDir
+	}
+	c.Maintenance = append(c.Maintenance, other.Maintenance...)
+	if other.SISPatterns.Course != "" {
+		c.SISPatterns.Course = other.SISPatterns.Course
+	}
+	if other.SISPatterns.Section != "" {
+		c.SISPatterns.Section = other.SISPatterns.Section
+	}
+	if other.SISPatterns.User != "" {
+		c.SISPatterns.User = other.SISPatterns.User
+	}
+	if len(other.KeywordFlags.Keywords) > 0 {
+		c.KeywordFlags.Keywords = other.KeywordFlags.Keywords
+	}
+	if other.KeywordFlags.KeywordsFile != "" {
+		c.KeywordFlags.KeywordsFile = other.KeywordFlags.KeywordsFile
+	}
+	if len(other.KeywordFlags.Operators) > 0 {
+		c.KeywordFlags.Operators = other.KeywordFlags.Operators
+	}
+	if other.KeywordFlags.AuditLog != "" {
+		c.KeywordFlags.AuditLog = other.KeywordFlags.AuditLog
+	}
+	if other.KeywordFlags.OutputDir != "" {
+		c.KeywordFlags.OutputDir = other.KeywordFlags.OutputDir
+	}
+	if len(other.Experimental) > 0 {
+		features := make(Features, len(c.Experimental)+len(other.Experimental))
+		for name, on := range c.Experimental {
+			features[name] = on
+		}
+		for name, on := range other.Experimental {
+			features[name] = on
+		}
+		c.Experimental = features
+	}
+}
@@ -0,0 +1,232 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// writeConfig writes a config file in a temporary directory and returns its path.
+func writeConfig(t *testing.T, yaml string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(yaml), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// clearEnv blanks the settings Load reads under each prefix, so the test doesn't depend on the
+// environment it runs in. Blank variables count as unset.
+func clearEnv(t *testing.T, prefixes ...string) {
+	t.Helper()
+	t.Setenv("OUTPUT_DIR", "")
+	for _, prefix := range prefixes {
+		for _, name := range []string{"TOKEN", "FALLBACK_TOKENS", "API_URL", "ACCOUNT_ID", "RATE_LIMIT", "TIMEOUT", "EXPERIMENTAL"} {
+			t.Setenv(prefix+"_"+name, "")
+		}
+	}
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	clearEnv(t, "CANVAS", "BETA")
+	path := writeConfig(t, `
+token: file-token
+base_url: https://file.example.com/api/v1/
+account_id: 5
+rate_limit: 300
+output_dir: file-reports
+profiles:
+  beta:
+    timeout: 30
+`)
+	t.Setenv("CANVAS_TOKEN", "canvas-env-token")
+	t.Setenv("BETA_TOKEN", "beta-env-token")
+	t.Setenv("BETA_RATE_LIMIT", "400")
+	t.Setenv("CANVAS_TIMEOUT", "45")
+
+	cfg, err := Load(path, "beta")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.Merge(Config{Timeout: 60}) // --timeout 60, as main applies the flags
+
+	checks := []struct {
+		setting   string
+		got, want any
+	}{
+		{"token: profile env over CANVAS env over file", cfg.Token, "beta-env-token"},
+		{"base_url: file", cfg.BaseURL, "https://file.example.com/api/v1/"},
+		{"account_id: file over default", cfg.AccountID, 5},
+		{"rate_limit: env over file", cfg.RateLimit, 400},
+		{"timeout: flag over env over profile", cfg.Timeout, 60},
+		{"output_dir: file", cfg.OutputDir, "file-reports"},
+		{"profile", cfg.Profile, "beta"},
+	}
+	for _, c := range checks {
+		if c.got != c.want {
+			t.Errorf("%s = %v, want %v", c.setting, c.got, c.want)
+		}
+	}
+
+	t.Setenv("OUTPUT_DIR", "env-reports")
+	cfg, err = Load(path, "beta")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.OutputDir != "env-reports" || cfg.Timeout != 45 {
+		t.Errorf("output_dir = %q, timeout = %d; want OUTPUT_DIR and CANVAS_TIMEOUT to win over the file", cfg.OutputDir, cfg.Timeout)
+	}
+}
+
+func TestLoadDefaults(t *testing.T) {
+	clearEnv(t, "CANVAS", "BETA")
+	t.Chdir(t.TempDir()) // no config.yaml to read
+
+	cfg, err := Load("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	def := Default()
+	if cfg.Profile != DefaultProfile || cfg.AccountID != def.AccountID || cfg.RateLimit != def.RateLimit || cfg.Timeout != def.Timeout {
+		t.Errorf("got %+v, want the defaults for profile %s", cfg, DefaultProfile)
+	}
+
+	if _, err := Load(filepath.Join(t.TempDir(), "missing.yaml"), ""); err == nil {
+		t.Error("a config file that was given but is missing loaded without an error")
+	}
+}
+
+func TestLoadProfileMerge(t *testing.T) {
+	clearEnv(t, "CANVAS", "BETA", "PROD")
+	path := writeConfig(t, `
+default_profile: prod
+token: shared-token
+fallback_tokens: [shared-fallback]
+sis_patterns:
+  course: '\d{4}-\d{2}-[A-Z]+-\d{3}'
+experimental:
+  graphql: true
+maintenance:
+  - {start: 2025-01-04T00:00:00Z, end: 2025-01-04T06:00:00Z, reason: beta refresh}
+profiles:
+  prod:
+    base_url: https://prod.example.com/api/v1/
+    fallback_tokens: [prod-fallback-1, prod-fallback-2]
+    sis_patterns:
+      user: '\d{7}'
+    experimental:
+      graphql: false
+    maintenance:
+      - {start: 2025-01-11T00:00:00Z, end: 2025-01-11T02:00:00Z, reason: upgrade}
+  beta:
+    base_url: https://beta.example.com/api/v1/
+`)
+
+	cfg, err := Load(path, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Profile != "prod" || cfg.BaseURL != "https://prod.example.com/api/v1/" {
+		t.Errorf("profile %q with base_url %q, want default_profile prod", cfg.Profile, cfg.BaseURL)
+	}
+	if cfg.Token != "shared-token" {
+		t.Errorf("token = %q, want the top-level token", cfg.Token)
+	}
+	if !slices.Equal(cfg.FallbackTokens, []string{"prod-fallback-1", "prod-fallback-2"}) {
+		t.Errorf("fallback tokens = %q, want the profile's to replace the top-level ones", cfg.FallbackTokens)
+	}
+	if cfg.SISPatterns.Course == "" || cfg.SISPatterns.User != `\d{7}` {
+		t.Errorf("SIS patterns = %+v, want the top-level course and the profile's user pattern", cfg.SISPatterns)
+	}
+	if cfg.Enabled("graphql") {
+		t.Error("graphql is on, want the profile to switch it off")
+	}
+	if len(cfg.Maintenance) != 2 {
+		t.Errorf("got %d maintenance windows, want the top-level and the profile's", len(cfg.Maintenance))
+	}
+
+	cfg, err = Load(path, "beta")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.BaseURL != "https://beta.example.com/api/v1/" || !cfg.Enabled("graphql") || len(cfg.Maintenance) != 1 {
+		t.Errorf("beta profile got %+v, want its base_url and only the top-level settings", cfg)
+	}
+
+	if _, err := Load(path, "staging"); err == nil || !strings.Contains(err.Error(), "unknown profile") {
+		t.Errorf("err = %v, want an unknown profile error", err)
+	}
+	t.Setenv("STAGING_TOKEN", "staging-token")
+	if cfg, err := Load(path, "staging"); err != nil || cfg.Token != "staging-token" {
+		t.Errorf("profile from the environment: token %q, err %v", cfg.Token, err)
+	}
+}
+
+func TestLoadKeywordFlags(t *testing.T) {
+	clearEnv(t, "CANVAS", "BETA", "PROD")
+	path := writeConfig(t, `
+keyword_flags:
+  keywords: [hopeless, "can't go on"]
+  keywords_file: keywords.txt
+  operators: [counselor1]
+  output_dir: data/care
+profiles:
+  prod:
+    keyword_flags:
+      operators: [counselor2, counselor3]
+      audit_log: /var/log/care.log
+`)
+	t.Setenv("PROD_TOKEN", "prod-token")
+
+	cfg, err := Load(path, "prod")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.Merge(Config{Timeout: 30}) // flags have no keyword settings and leave them alone
+	kf := cfg.KeywordFlags
+	if !slices.Equal(kf.Keywords, []string{"hopeless", "can't go on"}) || kf.KeywordsFile != "keywords.txt" {
+		t.Errorf("keywords = %q from %q, want the top-level ones", kf.Keywords, kf.KeywordsFile)
+	}
+	if !slices.Equal(kf.Operators, []string{"counselor2", "counselor3"}) {
+		t.Errorf("operators = %q, want the profile's to replace the top-level ones", kf.Operators)
+	}
+	if kf.AuditLog != "/var/log/care.log" || kf.OutputDir != "data/care" {
+		t.Errorf("audit log %q and output dir %q, want the profile's log and the top-level directory", kf.AuditLog, kf.OutputDir)
+	}
+
+	// the default profile has no keyword_flags and keeps the top-level settings
+	cfg, err = Load(path, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(cfg.KeywordFlags.Operators, []string{"counselor1"}) {
+		t.Errorf("beta operators = %q, want the top-level ones", cfg.KeywordFlags.Operators)
+	}
+}
+
+func TestLoadRejectsInvalidSettings(t *testing.T) {
+	clearEnv(t, "CANVAS", "BETA")
+	tests := []struct {
+		name, yaml, env, want string
+	}{
+		{"maintenance ends before it starts", "maintenance:\n  - {start: 2025-01-04T06:00:00Z, end: 2025-01-04T00:00:00Z, reason: refresh}\n", "", "must end after it starts"},
+		{"unknown feature", "experimental: {graphq: true}\n", "", "unknown experimental feature"},
+		{"unknown feature in the environment", "", "BETA_EXPERIMENTAL=graphq", "BETA_EXPERIMENTAL"},
+		{"bad number in the environment", "", "BETA_RATE_LIMIT=fast", "invalid BETA_RATE_LIMIT"},
+		{"bad yaml", "profiles: [beta\n", "", "error parsing config file"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if name, value, ok := strings.Cut(tt.env, "="); ok {
+				t.Setenv(name, value)
+			}
+			_, err := Load(writeConfig(t, tt.yaml), "")
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("err = %v, want one mentioning %q", err, tt.want)
+			}
+		})
+	}
+}
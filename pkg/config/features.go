@@ -0,0 +1,58 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Features switches experimental subsystems on or off, set under experimental: in the config file,
+// e.g. experimental: {graphql: true}. A profile's switches override the top-level ones one by one.
+type Features map[string]bool
+
+// KnownFeatures are the experimental subsystems and what they do. Every feature is off until it is
+// switched on, so a new subsystem can ship in the same build as the stable ones and be tried in one
+// environment first.
+var KnownFeatures = map[string]string{
+	"graphql": "queries through the Canvas GraphQL API",
+}
+
+// Enabled reports whether an experimental feature is switched on.
+func (c Config) Enabled(feature string) bool {
+	return c.Experimental[feature]
+}
+
+// validate rejects unknown feature names, which are most likely typos that would leave a feature off.
+func (f Features) validate() error {
+	for name := range f {
+		if _, ok := KnownFeatures[name]; !ok {
+			return fmt.Errorf("unknown experimental feature %q (known: %s)", name, strings.Join(FeatureNames(), ", "))
+		}
+	}
+	return nil
+}
+
+// FeatureNames returns the names of the known features, sorted.
+func FeatureNames() []string {
+	names := make([]string, 0, len(KnownFeatures))
+	for name := range KnownFeatures {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// parseFeatures reads a comma separated list of features to switch on; a name with a leading "-"
+// switches the feature off, e.g. "-graphql".
+func parseFeatures(s string) Features {
+	features := make(Features)
+	for _, name := range strings.Split(s, ",") {
+		name = strings.TrimSpace(name)
+		if off, ok := strings.CutPrefix(name, "-"); ok {
+			features[off] = false
+		} else if name != "" {
+			features[name] = true
+		}
+	}
+	return features
+}
@@ -0,0 +1,192 @@
+// Package gradebook builds a course's full gradebook in the layout of Canvas' own gradebook export.
+package gradebook
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/coraxwolf/CCTA_3-4/pkg/canvas"
+	"github.com/coraxwolf/CCTA_3-4/pkg/report"
+)
+
+// Gradebook is one course's grades: a column per assignment and a row per student.
+type Gradebook struct {
+	CourseID    int
+	Assignments []canvas.Assignment // in the gradebook's column order
+	Students    []Student           // sorted by sortable name
+}
+
+// Student is a gradebook row.
+type Student struct {
+	Name      string // sortable name, "Last, First"
+	ID        int
+	SISUserID string
+	LoginID   string
+	Sections  []string
+	Scores    map[int]string // by assignment ID: the score, "EX" when excused, or empty
+	Grades    canvas.EnrollmentGrades
+}
+
+// Options narrows the gradebook.
+type Options struct {
+	States        []string // student enrollment states; Canvas defaults to active and invited
+	PublishedOnly bool     // leave out unpublished assignments
+}
+
+// Build fetches a course's assignments, sections, student enrollments, and submissions concurrently
+// and assembles the gradebook. Test students are left out, as in Canvas' export.
+func Build(ctx context.Context, api *canvas.APIManager, courseID int, opts Options) (*Gradebook, error) {
+	var (
+		assignments []canvas.Assignment
+		sections    []canvas.Section
+		enrollments []canvas.Enrollment
+		submissions []canvas.Submission
+	)
+	fetches := []func() error{
+		func() (err error) {
+			assignments, err = api.Assignments().WithContext(ctx).ListAssignments(courseID)
+			return err
+		},
+		func() (err error) {
+			sections, err = api.Sections().WithContext(ctx).ListCourseSections(courseID)
+			return err
+		},
+		func() (err error) {
+			list := canvas.EnrollmentListOptions{Types: []string{"StudentEnrollment"}, States: opts.States}
+			enrollments, err = api.Enrollments().WithContext(ctx).ListCourseEnrollments(courseID, list)
+			return err
+		},
+		func() (err error) {
+			submissions, err = api.Submissions().WithContext(ctx).ListStudentSubmissions(courseID, canvas.SubmissionListOptions{})
+			return err
+		},
+	}
+	errs := make([]error, len(fetches))
+	var wg sync.WaitGroup
+	for i, fetch := range fetches {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = fetch()
+		}()
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return nil, fmt.Errorf("error building gradebook for course %d: %w", courseID, err)
+		}
+	}
+
+	gb := &Gradebook{CourseID: courseID}
+	for _, a := range assignments {
+		if opts.PublishedOnly && !a.Published {
+			continue
+		}
+		gb.Assignments = append(gb.Assignments, a)
+	}
+	sectionNames := make(map[int]string, len(sections))
+	for _, s := range sections {
+		sectionNames[s.ID] = s.Name
+	}
+	// A student has an enrollment per section, so rows are merged by user
+	rows := make(map[int]*Student)
+	for _, e := range enrollments {
+		row, ok := rows[e.UserID]
+		if !ok {
+			row = &Student{
+				Name:      e.User.SortableName,
+				ID:        e.UserID,
+				SISUserID: e.User.SISUserID,
+				LoginID:   e.User.LoginID,
+				Scores:    make(map[int]string),
+			}
+			if row.Name == "" {
+				row.Name = e.User.Name
+			}
+			if e.Grades != nil {
+				row.Grades = *e.Grades
+			}
+			rows[e.UserID] = row
+		}
+		if name, ok := sectionNames[e.SectionID]; ok && !slices.Contains(row.Sections, name) {
+			row.Sections = append(row.Sections, name)
+		}
+	}
+	for _, s := range submissions {
+		row, ok := rows[s.UserID]
+		if !ok {
+			continue // a test student, or an enrollment in a state that wasn't asked for
+		}
+		switch {
+		case s.Excused:
+			row.Scores[s.AssignmentID] = "EX"
+		case s.Score != nil:
+			row.Scores[s.AssignmentID] = formatScore(s.Score)
+		}
+	}
+	for _, row := range rows {
+		gb.Students = append(gb.Students, *row)
+	}
+	sort.Slice(gb.Students, func(i, j int) bool {
+		a, b := strings.ToLower(gb.Students[i].Name), strings.ToLower(gb.Students[j].Name)
+		if a != b {
+			return a < b
+		}
+		return gb.Students[i].ID < gb.Students[j].ID
+	})
+	return gb, nil
+}
+
+// Table lays the gradebook out like Canvas' CSV export: the student columns, a "Name (id)" column
+// per assignment, then the course totals. The first record is the Points Possible row. The letter
+// grade columns are only added when the course uses a grading scheme.
+func (gb *Gradebook) Table() report.Table {
+	header := []string{"Student", "ID", "SIS User ID", "SIS Login ID", "Section"}
+	points := []string{"    Points Possible", "", "", "", ""}
+	for _, a := range gb.Assignments {
+		header = append(header, fmt.Sprintf("%s (%d)", a.Name, a.ID))
+		points = append(points, formatScore(&a.PointsPossible))
+	}
+	totals := []string{"Current Score", "Unposted Current Score", "Final Score", "Unposted Final Score"}
+	letters := false
+	for _, s := range gb.Students {
+		if s.Grades.CurrentGrade != "" || s.Grades.FinalGrade != "" {
+			letters = true
+			break
+		}
+	}
+	if letters {
+		totals = append(totals, "Current Grade", "Unposted Current Grade", "Final Grade", "Unposted Final Grade")
+	}
+	header = append(header, totals...)
+	for range totals {
+		points = append(points, "(read only)")
+	}
+
+	records := [][]string{points}
+	for _, s := range gb.Students {
+		record := []string{s.Name, strconv.Itoa(s.ID), s.SISUserID, s.LoginID, strings.Join(s.Sections, ", ")}
+		for _, a := range gb.Assignments {
+			record = append(record, s.Scores[a.ID])
+		}
+		g := s.Grades
+		record = append(record, formatScore(g.CurrentScore), formatScore(g.UnpostedCurrentScore), formatScore(g.FinalScore), formatScore(g.UnpostedFinalScore))
+		if letters {
+			record = append(record, g.CurrentGrade, g.UnpostedCurrentGrade, g.FinalGrade, g.UnpostedFinalGrade)
+		}
+		records = append(records, record)
+	}
+	return report.Table{Header: header, Records: records}
+}
+
+func formatScore(score *float64) string {
+	if score == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*score, 'f', -1, 64)
+}
@@ -0,0 +1,105 @@
+// Package ics writes iCalendar (RFC 5545) files that calendar apps can import or subscribe to.
+package ics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Calendar is a published calendar of events.
+type Calendar struct {
+	Name   string // shown by calendar apps as the calendar's name
+	Events []Event
+	Stamp  time.Time // DTSTAMP of every event; the current time when zero
+}
+
+// Event is one calendar entry. An End before Start is written as Start, for point-in-time
+// entries such as due dates.
+type Event struct {
+	UID         string // stable across files so re-imports update the event instead of adding one
+	Summary     string
+	Description string
+	Location    string
+	URL         string
+	Start       time.Time
+	End         time.Time
+}
+
+// Encode writes the calendar as an iCalendar file. Times are written in UTC.
+func (c Calendar) Encode(out io.Writer) error {
+	w := bufio.NewWriter(out)
+	stamp := c.Stamp
+	if stamp.IsZero() {
+		stamp = time.Now()
+	}
+	line := func(name, value string) {
+		writeFolded(w, name+":"+value)
+	}
+	line("BEGIN", "VCALENDAR")
+	line("VERSION", "2.0")
+	line("PRODID", "-//CCTA//Canvas calendar export//EN")
+	line("CALSCALE", "GREGORIAN")
+	line("METHOD", "PUBLISH")
+	if c.Name != "" {
+		line("X-WR-CALNAME", escape(c.Name))
+	}
+	for _, e := range c.Events {
+		end := e.End
+		if end.Before(e.Start) {
+			end = e.Start
+		}
+		line("BEGIN", "VEVENT")
+		line("UID", escape(e.UID))
+		line("DTSTAMP", utc(stamp))
+		line("DTSTART", utc(e.Start))
+		line("DTEND", utc(end))
+		line("SUMMARY", escape(e.Summary))
+		if e.Description != "" {
+			line("DESCRIPTION", escape(e.Description))
+		}
+		if e.Location != "" {
+			line("LOCATION", escape(e.Location))
+		}
+		if e.URL != "" {
+			line("URL", e.URL)
+		}
+		line("END", "VEVENT")
+	}
+	line("END", "VCALENDAR")
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("error writing calendar: %w", err)
+	}
+	return nil
+}
+
+func utc(t time.Time) string {
+	return t.UTC().Format("20060102T150405Z")
+}
+
+var escaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`, "\r", `\n`)
+
+func escape(s string) string {
+	return escaper.Replace(s)
+}
+
+// writeFolded writes a content line, folding it into lines of at most 75 octets without splitting
+// a UTF-8 character. Continuation lines start with a space.
+func writeFolded(w *bufio.Writer, s string) {
+	limit := 75
+	for len(s) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(s[cut]) {
+			cut--
+		}
+		w.WriteString(s[:cut])
+		w.WriteString("\r\n ")
+		s = s[cut:]
+		limit = 74 // the leading space counts
+	}
+	w.WriteString(s)
+	w.WriteString("\r\n")
+}
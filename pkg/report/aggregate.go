@@ -0,0 +1,155 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Table is a report that is already flattened into string records, such as the result of Aggregate.
+// It can be passed to the Writer anywhere a slice of structs is accepted.
+type Table struct {
+	Header  []string
+	Records [][]string
+}
+
+// MarshalJSON encodes the table as a list of objects with the keys in header order.
+func (t Table) MarshalJSON() ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteByte('[')
+	for i, record := range t.Records {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteByte('{')
+		for j, name := range t.Header {
+			if j > 0 {
+				b.WriteByte(',')
+			}
+			key, _ := json.Marshal(name)
+			b.Write(key)
+			b.WriteByte(':')
+			value := ""
+			if j < len(record) {
+				value = record[j]
+			}
+			if isNumber(value) {
+				b.WriteString(value)
+			} else {
+				v, _ := json.Marshal(value)
+				b.Write(v)
+			}
+		}
+		b.WriteByte('}')
+	}
+	b.WriteByte(']')
+	return b.Bytes(), nil
+}
+
+// Aggregation configures the aggregate-only export mode used for institutional research requests.
+// Only counts (and the means of the metric columns) per group are written, never individual rows.
+type Aggregation struct {
+	GroupBy     []string // columns to group by; no columns gives a single total
+	Metrics     []string // numeric columns to average per group
+	MinCellSize int      // groups with fewer rows, and means of fewer values, than this are suppressed
+}
+
+// Aggregate groups the rows and returns one record per group with a count column and a <metric>_mean
+// column per metric. Groups smaller than MinCellSize are left out, and a mean of fewer than
+// MinCellSize non-blank values is left blank, since it could be one person's value in a large group.
+// The number of groups and means suppressed is returned.
+func Aggregate(rows any, agg Aggregation) (Table, int, error) {
+	if agg.MinCellSize < 1 {
+		return Table{}, 0, fmt.Errorf("minimum cell size must be at least 1")
+	}
+	header, records, err := Records(rows)
+	if err != nil {
+		return Table{}, 0, err
+	}
+	index := make(map[string]int, len(header))
+	for i, name := range header {
+		index[name] = i
+	}
+	lookup := func(names []string) ([]int, error) {
+		cols := make([]int, len(names))
+		for i, name := range names {
+			col, ok := index[name]
+			if !ok {
+				return nil, fmt.Errorf("unknown column %q (columns: %s)", name, strings.Join(header, ", "))
+			}
+			cols[i] = col
+		}
+		return cols, nil
+	}
+	groupCols, err := lookup(agg.GroupBy)
+	if err != nil {
+		return Table{}, 0, err
+	}
+	metricCols, err := lookup(agg.Metrics)
+	if err != nil {
+		return Table{}, 0, err
+	}
+
+	type group struct {
+		key    []string
+		count  int
+		sums   []float64
+		counts []int // values seen per metric, since blanks are skipped
+	}
+	groups := make(map[string]*group)
+	for _, record := range records {
+		key := make([]string, len(groupCols))
+		for i, col := range groupCols {
+			key[i] = record[col]
+		}
+		id := strings.Join(key, "\x00")
+		g, ok := groups[id]
+		if !ok {
+			g = &group{key: key, sums: make([]float64, len(metricCols)), counts: make([]int, len(metricCols))}
+			groups[id] = g
+		}
+		g.count++
+		for i, col := range metricCols {
+			if record[col] == "" {
+				continue
+			}
+			v, err := strconv.ParseFloat(record[col], 64)
+			if err != nil {
+				return Table{}, 0, fmt.Errorf("metric column %q has a non-numeric value %q", agg.Metrics[i], record[col])
+			}
+			g.sums[i] += v
+			g.counts[i]++
+		}
+	}
+
+	table := Table{Header: append(append([]string{}, agg.GroupBy...), "count")}
+	for _, name := range agg.Metrics {
+		table.Header = append(table.Header, name+"_mean")
+	}
+	suppressed := 0
+	for _, g := range groups {
+		if g.count < agg.MinCellSize {
+			suppressed++
+			continue
+		}
+		record := append(append([]string{}, g.key...), strconv.Itoa(g.count))
+		for i := range metricCols {
+			mean := ""
+			switch {
+			case g.counts[i] >= agg.MinCellSize:
+				mean = strconv.FormatFloat(g.sums[i]/float64(g.counts[i]), 'f', 2, 64)
+			case g.counts[i] > 0:
+				suppressed++
+			}
+			record = append(record, mean)
+		}
+		table.Records = append(table.Records, record)
+	}
+	sort.Slice(table.Records, func(i, j int) bool {
+		return strings.Join(table.Records[i][:len(groupCols)], "\x00") < strings.Join(table.Records[j][:len(groupCols)], "\x00")
+	})
+	return table, suppressed, nil
+}
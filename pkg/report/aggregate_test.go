@@ -0,0 +1,74 @@
+package report
+
+import (
+	"reflect"
+	"testing"
+)
+
+type gradeRow struct {
+	Dept  string   `csv:"dept"`
+	Score *float64 `csv:"score"`
+}
+
+func score(v float64) *float64 { return &v }
+
+func TestAggregateSuppressesSmallGroups(t *testing.T) {
+	var rows []gradeRow
+	for range 3 {
+		rows = append(rows, gradeRow{Dept: "BIO", Score: score(80)})
+	}
+	rows = append(rows, gradeRow{Dept: "CHM", Score: score(95)}) // one student
+
+	table, suppressed, err := Aggregate(rows, Aggregation{GroupBy: []string{"dept"}, Metrics: []string{"score"}, MinCellSize: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]string{{"BIO", "3", "80.00"}}
+	if !reflect.DeepEqual(table.Records, want) {
+		t.Errorf("records = %v, want %v", table.Records, want)
+	}
+	if suppressed != 1 {
+		t.Errorf("suppressed = %d, want 1", suppressed)
+	}
+}
+
+// A large group can still have a metric only one row has, whose mean is that row's value.
+func TestAggregateSuppressesSparseMeans(t *testing.T) {
+	rows := []gradeRow{{Dept: "BIO", Score: score(42)}}
+	for range 9 {
+		rows = append(rows, gradeRow{Dept: "BIO"})
+	}
+
+	table, suppressed, err := Aggregate(rows, Aggregation{GroupBy: []string{"dept"}, Metrics: []string{"score"}, MinCellSize: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]string{{"BIO", "10", ""}}
+	if !reflect.DeepEqual(table.Records, want) {
+		t.Errorf("records = %v, want %v", table.Records, want)
+	}
+	if suppressed != 1 {
+		t.Errorf("suppressed = %d, want 1 for the mean", suppressed)
+	}
+}
+
+func TestAggregateTotal(t *testing.T) {
+	rows := []gradeRow{{Dept: "BIO", Score: score(70)}, {Dept: "CHM", Score: score(90)}}
+
+	table, suppressed, err := Aggregate(rows, Aggregation{Metrics: []string{"score"}, MinCellSize: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"count", "score_mean"}; !reflect.DeepEqual(table.Header, want) {
+		t.Errorf("header = %v, want %v", table.Header, want)
+	}
+	if want := [][]string{{"2", "80.00"}}; !reflect.DeepEqual(table.Records, want) || suppressed != 0 {
+		t.Errorf("records = %v (%d suppressed), want %v", table.Records, suppressed, want)
+	}
+}
+
+func TestAggregateRejectsUnknownColumns(t *testing.T) {
+	if _, _, err := Aggregate([]gradeRow{}, Aggregation{GroupBy: []string{"term"}, MinCellSize: 1}); err == nil {
+		t.Error("grouping by an unknown column succeeded")
+	}
+}
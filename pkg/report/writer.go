@@ -0,0 +1,286 @@
+package report
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"time"
+)
+
+type Format string
+
+const (
+	FormatCSV  Format = "csv"
+	FormatJSON Format = "json"
+	FormatXLSX Format = "xlsx"
+)
+
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(strings.ToLower(s)); f {
+	case FormatCSV, FormatJSON, FormatXLSX:
+		return f, nil
+	}
+	return "", fmt.Errorf("unknown report format %q (expected csv, json, or xlsx)", s)
+}
+
+// ParseDelimiter accepts comma, semicolon, tab, or a single character.
+func ParseDelimiter(s string) (rune, error) {
+	switch strings.ToLower(s) {
+	case "", "comma", ",":
+		return ',', nil
+	case "semicolon", ";":
+		return ';', nil
+	case "tab", "\\t", "\t":
+		return '\t', nil
+	}
+	if r := []rune(s); len(r) == 1 && r[0] != '"' && r[0] != '\r' && r[0] != '\n' {
+		return r[0], nil
+	}
+	return 0, fmt.Errorf("invalid CSV delimiter %q (expected comma, semicolon, tab, or one character)", s)
+}
+
+// timeFormats are the named date formats. Formats other than rfc3339 are written in local time.
+var timeFormats = map[string]string{
+	"rfc3339": time.RFC3339,
+	"iso":     "2006-01-02 15:04:05",
+	"eu":      "02.01.2006 15:04",
+	"uk":      "02/01/2006 15:04",
+	"us":      "01/02/2006 15:04",
+}
+
+// ParseTimeFormat returns the Go time layout for a named format (rfc3339, iso, eu, uk, or us)
+// or a Go layout such as "2006-01-02".
+func ParseTimeFormat(s string) (string, error) {
+	if s == "" {
+		return time.RFC3339, nil
+	}
+	if layout, ok := timeFormats[strings.ToLower(s)]; ok {
+		return layout, nil
+	}
+	if strings.Contains(s, "2006") || strings.Contains(s, "06") {
+		return s, nil
+	}
+	return "", fmt.Errorf("invalid date format %q (expected rfc3339, iso, eu, uk, us, or a Go layout such as 2006-01-02)", s)
+}
+
+// Writer writes a slice of structs to a report file in Dir. Columns come from the csv struct tag,
+// then the json tag, then the field name; fields tagged "-" are skipped.
+//
+// When Aggregation is set every report is aggregated before it is written, so no individual rows
+// leave the writer. Suppressed holds the number of groups and means left out of the last report.
+//
+// Delimiter and BOM only apply to CSV, and TimeFormat to CSV and XLSX; JSON always uses RFC 3339.
+// A BOM and a semicolon delimiter make CSV files open correctly in Excel set up for European locales.
+type Writer struct {
+	Dir         string
+	Format      Format
+	Aggregation *Aggregation
+	Suppressed  int
+	Delimiter   rune        // CSV field separator; comma when zero
+	BOM         bool        // start CSV files with a UTF-8 byte order mark
+	TimeFormat  string      // Go layout for times; RFC 3339 when empty
+	FileMode    os.FileMode // permissions of the report file; 0644 when zero
+}
+
+func NewWriter(dir string, format Format) *Writer {
+	return &Writer{Dir: dir, Format: format}
+}
+
+// Write writes rows (a slice of structs or struct pointers) to Dir/name.<format> and returns the file path.
+func (w *Writer) Write(name string, rows any) (string, error) {
+	if err := os.MkdirAll(w.Dir, 0755); err != nil {
+		return "", fmt.Errorf("error creating directory %s: %w", w.Dir, err)
+	}
+	outputFile := filepath.Join(w.Dir, name+"."+string(w.Format))
+	mode := w.FileMode
+	if mode == 0 {
+		mode = 0644
+	}
+	of, err := os.OpenFile(outputFile, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode)
+	if err != nil {
+		return "", fmt.Errorf("error opening output file %s: %w", outputFile, err)
+	}
+	defer of.Close()
+	// OpenFile keeps the mode of a file that already exists, so set it before anything is written.
+	if err := of.Chmod(mode); err != nil {
+		return "", fmt.Errorf("error setting permissions of %s: %w", outputFile, err)
+	}
+	if err := w.Encode(of, name, rows); err != nil {
+		return "", fmt.Errorf("error writing %s: %w", outputFile, err)
+	}
+	if err := of.Close(); err != nil {
+		return "", fmt.Errorf("error closing %s: %w", outputFile, err)
+	}
+	return outputFile, nil
+}
+
+// Encode writes rows to out in the writer's format. name is used as the XLSX sheet name.
+func (w *Writer) Encode(out io.Writer, name string, rows any) error {
+	if w.Aggregation != nil {
+		table, suppressed, err := Aggregate(rows, *w.Aggregation)
+		if err != nil {
+			return fmt.Errorf("error aggregating report: %w", err)
+		}
+		rows, w.Suppressed = table, suppressed
+	}
+	if w.Format == FormatJSON {
+		return encodeJSON(out, rows)
+	}
+	header, records, err := records(rows, w.TimeFormat)
+	if err != nil {
+		return err
+	}
+	switch w.Format {
+	case FormatCSV:
+		return w.encodeCSV(out, header, records)
+	case FormatXLSX:
+		return encodeXLSX(out, name, header, records)
+	}
+	return fmt.Errorf("unknown report format %q", w.Format)
+}
+
+func (w *Writer) encodeCSV(out io.Writer, header []string, records [][]string) error {
+	if w.BOM {
+		if _, err := io.WriteString(out, "\uFEFF"); err != nil {
+			return fmt.Errorf("error writing BOM to CSV: %w", err)
+		}
+	}
+	writer := csv.NewWriter(out)
+	if w.Delimiter != 0 {
+		writer.Comma = w.Delimiter
+	}
+	if err := writer.Write(header); err != nil {
+		return fmt.Errorf("error writing header to CSV: %w", err)
+	}
+	if err := writer.WriteAll(records); err != nil {
+		return fmt.Errorf("error writing records to CSV: %w", err)
+	}
+	return nil
+}
+
+func encodeJSON(out io.Writer, rows any) error {
+	if _, _, err := Records(rows); err != nil {
+		return err // same validation as the other formats
+	}
+	data, err := json.MarshalIndent(rows, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error encoding JSON report: %w", err)
+	}
+	_, err = out.Write(append(data, '\n'))
+	return err
+}
+
+type column struct {
+	name  string
+	index []int
+}
+
+func columns(t reflect.Type) []column {
+	var cols []column
+	for _, field := range reflect.VisibleFields(t) {
+		if !field.IsExported() || field.Anonymous {
+			continue
+		}
+		name := tagName(field.Tag.Get("csv"))
+		if name == "" {
+			name = tagName(field.Tag.Get("json"))
+		}
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		cols = append(cols, column{name: name, index: field.Index})
+	}
+	return cols
+}
+
+func tagName(tag string) string {
+	name, _, _ := strings.Cut(tag, ",")
+	return name
+}
+
+// Records flattens rows (a slice of structs or struct pointers, or a Table) into a header and string records.
+// Times are written as RFC 3339.
+func Records(rows any) ([]string, [][]string, error) {
+	return records(rows, time.RFC3339)
+}
+
+func records(rows any, timeFormat string) ([]string, [][]string, error) {
+	if timeFormat == "" {
+		timeFormat = time.RFC3339
+	}
+	if t, ok := rows.(Table); ok {
+		return t.Header, t.Records, nil
+	}
+	v := reflect.ValueOf(rows)
+	if v.Kind() != reflect.Slice {
+		return nil, nil, fmt.Errorf("report rows must be a slice, got %T", rows)
+	}
+	elem := v.Type().Elem()
+	if elem.Kind() == reflect.Pointer {
+		elem = elem.Elem()
+	}
+	if elem.Kind() != reflect.Struct {
+		return nil, nil, fmt.Errorf("report rows must be structs, got %s", elem)
+	}
+	cols := columns(elem)
+	header := make([]string, len(cols))
+	for i, col := range cols {
+		header[i] = col.name
+	}
+	records := make([][]string, 0, v.Len())
+	for i := 0; i < v.Len(); i++ {
+		row := reflect.Indirect(v.Index(i))
+		record := make([]string, len(cols))
+		if row.IsValid() {
+			for j, col := range cols {
+				record[j] = formatValue(row.FieldByIndex(col.index), timeFormat)
+			}
+		}
+		records = append(records, record)
+	}
+	return header, records, nil
+}
+
+func formatValue(v reflect.Value, timeFormat string) string {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return ""
+		}
+		v = v.Elem()
+	}
+	if t, ok := v.Interface().(time.Time); ok {
+		if t.IsZero() {
+			return ""
+		}
+		if timeFormat != time.RFC3339 {
+			t = t.Local() // the named formats have no zone
+		}
+		return t.Format(timeFormat)
+	}
+	if s, ok := v.Interface().(fmt.Stringer); ok {
+		return s.String()
+	}
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array:
+		parts := make([]string, v.Len())
+		for i := range parts {
+			parts[i] = formatValue(v.Index(i), timeFormat)
+		}
+		return strings.Join(parts, "; ")
+	case reflect.Map, reflect.Struct:
+		data, err := json.Marshal(v.Interface())
+		if err != nil {
+			return fmt.Sprint(v.Interface())
+		}
+		return string(data)
+	}
+	return fmt.Sprint(v.Interface())
+}
@@ -0,0 +1,115 @@
+package report
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type personRow struct {
+	ID   string `csv:"id"`
+	Name string `csv:"name"`
+}
+
+func TestWriteFileMode(t *testing.T) {
+	dir := t.TempDir()
+	w := NewWriter(dir, FormatCSV)
+	w.FileMode = 0600
+	// An existing file keeps its mode on open, so it has to be changed as well.
+	existing := filepath.Join(dir, "flags.csv")
+	if err := os.WriteFile(existing, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := w.Write("flags", []personRow{{ID: "1", Name: "Ada"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if file != existing {
+		t.Errorf("wrote %s, want %s", file, existing)
+	}
+	info, err := os.Stat(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mode := info.Mode().Perm(); mode != 0600 {
+		t.Errorf("mode = %o, want 600", mode)
+	}
+}
+
+// numberTable has values that look like numbers but are not valid JSON numbers.
+var numberTable = Table{
+	Header:  []string{"value"},
+	Records: [][]string{{"42"}, {"-1.5"}, {"0"}, {"0.25"}, {"-05"}, {"5."}, {".5"}, {"007"}, {"-"}, {"1-2"}, {""}},
+}
+
+func TestEncodeJSONNumbers(t *testing.T) {
+	var b bytes.Buffer
+	if err := NewWriter("", FormatJSON).Encode(&b, "numbers", numberTable); err != nil {
+		t.Fatal(err)
+	}
+	if !json.Valid(b.Bytes()) {
+		t.Fatalf("invalid JSON:\n%s", b.String())
+	}
+	var got []map[string]any
+	if err := json.Unmarshal(b.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	want := []any{42.0, -1.5, 0.0, 0.25, "-05", "5.", ".5", "007", "-", "1-2", ""}
+	if len(got) != len(want) {
+		t.Fatalf("got %d rows, want %d", len(got), len(want))
+	}
+	for i, w := range want {
+		if got[i]["value"] != w {
+			t.Errorf("row %d = %#v, want %#v", i, got[i]["value"], w)
+		}
+	}
+}
+
+func TestEncodeCSV(t *testing.T) {
+	w := NewWriter("", FormatCSV)
+	w.Delimiter = ';'
+	var b bytes.Buffer
+	if err := w.Encode(&b, "people", []personRow{{ID: "007", Name: "Bond; James"}, {ID: "2", Name: "Ada"}}); err != nil {
+		t.Fatal(err)
+	}
+	want := "id;name\n007;\"Bond; James\"\n2;Ada\n"
+	if b.String() != want {
+		t.Errorf("got %q, want %q", b.String(), want)
+	}
+}
+
+func TestEncodeXLSXNumbers(t *testing.T) {
+	var b bytes.Buffer
+	if err := NewWriter("", FormatXLSX).Encode(&b, "numbers", numberTable); err != nil {
+		t.Fatal(err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(b.Bytes()), int64(b.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := zr.Open("xl/worksheets/sheet1.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := io.ReadAll(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sheet := string(data)
+	for _, n := range []string{"42", "-1.5", "0", "0.25"} {
+		if !strings.Contains(sheet, "<v>"+n+"</v>") {
+			t.Errorf("%s is not a number cell", n)
+		}
+	}
+	for _, s := range []string{"-05", "5.", ".5", "007", "1-2"} {
+		if !strings.Contains(sheet, `<t xml:space="preserve">`+s+"</t>") {
+			t.Errorf("%s is not a text cell", s)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package report
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// encodeXLSX writes a single-sheet workbook. Numeric cells are stored as numbers, everything else
+// as inline strings, which keeps the file valid without a shared strings table.
+func encodeXLSX(out io.Writer, sheetName string, header []string, records [][]string) error {
+	zw := zip.NewWriter(out)
+	parts := map[string]string{
+		"[Content_Types].xml":        xlsxContentTypes,
+		"_rels/.rels":                xlsxRootRels,
+		"xl/_rels/workbook.xml.rels": xlsxWorkbookRels,
+		"xl/workbook.xml":            fmt.Sprintf(xlsxWorkbook, xmlEscape(sheetTitle(sheetName))),
+		"xl/worksheets/sheet1.xml":   sheetXML(header, records),
+	}
+	// Write the parts in a fixed order so the output is reproducible
+	for _, name := range []string{"[Content_Types].xml", "_rels/.rels", "xl/workbook.xml", "xl/_rels/workbook.xml.rels", "xl/worksheets/sheet1.xml"} {
+		f, err := zw.Create(name)
+		if err != nil {
+			return fmt.Errorf("error creating %s in workbook: %w", name, err)
+		}
+		if _, err := f.Write([]byte(parts[name])); err != nil {
+			return fmt.Errorf("error writing %s in workbook: %w", name, err)
+		}
+	}
+	return zw.Close()
+}
+
+func sheetXML(header []string, records [][]string) string {
+	var b strings.Builder
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>`)
+	b.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+	writeRow(&b, 1, header, false)
+	for i, record := range records {
+		writeRow(&b, i+2, record, true)
+	}
+	b.WriteString(`</sheetData></worksheet>`)
+	return b.String()
+}
+
+func writeRow(b *strings.Builder, rowNum int, values []string, numbers bool) {
+	fmt.Fprintf(b, `<row r="%d">`, rowNum)
+	for col, value := range values {
+		ref := columnName(col) + strconv.Itoa(rowNum)
+		if numbers && isNumber(value) {
+			fmt.Fprintf(b, `<c r="%s"><v>%s</v></c>`, ref, value)
+		} else {
+			fmt.Fprintf(b, `<c r="%s" t="inlineStr"><is><t xml:space="preserve">%s</t></is></c>`, ref, xmlEscape(value))
+		}
+	}
+	b.WriteString(`</row>`)
+}
+
+// numberPattern matches a plain JSON number without an exponent. A leading zero is only allowed
+// before the decimal point, so IDs like 007 stay text, and 5. or .5 are not numbers.
+var numberPattern = regexp.MustCompile(`^-?(0|[1-9][0-9]*)(\.[0-9]+)?$`)
+
+// isNumber reports whether a cell can be written as a number in XLSX and unquoted in JSON.
+func isNumber(value string) bool {
+	return numberPattern.MatchString(value)
+}
+
+// columnName converts a zero based index to a spreadsheet column (0 -> A, 26 -> AA).
+func columnName(i int) string {
+	name := ""
+	for i++; i > 0; i = (i - 1) / 26 {
+		name = string(rune('A'+(i-1)%26)) + name
+	}
+	return name
+}
+
+// sheetTitle trims the name to Excel's 31 character limit and removes characters it rejects.
+func sheetTitle(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`[]:*?/\`, r) {
+			return '_'
+		}
+		return r
+	}, name)
+	if r := []rune(name); len(r) > 31 {
+		name = string(r[:31])
+	}
+	if name == "" {
+		name = "Report"
+	}
+	return name
+}
+
+func xmlEscape(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+const xlsxContentTypes = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types"><Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/><Default Extension="xml" ContentType="application/xml"/><Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/><Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/></Types>`
+
+const xlsxRootRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/></Relationships>`
+
+const xlsxWorkbookRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/></Relationships>`
+
+const xlsxWorkbook = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets><sheet name="%s" sheetId="1" r:id="rId1"/></sheets></workbook>`